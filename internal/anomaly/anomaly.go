@@ -0,0 +1,93 @@
+// Package anomaly finds unusual readings in a sensor's stream. A detector
+// looks at a reading against the readings recorded before it and reports a
+// finding when the reading stands out: far from the recent mean (zscore),
+// stuck at the same value (flatline), jumping away from the recent median
+// (spike) or changing faster than allowed (rate_of_change).
+package anomaly
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Detector types.
+const (
+	TypeZScore       = "zscore"
+	TypeFlatline     = "flatline"
+	TypeSpike        = "spike"
+	TypeRateOfChange = "rate_of_change"
+)
+
+// Types lists the built-in detector types.
+var Types = []string{TypeZScore, TypeFlatline, TypeSpike, TypeRateOfChange}
+
+// Point is a reading as detectors see it: its effective value and when it
+// was recorded.
+type Point struct {
+	Value float64
+	At    time.Time
+}
+
+// Finding describes an anomalous reading. Score is how far past the
+// detector's threshold the reading went, in the detector's own measure;
+// Expected is the value the detector expected, when it has one.
+type Finding struct {
+	Score    float64
+	Expected *float64
+	Message  string
+}
+
+type Detector interface {
+	// Window is the number of readings before the current one Detect needs.
+	Window() int
+	// Detect checks current against history, the readings recorded before
+	// it, oldest first. History may be shorter than Window.
+	Detect(history []Point, current Point) *Finding
+}
+
+// New builds a detector of the given type from its stored parameters and
+// checks them.
+func New(kind string, params map[string]any) (Detector, error) {
+	switch kind {
+	case TypeZScore:
+		detector := &ZScore{WindowSize: 30, Threshold: 3, MinSamples: 10}
+		if err := decodeParams(params, detector); err != nil {
+			return nil, err
+		}
+		return detector, detector.validate()
+	case TypeFlatline:
+		detector := &Flatline{Count: 10}
+		if err := decodeParams(params, detector); err != nil {
+			return nil, err
+		}
+		return detector, detector.validate()
+	case TypeSpike:
+		detector := &Spike{WindowSize: 5, Ratio: 0.5}
+		if err := decodeParams(params, detector); err != nil {
+			return nil, err
+		}
+		return detector, detector.validate()
+	case TypeRateOfChange:
+		detector := &RateOfChange{PerSeconds: 60}
+		if err := decodeParams(params, detector); err != nil {
+			return nil, err
+		}
+		return detector, detector.validate()
+	default:
+		return nil, fmt.Errorf("unknown detector type %q", kind)
+	}
+}
+
+// decodeParams reads stored parameters into a detector through JSON,
+// keeping the defaults of fields they do not set.
+func decodeParams(params map[string]any, detector any) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, detector); err != nil {
+		return fmt.Errorf("invalid detector params: %w", err)
+	}
+	return nil
+}
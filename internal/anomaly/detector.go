@@ -0,0 +1,207 @@
+package anomaly
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// ZScore flags a reading more than Threshold standard deviations away from
+// the mean of the last WindowSize readings. It stays silent until
+// MinSamples readings are known and while they do not vary.
+type ZScore struct {
+	WindowSize int     `json:"window"`
+	Threshold  float64 `json:"threshold"`
+	MinSamples int     `json:"min_samples"`
+}
+
+func (d *ZScore) validate() error {
+	switch {
+	case d.WindowSize < 2 || d.WindowSize > 1000:
+		return errors.New("zscore window must be between 2 and 1000")
+	case d.Threshold <= 0:
+		return errors.New("zscore threshold must be positive")
+	case d.MinSamples < 2 || d.MinSamples > d.WindowSize:
+		return errors.New("zscore min_samples must be between 2 and window")
+	}
+	return nil
+}
+
+func (d *ZScore) Window() int { return d.WindowSize }
+
+func (d *ZScore) Detect(history []Point, current Point) *Finding {
+	history = last(history, d.WindowSize)
+	if len(history) < d.MinSamples {
+		return nil
+	}
+
+	var sum float64
+	for _, point := range history {
+		sum += point.Value
+	}
+	mean := sum / float64(len(history))
+
+	var squares float64
+	for _, point := range history {
+		squares += (point.Value - mean) * (point.Value - mean)
+	}
+	deviation := math.Sqrt(squares / float64(len(history)-1))
+	if deviation == 0 {
+		return nil
+	}
+
+	score := math.Abs(current.Value-mean) / deviation
+	if score <= d.Threshold {
+		return nil
+	}
+	return &Finding{
+		Score:    score,
+		Expected: &mean,
+		Message:  fmt.Sprintf("value %g is %.1f standard deviations from the mean %g of the last %d readings", current.Value, score, mean, len(history)),
+	}
+}
+
+// Flatline flags a sensor stuck at one value: the Count-th reading in a row
+// within Tolerance of the one before it. Only the reading completing the
+// run is flagged, so a stuck sensor is reported once until it moves again.
+type Flatline struct {
+	Count     int     `json:"count"`
+	Tolerance float64 `json:"tolerance"`
+}
+
+func (d *Flatline) validate() error {
+	switch {
+	case d.Count < 2 || d.Count > 1000:
+		return errors.New("flatline count must be between 2 and 1000")
+	case d.Tolerance < 0:
+		return errors.New("flatline tolerance must not be negative")
+	}
+	return nil
+}
+
+func (d *Flatline) Window() int { return d.Count }
+
+func (d *Flatline) Detect(history []Point, current Point) *Finding {
+	run := 1
+	previous := current
+	for i := len(history) - 1; i >= 0; i-- {
+		if math.Abs(history[i].Value-previous.Value) > d.Tolerance {
+			break
+		}
+		run++
+		previous = history[i]
+	}
+	if run != d.Count {
+		return nil
+	}
+
+	value := current.Value
+	return &Finding{
+		Score:    float64(run),
+		Expected: &value,
+		Message:  fmt.Sprintf("value stuck at %g for %d readings since %s", current.Value, run, previous.At.UTC().Format("2006-01-02T15:04:05Z07:00")),
+	}
+}
+
+// Spike flags a reading that jumps away from the median of the last
+// WindowSize readings by more than Delta when set, or else by more than
+// Ratio of the median's magnitude.
+type Spike struct {
+	WindowSize int      `json:"window"`
+	Ratio      float64  `json:"ratio"`
+	Delta      *float64 `json:"delta,omitempty"`
+}
+
+func (d *Spike) validate() error {
+	switch {
+	case d.WindowSize < 1 || d.WindowSize > 1000:
+		return errors.New("spike window must be between 1 and 1000")
+	case d.Delta != nil && *d.Delta <= 0:
+		return errors.New("spike delta must be positive")
+	case d.Delta == nil && d.Ratio <= 0:
+		return errors.New("spike ratio must be positive")
+	}
+	return nil
+}
+
+func (d *Spike) Window() int { return d.WindowSize }
+
+func (d *Spike) Detect(history []Point, current Point) *Finding {
+	history = last(history, d.WindowSize)
+	if len(history) < d.WindowSize {
+		return nil
+	}
+
+	values := make([]float64, len(history))
+	for i, point := range history {
+		values[i] = point.Value
+	}
+	sort.Float64s(values)
+	median := values[len(values)/2]
+	if len(values)%2 == 0 {
+		median = (values[len(values)/2-1] + values[len(values)/2]) / 2
+	}
+
+	limit := d.Ratio * math.Abs(median)
+	if d.Delta != nil {
+		limit = *d.Delta
+	}
+	jump := math.Abs(current.Value - median)
+	if limit == 0 || jump <= limit {
+		return nil
+	}
+	return &Finding{
+		Score:    jump / limit,
+		Expected: &median,
+		Message:  fmt.Sprintf("value %g jumped %g away from the median %g of the last %d readings", current.Value, jump, median, len(history)),
+	}
+}
+
+// RateOfChange flags a reading whose change since the previous one exceeds
+// MaxRate per PerSeconds seconds.
+type RateOfChange struct {
+	MaxRate    float64 `json:"max_rate"`
+	PerSeconds float64 `json:"per_seconds"`
+}
+
+func (d *RateOfChange) validate() error {
+	switch {
+	case d.MaxRate <= 0:
+		return errors.New("rate_of_change max_rate must be positive")
+	case d.PerSeconds <= 0:
+		return errors.New("rate_of_change per_seconds must be positive")
+	}
+	return nil
+}
+
+func (d *RateOfChange) Window() int { return 1 }
+
+func (d *RateOfChange) Detect(history []Point, current Point) *Finding {
+	if len(history) == 0 {
+		return nil
+	}
+	previous := history[len(history)-1]
+	elapsed := current.At.Sub(previous.At).Seconds()
+	if elapsed <= 0 {
+		return nil
+	}
+
+	rate := math.Abs(current.Value-previous.Value) / elapsed * d.PerSeconds
+	if rate <= d.MaxRate {
+		return nil
+	}
+	return &Finding{
+		Score:    rate / d.MaxRate,
+		Expected: &previous.Value,
+		Message:  fmt.Sprintf("value changed from %g to %g, %g per %gs against at most %g", previous.Value, current.Value, rate, d.PerSeconds, d.MaxRate),
+	}
+}
+
+// last returns the last n points.
+func last(points []Point, n int) []Point {
+	if len(points) > n {
+		return points[len(points)-n:]
+	}
+	return points
+}
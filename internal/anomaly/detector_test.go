@@ -0,0 +1,152 @@
+package anomaly
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2026, time.March, 10, 8, 0, 0, 0, time.UTC)
+
+// series returns points one minute apart, starting at start.
+func series(values ...float64) []Point {
+	points := make([]Point, len(values))
+	for i, value := range values {
+		points[i] = Point{Value: value, At: start.Add(time.Duration(i) * time.Minute)}
+	}
+	return points
+}
+
+// next returns the point a minute after history.
+func next(history []Point, value float64) Point {
+	return Point{Value: value, At: start.Add(time.Duration(len(history)) * time.Minute)}
+}
+
+func delta(value float64) *float64 {
+	return &value
+}
+
+type detectorTest struct {
+	name     string
+	detector Detector
+	history  []Point
+	current  Point
+	// score and expected of the finding; no finding when score is zero
+	score    float64
+	expected float64
+}
+
+func runDetectorTests(t *testing.T, tests []detectorTest) {
+	t.Helper()
+
+	for _, test := range tests {
+		finding := test.detector.Detect(test.history, test.current)
+		switch {
+		case test.score == 0 && finding != nil:
+			t.Errorf("%s: got finding %+v, want none", test.name, finding)
+		case test.score == 0:
+		case finding == nil:
+			t.Errorf("%s: got no finding, want score %g", test.name, test.score)
+		case math.Abs(finding.Score-test.score) > 1e-9 || finding.Expected == nil ||
+			math.Abs(*finding.Expected-test.expected) > 1e-9:
+			t.Errorf("%s: got score %g expected %v, want %g %g", test.name, finding.Score, finding.Expected, test.score, test.expected)
+		}
+	}
+}
+
+func TestZScore(t *testing.T) {
+	detector := &ZScore{WindowSize: 5, Threshold: 2, MinSamples: 3}
+	// 1..5 have the mean 3 and the sample standard deviation sqrt(2.5)
+	rising := series(1, 2, 3, 4, 5)
+
+	runDetectorTests(t, []detectorTest{
+		{"fewer than min_samples", detector, series(1, 2), next(series(1, 2), 100), 0, 0},
+		{"min_samples reached", detector, series(1, 3, 5), next(series(1, 3, 5), 8), 2.5, 3},
+		{"zero variance", detector, series(5, 5, 5, 5), next(series(5, 5, 5, 5), 100), 0, 0},
+		{"within threshold", detector, rising, next(rising, 6), 0, 0},
+		{"past threshold", detector, rising, next(rising, 7), 4 / math.Sqrt(2.5), 3},
+		{"below the mean", detector, rising, next(rising, -1), 4 / math.Sqrt(2.5), 3},
+		{"older readings fall out of the window", detector, series(100, 1, 2, 3, 4, 5), next(rising, 7), 4 / math.Sqrt(2.5), 3},
+	})
+}
+
+func TestFlatline(t *testing.T) {
+	detector := &Flatline{Count: 3, Tolerance: 0.1}
+
+	runDetectorTests(t, []detectorTest{
+		{"no history", detector, nil, next(nil, 5), 0, 0},
+		{"run too short", detector, series(1, 5), next(series(1, 5), 5), 0, 0},
+		{"run completed", detector, series(1, 5, 5.05), next(series(1, 5, 5.05), 5), 3, 5},
+		{"longer run is not reported again", detector, series(5, 5, 5), next(series(5, 5, 5), 5), 0, 0},
+		{"moved away", detector, series(5, 5), next(series(5, 5), 6), 0, 0},
+		{"tolerance is against the previous reading", &Flatline{Count: 4, Tolerance: 0.5},
+			series(1, 1.4, 1.8), next(series(1, 1.4, 1.8), 2.2), 4, 2.2},
+		{"zero tolerance", &Flatline{Count: 2}, series(3), next(series(3), 3), 2, 3},
+		{"zero tolerance moved", &Flatline{Count: 2}, series(3), next(series(3), 3.001), 0, 0},
+	})
+}
+
+func TestSpike(t *testing.T) {
+	odd := &Spike{WindowSize: 3, Ratio: 0.5}
+	even := &Spike{WindowSize: 4, Ratio: 0.5}
+
+	runDetectorTests(t, []detectorTest{
+		{"odd window", odd, series(10, 30, 20), next(series(10, 30, 20), 35), 1.5, 20},
+		{"odd window within ratio", odd, series(10, 30, 20), next(series(10, 30, 20), 29), 0, 0},
+		{"even window", even, series(10, 40, 20, 30), next(series(10, 40, 20, 30), 40), 1.2, 25},
+		{"even window within ratio", even, series(10, 40, 20, 30), next(series(10, 40, 20, 30), 35), 0, 0},
+		{"window not full", even, series(10, 40, 20), next(series(10, 40, 20), 100), 0, 0},
+		{"older readings fall out of the window", odd, series(1000, 10, 30, 20), next(series(1000, 10, 30, 20), 35), 1.5, 20},
+		{"zero median without delta", odd, series(0, 0, 0), next(series(0, 0, 0), 3), 0, 0},
+		{"delta", &Spike{WindowSize: 3, Delta: delta(2)}, series(0, 0, 0), next(series(0, 0, 0), 3), 1.5, 0},
+		{"delta over ratio", &Spike{WindowSize: 3, Ratio: 0.01, Delta: delta(20)}, series(10, 30, 20), next(series(10, 30, 20), 35), 0, 0},
+	})
+}
+
+func TestRateOfChange(t *testing.T) {
+	detector := &RateOfChange{MaxRate: 1, PerSeconds: 60}
+	previous := series(10)
+
+	runDetectorTests(t, []detectorTest{
+		{"no history", detector, nil, next(nil, 100), 0, 0},
+		{"within rate", detector, previous, next(previous, 10.5), 0, 0},
+		{"past rate", detector, previous, next(previous, 12), 2, 10},
+		{"falling", detector, previous, next(previous, 7), 3, 10},
+		{"rate over a longer gap", detector, previous, Point{Value: 12, At: start.Add(4 * time.Minute)}, 0, 0},
+		{"same time", detector, previous, Point{Value: 100, At: start}, 0, 0},
+		{"earlier than the previous reading", detector, previous, Point{Value: 100, At: start.Add(-time.Minute)}, 0, 0},
+		{"only the last reading counts", detector, series(0, 10), next(series(0, 10), 10.5), 0, 0},
+	})
+}
+
+func TestNew(t *testing.T) {
+	detector, err := New(TypeZScore, map[string]any{"threshold": 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if zscore := detector.(*ZScore); zscore.WindowSize != 30 || zscore.Threshold != 4 || zscore.MinSamples != 10 {
+		t.Fatalf("got %+v, want the defaults with threshold 4", zscore)
+	}
+
+	for _, test := range []struct {
+		kind   string
+		params map[string]any
+		err    string
+	}{
+		{TypeZScore, map[string]any{"min_samples": 40}, "min_samples must be between 2 and window"},
+		{TypeFlatline, map[string]any{"count": 1}, "count must be between 2 and 1000"},
+		{TypeFlatline, map[string]any{"tolerance": -1}, "tolerance must not be negative"},
+		{TypeSpike, map[string]any{"ratio": 0}, "ratio must be positive"},
+		{TypeSpike, map[string]any{"delta": 0}, "delta must be positive"},
+		{TypeRateOfChange, map[string]any{}, "max_rate must be positive"},
+		{TypeRateOfChange, map[string]any{"max_rate": 1, "per_seconds": 0}, "per_seconds must be positive"},
+		{TypeZScore, map[string]any{"window": "wide"}, "invalid detector params"},
+		{"seasonal", nil, `unknown detector type "seasonal"`},
+	} {
+		_, err := New(test.kind, test.params)
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s %v: err = %v, want %q", test.kind, test.params, err, test.err)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Cache stores opaque values by key for a limited time. A missing or
+// expired key is reported through ok rather than an error, which is
+// reserved for failures of the backing store. Implementations are safe for
+// concurrent use.
+type Cache interface {
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// Stats counts the lookups served by a cache. Errors are lookups or writes
+// that failed in the backing store; failed lookups also count as misses.
+type Stats struct {
+	Hits   int64
+	Misses int64
+	Errors int64
+}
+
+// HitRatio is the share of lookups served from the cache, zero before the
+// first lookup.
+func (s Stats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Lookup reads values of T through a Cache, storing them as JSON under
+// Name and their id. The cache is best effort: when it fails, the value is
+// loaded as if it missed and the failure is only logged and counted.
+type Lookup[T any] struct {
+	Cache  Cache
+	Log    *logrus.Logger
+	Name   string
+	TTL    time.Duration
+	hits   atomic.Int64
+	misses atomic.Int64
+	errors atomic.Int64
+}
+
+func NewLookup[T any](cache Cache, logger *logrus.Logger, name string, ttl time.Duration) *Lookup[T] {
+	return &Lookup[T]{
+		Cache: cache,
+		Log:   logger,
+		Name:  name,
+		TTL:   ttl,
+	}
+}
+
+// Get returns the cached value of id, or calls load and caches what it
+// returns. Errors of load are returned as they are and nothing is cached
+// for them, so missing records are looked up again every time.
+func (l *Lookup[T]) Get(ctx context.Context, id string, load func() (*T, error)) (*T, error) {
+	key := l.key(id)
+
+	data, ok, err := l.Cache.Get(ctx, key)
+	if err != nil {
+		l.errors.Add(1)
+		l.Log.Warnf("Failed get %s from cache : %+v", l.Name, err)
+	}
+	if ok {
+		value := new(T)
+		if err = json.Unmarshal(data, value); err == nil {
+			l.hits.Add(1)
+			return value, nil
+		}
+		l.errors.Add(1)
+		l.Log.Warnf("Failed decode %s from cache : %+v", l.Name, err)
+	}
+	l.misses.Add(1)
+
+	value, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err = json.Marshal(value); err == nil {
+		err = l.Cache.Set(ctx, key, data, l.TTL)
+	}
+	if err != nil {
+		l.errors.Add(1)
+		l.Log.Warnf("Failed set %s to cache : %+v", l.Name, err)
+	}
+	return value, nil
+}
+
+// Invalidate drops the cached values of ids, so the next Get loads them
+// again.
+func (l *Lookup[T]) Invalidate(ctx context.Context, ids ...string) {
+	if len(ids) == 0 {
+		return
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = l.key(id)
+	}
+	if err := l.Cache.Delete(ctx, keys...); err != nil {
+		l.errors.Add(1)
+		l.Log.Warnf("Failed invalidate %s in cache : %+v", l.Name, err)
+	}
+}
+
+// Stats returns the lookups counted since the Lookup was created.
+func (l *Lookup[T]) Stats() Stats {
+	return Stats{
+		Hits:   l.hits.Load(),
+		Misses: l.misses.Load(),
+		Errors: l.errors.Load(),
+	}
+}
+
+func (l *Lookup[T]) key(id string) string {
+	return l.Name + ":" + id
+}
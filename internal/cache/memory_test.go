@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	memory := NewMemory(2)
+
+	memory.Set(ctx, "a", []byte("1"), 0)
+	memory.Set(ctx, "b", []byte("2"), 0)
+	// reading a makes b the least recently used
+	if _, ok, _ := memory.Get(ctx, "a"); !ok {
+		t.Fatal("a missing")
+	}
+	memory.Set(ctx, "c", []byte("3"), 0)
+
+	if _, ok, _ := memory.Get(ctx, "b"); ok {
+		t.Fatal("b kept over the size")
+	}
+	for key, want := range map[string]string{"a": "1", "c": "3"} {
+		if value, ok, _ := memory.Get(ctx, key); !ok || string(value) != want {
+			t.Fatalf("%s = %q %v, want %q", key, value, ok, want)
+		}
+	}
+
+	// replacing a value refreshes it rather than adding an entry
+	memory.Set(ctx, "a", []byte("4"), 0)
+	memory.Set(ctx, "d", []byte("5"), 0)
+	if memory.Len() != 2 {
+		t.Fatalf("Len = %d", memory.Len())
+	}
+	if value, ok, _ := memory.Get(ctx, "a"); !ok || string(value) != "4" {
+		t.Fatalf("a = %q %v", value, ok)
+	}
+	if _, ok, _ := memory.Get(ctx, "c"); ok {
+		t.Fatal("c kept over the size")
+	}
+}
+
+func TestMemoryExpires(t *testing.T) {
+	ctx := context.Background()
+	memory := NewMemory(0)
+
+	memory.Set(ctx, "short", []byte("1"), 10*time.Millisecond)
+	memory.Set(ctx, "forever", []byte("2"), 0)
+	time.Sleep(20 * time.Millisecond)
+
+	// expired entries are held until looked up
+	if memory.Len() != 2 {
+		t.Fatalf("Len = %d", memory.Len())
+	}
+	if _, ok, _ := memory.Get(ctx, "short"); ok {
+		t.Fatal("expired entry found")
+	}
+	if memory.Len() != 1 {
+		t.Fatalf("Len after lookup = %d", memory.Len())
+	}
+	if _, ok, _ := memory.Get(ctx, "forever"); !ok {
+		t.Fatal("entry without ttl expired")
+	}
+
+	// a new ttl replaces the old one
+	memory.Set(ctx, "forever", []byte("2"), 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	if _, ok, _ := memory.Get(ctx, "forever"); ok {
+		t.Fatal("entry kept past its new ttl")
+	}
+}
+
+func TestMemoryDelete(t *testing.T) {
+	ctx := context.Background()
+	memory := NewMemory(10)
+
+	memory.Set(ctx, "a", []byte("1"), 0)
+	memory.Set(ctx, "b", []byte("2"), 0)
+	memory.Delete(ctx, "a", "missing")
+
+	if _, ok, _ := memory.Get(ctx, "a"); ok {
+		t.Fatal("deleted entry found")
+	}
+	if memory.Len() != 1 {
+		t.Fatalf("Len = %d", memory.Len())
+	}
+}
@@ -0,0 +1,275 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// RedisClient sends one command to a Redis-compatible server and returns
+// its reply: a string for status replies, []byte or nil for bulk strings,
+// int64 for integers and []any for arrays. Error replies are returned as
+// RedisError. An in-process fake keeping a map satisfies it in tests.
+type RedisClient interface {
+	Do(ctx context.Context, args ...any) (any, error)
+}
+
+// RedisError is an error reply of the server.
+type RedisError string
+
+func (e RedisError) Error() string {
+	return string(e)
+}
+
+// Redis is a Cache kept in a Redis-compatible server, so entries are shared
+// by every instance of the application. Keys are stored under Prefix.
+type Redis struct {
+	Client RedisClient
+	Prefix string
+}
+
+func NewRedis(client RedisClient, prefix string) *Redis {
+	return &Redis{
+		Client: client,
+		Prefix: prefix,
+	}
+}
+
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	reply, err := r.Client.Do(ctx, "GET", r.Prefix+key)
+	if err != nil {
+		return nil, false, err
+	}
+	switch value := reply.(type) {
+	case nil:
+		return nil, false, nil
+	case []byte:
+		return value, true, nil
+	case string:
+		return []byte(value), true, nil
+	default:
+		return nil, false, fmt.Errorf("unexpected reply to GET: %T", reply)
+	}
+}
+
+// Set stores value under key; a ttl of zero or less keeps it until the
+// server evicts it.
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	args := []any{"SET", r.Prefix + key, value}
+	if ttl > 0 {
+		args = append(args, "PX", ttl.Milliseconds())
+	}
+	_, err := r.Client.Do(ctx, args...)
+	return err
+}
+
+func (r *Redis) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	args := make([]any, 0, len(keys)+1)
+	args = append(args, "DEL")
+	for _, key := range keys {
+		args = append(args, r.Prefix+key)
+	}
+	_, err := r.Client.Do(ctx, args...)
+	return err
+}
+
+// redisDialTimeout bounds connecting to the server; commands are bounded by
+// their context, or by redisCommandTimeout when it has no deadline.
+const (
+	redisDialTimeout    = 5 * time.Second
+	redisCommandTimeout = 2 * time.Second
+)
+
+// RedisConn is a RedisClient speaking RESP over TCP. It keeps up to Idle
+// connections open between commands and authenticates and selects DB on
+// every new one.
+type RedisConn struct {
+	Addr     string
+	Password string
+	DB       int
+	idle     chan *redisConn
+}
+
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func NewRedisConn(addr string, password string, db int, idle int) *RedisConn {
+	if idle <= 0 {
+		idle = 8
+	}
+	return &RedisConn{
+		Addr:     addr,
+		Password: password,
+		DB:       db,
+		idle:     make(chan *redisConn, idle),
+	}
+}
+
+// Do runs a command on an idle connection, or a new one when none is. A
+// connection that failed mid-command is closed rather than reused, since
+// its stream may hold part of a reply.
+func (c *RedisConn) Do(ctx context.Context, args ...any) (any, error) {
+	conn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := conn.do(ctx, args...)
+	var redisErr RedisError
+	if err != nil && !errors.As(err, &redisErr) {
+		conn.conn.Close()
+		return nil, err
+	}
+
+	select {
+	case c.idle <- conn:
+	default:
+		conn.conn.Close()
+	}
+	return reply, err
+}
+
+// Close closes the idle connections.
+func (c *RedisConn) Close() error {
+	for {
+		select {
+		case conn := <-c.idle:
+			conn.conn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+func (c *RedisConn) get(ctx context.Context) (*redisConn, error) {
+	select {
+	case conn := <-c.idle:
+		return conn, nil
+	default:
+	}
+
+	dialer := net.Dialer{Timeout: redisDialTimeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", c.Addr)
+	if err != nil {
+		return nil, err
+	}
+	conn := &redisConn{conn: netConn, reader: bufio.NewReader(netConn)}
+
+	if c.Password != "" {
+		if _, err := conn.do(ctx, "AUTH", c.Password); err != nil {
+			netConn.Close()
+			return nil, err
+		}
+	}
+	if c.DB != 0 {
+		if _, err := conn.do(ctx, "SELECT", c.DB); err != nil {
+			netConn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+func (c *redisConn) do(ctx context.Context, args ...any) (any, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(redisCommandTimeout)
+	}
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	if _, err := c.conn.Write(appendRedisCommand(nil, args)); err != nil {
+		return nil, err
+	}
+	return readRedisReply(c.reader)
+}
+
+// appendRedisCommand encodes a command as a RESP array of bulk strings.
+func appendRedisCommand(buf []byte, args []any) []byte {
+	buf = append(buf, '*')
+	buf = strconv.AppendInt(buf, int64(len(args)), 10)
+	buf = append(buf, '\r', '\n')
+	for _, arg := range args {
+		var value []byte
+		switch arg := arg.(type) {
+		case []byte:
+			value = arg
+		case string:
+			value = []byte(arg)
+		case int:
+			value = strconv.AppendInt(nil, int64(arg), 10)
+		case int64:
+			value = strconv.AppendInt(nil, arg, 10)
+		default:
+			value = fmt.Append(nil, arg)
+		}
+		buf = append(buf, '$')
+		buf = strconv.AppendInt(buf, int64(len(value)), 10)
+		buf = append(buf, '\r', '\n')
+		buf = append(buf, value...)
+		buf = append(buf, '\r', '\n')
+	}
+	return buf
+}
+
+// readRedisReply decodes one RESP reply.
+func readRedisReply(reader *bufio.Reader) (any, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("malformed reply %q", line)
+	}
+	kind, body := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, RedisError(body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		size, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		value := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, value); err != nil {
+			return nil, err
+		}
+		return value[:size], nil
+	case '*':
+		size, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		values := make([]any, size)
+		for i := range values {
+			if values[i], err = readRedisReply(reader); err != nil {
+				return nil, err
+			}
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("malformed reply %q", line)
+	}
+}
@@ -0,0 +1,257 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis is an in-process RedisClient keeping a map, answering GET, SET
+// with PX and DEL like a server would.
+type fakeRedis struct {
+	mu       sync.Mutex
+	values   map[string][]byte
+	expires  map[string]time.Time
+	commands [][]any
+	err      error
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{
+		values:  make(map[string][]byte),
+		expires: make(map[string]time.Time),
+	}
+}
+
+func (f *fakeRedis) Do(_ context.Context, args ...any) (any, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.commands = append(f.commands, args)
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	switch args[0] {
+	case "GET":
+		key := args[1].(string)
+		if expires, ok := f.expires[key]; ok && !time.Now().Before(expires) {
+			delete(f.values, key)
+			delete(f.expires, key)
+		}
+		if value, ok := f.values[key]; ok {
+			return value, nil
+		}
+		return nil, nil
+	case "SET":
+		key := args[1].(string)
+		f.values[key] = args[2].([]byte)
+		delete(f.expires, key)
+		if len(args) == 5 && args[3] == "PX" {
+			f.expires[key] = time.Now().Add(time.Duration(args[4].(int64)) * time.Millisecond)
+		}
+		return "OK", nil
+	case "DEL":
+		var deleted int64
+		for _, arg := range args[1:] {
+			key := arg.(string)
+			if _, ok := f.values[key]; ok {
+				deleted++
+			}
+			delete(f.values, key)
+			delete(f.expires, key)
+		}
+		return deleted, nil
+	default:
+		return nil, RedisError(fmt.Sprintf("ERR unknown command '%v'", args[0]))
+	}
+}
+
+func TestRedis(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeRedis()
+	redis := NewRedis(fake, "test:")
+
+	if _, ok, err := redis.Get(ctx, "sensor:1"); ok || err != nil {
+		t.Fatalf("Get before Set: ok %v, err %v", ok, err)
+	}
+
+	if err := redis.Set(ctx, "sensor:1", []byte(`{"id":"1"}`), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := redis.Set(ctx, "sensor:2", []byte(`{"id":"2"}`), 0); err != nil {
+		t.Fatal(err)
+	}
+	if value, ok, err := redis.Get(ctx, "sensor:1"); !ok || err != nil || string(value) != `{"id":"1"}` {
+		t.Fatalf("Get: %q %v %v", value, ok, err)
+	}
+
+	if err := redis.Delete(ctx, "sensor:1", "sensor:2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := redis.Get(ctx, "sensor:2"); ok {
+		t.Fatal("deleted key still found")
+	}
+
+	want := [][]any{
+		{"GET", "test:sensor:1"},
+		{"SET", "test:sensor:1", []byte(`{"id":"1"}`), "PX", int64(60000)},
+		{"SET", "test:sensor:2", []byte(`{"id":"2"}`)},
+		{"GET", "test:sensor:1"},
+		{"DEL", "test:sensor:1", "test:sensor:2"},
+		{"GET", "test:sensor:2"},
+	}
+	if !reflect.DeepEqual(fake.commands, want) {
+		t.Fatalf("commands = %q", fake.commands)
+	}
+}
+
+func TestRedisExpires(t *testing.T) {
+	ctx := context.Background()
+	redis := NewRedis(newFakeRedis(), "")
+
+	redis.Set(ctx, "k", []byte("v"), 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	if _, ok, err := redis.Get(ctx, "k"); ok || err != nil {
+		t.Fatalf("expired key: ok %v, err %v", ok, err)
+	}
+}
+
+func TestRedisErrors(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeRedis()
+	redis := NewRedis(fake, "")
+
+	fake.err = RedisError("LOADING Redis is loading the dataset in memory")
+	if _, ok, err := redis.Get(ctx, "k"); ok || !errors.As(err, new(RedisError)) {
+		t.Fatalf("Get: ok %v, err %v", ok, err)
+	}
+	if err := redis.Set(ctx, "k", []byte("v"), 0); err == nil {
+		t.Fatal("Set succeeded on an error reply")
+	}
+
+	// nothing is sent for an empty delete
+	fake.err = nil
+	fake.commands = nil
+	if err := redis.Delete(ctx); err != nil || len(fake.commands) != 0 {
+		t.Fatalf("Delete: %v, commands %q", err, fake.commands)
+	}
+}
+
+func TestAppendRedisCommand(t *testing.T) {
+	got := appendRedisCommand(nil, []any{"SET", "k", []byte("a\r\nb"), "PX", int64(1500), 3, 2.5})
+	want := "*7\r\n$3\r\nSET\r\n$1\r\nk\r\n$4\r\na\r\nb\r\n$2\r\nPX\r\n$4\r\n1500\r\n$1\r\n3\r\n$3\r\n2.5\r\n"
+	if string(got) != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	if got := appendRedisCommand([]byte("x"), []any{""}); string(got) != "x*1\r\n$0\r\n\r\n" {
+		t.Fatalf("empty argument: %q", got)
+	}
+}
+
+func TestReadRedisReply(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		input string
+		want  any
+		err   string
+	}{
+		{"status", "+OK\r\n", "OK", ""},
+		{"error", "-ERR wrong number of arguments\r\n", nil, "ERR wrong number of arguments"},
+		{"integer", ":-42\r\n", int64(-42), ""},
+		{"bulk", "$5\r\nhe\r\nl\r\n", []byte("he\r\nl"), ""},
+		{"empty bulk", "$0\r\n\r\n", []byte{}, ""},
+		{"nil bulk", "$-1\r\n", nil, ""},
+		{"array", "*3\r\n$1\r\na\r\n:1\r\n$-1\r\n", []any{[]byte("a"), int64(1), nil}, ""},
+		{"nested array", "*1\r\n*1\r\n+x\r\n", []any{[]any{"x"}}, ""},
+		{"nil array", "*-1\r\n", nil, ""},
+		{"no carriage return", "+OK\n", nil, "malformed reply"},
+		{"unknown kind", "?1\r\n", nil, "malformed reply"},
+		{"bad integer", ":x\r\n", nil, "invalid syntax"},
+		{"short bulk", "$5\r\nab", nil, io.ErrUnexpectedEOF.Error()},
+		{"short array", "*2\r\n+a\r\n", nil, io.EOF.Error()},
+	} {
+		got, err := readRedisReply(bufio.NewReader(strings.NewReader(test.input)))
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%s: err = %v, want %q", test.name, err, test.err)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %#v %v, want %#v", test.name, got, err, test.want)
+		}
+	}
+
+	var redisErr RedisError
+	_, err := readRedisReply(bufio.NewReader(strings.NewReader("-WRONGTYPE\r\n")))
+	if !errors.As(err, &redisErr) {
+		t.Fatalf("err = %T, want RedisError", err)
+	}
+}
+
+func TestRedisConn(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	// a server answering each command with its arguments joined
+	commands := make(chan string, 8)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+		reader := bufio.NewReader(conn)
+		for {
+			reply, err := readRedisReply(reader)
+			if err != nil {
+				return
+			}
+			var args []string
+			for _, arg := range reply.([]any) {
+				args = append(args, string(arg.([]byte)))
+			}
+			command := strings.Join(args, " ")
+			commands <- command
+			fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(command), command)
+		}
+	}()
+
+	client := NewRedisConn(listener.Addr().String(), "secret", 2, 1)
+	defer client.Close()
+
+	for i := 0; i < 2; i++ {
+		reply, err := client.Do(context.Background(), "GET", "k")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(reply.([]byte)) != "GET k" {
+			t.Fatalf("reply = %q", reply)
+		}
+	}
+
+	// the connection is set up once and reused for the second command
+	close(commands)
+	var got []string
+	for command := range commands {
+		got = append(got, command)
+	}
+	if strings.Join(got, ",") != "AUTH secret,SELECT 2,GET k,GET k" {
+		t.Fatalf("commands = %q", got)
+	}
+}
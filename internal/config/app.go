@@ -1,9 +1,12 @@
 package config
 
 import (
+	"context"
 	"mertani_test/internal/delivery/http"
 	"mertani_test/internal/delivery/http/route"
+	"mertani_test/internal/event"
 	"mertani_test/internal/repository"
+	"mertani_test/internal/stream"
 	"mertani_test/internal/usecase"
 	"mertani_test/internal/utils"
 
@@ -25,18 +28,164 @@ type BootstrapConfig struct {
 }
 
 func Bootstrap(config *BootstrapConfig) {
-	deviceRepository := repository.NewDeviceRepository(config.Log)
-	deviceUseCase := usecase.NewDeviceUseCase(config.DB, config.Log, config.Validator, deviceRepository)
-	deviceController := http.NewDeviceController(deviceUseCase, config.Log)	
+	hub := stream.NewHub(config.Config.GetInt("STREAM_BUFFER"))
+	bus := event.NewBus(config.Log)
+
+	// brokers other than the in-process bus are added to the fanout
+	outboxRepository := repository.NewOutboxRepository(config.Log)
+	outboxUseCase := usecase.NewOutboxUseCase(config.DB, config.Log, outboxRepository, event.Fanout{bus},
+		config.Config.GetDuration("OUTBOX_RETENTION"))
+
+	readingRepository := repository.NewReadingRepository(config.Log)
+	siteRepository := repository.NewSiteRepository(config.Log)
+	sensorTypeRepository := repository.NewSensorTypeRepository(config.Log)
+	sensorCalibrationRepository := repository.NewSensorCalibrationRepository(config.Log)
 
+	deviceRepository := repository.NewDeviceRepository(config.Log, config.Config.GetBool("DB_POSTGIS"))
 	sensorRepository := repository.NewSensorRepository(config.Log)
-	sensorUseCase := usecase.NewSensorUseCase(config.DB, config.Log, config.Validator, sensorRepository)
+	sensorLatestRepository := repository.NewSensorLatestRepository(config.Log)
+
+	lookupCache := usecase.NewLookupCache(NewCache(config.Config, config.Log), config.Log, deviceRepository, sensorRepository,
+		sensorLatestRepository, config.Config.GetDuration("CACHE_TTL"))
+	cacheController := http.NewCacheController(lookupCache, config.Log)
+
+	deviceUseCase := usecase.NewDeviceUseCase(config.DB, config.Log, config.Validator, deviceRepository, sensorRepository, sensorLatestRepository, siteRepository, lookupCache, outboxUseCase)
+	deviceController := http.NewDeviceController(deviceUseCase, config.Log)	
+
+	sensorUseCase := usecase.NewSensorUseCase(config.DB, config.Log, config.Validator, sensorRepository, sensorTypeRepository, deviceRepository, lookupCache, outboxUseCase)
 	sensorController := http.NewSensorController(sensorUseCase, config.Log)
-	
+
+	configSchemaRepository := repository.NewConfigSchemaRepository(config.Log)
+	configSchemaUseCase := usecase.NewConfigSchemaUseCase(config.DB, config.Log, config.Validator, configSchemaRepository)
+	configSchemaController := http.NewConfigSchemaController(configSchemaUseCase, config.Log)
+
+	deviceConfigRepository := repository.NewDeviceConfigRepository(config.Log)
+	deviceConfigUseCase := usecase.NewDeviceConfigUseCase(config.DB, config.Log, config.Validator, deviceRepository, deviceConfigRepository, configSchemaRepository, lookupCache)
+	deviceConfigController := http.NewDeviceConfigController(deviceConfigUseCase, config.Log)
+
+	siteUseCase := usecase.NewSiteUseCase(config.DB, config.Log, config.Validator, siteRepository, deviceRepository, lookupCache, outboxUseCase)
+	siteController := http.NewSiteController(siteUseCase, config.Log)
+
+	sensorTypeUseCase := usecase.NewSensorTypeUseCase(config.DB, config.Log, config.Validator, sensorTypeRepository)
+	sensorTypeController := http.NewSensorTypeController(sensorTypeUseCase, config.Log)
+
+	anomalyDetectorRepository := repository.NewAnomalyDetectorRepository(config.Log)
+	anomalyRepository := repository.NewAnomalyRepository(config.Log)
+	anomalyUseCase := usecase.NewAnomalyUseCase(config.DB, config.Log, config.Validator, anomalyDetectorRepository, anomalyRepository,
+		readingRepository, sensorRepository, sensorTypeRepository, outboxUseCase)
+	anomalyController := http.NewAnomalyController(anomalyUseCase, config.Log)
+	if err := anomalyUseCase.Start(context.Background()); err != nil {
+		config.Log.Fatalf("Failed to start anomaly detectors: %v", err)
+	}
+
+	readingRollupRepository := repository.NewReadingRollupRepository(config.Log)
+	readingUseCase := usecase.NewReadingUseCase(config.DB, config.Log, config.Validator, readingRepository, sensorRepository, sensorTypeRepository, sensorCalibrationRepository, deviceRepository, readingRollupRepository, sensorLatestRepository, lookupCache, hub, anomalyUseCase)
+	readingController := http.NewReadingController(readingUseCase, config.Log)
+
+	retentionPolicyRepository := repository.NewRetentionPolicyRepository(config.Log)
+	retentionUseCase := usecase.NewRetentionUseCase(config.DB, config.Log, config.Validator, retentionPolicyRepository, readingRollupRepository,
+		sensorRepository, sensorTypeRepository, config.Config.GetDuration("RETENTION_INTERVAL"))
+	retentionController := http.NewRetentionController(retentionUseCase, config.Log)
+	if err := retentionUseCase.Start(context.Background()); err != nil {
+		config.Log.Fatalf("Failed to start retention compaction: %v", err)
+	}
+
+	readingPartitionRepository := repository.NewReadingPartitionRepository(config.Log)
+	readingPartitionUseCase := usecase.NewReadingPartitionUseCase(config.DB, config.Log, readingPartitionRepository,
+		config.Config.GetInt("READING_PARTITION_AHEAD"), config.Config.GetInt("READING_PARTITION_RETENTION"))
+	if err := readingPartitionUseCase.Start(context.Background()); err != nil {
+		config.Log.Fatalf("Failed to start reading partition maintenance: %v", err)
+	}
+
+	sensorCalibrationUseCase := usecase.NewSensorCalibrationUseCase(config.DB, config.Log, config.Validator, sensorRepository, sensorCalibrationRepository, readingRepository, sensorLatestRepository)
+	sensorCalibrationController := http.NewSensorCalibrationController(sensorCalibrationUseCase, config.Log)
+
+	payloadDecoderRepository := repository.NewPayloadDecoderRepository(config.Log)
+	payloadDecoderUseCase := usecase.NewPayloadDecoderUseCase(config.DB, config.Log, config.Validator, payloadDecoderRepository)
+	payloadDecoderController := http.NewPayloadDecoderController(payloadDecoderUseCase, config.Log)
+
+	uplinkUseCase := usecase.NewUplinkUseCase(config.DB, config.Log, config.Validator, deviceRepository, payloadDecoderRepository, readingUseCase)
+	uplinkController := http.NewUplinkController(uplinkUseCase, config.Log)
+
+	lineProtocolUseCase := usecase.NewLineProtocolUseCase(config.DB, config.Log, deviceRepository, sensorRepository,
+		sensorUseCase, readingUseCase, config.Config.GetString("INFLUX_DEVICE_TAG"), config.Config.GetBool("INFLUX_AUTO_CREATE_SENSORS"))
+	lineProtocolController := http.NewLineProtocolController(lineProtocolUseCase, config.Log)
+
+	streamUseCase := usecase.NewStreamUseCase(config.Log, config.Validator, hub)
+	streamController := http.NewStreamController(streamUseCase, config.Log)
+	bus.Subscribe("stream", streamUseCase.HandleEvent)
+
+	exportJobRepository := repository.NewExportJobRepository(config.Log)
+	exportJobUseCase := usecase.NewExportJobUseCase(config.DB, config.Log, config.Validator, exportJobRepository, readingUseCase,
+		config.Config.GetString("EXPORT_STORAGE_DIR"), config.Config.GetInt("EXPORT_WORKERS"), config.Config.GetDuration("EXPORT_RETENTION"),
+		config.Config.GetDuration("EXPORT_JOB_TIMEOUT"))
+	exportJobController := http.NewExportJobController(exportJobUseCase, config.Log)
+	if err := exportJobUseCase.Start(context.Background()); err != nil {
+		config.Log.Fatalf("Failed to start export workers: %v", err)
+	}
+
+	webhookRepository := repository.NewWebhookRepository(config.Log)
+	webhookDeliveryRepository := repository.NewWebhookDeliveryRepository(config.Log)
+	webhookUseCase := usecase.NewWebhookUseCase(config.DB, config.Log, config.Validator, webhookRepository, webhookDeliveryRepository, hub,
+		config.Config.GetInt("WEBHOOK_WORKERS"), config.Config.GetInt("WEBHOOK_MAX_ATTEMPTS"), config.Config.GetDuration("WEBHOOK_TIMEOUT"))
+	webhookController := http.NewWebhookController(webhookUseCase, config.Log)
+	if err := webhookUseCase.Start(context.Background()); err != nil {
+		config.Log.Fatalf("Failed to start webhook workers: %v", err)
+	}
+	bus.Subscribe("webhook", webhookUseCase.HandleEvent)
+
+	notificationChannelRepository := repository.NewNotificationChannelRepository(config.Log)
+	notificationRuleRepository := repository.NewNotificationRuleRepository(config.Log)
+	notificationRepository := repository.NewNotificationRepository(config.Log)
+	notificationUseCase := usecase.NewNotificationUseCase(config.DB, config.Log, config.Validator,
+		notificationChannelRepository, notificationRuleRepository, notificationRepository,
+		config.Config.GetInt("NOTIFY_WORKERS"), config.Config.GetInt("NOTIFY_MAX_ATTEMPTS"), config.Config.GetDuration("NOTIFY_TIMEOUT"))
+	notificationController := http.NewNotificationController(notificationUseCase, config.Log)
+	if err := notificationUseCase.Start(context.Background()); err != nil {
+		config.Log.Fatalf("Failed to start notification workers: %v", err)
+	}
+	bus.Subscribe("notification", notificationUseCase.HandleEvent, event.TypeAlert, event.TypeAlertEscalated)
+
+	alertRepository := repository.NewAlertRepository(config.Log)
+	alertCommentRepository := repository.NewAlertCommentRepository(config.Log)
+	silenceRepository := repository.NewSilenceRepository(config.Log)
+	escalationPolicyRepository := repository.NewEscalationPolicyRepository(config.Log)
+	alertUseCase := usecase.NewAlertUseCase(config.DB, config.Log, config.Validator,
+		alertRepository, alertCommentRepository, silenceRepository, escalationPolicyRepository,
+		notificationChannelRepository, deviceRepository, sensorRepository, anomalyRepository,
+		outboxUseCase, config.Config.GetDuration("ALERT_SCHEDULER_INTERVAL"))
+	alertController := http.NewAlertController(alertUseCase, config.Log)
+	if err := alertUseCase.Start(context.Background()); err != nil {
+		config.Log.Fatalf("Failed to start alert scheduler: %v", err)
+	}
+	bus.Subscribe("alert", alertUseCase.HandleEvent, event.TypeAnomaly)
+
+	// relay only once every subscriber is registered
+	if err := outboxUseCase.Start(context.Background()); err != nil {
+		config.Log.Fatalf("Failed to start outbox relay: %v", err)
+	}
+
 	routeConfig := route.RouteConfig{
 		App:                config.App,
 		DeviceController: deviceController,
 		SensorController: sensorController,
+		DeviceConfigController: deviceConfigController,
+		ConfigSchemaController: configSchemaController,
+		SiteController:         siteController,
+		SensorTypeController:   sensorTypeController,
+		ReadingController:      readingController,
+		SensorCalibrationController: sensorCalibrationController,
+		PayloadDecoderController:    payloadDecoderController,
+		UplinkController:            uplinkController,
+		LineProtocolController:      lineProtocolController,
+		ExportJobController:         exportJobController,
+		StreamController:            streamController,
+		WebhookController:           webhookController,
+		NotificationController:      notificationController,
+		AlertController:             alertController,
+		AnomalyController:           anomalyController,
+		RetentionController:         retentionController,
+		CacheController:             cacheController,
 	}
 	routeConfig.Setup()
 }
@@ -0,0 +1,25 @@
+package config
+
+import (
+	"mertani_test/internal/cache"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// NewCache returns the cache store selected by CACHE_DRIVER: memory, the
+// default, keeps entries per instance; redis shares them between instances
+// through a Redis-compatible server.
+func NewCache(viper *viper.Viper, log *logrus.Logger) cache.Cache {
+	switch driver := viper.GetString("CACHE_DRIVER"); driver {
+	case "", "memory":
+		return cache.NewMemory(viper.GetInt("CACHE_SIZE"))
+	case "redis":
+		client := cache.NewRedisConn(viper.GetString("REDIS_ADDR"), viper.GetString("REDIS_PASSWORD"),
+			viper.GetInt("REDIS_DB"), viper.GetInt("REDIS_POOL_SIZE"))
+		return cache.NewRedis(client, viper.GetString("CACHE_PREFIX"))
+	default:
+		log.Fatalf("Unknown cache driver: %s", driver)
+		return nil
+	}
+}
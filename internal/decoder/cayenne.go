@@ -0,0 +1,99 @@
+package decoder
+
+import "fmt"
+
+func init() {
+	Register("cayenne_lpp", DecoderFunc(DecodeCayenneLPP))
+}
+
+type cayenneType struct {
+	name    string
+	size    int
+	signed  bool
+	divisor float64
+	axes    []string
+}
+
+// cayenneTypes follows the Cayenne Low Power Payload data types.
+var cayenneTypes = map[byte]cayenneType{
+	0:   {name: "digital_input", size: 1, divisor: 1},
+	1:   {name: "digital_output", size: 1, divisor: 1},
+	2:   {name: "analog_input", size: 2, signed: true, divisor: 100},
+	3:   {name: "analog_output", size: 2, signed: true, divisor: 100},
+	101: {name: "illuminance", size: 2, divisor: 1},
+	102: {name: "presence", size: 1, divisor: 1},
+	103: {name: "temperature", size: 2, signed: true, divisor: 10},
+	104: {name: "humidity", size: 1, divisor: 2},
+	113: {name: "accelerometer", size: 2, signed: true, divisor: 1000, axes: []string{"x", "y", "z"}},
+	115: {name: "barometer", size: 2, divisor: 10},
+	134: {name: "gyrometer", size: 2, signed: true, divisor: 100, axes: []string{"x", "y", "z"}},
+}
+
+// DecodeCayenneLPP decodes a Cayenne LPP frame. Measurements are named
+// <type>_<channel>, with an axis suffix for multi-axis types, e.g.
+// temperature_1 or gps_4_latitude.
+func DecodeCayenneLPP(payload []byte) ([]Measurement, error) {
+	var measurements []Measurement
+	for pos := 0; pos < len(payload); {
+		if pos+2 > len(payload) {
+			return nil, fmt.Errorf("truncated header at byte %d", pos)
+		}
+		channel, kind := payload[pos], payload[pos+1]
+		pos += 2
+
+		// gps packs three 24-bit values with different resolutions
+		if kind == 136 {
+			if pos+9 > len(payload) {
+				return nil, fmt.Errorf("truncated gps value at byte %d", pos)
+			}
+			prefix := fmt.Sprintf("gps_%d_", channel)
+			measurements = append(measurements,
+				Measurement{Name: prefix + "latitude", Value: float64(readInt(payload[pos:pos+3], true, false)) / 10000},
+				Measurement{Name: prefix + "longitude", Value: float64(readInt(payload[pos+3:pos+6], true, false)) / 10000},
+				Measurement{Name: prefix + "altitude", Value: float64(readInt(payload[pos+6:pos+9], true, false)) / 100},
+			)
+			pos += 9
+			continue
+		}
+
+		t, ok := cayenneTypes[kind]
+		if !ok {
+			return nil, fmt.Errorf("unknown data type %d at byte %d", kind, pos-1)
+		}
+		axes := t.axes
+		if axes == nil {
+			axes = []string{""}
+		}
+		if pos+t.size*len(axes) > len(payload) {
+			return nil, fmt.Errorf("truncated %s value at byte %d", t.name, pos)
+		}
+
+		for _, axis := range axes {
+			name := fmt.Sprintf("%s_%d", t.name, channel)
+			if axis != "" {
+				name += "_" + axis
+			}
+			raw := readInt(payload[pos:pos+t.size], t.signed, false)
+			measurements = append(measurements, Measurement{Name: name, Value: float64(raw) / t.divisor})
+			pos += t.size
+		}
+	}
+	return measurements, nil
+}
+
+// readInt reads a big- or little-endian integer of up to 8 bytes,
+// sign-extending it when signed.
+func readInt(b []byte, signed bool, littleEndian bool) int64 {
+	var u uint64
+	for i := range b {
+		index := i
+		if littleEndian {
+			index = len(b) - 1 - i
+		}
+		u = u<<8 | uint64(b[index])
+	}
+	if signed && len(b) < 8 && u&(1<<(8*len(b)-1)) != 0 {
+		u |= ^uint64(0) << (8 * len(b))
+	}
+	return int64(u)
+}
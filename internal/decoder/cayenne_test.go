@@ -0,0 +1,107 @@
+package decoder
+
+import (
+	"encoding/hex"
+	"math"
+	"strings"
+	"testing"
+)
+
+func decodeHex(t *testing.T, payload string) []byte {
+	t.Helper()
+
+	data, err := hex.DecodeString(strings.ReplaceAll(payload, " ", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func expectMeasurements(t *testing.T, name string, got []Measurement, want []Measurement) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("%s: got %v, want %v", name, got, want)
+	}
+	for i := range want {
+		if got[i].Name != want[i].Name || math.Abs(got[i].Value-want[i].Value) > 1e-9 {
+			t.Fatalf("%s: got %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestDecodeCayenneLPP(t *testing.T) {
+	// the examples of the Cayenne LPP documentation, and the other types
+	for _, test := range []struct {
+		name    string
+		payload string
+		want    []Measurement
+	}{
+		{"two temperatures", "03 67 01 10 05 67 00 FF", []Measurement{
+			{"temperature_3", 27.2}, {"temperature_5", 25.5},
+		}},
+		{"negative temperature", "01 67 FF D7", []Measurement{{"temperature_1", -4.1}}},
+		{"accelerometer", "06 71 04 D2 FB 2E 00 00", []Measurement{
+			{"accelerometer_6_x", 1.234}, {"accelerometer_6_y", -1.234}, {"accelerometer_6_z", 0},
+		}},
+		{"gps", "01 88 06 76 5F F2 96 0A 00 03 E8", []Measurement{
+			{"gps_1_latitude", 42.3519}, {"gps_1_longitude", -87.9094}, {"gps_1_altitude", 10},
+		}},
+		{"negative gps", "02 88 F9 89 A1 10 4F C8 FF FF 9C", []Measurement{
+			{"gps_2_latitude", -42.3519}, {"gps_2_longitude", 106.9}, {"gps_2_altitude", -1},
+		}},
+		{"unsigned types", "01 68 61 02 65 FF FF 03 73 27 10 04 00 01", []Measurement{
+			{"humidity_1", 48.5}, {"illuminance_2", 65535}, {"barometer_3", 1000}, {"digital_input_4", 1},
+		}},
+		{"analog", "05 02 FF 38", []Measurement{{"analog_input_5", -2}}},
+		{"empty", "", nil},
+	} {
+		got, err := DecodeCayenneLPP(decodeHex(t, test.payload))
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		expectMeasurements(t, test.name, got, test.want)
+	}
+}
+
+func TestDecodeCayenneLPPRejects(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		payload string
+		err     string
+	}{
+		{"header", "03", "truncated header at byte 0"},
+		{"second header", "03 67 01 10 05", "truncated header at byte 4"},
+		{"value", "03 67 01", "truncated temperature value at byte 2"},
+		{"axis", "06 71 04 D2 FB 2E 00", "truncated accelerometer value at byte 2"},
+		{"gps", "01 88 06 76 5F F2 96 0A 00 03", "truncated gps value at byte 2"},
+		{"unknown type", "01 FF 00", "unknown data type 255 at byte 1"},
+	} {
+		_, err := DecodeCayenneLPP(decodeHex(t, test.payload))
+		if err == nil || err.Error() != test.err {
+			t.Errorf("%s: err = %v, want %q", test.name, err, test.err)
+		}
+	}
+}
+
+func TestReadInt(t *testing.T) {
+	for _, test := range []struct {
+		bytes        []byte
+		signed       bool
+		littleEndian bool
+		want         int64
+	}{
+		{[]byte{0x01, 0x02}, false, false, 0x0102},
+		{[]byte{0x01, 0x02}, false, true, 0x0201},
+		{[]byte{0xFF, 0xFE}, true, false, -2},
+		{[]byte{0xFE, 0xFF}, true, true, -2},
+		{[]byte{0xFF, 0xFE}, false, false, 0xFFFE},
+		{[]byte{0x80, 0x00, 0x00}, true, false, -0x800000},
+		{[]byte{0x7F, 0xFF, 0xFF}, true, false, 0x7FFFFF},
+		{[]byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}, true, false, -1},
+	} {
+		if got := readInt(test.bytes, test.signed, test.littleEndian); got != test.want {
+			t.Errorf("readInt(% x, %v, %v) = %d, want %d", test.bytes, test.signed, test.littleEndian, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Package decoder turns raw binary uplink payloads into named measurements.
+// Decoders are either built-in Go implementations registered by name or a
+// declarative byte Layout stored per hardware model.
+package decoder
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MaxPayloadSize bounds accepted payloads; LoRaWAN frames are at most 242 bytes.
+const MaxPayloadSize = 256
+
+// Measurement is a single decoded value, named after the channel it came from.
+type Measurement struct {
+	Name  string  `json:"name"`
+	Value float64 `json:"value"`
+}
+
+type Decoder interface {
+	Decode(payload []byte) ([]Measurement, error)
+}
+
+// DecoderFunc adapts a function to the Decoder interface.
+type DecoderFunc func(payload []byte) ([]Measurement, error)
+
+func (f DecoderFunc) Decode(payload []byte) ([]Measurement, error) {
+	return f(payload)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Decoder{}
+)
+
+// Register makes a built-in decoder available under a name. It panics on
+// duplicates since registration happens at init time.
+func Register(name string, d Decoder) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, exists := registry[name]; exists {
+		panic("decoder: duplicate registration of " + name)
+	}
+	registry[name] = d
+}
+
+func Lookup(name string) (Decoder, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	d, ok := registry[name]
+	return d, ok
+}
+
+// Names lists the registered built-in decoders.
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParsePayload decodes a payload given as hex or base64. With an empty
+// encoding, even-length hex strings are read as hex and anything else as
+// base64.
+func ParsePayload(payload string, encoding string) ([]byte, error) {
+	payload = strings.TrimSpace(payload)
+	if payload == "" {
+		return nil, errors.New("payload is empty")
+	}
+
+	var data []byte
+	var err error
+	switch encoding {
+	case "hex":
+		data, err = hex.DecodeString(payload)
+	case "base64":
+		data, err = base64.StdEncoding.DecodeString(payload)
+	case "":
+		data, err = hex.DecodeString(payload)
+		if err != nil {
+			data, err = base64.StdEncoding.DecodeString(payload)
+		}
+	default:
+		return nil, fmt.Errorf("unknown encoding %q", encoding)
+	}
+	if err != nil {
+		if encoding == "" {
+			encoding = "hex or base64"
+		}
+		return nil, fmt.Errorf("payload is not valid %s", encoding)
+	}
+	if len(data) > MaxPayloadSize {
+		return nil, fmt.Errorf("payload is larger than %d bytes", MaxPayloadSize)
+	}
+	return data, nil
+}
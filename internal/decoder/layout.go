@@ -0,0 +1,149 @@
+package decoder
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+)
+
+// MaxLayoutFields bounds the size of a declarative layout.
+const MaxLayoutFields = 64
+
+var fieldSizes = map[string]int{
+	"u8": 1, "i8": 1,
+	"u16": 2, "i16": 2,
+	"u24": 3, "i24": 3,
+	"u32": 4, "i32": 4,
+	"f32": 4, "f64": 8,
+}
+
+var fieldNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]{0,99}$`)
+
+// Field reads one value at a fixed byte offset. Integer fields may be masked
+// and shifted to extract bit fields; the result is raw*Scale + Offset.
+type Field struct {
+	Name   string   `json:"name"`
+	Offset int      `json:"offset"`
+	Type   string   `json:"type"`
+	Endian string   `json:"endian,omitempty"`
+	Mask   uint64   `json:"mask,omitempty"`
+	Shift  uint     `json:"shift,omitempty"`
+	Scale  *float64 `json:"scale,omitempty"`
+	Add    float64  `json:"add,omitempty"`
+}
+
+// Layout is a declarative decoder: a list of fields read from the payload.
+// It only reads within the payload bounds and performs no other operations.
+type Layout []Field
+
+// Validate checks a layout before it is stored.
+func (l Layout) Validate() error {
+	if len(l) == 0 {
+		return errors.New("layout has no fields")
+	}
+	if len(l) > MaxLayoutFields {
+		return fmt.Errorf("layout has more than %d fields", MaxLayoutFields)
+	}
+
+	names := map[string]bool{}
+	for i, field := range l {
+		if !fieldNamePattern.MatchString(field.Name) {
+			return fmt.Errorf("field %d: invalid name %q", i, field.Name)
+		}
+		if names[field.Name] {
+			return fmt.Errorf("field %d: duplicate name %q", i, field.Name)
+		}
+		names[field.Name] = true
+
+		size, ok := fieldSizes[field.Type]
+		if !ok {
+			return fmt.Errorf("field %s: unknown type %q", field.Name, field.Type)
+		}
+		if field.Offset < 0 || field.Offset+size > MaxPayloadSize {
+			return fmt.Errorf("field %s: offset %d is out of range", field.Name, field.Offset)
+		}
+		if field.Endian != "" && field.Endian != "big" && field.Endian != "little" {
+			return fmt.Errorf("field %s: endian must be big or little", field.Name)
+		}
+		if (field.Mask != 0 || field.Shift != 0) && (field.Type == "f32" || field.Type == "f64") {
+			return fmt.Errorf("field %s: mask and shift apply to integer types only", field.Name)
+		}
+		if field.Shift >= uint(8*size) {
+			return fmt.Errorf("field %s: shift is larger than the field", field.Name)
+		}
+	}
+	return nil
+}
+
+func (l Layout) Decode(payload []byte) ([]Measurement, error) {
+	measurements := make([]Measurement, 0, len(l))
+	for _, field := range l {
+		size := fieldSizes[field.Type]
+		if field.Offset+size > len(payload) {
+			return nil, fmt.Errorf("field %s: payload is %d bytes, need %d", field.Name, len(payload), field.Offset+size)
+		}
+		b := payload[field.Offset : field.Offset+size]
+		littleEndian := field.Endian == "little"
+
+		var raw float64
+		switch field.Type {
+		case "f32":
+			raw = float64(math.Float32frombits(uint32(readInt(b, false, littleEndian))))
+		case "f64":
+			raw = math.Float64frombits(uint64(readInt(b, false, littleEndian)))
+		default:
+			signed := field.Type[0] == 'i'
+			if field.Mask != 0 || field.Shift != 0 {
+				// bit fields are extracted from the unsigned value
+				u := uint64(readInt(b, false, littleEndian))
+				if field.Mask != 0 {
+					u &= field.Mask
+				}
+				raw = float64(u >> field.Shift)
+			} else {
+				raw = float64(readInt(b, signed, littleEndian))
+			}
+		}
+
+		scale := 1.0
+		if field.Scale != nil {
+			scale = *field.Scale
+		}
+		value := raw*scale + field.Add
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return nil, fmt.Errorf("field %s: value is not a finite number", field.Name)
+		}
+		measurements = append(measurements, Measurement{Name: field.Name, Value: value})
+	}
+	return measurements, nil
+}
+
+func (l Layout) Value() (driver.Value, error) {
+	if l == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (l *Layout) Scan(value any) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into Layout", value)
+	}
+	return json.Unmarshal(data, l)
+}
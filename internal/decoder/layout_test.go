@@ -0,0 +1,133 @@
+package decoder
+
+import (
+	"strings"
+	"testing"
+)
+
+func scale(value float64) *float64 {
+	return &value
+}
+
+func TestLayoutDecode(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		layout  Layout
+		payload string
+		want    []Measurement
+	}{
+		{"endianness", Layout{
+			{Name: "big", Offset: 0, Type: "u16"},
+			{Name: "little", Offset: 0, Type: "u16", Endian: "little"},
+		}, "01 02", []Measurement{{"big", 0x0102}, {"little", 0x0201}}},
+		{"signed", Layout{
+			{Name: "i8", Offset: 0, Type: "i8"},
+			{Name: "u8", Offset: 0, Type: "u8"},
+			{Name: "i16", Offset: 1, Type: "i16"},
+			{Name: "i16le", Offset: 1, Type: "i16", Endian: "little"},
+			{Name: "i24", Offset: 3, Type: "i24"},
+			{Name: "i32", Offset: 6, Type: "i32", Endian: "little"},
+		}, "FF FF 38 80 00 00 FE FF FF FF", []Measurement{
+			{"i8", -1}, {"u8", 255}, {"i16", -200}, {"i16le", 0x38FF}, {"i24", -0x800000}, {"i32", -2},
+		}},
+		{"scaled", Layout{
+			{Name: "temperature", Offset: 0, Type: "i16", Scale: scale(0.01), Add: -40},
+		}, "0F A0", []Measurement{{"temperature", 0}}},
+		{"bit fields", Layout{
+			// a signed type is masked as unsigned, so the top bit is data
+			{Name: "flag", Offset: 0, Type: "i8", Mask: 0x80, Shift: 7},
+			{Name: "level", Offset: 0, Type: "u8", Mask: 0x70, Shift: 4},
+			{Name: "low", Offset: 0, Type: "u8", Mask: 0x0F},
+			{Name: "high_nibble", Offset: 1, Type: "u16", Endian: "little", Shift: 12},
+		}, "D5 00 F0", []Measurement{{"flag", 1}, {"level", 5}, {"low", 5}, {"high_nibble", 15}}},
+		{"floats", Layout{
+			{Name: "f32", Offset: 0, Type: "f32"},
+			{Name: "f32le", Offset: 4, Type: "f32", Endian: "little"},
+			{Name: "f64", Offset: 8, Type: "f64"},
+		}, "41 C8 00 00 00 00 C8 C1 40 09 21 FB 54 44 2D 18", []Measurement{
+			{"f32", 25}, {"f32le", -25}, {"f64", 3.141592653589793},
+		}},
+		{"trailing bytes", Layout{
+			{Name: "first", Offset: 0, Type: "u8"},
+		}, "07 FF FF", []Measurement{{"first", 7}}},
+	} {
+		if err := test.layout.Validate(); err != nil {
+			t.Fatalf("%s: Validate: %v", test.name, err)
+		}
+		got, err := test.layout.Decode(decodeHex(t, test.payload))
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		expectMeasurements(t, test.name, got, test.want)
+	}
+}
+
+func TestLayoutDecodeRejects(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		layout  Layout
+		payload string
+		err     string
+	}{
+		{"truncated", Layout{{Name: "v", Offset: 1, Type: "u16"}}, "01 02", "field v: payload is 2 bytes, need 3"},
+		{"empty", Layout{{Name: "v", Offset: 0, Type: "u8"}}, "", "field v: payload is 0 bytes, need 1"},
+		{"nan", Layout{{Name: "v", Offset: 0, Type: "f32"}}, "7F C0 00 00", "field v: value is not a finite number"},
+		{"infinity", Layout{{Name: "v", Offset: 0, Type: "f64"}}, "7F F0 00 00 00 00 00 00", "field v: value is not a finite number"},
+		{"overflow", Layout{{Name: "v", Offset: 0, Type: "f32", Scale: scale(1e300)}}, "7F 7F FF FF", "field v: value is not a finite number"},
+	} {
+		_, err := test.layout.Decode(decodeHex(t, test.payload))
+		if err == nil || err.Error() != test.err {
+			t.Errorf("%s: err = %v, want %q", test.name, err, test.err)
+		}
+	}
+}
+
+func TestLayoutValidate(t *testing.T) {
+	tooMany := make(Layout, MaxLayoutFields+1)
+	for i := range tooMany {
+		tooMany[i] = Field{Name: "f" + strings.Repeat("x", i), Type: "u8"}
+	}
+
+	for _, test := range []struct {
+		name   string
+		layout Layout
+		err    string
+	}{
+		{"no fields", Layout{}, "layout has no fields"},
+		{"too many fields", tooMany, "more than"},
+		{"bad name", Layout{{Name: "1st", Type: "u8"}}, "invalid name"},
+		{"duplicate", Layout{{Name: "a", Type: "u8"}, {Name: "a", Type: "u8", Offset: 1}}, "duplicate name"},
+		{"unknown type", Layout{{Name: "a", Type: "u12"}}, "unknown type"},
+		{"negative offset", Layout{{Name: "a", Type: "u8", Offset: -1}}, "out of range"},
+		{"past the payload", Layout{{Name: "a", Type: "u16", Offset: MaxPayloadSize - 1}}, "out of range"},
+		{"endian", Layout{{Name: "a", Type: "u16", Endian: "middle"}}, "endian must be"},
+		{"masked float", Layout{{Name: "a", Type: "f32", Mask: 1}}, "integer types only"},
+		{"shift", Layout{{Name: "a", Type: "u8", Shift: 8}}, "shift is larger"},
+	} {
+		err := test.layout.Validate()
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: err = %v, want %q", test.name, err, test.err)
+		}
+	}
+}
+
+func TestLayoutScan(t *testing.T) {
+	layout := Layout{{Name: "v", Offset: 2, Type: "i16", Endian: "little", Scale: scale(0.5)}}
+
+	value, err := layout.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var scanned Layout
+	if err := scanned.Scan(value); err != nil {
+		t.Fatal(err)
+	}
+	if len(scanned) != 1 || scanned[0].Name != "v" || scanned[0].Offset != 2 ||
+		scanned[0].Endian != "little" || *scanned[0].Scale != 0.5 {
+		t.Fatalf("scanned %+v", scanned)
+	}
+
+	if err := scanned.Scan(nil); err != nil || scanned != nil {
+		t.Fatalf("scan nil: %v %v", scanned, err)
+	}
+}
@@ -0,0 +1,551 @@
+package http
+
+import (
+	"errors"
+	"mertani_test/internal/model"
+	"mertani_test/internal/usecase"
+	"mertani_test/internal/utils"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type AlertController struct {
+	Log     *logrus.Logger
+	UseCase *usecase.AlertUseCase
+}
+
+func NewAlertController(useCase *usecase.AlertUseCase, logger *logrus.Logger) *AlertController {
+	return &AlertController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+// Raise godoc
+// @Summary Raise Alert
+// @Description Fire an alert about a device or sensor. Raising an alert whose fingerprint is firing already updates that alert and notifies it again unless it is acknowledged or silenced. The fingerprint defaults to one derived from the title, device and sensor; labels add to those of the device and sensor, which silences and escalation policies match on.
+// @Tags Alerts
+// @Accept json
+// @Produce json
+// @Param request body model.RaiseAlertRequest true "Raise Alert Request"
+// @Success 201 {object} model.AlertResponse
+// @Failure 400 {object} map[string]interface{}
+// @Router /alerts [post]
+func (c *AlertController) Raise(ctx *fiber.Ctx) error {
+	request := new(model.RaiseAlertRequest)
+
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to parse request body"))
+	}
+
+	alert, err := c.UseCase.Raise(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to raise alert : %+v", err)
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusCreated).
+		JSON(utils.SuccessResponse(fiber.StatusCreated, "alert raised successfully", alert))
+}
+
+// FindAll godoc
+// @Summary Get Alerts List
+// @Description Get list of alerts with pagination
+// @Tags Alerts
+// @Accept json
+// @Produce json
+// @Param status query string false "firing or resolved"
+// @Param severity query string false "info, warning or critical"
+// @Param acknowledged query bool false "Acknowledged or not"
+// @Param device_id query string false "Device ID"
+// @Param sensor_id query string false "Sensor ID"
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Param order_by query string false "Order by field"
+// @Param sort_by query string false "Sort by direction (asc/desc)"
+// @Success 200 {object} model.AlertResponse
+// @Failure 400 {object} map[string]interface{}
+// @Router /alerts [get]
+func (c *AlertController) FindAll(ctx *fiber.Ctx) error {
+	req := &utils.PaginationRequest{
+		Page:    ctx.QueryInt("page", 1),
+		Limit:   ctx.QueryInt("limit", 10),
+		OrderBy: ctx.Query("order_by", "created_at"),
+		SortBy:  ctx.Query("sort_by", "desc"),
+	}
+
+	filter := &model.AlertFilter{
+		Status:   ctx.Query("status"),
+		Severity: ctx.Query("severity"),
+		DeviceID: ctx.Query("device_id"),
+		SensorID: ctx.Query("sensor_id"),
+	}
+	if value := ctx.Query("acknowledged"); value != "" {
+		acknowledged, err := strconv.ParseBool(value)
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, "acknowledged must be true or false"))
+		}
+		filter.Acknowledged = &acknowledged
+	}
+
+	alerts, pagination, err := c.UseCase.FindAll(ctx.Context(), req, filter)
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponseWithPagination(fiber.StatusOK, "get list alert successfully", alerts, pagination))
+}
+
+// FindByID godoc
+// @Summary Get Alert by ID
+// @Description Get alert details by ID with its comments
+// @Tags Alerts
+// @Accept json
+// @Produce json
+// @Param id path string true "Alert ID"
+// @Success 200 {object} model.AlertResponse
+// @Failure 404 {object} map[string]interface{}
+// @Router /alerts/{id} [get]
+func (c *AlertController) FindByID(ctx *fiber.Ctx) error {
+	alert, err := c.UseCase.FindByID(ctx.Context(), ctx.Params("id"))
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "alert not found"))
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponse(fiber.StatusOK, "get detail alert successfully", alert))
+}
+
+// Acknowledge godoc
+// @Summary Acknowledge Alert
+// @Description Mark a firing alert as taken care of, which stops its escalation. The body is optional.
+// @Tags Alerts
+// @Accept json
+// @Produce json
+// @Param id path string true "Alert ID"
+// @Param request body model.AcknowledgeAlertRequest false "Acknowledge Alert Request"
+// @Success 200 {object} model.AlertResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /alerts/{id}/acknowledge [post]
+func (c *AlertController) Acknowledge(ctx *fiber.Ctx) error {
+	request := new(model.AcknowledgeAlertRequest)
+
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(request); err != nil {
+			c.Log.Warnf("Failed to parse request body : %+v", err)
+			return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to parse request body"))
+		}
+	}
+
+	alert, err := c.UseCase.Acknowledge(ctx.Context(), ctx.Params("id"), request)
+	if err != nil {
+		return c.actionError(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponse(fiber.StatusOK, "acknowledge alert successfully", alert))
+}
+
+// Resolve godoc
+// @Summary Resolve Alert
+// @Description Resolve a firing alert, acknowledged or not, and notify the resolution unless it is silenced. The body is optional.
+// @Tags Alerts
+// @Accept json
+// @Produce json
+// @Param id path string true "Alert ID"
+// @Param request body model.ResolveAlertRequest false "Resolve Alert Request"
+// @Success 200 {object} model.AlertResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /alerts/{id}/resolve [post]
+func (c *AlertController) Resolve(ctx *fiber.Ctx) error {
+	request := new(model.ResolveAlertRequest)
+
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(request); err != nil {
+			c.Log.Warnf("Failed to parse request body : %+v", err)
+			return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to parse request body"))
+		}
+	}
+
+	alert, err := c.UseCase.Resolve(ctx.Context(), ctx.Params("id"), request)
+	if err != nil {
+		return c.actionError(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponse(fiber.StatusOK, "resolve alert successfully", alert))
+}
+
+// AddComment godoc
+// @Summary Comment on Alert
+// @Description Add a comment to an alert
+// @Tags Alerts
+// @Accept json
+// @Produce json
+// @Param id path string true "Alert ID"
+// @Param request body model.CreateAlertCommentRequest true "Alert Comment Request"
+// @Success 201 {object} model.AlertCommentResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /alerts/{id}/comments [post]
+func (c *AlertController) AddComment(ctx *fiber.Ctx) error {
+	request := new(model.CreateAlertCommentRequest)
+
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to parse request body"))
+	}
+
+	comment, err := c.UseCase.AddComment(ctx.Context(), ctx.Params("id"), request)
+	if err != nil {
+		return c.actionError(ctx, err)
+	}
+
+	return ctx.Status(fiber.StatusCreated).
+		JSON(utils.SuccessResponse(fiber.StatusCreated, "alert comment created successfully", comment))
+}
+
+// actionError answers the error of an action on an alert.
+func (c *AlertController) actionError(ctx *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, utils.ErrValidation):
+		return ctx.Status(fiber.StatusBadRequest).
+			JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+	case errors.Is(err, utils.ErrNotFound):
+		return ctx.Status(fiber.StatusNotFound).
+			JSON(utils.ErrorResponse(fiber.StatusNotFound, "alert not found"))
+
+	case errors.Is(err, utils.ErrConflict):
+		return ctx.Status(fiber.StatusConflict).
+			JSON(utils.ErrorResponse(fiber.StatusConflict, err.Error()))
+
+	default: // internal error
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+	}
+}
+
+// CreateSilence godoc
+// @Summary Create Silence
+// @Description Mute firing alerts whose labels match selector and, when given, whose sensor is of sensor_type, from starts_at (default now) to ends_at or for duration_minutes. Muted alerts are neither notified nor escalated; those still firing when the silence ends or is deleted are notified then.
+// @Tags Alerts
+// @Accept json
+// @Produce json
+// @Param request body model.CreateSilenceRequest true "Silence Request"
+// @Success 201 {object} model.SilenceResponse
+// @Failure 400 {object} map[string]interface{}
+// @Router /silences [post]
+func (c *AlertController) CreateSilence(ctx *fiber.Ctx) error {
+	request := new(model.CreateSilenceRequest)
+
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to parse request body"))
+	}
+
+	silence, err := c.UseCase.CreateSilence(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to create silence : %+v", err)
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusCreated).
+		JSON(utils.SuccessResponse(fiber.StatusCreated, "silence created successfully", silence))
+}
+
+// FindAllSilences godoc
+// @Summary Get Silences List
+// @Description Get list of silences with pagination
+// @Tags Alerts
+// @Accept json
+// @Produce json
+// @Param state query string false "pending, active or expired"
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Param order_by query string false "Order by field"
+// @Param sort_by query string false "Sort by direction (asc/desc)"
+// @Success 200 {object} model.SilenceResponse
+// @Failure 400 {object} map[string]interface{}
+// @Router /silences [get]
+func (c *AlertController) FindAllSilences(ctx *fiber.Ctx) error {
+	req := &utils.PaginationRequest{
+		Page:    ctx.QueryInt("page", 1),
+		Limit:   ctx.QueryInt("limit", 10),
+		OrderBy: ctx.Query("order_by", "created_at"),
+		SortBy:  ctx.Query("sort_by", "desc"),
+	}
+
+	silences, pagination, err := c.UseCase.FindAllSilences(ctx.Context(), req, &model.SilenceFilter{State: ctx.Query("state")})
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponseWithPagination(fiber.StatusOK, "get list silence successfully", silences, pagination))
+}
+
+// FindSilenceByID godoc
+// @Summary Get Silence by ID
+// @Description Get silence details by ID
+// @Tags Alerts
+// @Accept json
+// @Produce json
+// @Param id path string true "Silence ID"
+// @Success 200 {object} model.SilenceResponse
+// @Failure 404 {object} map[string]interface{}
+// @Router /silences/{id} [get]
+func (c *AlertController) FindSilenceByID(ctx *fiber.Ctx) error {
+	silence, err := c.UseCase.FindSilenceByID(ctx.Context(), ctx.Params("id"))
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "silence not found"))
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponse(fiber.StatusOK, "get detail silence successfully", silence))
+}
+
+// DeleteSilence godoc
+// @Summary Delete Silence
+// @Description Delete silence by ID, ending it early
+// @Tags Alerts
+// @Accept json
+// @Produce json
+// @Param id path string true "Silence ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /silences/{id} [delete]
+func (c *AlertController) DeleteSilence(ctx *fiber.Ctx) error {
+	err := c.UseCase.DeleteSilence(ctx.Context(), ctx.Params("id"))
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "silence not found"))
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.DefaultSuccessResponse(fiber.StatusOK, "delete silence successfully"))
+}
+
+// CreateEscalationPolicy godoc
+// @Summary Create Escalation Policy
+// @Description Escalate alerts of at least min_severity whose labels match selector and, when given, whose sensor is of sensor_type. Each step notifies its channels once the alert has stayed unacknowledged for after_minutes since it fired or since the previous step. A new alert follows the oldest active policy applying to it.
+// @Tags Alerts
+// @Accept json
+// @Produce json
+// @Param request body model.CreateEscalationPolicyRequest true "Escalation Policy Request"
+// @Success 201 {object} model.EscalationPolicyResponse
+// @Failure 400 {object} map[string]interface{}
+// @Router /escalation-policies [post]
+func (c *AlertController) CreateEscalationPolicy(ctx *fiber.Ctx) error {
+	request := new(model.CreateEscalationPolicyRequest)
+
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to parse request body"))
+	}
+
+	policy, err := c.UseCase.CreateEscalationPolicy(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to create escalation policy : %+v", err)
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusCreated).
+		JSON(utils.SuccessResponse(fiber.StatusCreated, "escalation policy created successfully", policy))
+}
+
+// FindAllEscalationPolicies godoc
+// @Summary Get Escalation Policies List
+// @Description Get list of escalation policies with pagination
+// @Tags Alerts
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Param order_by query string false "Order by field"
+// @Param sort_by query string false "Sort by direction (asc/desc)"
+// @Success 200 {object} model.EscalationPolicyResponse
+// @Failure 500 {object} map[string]interface{}
+// @Router /escalation-policies [get]
+func (c *AlertController) FindAllEscalationPolicies(ctx *fiber.Ctx) error {
+	req := &utils.PaginationRequest{
+		Page:    ctx.QueryInt("page", 1),
+		Limit:   ctx.QueryInt("limit", 10),
+		OrderBy: ctx.Query("order_by", "created_at"),
+		SortBy:  ctx.Query("sort_by", "desc"),
+	}
+
+	policies, pagination, err := c.UseCase.FindAllEscalationPolicies(ctx.Context(), req)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponseWithPagination(fiber.StatusOK, "get list escalation policy successfully", policies, pagination))
+}
+
+// FindEscalationPolicyByID godoc
+// @Summary Get Escalation Policy by ID
+// @Description Get escalation policy details by ID
+// @Tags Alerts
+// @Accept json
+// @Produce json
+// @Param id path string true "Escalation Policy ID"
+// @Success 200 {object} model.EscalationPolicyResponse
+// @Failure 404 {object} map[string]interface{}
+// @Router /escalation-policies/{id} [get]
+func (c *AlertController) FindEscalationPolicyByID(ctx *fiber.Ctx) error {
+	policy, err := c.UseCase.FindEscalationPolicyByID(ctx.Context(), ctx.Params("id"))
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "escalation policy not found"))
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponse(fiber.StatusOK, "get detail escalation policy successfully", policy))
+}
+
+// UpdateEscalationPolicy godoc
+// @Summary Update Escalation Policy
+// @Description Update escalation policy by ID; alerts already following it take the new steps from their next escalation on
+// @Tags Alerts
+// @Accept json
+// @Produce json
+// @Param id path string true "Escalation Policy ID"
+// @Param request body model.UpdateEscalationPolicyRequest true "Escalation Policy Request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /escalation-policies/{id} [put]
+func (c *AlertController) UpdateEscalationPolicy(ctx *fiber.Ctx) error {
+	request := new(model.UpdateEscalationPolicyRequest)
+	id := ctx.Params("id")
+
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to parse request body"))
+	}
+
+	err = c.UseCase.UpdateEscalationPolicy(ctx.Context(), id, request)
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrNotFound):
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "escalation policy not found"))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.DefaultSuccessResponse(fiber.StatusOK, "update escalation policy successfully"))
+}
+
+// DeleteEscalationPolicy godoc
+// @Summary Delete Escalation Policy
+// @Description Delete escalation policy by ID, which stops the escalation of the alerts following it
+// @Tags Alerts
+// @Accept json
+// @Produce json
+// @Param id path string true "Escalation Policy ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /escalation-policies/{id} [delete]
+func (c *AlertController) DeleteEscalationPolicy(ctx *fiber.Ctx) error {
+	err := c.UseCase.DeleteEscalationPolicy(ctx.Context(), ctx.Params("id"))
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "escalation policy not found"))
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.DefaultSuccessResponse(fiber.StatusOK, "delete escalation policy successfully"))
+}
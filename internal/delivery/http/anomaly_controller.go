@@ -0,0 +1,263 @@
+package http
+
+import (
+	"errors"
+	"mertani_test/internal/model"
+	"mertani_test/internal/usecase"
+	"mertani_test/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type AnomalyController struct {
+	Log     *logrus.Logger
+	UseCase *usecase.AnomalyUseCase
+}
+
+func NewAnomalyController(useCase *usecase.AnomalyUseCase, logger *logrus.Logger) *AnomalyController {
+	return &AnomalyController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+// CreateDetector godoc
+// @Summary Create Anomaly Detector
+// @Description Run a detector on the readings of a sensor, or of every sensor of a sensor type. A detector set on a sensor replaces one of the same type set on its sensor type. Types are zscore (window, threshold, min_samples), flatline (count, tolerance), spike (window, ratio or delta) and rate_of_change (max_rate, per_seconds). With raise_alert, which is the default, every anomaly found fires an alert of the detector's severity.
+// @Tags Anomalies
+// @Accept json
+// @Produce json
+// @Param request body model.CreateAnomalyDetectorRequest true "Anomaly Detector Request"
+// @Success 201 {object} model.AnomalyDetectorResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /anomaly-detectors [post]
+func (c *AnomalyController) CreateDetector(ctx *fiber.Ctx) error {
+	request := new(model.CreateAnomalyDetectorRequest)
+
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to parse request body"))
+	}
+
+	detector, err := c.UseCase.CreateDetector(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to create anomaly detector : %+v", err)
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrConflict):
+			return ctx.Status(fiber.StatusConflict).
+				JSON(utils.ErrorResponse(fiber.StatusConflict, err.Error()))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusCreated).
+		JSON(utils.SuccessResponse(fiber.StatusCreated, "anomaly detector created successfully", detector))
+}
+
+// FindAllDetectors godoc
+// @Summary Get Anomaly Detectors List
+// @Description Get list of anomaly detectors with pagination
+// @Tags Anomalies
+// @Accept json
+// @Produce json
+// @Param sensor_id query string false "Sensor ID"
+// @Param sensor_type query string false "Sensor type"
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Param order_by query string false "Order by field"
+// @Param sort_by query string false "Sort by direction (asc/desc)"
+// @Success 200 {object} model.AnomalyDetectorResponse
+// @Failure 400 {object} map[string]interface{}
+// @Router /anomaly-detectors [get]
+func (c *AnomalyController) FindAllDetectors(ctx *fiber.Ctx) error {
+	req := &utils.PaginationRequest{
+		Page:    ctx.QueryInt("page", 1),
+		Limit:   ctx.QueryInt("limit", 10),
+		OrderBy: ctx.Query("order_by", "created_at"),
+		SortBy:  ctx.Query("sort_by", "desc"),
+	}
+
+	filter := &model.AnomalyDetectorFilter{
+		SensorID:   ctx.Query("sensor_id"),
+		SensorType: ctx.Query("sensor_type"),
+	}
+
+	detectors, pagination, err := c.UseCase.FindAllDetectors(ctx.Context(), req, filter)
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponseWithPagination(fiber.StatusOK, "get list anomaly detector successfully", detectors, pagination))
+}
+
+// FindDetectorByID godoc
+// @Summary Get Anomaly Detector by ID
+// @Description Get anomaly detector details by ID
+// @Tags Anomalies
+// @Accept json
+// @Produce json
+// @Param id path string true "Anomaly Detector ID"
+// @Success 200 {object} model.AnomalyDetectorResponse
+// @Failure 404 {object} map[string]interface{}
+// @Router /anomaly-detectors/{id} [get]
+func (c *AnomalyController) FindDetectorByID(ctx *fiber.Ctx) error {
+	detector, err := c.UseCase.FindDetectorByID(ctx.Context(), ctx.Params("id"))
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "anomaly detector not found"))
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponse(fiber.StatusOK, "get detail anomaly detector successfully", detector))
+}
+
+// UpdateDetector godoc
+// @Summary Update Anomaly Detector
+// @Description Update anomaly detector by ID; params given replace the stored ones as a whole
+// @Tags Anomalies
+// @Accept json
+// @Produce json
+// @Param id path string true "Anomaly Detector ID"
+// @Param request body model.UpdateAnomalyDetectorRequest true "Anomaly Detector Request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /anomaly-detectors/{id} [put]
+func (c *AnomalyController) UpdateDetector(ctx *fiber.Ctx) error {
+	request := new(model.UpdateAnomalyDetectorRequest)
+	id := ctx.Params("id")
+
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to parse request body"))
+	}
+
+	err = c.UseCase.UpdateDetector(ctx.Context(), id, request)
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrNotFound):
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "anomaly detector not found"))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.DefaultSuccessResponse(fiber.StatusOK, "update anomaly detector successfully"))
+}
+
+// DeleteDetector godoc
+// @Summary Delete Anomaly Detector
+// @Description Delete anomaly detector by ID; the anomalies it found are kept
+// @Tags Anomalies
+// @Accept json
+// @Produce json
+// @Param id path string true "Anomaly Detector ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /anomaly-detectors/{id} [delete]
+func (c *AnomalyController) DeleteDetector(ctx *fiber.Ctx) error {
+	err := c.UseCase.DeleteDetector(ctx.Context(), ctx.Params("id"))
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "anomaly detector not found"))
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.DefaultSuccessResponse(fiber.StatusOK, "delete anomaly detector successfully"))
+}
+
+// FindBySensor godoc
+// @Summary Get Sensor Anomalies
+// @Description Get the anomalies found in a sensor's readings with pagination, newest first by default
+// @Tags Anomalies
+// @Accept json
+// @Produce json
+// @Param id path string true "Sensor ID"
+// @Param from query string false "Start of the time range (RFC3339)"
+// @Param to query string false "End of the time range (RFC3339)"
+// @Param detector query string false "zscore, flatline, spike or rate_of_change"
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Param order_by query string false "Order by field"
+// @Param sort_by query string false "Sort by direction (asc/desc)"
+// @Success 200 {object} model.AnomalyResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /sensors/{id}/anomalies [get]
+func (c *AnomalyController) FindBySensor(ctx *fiber.Ctx) error {
+	req := &utils.PaginationRequest{
+		Page:    ctx.QueryInt("page", 1),
+		Limit:   ctx.QueryInt("limit", 10),
+		OrderBy: ctx.Query("order_by", "recorded_at"),
+		SortBy:  ctx.Query("sort_by", "desc"),
+	}
+
+	filter := &model.AnomalyFilter{Detector: ctx.Query("detector")}
+	var err error
+	if filter.From, err = parseTimeQuery(ctx, "from"); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).
+			JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+	if filter.To, err = parseTimeQuery(ctx, "to"); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).
+			JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	anomalies, pagination, err := c.UseCase.FindBySensor(ctx.Context(), ctx.Params("id"), filter, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrNotFound):
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "sensor not found"))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponseWithPagination(fiber.StatusOK, "get list anomaly successfully", anomalies, pagination))
+}
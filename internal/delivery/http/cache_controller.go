@@ -0,0 +1,33 @@
+package http
+
+import (
+	"mertani_test/internal/usecase"
+	"mertani_test/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type CacheController struct {
+	Log     *logrus.Logger
+	UseCase *usecase.LookupCache
+}
+
+func NewCacheController(useCase *usecase.LookupCache, logger *logrus.Logger) *CacheController {
+	return &CacheController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+// Stats godoc
+// @Summary Get Cache Stats
+// @Description Get the hits, misses and store errors of the device and sensor lookup cache since the application started. Counters are kept per instance, also when the cache itself is shared through Redis.
+// @Tags Cache
+// @Produce json
+// @Success 200 {object} model.CacheStatsResponse
+// @Router /cache/stats [get]
+func (c *CacheController) Stats(ctx *fiber.Ctx) error {
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponse(fiber.StatusOK, "get cache stats successfully", c.UseCase.Stats()))
+}
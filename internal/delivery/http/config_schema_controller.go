@@ -0,0 +1,194 @@
+package http
+
+import (
+	"errors"
+	"mertani_test/internal/model"
+	"mertani_test/internal/usecase"
+	"mertani_test/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type ConfigSchemaController struct {
+	Log     *logrus.Logger
+	UseCase *usecase.ConfigSchemaUseCase
+}
+
+func NewConfigSchemaController(useCase *usecase.ConfigSchemaUseCase, logger *logrus.Logger) *ConfigSchemaController {
+	return &ConfigSchemaController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+// CreateConfigSchema godoc
+// @Summary Create Config Schema
+// @Description Create config schema for a hardware model
+// @Tags Config Schemas
+// @Accept json
+// @Produce json
+// @Param request body model.CreateConfigSchemaRequest true "Config Schema Request"
+// @Success 200 {object} model.ConfigSchemaResponse
+// @Failure 400 {object} map[string]interface{}
+// @Router /config-schemas [post]
+func (c *ConfigSchemaController) Create(ctx *fiber.Ctx) error {
+	request := new(model.CreateConfigSchemaRequest)
+
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to parse request body"))
+	}
+
+	err = c.UseCase.Create(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to create config schema : %+v", err)
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrConflict):
+			return ctx.Status(fiber.StatusConflict).
+				JSON(utils.ErrorResponse(fiber.StatusConflict, err.Error()))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusCreated).
+		JSON(utils.DefaultSuccessResponse(fiber.StatusCreated, "config schema created successfully"))
+}
+
+// FindAll godoc
+// @Summary Get Config Schemas List
+// @Description Get list of config schemas with pagination
+// @Tags Config Schemas
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Param order_by query string false "Order by field"
+// @Param sort_by query string false "Sort by direction (asc/desc)"
+// @Success 200 {object} model.ConfigSchemaResponse
+// @Failure 500 {object} map[string]interface{}
+// @Router /config-schemas [get]
+func (c *ConfigSchemaController) FindAll(ctx *fiber.Ctx) error {
+	req := &utils.PaginationRequest{
+		Page:    ctx.QueryInt("page", 1),
+		Limit:   ctx.QueryInt("limit", 10),
+		OrderBy: ctx.Query("order_by", "created_at"),
+		SortBy:  ctx.Query("sort_by", "desc"),
+	}
+
+	schemas, pagination, err := c.UseCase.FindAll(ctx.Context(), req)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponseWithPagination(fiber.StatusOK, "get list config schema successfully", schemas, pagination))
+}
+
+// FindByID godoc
+// @Summary Get Config Schema by ID
+// @Description Get config schema details by ID
+// @Tags Config Schemas
+// @Accept json
+// @Produce json
+// @Param id path string true "Config Schema ID"
+// @Success 200 {object} model.ConfigSchemaResponse
+// @Failure 404 {object} map[string]interface{}
+// @Router /config-schemas/{id} [get]
+func (c *ConfigSchemaController) FindByID(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	schema, err := c.UseCase.FindByID(ctx.Context(), id)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "config schema not found"))
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponse(fiber.StatusOK, "get detail config schema successfully", schema))
+}
+
+// UpdateConfigSchema godoc
+// @Summary Update Config Schema
+// @Description Update config schema by ID
+// @Tags Config Schemas
+// @Accept json
+// @Produce json
+// @Param id path string true "Config Schema ID"
+// @Param request body model.UpdateConfigSchemaRequest true "Config Schema Request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /config-schemas/{id} [put]
+func (c *ConfigSchemaController) Update(ctx *fiber.Ctx) error {
+	request := new(model.UpdateConfigSchemaRequest)
+	id := ctx.Params("id")
+
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to parse request body"))
+	}
+
+	err = c.UseCase.Update(ctx.Context(), id, request)
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrNotFound):
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "config schema not found"))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.DefaultSuccessResponse(fiber.StatusOK, "update config schema successfully"))
+}
+
+// DeleteConfigSchema godoc
+// @Summary Delete Config Schema
+// @Description Delete config schema by ID
+// @Tags Config Schemas
+// @Accept json
+// @Produce json
+// @Param id path string true "Config Schema ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /config-schemas/{id} [delete]
+func (c *ConfigSchemaController) Delete(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	err := c.UseCase.Delete(ctx.Context(), id)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "config schema not found"))
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.DefaultSuccessResponse(fiber.StatusOK, "delete config schema successfully"))
+}
@@ -0,0 +1,211 @@
+package http
+
+import (
+	"errors"
+	"mertani_test/internal/model"
+	"mertani_test/internal/usecase"
+	"mertani_test/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type DeviceConfigController struct {
+	Log     *logrus.Logger
+	UseCase *usecase.DeviceConfigUseCase
+}
+
+func NewDeviceConfigController(useCase *usecase.DeviceConfigUseCase, logger *logrus.Logger) *DeviceConfigController {
+	return &DeviceConfigController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+// SetDesired godoc
+// @Summary Set Desired Device Config
+// @Description Store a new version of the desired config document, validated against the hardware model schema
+// @Tags Device Config
+// @Accept json
+// @Produce json
+// @Param id path string true "Device ID"
+// @Param request body model.UpdateDesiredConfigRequest true "Desired Config Request"
+// @Success 200 {object} model.DeviceConfigResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /devices/{id}/config/desired [put]
+func (c *DeviceConfigController) SetDesired(ctx *fiber.Ctx) error {
+	request := new(model.UpdateDesiredConfigRequest)
+	id := ctx.Params("id")
+
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to parse request body"))
+	}
+
+	config, err := c.UseCase.SetDesired(ctx.UserContext(), id, request)
+	if err != nil {
+		c.Log.Warnf("Failed to set desired config : %+v", err)
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrNotFound):
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "device not found"))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponse(fiber.StatusOK, "set desired config successfully", config))
+}
+
+// FindCurrent godoc
+// @Summary Get Device Config
+// @Description Get the latest desired and reported config of a device
+// @Tags Device Config
+// @Accept json
+// @Produce json
+// @Param id path string true "Device ID"
+// @Success 200 {object} model.DeviceConfigStateResponse
+// @Failure 404 {object} map[string]interface{}
+// @Router /devices/{id}/config [get]
+func (c *DeviceConfigController) FindCurrent(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	config, err := c.UseCase.FindCurrent(ctx.Context(), id)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "device not found"))
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponse(fiber.StatusOK, "get device config successfully", config))
+}
+
+// FindHistory godoc
+// @Summary Get Device Config History
+// @Description Get versioned config documents of a device
+// @Tags Device Config
+// @Accept json
+// @Produce json
+// @Param id path string true "Device ID"
+// @Param kind query string false "Config kind (desired or reported)"
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Success 200 {object} model.DeviceConfigResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /devices/{id}/config/history [get]
+func (c *DeviceConfigController) FindHistory(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	req := &utils.PaginationRequest{
+		Page:    ctx.QueryInt("page", 1),
+		Limit:   ctx.QueryInt("limit", 10),
+		OrderBy: "version",
+		SortBy:  "desc",
+	}
+
+	configs, pagination, err := c.UseCase.FindHistory(ctx.Context(), id, ctx.Query("kind", ""), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrNotFound):
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "device not found"))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponseWithPagination(fiber.StatusOK, "get device config history successfully", configs, pagination))
+}
+
+// Diff godoc
+// @Summary Get Device Config Drift
+// @Description Compare the latest desired config with the latest reported config
+// @Tags Device Config
+// @Accept json
+// @Produce json
+// @Param id path string true "Device ID"
+// @Success 200 {object} model.DeviceConfigDiffResponse
+// @Failure 404 {object} map[string]interface{}
+// @Router /devices/{id}/config/diff [get]
+func (c *DeviceConfigController) Diff(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	diff, err := c.UseCase.Diff(ctx.Context(), id)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "device not found"))
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponse(fiber.StatusOK, "get device config diff successfully", diff))
+}
+
+// CheckIn godoc
+// @Summary Device Check-In
+// @Description Record the config reported by the device and return any pending desired config
+// @Tags Device Config
+// @Accept json
+// @Produce json
+// @Param id path string true "Device ID"
+// @Param request body model.DeviceCheckInRequest true "Check-In Request"
+// @Success 200 {object} model.DeviceCheckInResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /devices/{id}/check-in [post]
+func (c *DeviceConfigController) CheckIn(ctx *fiber.Ctx) error {
+	request := new(model.DeviceCheckInRequest)
+	id := ctx.Params("id")
+
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to parse request body"))
+	}
+
+	checkIn, err := c.UseCase.CheckIn(ctx.UserContext(), id, request)
+	if err != nil {
+		c.Log.Warnf("Failed to check in device : %+v", err)
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrNotFound):
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "device not found"))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponse(fiber.StatusOK, "device check-in successfully", checkIn))
+}
@@ -1,7 +1,11 @@
 package http
 
 import (
+	"bufio"
+	"bytes"
 	"errors"
+	"fmt"
+	"io"
 	"mertani_test/internal/model"
 	"mertani_test/internal/usecase"
 	"mertani_test/internal/utils"
@@ -74,7 +78,12 @@ func (c *DeviceController) Create(ctx *fiber.Ctx) error {
 // @Param order_by query string false "Field to order by"
 // @Param sort_by query string false "Sort direction (asc or desc)"
 // @Param search query string false "Search term"
+// @Param near query string false "Center point as latitude,longitude"
+// @Param radius_km query number false "Radius around near in kilometres (default 5)"
+// @Param bbox query string false "Bounding box as min_lng,min_lat,max_lng,max_lat"
+// @Param selector query string false "Label selector, e.g. crop=rice,field in (A,B),!deprecated"
 // @Success 200 {object} model.DeviceResponse
+// @Failure 400 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /devices [get]
 func (c *DeviceController) FindAll(ctx *fiber.Ctx) error {
@@ -86,8 +95,19 @@ func (c *DeviceController) FindAll(ctx *fiber.Ctx) error {
 		Search:  ctx.Query("search", ""),
 	}
 
-	devices, pagination, err := c.UseCase.FindAll(ctx.Context(), req)
+	filter, err := parseDeviceFilter(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).
+			JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	devices, pagination, err := c.UseCase.FindAll(ctx.Context(), req, filter)
 	if err != nil {
+		if errors.Is(err, utils.ErrValidation) {
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
+
 		return ctx.Status(fiber.StatusInternalServerError).
 			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, err.Error()))
 	}
@@ -96,6 +116,53 @@ func (c *DeviceController) FindAll(ctx *fiber.Ctx) error {
 		JSON(utils.SuccessResponseWithPagination(fiber.StatusOK, "get list device successfully", devices, pagination))
 }
 
+// FindAllGeoJSON godoc
+// @Summary Export Devices as GeoJSON
+// @Description Get devices as a GeoJSON FeatureCollection with sensors and the latest reading per sensor
+// @Tags Devices
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page (default 1000)"
+// @Param order_by query string false "Field to order by"
+// @Param sort_by query string false "Sort direction (asc or desc)"
+// @Param search query string false "Search term"
+// @Param near query string false "Center point as latitude,longitude"
+// @Param radius_km query number false "Radius around near in kilometres (default 5)"
+// @Param bbox query string false "Bounding box as min_lng,min_lat,max_lng,max_lat"
+// @Param selector query string false "Label selector, e.g. crop=rice,field in (A,B),!deprecated"
+// @Success 200 {object} model.GeoJSONFeatureCollection
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /devices.geojson [get]
+func (c *DeviceController) FindAllGeoJSON(ctx *fiber.Ctx) error {
+	req := &utils.PaginationRequest{
+		Page:    ctx.QueryInt("page", 1),
+		Limit:   ctx.QueryInt("limit", 1000),
+		OrderBy: ctx.Query("order_by", "created_at"),
+		SortBy:  ctx.Query("sort_by", "desc"),
+		Search:  ctx.Query("search", ""),
+	}
+
+	filter, err := parseDeviceFilter(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).
+			JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	collection, err := c.UseCase.FindAllGeoJSON(ctx.Context(), req, filter)
+	if err != nil {
+		if errors.Is(err, utils.ErrValidation) {
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(collection, "application/geo+json")
+}
+
 // FindByID godoc
 // @Summary Get Device by ID
 // @Description Get device details by ID
@@ -148,13 +215,19 @@ func (c *DeviceController) Update(ctx *fiber.Ctx) error {
 
 	err = c.UseCase.Update(ctx.Context(), id, request)
 	if err != nil {
-		if errors.Is(err, utils.ErrNotFound) {
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrNotFound):
 			return ctx.Status(fiber.StatusNotFound).
 				JSON(utils.ErrorResponse(fiber.StatusNotFound, "device not found"))
-		}
 
-		return ctx.Status(fiber.StatusInternalServerError).
-			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
 	}
 
 	return ctx.Status(fiber.StatusOK).
@@ -188,3 +261,219 @@ func (c *DeviceController) Delete(ctx *fiber.Ctx) error {
 	return ctx.Status(fiber.StatusOK).
 		JSON(utils.DefaultSuccessResponse(fiber.StatusOK, "delete device successfully"))
 }
+
+// SetLabels godoc
+// @Summary Set Device Labels
+// @Description Add or overwrite labels on a device
+// @Tags Devices
+// @Accept json
+// @Produce json
+// @Param id path string true "Device ID"
+// @Param request body model.UpdateLabelsRequest true "Labels Request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /devices/{id}/labels [put]
+func (c *DeviceController) SetLabels(ctx *fiber.Ctx) error {
+	request := new(model.UpdateLabelsRequest)
+	id := ctx.Params("id")
+
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to parse request body"))
+	}
+
+	err = c.UseCase.SetLabels(ctx.Context(), id, request)
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrNotFound):
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "device not found"))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.DefaultSuccessResponse(fiber.StatusOK, "set device labels successfully"))
+}
+
+// RemoveLabel godoc
+// @Summary Remove Device Label
+// @Description Remove a label from a device
+// @Tags Devices
+// @Accept json
+// @Produce json
+// @Param id path string true "Device ID"
+// @Param key path string true "Label key"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /devices/{id}/labels/{key} [delete]
+func (c *DeviceController) RemoveLabel(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	err := c.UseCase.RemoveLabel(ctx.Context(), id, ctx.Params("key"))
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.DefaultSuccessResponse(fiber.StatusOK, "remove device label successfully"))
+}
+
+// Import godoc
+// @Summary Import Devices from CSV
+// @Description Create or update devices by name from CSV with the columns name, location, status, hardware_model, site_id, latitude, longitude and labels (key=value;key=value). Only name is required; columns left out are kept on update. Nothing is written when any row fails.
+// @Tags Devices
+// @Accept text/csv,mpfd
+// @Produce json
+// @Param dry_run query bool false "Validate only"
+// @Param file formData file false "CSV file, or send the CSV as the request body"
+// @Success 200 {object} model.ImportResponse
+// @Failure 400 {object} map[string]interface{}
+// @Router /devices/import [post]
+func (c *DeviceController) Import(ctx *fiber.Ctx) error {
+	reader, err := importReader(ctx)
+	if err != nil {
+		c.Log.Warnf("Failed to read import file : %+v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to read import file"))
+	}
+
+	result, err := c.UseCase.Import(ctx.UserContext(), reader, ctx.QueryBool("dry_run", false))
+	if err != nil {
+		c.Log.Warnf("Failed to import devices : %+v", err)
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	if result.Failed > 0 {
+		return ctx.Status(fiber.StatusBadRequest).
+			JSON(utils.ErrorResponseWithData(fiber.StatusBadRequest, fmt.Sprintf("%d of %d rows are invalid", result.Failed, result.Rows), result))
+	}
+
+	message := "import devices successfully"
+	if result.DryRun {
+		message = "import devices validated, nothing written"
+	}
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponse(fiber.StatusOK, message, result))
+}
+
+// Export godoc
+// @Summary Export Devices to CSV
+// @Description Stream devices as CSV in the import layout. Honors the list search and filters; rows come in id order.
+// @Tags Devices
+// @Produce text/csv
+// @Param search query string false "Search term"
+// @Param near query string false "Center point as latitude,longitude"
+// @Param radius_km query number false "Radius around near in kilometres (default 5)"
+// @Param bbox query string false "Bounding box as min_lng,min_lat,max_lng,max_lat"
+// @Param selector query string false "Label selector, e.g. crop=rice,field in (A,B),!deprecated"
+// @Success 200 {string} string "CSV"
+// @Failure 400 {object} map[string]interface{}
+// @Router /devices/export.csv [get]
+func (c *DeviceController) Export(ctx *fiber.Ctx) error {
+	filter, err := parseDeviceFilter(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).
+			JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	export, err := c.UseCase.Export(ctx.UserContext(), ctx.Query("search", ""), filter)
+	if err != nil {
+		if errors.Is(err, utils.ErrValidation) {
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+	}
+
+	return streamAttachment(ctx, "devices.csv", "text/csv; charset=utf-8", export)
+}
+
+func parseDeviceFilter(ctx *fiber.Ctx) (*model.DeviceFilter, error) {
+	filter := &model.DeviceFilter{
+		RadiusKm: ctx.QueryFloat("radius_km", 5),
+	}
+
+	if near := ctx.Query("near"); near != "" {
+		point, err := utils.ParseCoordinates(near, 2)
+		if err != nil {
+			return nil, fmt.Errorf("%w: near: %s", utils.ErrValidation, err.Error())
+		}
+		filter.Near = point
+	}
+
+	if bbox := ctx.Query("bbox"); bbox != "" {
+		box, err := utils.ParseCoordinates(bbox, 4)
+		if err != nil {
+			return nil, fmt.Errorf("%w: bbox: %s", utils.ErrValidation, err.Error())
+		}
+		filter.BBox = box
+	}
+
+	if selector := ctx.Query("selector"); selector != "" {
+		requirements, err := utils.ParseLabelSelector(selector)
+		if err != nil {
+			return nil, fmt.Errorf("%w: selector: %s", utils.ErrValidation, err.Error())
+		}
+		filter.Selector = requirements
+	}
+
+	return filter, nil
+}
+
+// importReader takes the CSV from a multipart "file" field when present
+// and from the raw request body otherwise.
+func importReader(ctx *fiber.Ctx) (io.Reader, error) {
+	if form, err := ctx.MultipartForm(); err == nil {
+		if files := form.File["file"]; len(files) > 0 {
+			file, err := files[0].Open()
+			if err != nil {
+				return nil, err
+			}
+			defer file.Close()
+
+			data, err := io.ReadAll(file)
+			if err != nil {
+				return nil, err
+			}
+			return bytes.NewReader(data), nil
+		}
+	}
+	return bytes.NewReader(ctx.Body()), nil
+}
+
+// streamAttachment sends a download. The status is committed before
+// streaming starts, so a failure half way is only logged and cuts the file
+// short.
+func streamAttachment(ctx *fiber.Ctx, filename string, contentType string, write func(w io.Writer) error) error {
+	ctx.Attachment(filename)
+	ctx.Set(fiber.HeaderContentType, contentType)
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		_ = write(w)
+	})
+	return nil
+}
@@ -0,0 +1,122 @@
+package http
+
+import (
+	"errors"
+	"mertani_test/internal/model"
+	"mertani_test/internal/usecase"
+	"mertani_test/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type ExportJobController struct {
+	Log     *logrus.Logger
+	UseCase *usecase.ExportJobUseCase
+}
+
+func NewExportJobController(useCase *usecase.ExportJobUseCase, logger *logrus.Logger) *ExportJobController {
+	return &ExportJobController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+// Create godoc
+// @Summary Create Export Job
+// @Description Queue a readings export of a sensor (sensor_readings) or of all sensors of a device (device_readings). The file is produced in the background; poll the job and download it once completed.
+// @Tags Exports
+// @Accept json
+// @Produce json
+// @Param request body model.CreateExportJobRequest true "Export Job Request"
+// @Success 202 {object} model.ExportJobResponse
+// @Failure 400 {object} map[string]interface{}
+// @Router /exports [post]
+func (c *ExportJobController) Create(ctx *fiber.Ctx) error {
+	request := new(model.CreateExportJobRequest)
+
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to parse request body"))
+	}
+
+	job, err := c.UseCase.Create(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to create export job : %+v", err)
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusAccepted).
+		JSON(utils.SuccessResponse(fiber.StatusAccepted, "export job queued", job))
+}
+
+// FindByID godoc
+// @Summary Get Export Job
+// @Description Get the status and progress (rows written) of an export job; completed jobs carry a download URL until they expire
+// @Tags Exports
+// @Produce json
+// @Param id path string true "Export Job ID"
+// @Success 200 {object} model.ExportJobResponse
+// @Failure 404 {object} map[string]interface{}
+// @Router /exports/{id} [get]
+func (c *ExportJobController) FindByID(ctx *fiber.Ctx) error {
+	job, err := c.UseCase.FindByID(ctx.UserContext(), ctx.Params("id"))
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "export job not found"))
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponse(fiber.StatusOK, "get detail export job successfully", job))
+}
+
+// Download godoc
+// @Summary Download Export
+// @Description Download the file of a completed export job
+// @Tags Exports
+// @Produce octet-stream
+// @Param id path string true "Export Job ID"
+// @Success 200 {file} file
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /exports/{id}/download [get]
+func (c *ExportJobController) Download(ctx *fiber.Ctx) error {
+	job, err := c.UseCase.Download(ctx.UserContext(), ctx.Params("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrNotFound):
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, err.Error()))
+
+		case errors.Is(err, utils.ErrConflict):
+			return ctx.Status(fiber.StatusConflict).
+				JSON(utils.ErrorResponse(fiber.StatusConflict, err.Error()))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	if err := ctx.Download(job.FilePath, job.FileName); err != nil {
+		c.Log.Warnf("Failed to send export file : %+v", err)
+		return ctx.Status(fiber.StatusNotFound).
+			JSON(utils.ErrorResponse(fiber.StatusNotFound, "export file not found"))
+	}
+	ctx.Set(fiber.HeaderContentType, job.ContentType)
+	return nil
+}
@@ -0,0 +1,58 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"mertani_test/internal/usecase"
+	"mertani_test/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type LineProtocolController struct {
+	Log     *logrus.Logger
+	UseCase *usecase.LineProtocolUseCase
+}
+
+func NewLineProtocolController(useCase *usecase.LineProtocolUseCase, logger *logrus.Logger) *LineProtocolController {
+	return &LineProtocolController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+// Write godoc
+// @Summary Write Line Protocol
+// @Description Ingest InfluxDB line protocol. Devices are matched by the device_id tag or by name from the device tag, fields by sensor channel label or name ("<measurement>.<field>" or "<field>"). Lines that fail are reported and skipped.
+// @Tags Readings
+// @Accept plain
+// @Produce json
+// @Param precision query string false "Timestamp precision (ns/us/ms/s)"
+// @Param request body string true "Line protocol"
+// @Success 200 {object} model.LineWriteResponse
+// @Failure 400 {object} map[string]interface{}
+// @Router /write [post]
+func (c *LineProtocolController) Write(ctx *fiber.Ctx) error {
+	result, err := c.UseCase.Write(ctx.UserContext(), ctx.Body(), ctx.Query("precision", "ns"))
+	if err != nil {
+		c.Log.Warnf("Failed to write line protocol : %+v", err)
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	message := "write successfully"
+	if result.Failed > 0 {
+		message = fmt.Sprintf("partial write, %d of %d lines failed", result.Failed, result.Lines)
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponse(fiber.StatusOK, message, result))
+}
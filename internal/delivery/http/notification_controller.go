@@ -0,0 +1,435 @@
+package http
+
+import (
+	"errors"
+	"mertani_test/internal/model"
+	"mertani_test/internal/usecase"
+	"mertani_test/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type NotificationController struct {
+	Log     *logrus.Logger
+	UseCase *usecase.NotificationUseCase
+}
+
+func NewNotificationController(useCase *usecase.NotificationUseCase, logger *logrus.Logger) *NotificationController {
+	return &NotificationController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+// CreateChannel godoc
+// @Summary Create Notification Channel
+// @Description Create a channel alerts are sent to. type is email (config: host, port, username, password, from, to, starttls), chat (config: api_url, token, chat_id; api_url defaults to the Telegram Bot API) or webhook (config: url, headers). Secrets are masked in responses; sending a masked value back on update keeps the stored one.
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param request body model.CreateNotificationChannelRequest true "Notification Channel Request"
+// @Success 201 {object} model.NotificationChannelResponse
+// @Failure 400 {object} map[string]interface{}
+// @Router /notification-channels [post]
+func (c *NotificationController) CreateChannel(ctx *fiber.Ctx) error {
+	request := new(model.CreateNotificationChannelRequest)
+
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to parse request body"))
+	}
+
+	channel, err := c.UseCase.CreateChannel(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to create notification channel : %+v", err)
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusCreated).
+		JSON(utils.SuccessResponse(fiber.StatusCreated, "notification channel created successfully", channel))
+}
+
+// FindAllChannels godoc
+// @Summary Get Notification Channels List
+// @Description Get list of notification channels with pagination
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Param order_by query string false "Order by field"
+// @Param sort_by query string false "Sort by direction (asc/desc)"
+// @Success 200 {object} model.NotificationChannelResponse
+// @Failure 500 {object} map[string]interface{}
+// @Router /notification-channels [get]
+func (c *NotificationController) FindAllChannels(ctx *fiber.Ctx) error {
+	req := &utils.PaginationRequest{
+		Page:    ctx.QueryInt("page", 1),
+		Limit:   ctx.QueryInt("limit", 10),
+		OrderBy: ctx.Query("order_by", "created_at"),
+		SortBy:  ctx.Query("sort_by", "desc"),
+	}
+
+	channels, pagination, err := c.UseCase.FindAllChannels(ctx.Context(), req)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponseWithPagination(fiber.StatusOK, "get list notification channel successfully", channels, pagination))
+}
+
+// FindChannelByID godoc
+// @Summary Get Notification Channel by ID
+// @Description Get notification channel details by ID
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param id path string true "Notification Channel ID"
+// @Success 200 {object} model.NotificationChannelResponse
+// @Failure 404 {object} map[string]interface{}
+// @Router /notification-channels/{id} [get]
+func (c *NotificationController) FindChannelByID(ctx *fiber.Ctx) error {
+	channel, err := c.UseCase.FindChannelByID(ctx.Context(), ctx.Params("id"))
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "notification channel not found"))
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponse(fiber.StatusOK, "get detail notification channel successfully", channel))
+}
+
+// UpdateChannel godoc
+// @Summary Update Notification Channel
+// @Description Update notification channel by ID; a given config replaces the stored one
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param id path string true "Notification Channel ID"
+// @Param request body model.UpdateNotificationChannelRequest true "Notification Channel Request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /notification-channels/{id} [put]
+func (c *NotificationController) UpdateChannel(ctx *fiber.Ctx) error {
+	request := new(model.UpdateNotificationChannelRequest)
+	id := ctx.Params("id")
+
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to parse request body"))
+	}
+
+	err = c.UseCase.UpdateChannel(ctx.Context(), id, request)
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrNotFound):
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "notification channel not found"))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.DefaultSuccessResponse(fiber.StatusOK, "update notification channel successfully"))
+}
+
+// DeleteChannel godoc
+// @Summary Delete Notification Channel
+// @Description Delete notification channel by ID together with its notifications
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param id path string true "Notification Channel ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /notification-channels/{id} [delete]
+func (c *NotificationController) DeleteChannel(ctx *fiber.Ctx) error {
+	err := c.UseCase.DeleteChannel(ctx.Context(), ctx.Params("id"))
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "notification channel not found"))
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.DefaultSuccessResponse(fiber.StatusOK, "delete notification channel successfully"))
+}
+
+// TestChannel godoc
+// @Summary Test Notification Channel
+// @Description Send a sample alert through the channel right away and report the channel's error, if any
+// @Tags Notifications
+// @Produce json
+// @Param id path string true "Notification Channel ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 502 {object} map[string]interface{}
+// @Router /notification-channels/{id}/test [post]
+func (c *NotificationController) TestChannel(ctx *fiber.Ctx) error {
+	err := c.UseCase.TestChannel(ctx.UserContext(), ctx.Params("id"))
+	if err != nil {
+		c.Log.Warnf("Failed to test notification channel : %+v", err)
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrNotFound):
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "notification channel not found"))
+
+		case errors.Is(err, utils.ErrIntegration):
+			return ctx.Status(fiber.StatusBadGateway).
+				JSON(utils.ErrorResponse(fiber.StatusBadGateway, err.Error()))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.DefaultSuccessResponse(fiber.StatusOK, "test notification sent successfully"))
+}
+
+// CreateRule godoc
+// @Summary Create Notification Rule
+// @Description Route alerts of at least min_severity whose labels match selector to channels. subject_template and body_template are Go text/template over the alert fields plus .Rule and .Repeats, with upper, lower and label functions. During quiet hours (quiet_start to quiet_end, HH:MM in timezone) only critical alerts are sent. Repeats of an alert within group_window_seconds of the last notification are held back and counted in the next one.
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param request body model.CreateNotificationRuleRequest true "Notification Rule Request"
+// @Success 201 {object} model.NotificationRuleResponse
+// @Failure 400 {object} map[string]interface{}
+// @Router /notification-rules [post]
+func (c *NotificationController) CreateRule(ctx *fiber.Ctx) error {
+	request := new(model.CreateNotificationRuleRequest)
+
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to parse request body"))
+	}
+
+	rule, err := c.UseCase.CreateRule(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to create notification rule : %+v", err)
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusCreated).
+		JSON(utils.SuccessResponse(fiber.StatusCreated, "notification rule created successfully", rule))
+}
+
+// FindAllRules godoc
+// @Summary Get Notification Rules List
+// @Description Get list of notification rules with pagination
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Param order_by query string false "Order by field"
+// @Param sort_by query string false "Sort by direction (asc/desc)"
+// @Success 200 {object} model.NotificationRuleResponse
+// @Failure 500 {object} map[string]interface{}
+// @Router /notification-rules [get]
+func (c *NotificationController) FindAllRules(ctx *fiber.Ctx) error {
+	req := &utils.PaginationRequest{
+		Page:    ctx.QueryInt("page", 1),
+		Limit:   ctx.QueryInt("limit", 10),
+		OrderBy: ctx.Query("order_by", "created_at"),
+		SortBy:  ctx.Query("sort_by", "desc"),
+	}
+
+	rules, pagination, err := c.UseCase.FindAllRules(ctx.Context(), req)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponseWithPagination(fiber.StatusOK, "get list notification rule successfully", rules, pagination))
+}
+
+// FindRuleByID godoc
+// @Summary Get Notification Rule by ID
+// @Description Get notification rule details by ID
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param id path string true "Notification Rule ID"
+// @Success 200 {object} model.NotificationRuleResponse
+// @Failure 404 {object} map[string]interface{}
+// @Router /notification-rules/{id} [get]
+func (c *NotificationController) FindRuleByID(ctx *fiber.Ctx) error {
+	rule, err := c.UseCase.FindRuleByID(ctx.Context(), ctx.Params("id"))
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "notification rule not found"))
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponse(fiber.StatusOK, "get detail notification rule successfully", rule))
+}
+
+// UpdateRule godoc
+// @Summary Update Notification Rule
+// @Description Update notification rule by ID
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param id path string true "Notification Rule ID"
+// @Param request body model.UpdateNotificationRuleRequest true "Notification Rule Request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /notification-rules/{id} [put]
+func (c *NotificationController) UpdateRule(ctx *fiber.Ctx) error {
+	request := new(model.UpdateNotificationRuleRequest)
+	id := ctx.Params("id")
+
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to parse request body"))
+	}
+
+	err = c.UseCase.UpdateRule(ctx.Context(), id, request)
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrNotFound):
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "notification rule not found"))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.DefaultSuccessResponse(fiber.StatusOK, "update notification rule successfully"))
+}
+
+// DeleteRule godoc
+// @Summary Delete Notification Rule
+// @Description Delete notification rule by ID together with its notifications
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param id path string true "Notification Rule ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /notification-rules/{id} [delete]
+func (c *NotificationController) DeleteRule(ctx *fiber.Ctx) error {
+	err := c.UseCase.DeleteRule(ctx.Context(), ctx.Params("id"))
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "notification rule not found"))
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.DefaultSuccessResponse(fiber.StatusOK, "delete notification rule successfully"))
+}
+
+// FindAll godoc
+// @Summary Get Notifications
+// @Description Delivery log of notifications: the rendered message, the alert, attempts, next retry and the latest error
+// @Tags Notifications
+// @Produce json
+// @Param status query string false "pending, sent, failed, suppressed or quiet"
+// @Param channel_id query string false "Notification Channel ID"
+// @Param rule_id query string false "Notification Rule ID"
+// @Param alert_id query string false "Alert ID"
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Param order_by query string false "Order by field"
+// @Param sort_by query string false "Sort by direction (asc/desc)"
+// @Success 200 {object} model.NotificationResponse
+// @Failure 400 {object} map[string]interface{}
+// @Router /notifications [get]
+func (c *NotificationController) FindAll(ctx *fiber.Ctx) error {
+	req := &utils.PaginationRequest{
+		Page:    ctx.QueryInt("page", 1),
+		Limit:   ctx.QueryInt("limit", 10),
+		OrderBy: ctx.Query("order_by", "created_at"),
+		SortBy:  ctx.Query("sort_by", "desc"),
+	}
+
+	filter := &model.NotificationFilter{
+		Status:    ctx.Query("status"),
+		ChannelID: ctx.Query("channel_id"),
+		RuleID:    ctx.Query("rule_id"),
+		AlertID:   ctx.Query("alert_id"),
+	}
+
+	notifications, pagination, err := c.UseCase.FindAll(ctx.Context(), req, filter)
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponseWithPagination(fiber.StatusOK, "get list notification successfully", notifications, pagination))
+}
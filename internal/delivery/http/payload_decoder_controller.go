@@ -0,0 +1,235 @@
+package http
+
+import (
+	"errors"
+	"mertani_test/internal/model"
+	"mertani_test/internal/usecase"
+	"mertani_test/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type PayloadDecoderController struct {
+	Log     *logrus.Logger
+	UseCase *usecase.PayloadDecoderUseCase
+}
+
+func NewPayloadDecoderController(useCase *usecase.PayloadDecoderUseCase, logger *logrus.Logger) *PayloadDecoderController {
+	return &PayloadDecoderController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+// CreatePayloadDecoder godoc
+// @Summary Create Payload Decoder
+// @Description Register the decoder for a hardware model: a built-in name (e.g. cayenne_lpp) or "layout" with a byte layout
+// @Tags Payload Decoders
+// @Accept json
+// @Produce json
+// @Param request body model.CreatePayloadDecoderRequest true "Payload Decoder Request"
+// @Success 200 {object} model.PayloadDecoderResponse
+// @Failure 400 {object} map[string]interface{}
+// @Router /payload-decoders [post]
+func (c *PayloadDecoderController) Create(ctx *fiber.Ctx) error {
+	request := new(model.CreatePayloadDecoderRequest)
+
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to parse request body"))
+	}
+
+	err = c.UseCase.Create(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to create payload decoder : %+v", err)
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrConflict):
+			return ctx.Status(fiber.StatusConflict).
+				JSON(utils.ErrorResponse(fiber.StatusConflict, err.Error()))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusCreated).
+		JSON(utils.DefaultSuccessResponse(fiber.StatusCreated, "payload decoder created successfully"))
+}
+
+// FindAll godoc
+// @Summary Get Payload Decoders List
+// @Description Get list of payload decoders with pagination
+// @Tags Payload Decoders
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Param order_by query string false "Order by field"
+// @Param sort_by query string false "Sort by direction (asc/desc)"
+// @Success 200 {object} model.PayloadDecoderResponse
+// @Failure 500 {object} map[string]interface{}
+// @Router /payload-decoders [get]
+func (c *PayloadDecoderController) FindAll(ctx *fiber.Ctx) error {
+	req := &utils.PaginationRequest{
+		Page:    ctx.QueryInt("page", 1),
+		Limit:   ctx.QueryInt("limit", 10),
+		OrderBy: ctx.Query("order_by", "created_at"),
+		SortBy:  ctx.Query("sort_by", "desc"),
+	}
+
+	payloadDecoders, pagination, err := c.UseCase.FindAll(ctx.Context(), req)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponseWithPagination(fiber.StatusOK, "get list payload decoder successfully", payloadDecoders, pagination))
+}
+
+// FindByID godoc
+// @Summary Get Payload Decoder by ID
+// @Description Get payload decoder details by ID
+// @Tags Payload Decoders
+// @Accept json
+// @Produce json
+// @Param id path string true "Payload Decoder ID"
+// @Success 200 {object} model.PayloadDecoderResponse
+// @Failure 404 {object} map[string]interface{}
+// @Router /payload-decoders/{id} [get]
+func (c *PayloadDecoderController) FindByID(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	payloadDecoder, err := c.UseCase.FindByID(ctx.Context(), id)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "payload decoder not found"))
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponse(fiber.StatusOK, "get detail payload decoder successfully", payloadDecoder))
+}
+
+// UpdatePayloadDecoder godoc
+// @Summary Update Payload Decoder
+// @Description Update payload decoder by ID
+// @Tags Payload Decoders
+// @Accept json
+// @Produce json
+// @Param id path string true "Payload Decoder ID"
+// @Param request body model.UpdatePayloadDecoderRequest true "Payload Decoder Request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /payload-decoders/{id} [put]
+func (c *PayloadDecoderController) Update(ctx *fiber.Ctx) error {
+	request := new(model.UpdatePayloadDecoderRequest)
+	id := ctx.Params("id")
+
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to parse request body"))
+	}
+
+	err = c.UseCase.Update(ctx.Context(), id, request)
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrNotFound):
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "payload decoder not found"))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.DefaultSuccessResponse(fiber.StatusOK, "update payload decoder successfully"))
+}
+
+// DeletePayloadDecoder godoc
+// @Summary Delete Payload Decoder
+// @Description Delete payload decoder by ID
+// @Tags Payload Decoders
+// @Accept json
+// @Produce json
+// @Param id path string true "Payload Decoder ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /payload-decoders/{id} [delete]
+func (c *PayloadDecoderController) Delete(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	err := c.UseCase.Delete(ctx.Context(), id)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "payload decoder not found"))
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.DefaultSuccessResponse(fiber.StatusOK, "delete payload decoder successfully"))
+}
+
+// Test godoc
+// @Summary Test Payload Decoder
+// @Description Decode a sample hex or base64 payload without storing anything, using the decoder of a hardware model or an inline decoder
+// @Tags Payload Decoders
+// @Accept json
+// @Produce json
+// @Param request body model.TestPayloadDecoderRequest true "Test Request"
+// @Success 200 {object} model.DecodedPayloadResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /payload-decoders/test [post]
+func (c *PayloadDecoderController) Test(ctx *fiber.Ctx) error {
+	request := new(model.TestPayloadDecoderRequest)
+
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to parse request body"))
+	}
+
+	decoded, err := c.UseCase.Test(ctx.UserContext(), request)
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrNotFound):
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, err.Error()))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponse(fiber.StatusOK, "decode payload successfully", decoded))
+}
@@ -0,0 +1,310 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mertani_test/internal/model"
+	"mertani_test/internal/usecase"
+	"mertani_test/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type ReadingController struct {
+	Log     *logrus.Logger
+	UseCase *usecase.ReadingUseCase
+}
+
+func NewReadingController(useCase *usecase.ReadingUseCase, logger *logrus.Logger) *ReadingController {
+	return &ReadingController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+// CreateReading godoc
+// @Summary Create Reading
+// @Description Store a reading for a sensor; values outside the sensor type physical range and readings recorded more than 5 minutes in the future are rejected, while values outside the sensor's valid range and readings recorded at the same time as an earlier one are stored flagged
+// @Tags Readings
+// @Accept json
+// @Produce json
+// @Param id path string true "Sensor ID"
+// @Param request body model.CreateReadingRequest true "Reading Request"
+// @Success 200 {object} model.ReadingResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /sensors/{id}/readings [post]
+func (c *ReadingController) Create(ctx *fiber.Ctx) error {
+	request := new(model.CreateReadingRequest)
+	id := ctx.Params("id")
+
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to parse request body"))
+	}
+
+	reading, err := c.UseCase.Create(ctx.UserContext(), id, request)
+	if err != nil {
+		c.Log.Warnf("Failed to create reading : %+v", err)
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrNotFound):
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "sensor not found"))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusCreated).
+		JSON(utils.SuccessResponse(fiber.StatusCreated, "reading created successfully", reading))
+}
+
+// FindAll godoc
+// @Summary Get Sensor Readings
+// @Description Get readings of a sensor with pagination and an optional time range
+// @Tags Readings
+// @Accept json
+// @Produce json
+// @Param id path string true "Sensor ID"
+// @Param from query string false "Recorded from (RFC3339)"
+// @Param to query string false "Recorded to (RFC3339)"
+// @Param unit query string false "Convert values to this unit, e.g. °F or in"
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Param sort_by query string false "Sort by direction (asc/desc)"
+// @Success 200 {object} model.ReadingResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /sensors/{id}/readings [get]
+func (c *ReadingController) FindAll(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	req := &utils.PaginationRequest{
+		Page:    ctx.QueryInt("page", 1),
+		Limit:   ctx.QueryInt("limit", 100),
+		OrderBy: "recorded_at",
+		SortBy:  ctx.Query("sort_by", "desc"),
+	}
+
+	filter, err := parseReadingFilter(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).
+			JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	readings, pagination, err := c.UseCase.FindAll(ctx.Context(), id, filter, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrNotFound):
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "sensor not found"))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponseWithPagination(fiber.StatusOK, "get list reading successfully", readings, pagination))
+}
+
+// Aggregate godoc
+// @Summary Aggregate Sensor Readings
+// @Description Get count, min, max and avg of a sensor's readings per time bucket. Readings compacted by retention policies are served from their hourly or daily rollups, so older buckets finer than the rollup resolution come at that resolution.
+// @Tags Readings
+// @Accept json
+// @Produce json
+// @Param id path string true "Sensor ID"
+// @Param interval query string false "Bucket size (minute/hour/day/week/month)"
+// @Param from query string false "Recorded from (RFC3339)"
+// @Param to query string false "Recorded to (RFC3339)"
+// @Param unit query string false "Convert values to this unit, e.g. °F or in"
+// @Success 200 {object} model.ReadingAggregateResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /sensors/{id}/readings/aggregate [get]
+func (c *ReadingController) Aggregate(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	filter, err := parseReadingFilter(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).
+			JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	aggregates, err := c.UseCase.Aggregate(ctx.Context(), id, filter, ctx.Query("interval", "hour"))
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrNotFound):
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "sensor not found"))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponse(fiber.StatusOK, "get reading aggregate successfully", aggregates))
+}
+
+// Quality godoc
+// @Summary Get Sensor Data Quality
+// @Description Report how well a sensor reported over a time range: the completeness percentage against its expected interval, the gaps in which it stayed silent for more than two intervals, and the number of readings flagged out_of_range or duplicate on ingest. The interval is the sensor's expected_interval, or else estimated from its latest readings. The range defaults to the last 24 hours and may span up to 31 days. Readings compacted into rollups by retention are not reported on: the range then starts at compacted_until.
+// @Tags Readings
+// @Accept json
+// @Produce json
+// @Param id path string true "Sensor ID"
+// @Param from query string false "Recorded from (RFC3339)"
+// @Param to query string false "Recorded to (RFC3339)"
+// @Success 200 {object} model.ReadingQualityResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /sensors/{id}/quality [get]
+func (c *ReadingController) Quality(ctx *fiber.Ctx) error {
+	filter, err := parseReadingFilter(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).
+			JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	quality, err := c.UseCase.Quality(ctx.Context(), ctx.Params("id"), filter)
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrNotFound):
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "sensor not found"))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponse(fiber.StatusOK, "get sensor quality successfully", quality))
+}
+
+// Export godoc
+// @Summary Export Sensor Readings
+// @Description Stream a sensor's readings as CSV, NDJSON or Parquet. With interval, readings are resampled into count/min/max/avg buckets.
+// @Tags Readings
+// @Produce text/csv,application/x-ndjson,application/vnd.apache.parquet
+// @Param id path string true "Sensor ID"
+// @Param format query string false "csv (default), ndjson or parquet"
+// @Param from query string false "Recorded from (RFC3339)"
+// @Param to query string false "Recorded to (RFC3339)"
+// @Param interval query string false "Resample into buckets (minute/hour/day/week/month)"
+// @Param unit query string false "Convert values to this unit, e.g. °F or in"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /sensors/{id}/readings/export [get]
+func (c *ReadingController) Export(ctx *fiber.Ctx) error {
+	readingExport, err := c.UseCase.ExportSensor(ctx.UserContext(), ctx.Params("id"), parseReadingExportRequest(ctx))
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrNotFound):
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "sensor not found"))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return streamReadingExport(ctx, readingExport)
+}
+
+// ExportDevice godoc
+// @Summary Export Device Readings
+// @Description Stream the readings of all sensors of a device as CSV, NDJSON or Parquet. With interval, readings are resampled into count/min/max/avg buckets per sensor.
+// @Tags Readings
+// @Produce text/csv,application/x-ndjson,application/vnd.apache.parquet
+// @Param id path string true "Device ID"
+// @Param format query string false "csv (default), ndjson or parquet"
+// @Param from query string false "Recorded from (RFC3339)"
+// @Param to query string false "Recorded to (RFC3339)"
+// @Param interval query string false "Resample into buckets (minute/hour/day/week/month)"
+// @Param unit query string false "Convert values of compatible sensors to this unit"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /devices/{id}/readings/export [get]
+func (c *ReadingController) ExportDevice(ctx *fiber.Ctx) error {
+	readingExport, err := c.UseCase.ExportDevice(ctx.UserContext(), ctx.Params("id"), parseReadingExportRequest(ctx))
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrNotFound):
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "device not found"))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return streamReadingExport(ctx, readingExport)
+}
+
+func parseReadingFilter(ctx *fiber.Ctx) (*model.ReadingFilter, error) {
+	from, err := parseTimeQuery(ctx, "from")
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseTimeQuery(ctx, "to")
+	if err != nil {
+		return nil, err
+	}
+	return &model.ReadingFilter{From: from, To: to, Unit: ctx.Query("unit")}, nil
+}
+
+func parseReadingExportRequest(ctx *fiber.Ctx) *model.ReadingExportRequest {
+	return &model.ReadingExportRequest{
+		Format:   ctx.Query("format", "csv"),
+		From:     ctx.Query("from"),
+		To:       ctx.Query("to"),
+		Interval: ctx.Query("interval"),
+		Unit:     ctx.Query("unit"),
+	}
+}
+
+func streamReadingExport(ctx *fiber.Ctx, readingExport *usecase.ReadingExport) error {
+	// the stream outlives the handler, so it must not hold on to the
+	// request context
+	return streamAttachment(ctx, readingExport.Filename, readingExport.ContentType, func(w io.Writer) error {
+		return readingExport.Write(context.Background(), w)
+	})
+}
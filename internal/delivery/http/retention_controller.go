@@ -0,0 +1,191 @@
+package http
+
+import (
+	"errors"
+	"mertani_test/internal/model"
+	"mertani_test/internal/usecase"
+	"mertani_test/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type RetentionController struct {
+	Log     *logrus.Logger
+	UseCase *usecase.RetentionUseCase
+}
+
+func NewRetentionController(useCase *usecase.RetentionUseCase, logger *logrus.Logger) *RetentionController {
+	return &RetentionController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+// Create godoc
+// @Summary Create Retention Policy
+// @Description Set how long readings of a sensor type are kept, in days, zero keeping them forever: raw readings for raw_days, then rolled up per hour until hourly_days, then per day until daily_days. Without sensor_type the policy is the default for sensor types that have none. A background job compacts readings past each retention; aggregate queries keep covering compacted readings through the rollups.
+// @Tags Retention
+// @Accept json
+// @Produce json
+// @Param request body model.CreateRetentionPolicyRequest true "Retention Policy Request"
+// @Success 201 {object} model.RetentionPolicyResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /retention-policies [post]
+func (c *RetentionController) Create(ctx *fiber.Ctx) error {
+	request := new(model.CreateRetentionPolicyRequest)
+
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to parse request body"))
+	}
+
+	policy, err := c.UseCase.Create(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to create retention policy : %+v", err)
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrConflict):
+			return ctx.Status(fiber.StatusConflict).
+				JSON(utils.ErrorResponse(fiber.StatusConflict, err.Error()))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusCreated).
+		JSON(utils.SuccessResponse(fiber.StatusCreated, "retention policy created successfully", policy))
+}
+
+// FindAll godoc
+// @Summary Get Retention Policies List
+// @Description Get list of retention policies with pagination
+// @Tags Retention
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Param order_by query string false "Order by field"
+// @Param sort_by query string false "Sort by direction (asc/desc)"
+// @Success 200 {object} model.RetentionPolicyResponse
+// @Failure 500 {object} map[string]interface{}
+// @Router /retention-policies [get]
+func (c *RetentionController) FindAll(ctx *fiber.Ctx) error {
+	req := &utils.PaginationRequest{
+		Page:    ctx.QueryInt("page", 1),
+		Limit:   ctx.QueryInt("limit", 10),
+		OrderBy: ctx.Query("order_by", "created_at"),
+		SortBy:  ctx.Query("sort_by", "desc"),
+	}
+
+	policies, pagination, err := c.UseCase.FindAll(ctx.Context(), req)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponseWithPagination(fiber.StatusOK, "get list retention policy successfully", policies, pagination))
+}
+
+// FindByID godoc
+// @Summary Get Retention Policy by ID
+// @Description Get retention policy details by ID
+// @Tags Retention
+// @Accept json
+// @Produce json
+// @Param id path string true "Retention Policy ID"
+// @Success 200 {object} model.RetentionPolicyResponse
+// @Failure 404 {object} map[string]interface{}
+// @Router /retention-policies/{id} [get]
+func (c *RetentionController) FindByID(ctx *fiber.Ctx) error {
+	policy, err := c.UseCase.FindByID(ctx.Context(), ctx.Params("id"))
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "retention policy not found"))
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponse(fiber.StatusOK, "get detail retention policy successfully", policy))
+}
+
+// Update godoc
+// @Summary Update Retention Policy
+// @Description Update retention policy by ID; the next compaction applies it, and lengthening a retention does not bring back readings already compacted
+// @Tags Retention
+// @Accept json
+// @Produce json
+// @Param id path string true "Retention Policy ID"
+// @Param request body model.UpdateRetentionPolicyRequest true "Retention Policy Request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /retention-policies/{id} [put]
+func (c *RetentionController) Update(ctx *fiber.Ctx) error {
+	request := new(model.UpdateRetentionPolicyRequest)
+	id := ctx.Params("id")
+
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to parse request body"))
+	}
+
+	err = c.UseCase.Update(ctx.Context(), id, request)
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrNotFound):
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "retention policy not found"))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.DefaultSuccessResponse(fiber.StatusOK, "update retention policy successfully"))
+}
+
+// Delete godoc
+// @Summary Delete Retention Policy
+// @Description Delete retention policy by ID; its sensors fall back to the default policy, if any
+// @Tags Retention
+// @Accept json
+// @Produce json
+// @Param id path string true "Retention Policy ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /retention-policies/{id} [delete]
+func (c *RetentionController) Delete(ctx *fiber.Ctx) error {
+	err := c.UseCase.Delete(ctx.Context(), ctx.Params("id"))
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "retention policy not found"))
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.DefaultSuccessResponse(fiber.StatusOK, "delete retention policy successfully"))
+}
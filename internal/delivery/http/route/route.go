@@ -10,6 +10,23 @@ type RouteConfig struct {
 	App                *fiber.App
 	DeviceController *http.DeviceController
 	SensorController *http.SensorController
+	DeviceConfigController *http.DeviceConfigController
+	ConfigSchemaController *http.ConfigSchemaController
+	SiteController         *http.SiteController
+	SensorTypeController   *http.SensorTypeController
+	ReadingController      *http.ReadingController
+	SensorCalibrationController *http.SensorCalibrationController
+	PayloadDecoderController    *http.PayloadDecoderController
+	UplinkController            *http.UplinkController
+	LineProtocolController      *http.LineProtocolController
+	ExportJobController         *http.ExportJobController
+	StreamController            *http.StreamController
+	WebhookController           *http.WebhookController
+	NotificationController      *http.NotificationController
+	AlertController             *http.AlertController
+	AnomalyController           *http.AnomalyController
+	RetentionController         *http.RetentionController
+	CacheController             *http.CacheController
 }
 
 func (c *RouteConfig) Setup() {
@@ -19,18 +36,147 @@ func (c *RouteConfig) Setup() {
 func (c *RouteConfig) SetupGuestRoute() {
 	api := c.App.Group("/api/v1")
 
+	api.Get("/devices.geojson", c.DeviceController.FindAllGeoJSON)
+	api.Post("/write", c.LineProtocolController.Write)
+	api.Get("/stream", c.StreamController.Events)
+	api.Get("/stream/ws", c.StreamController.WebSocket)
+	api.Get("/cache/stats", c.CacheController.Stats)
+
 	device := api.Group("/devices")
 	device.Post("", c.DeviceController.Create)
 	device.Get("", c.DeviceController.FindAll)
+	device.Post("/import", c.DeviceController.Import)
+	device.Get("/export.csv", c.DeviceController.Export)
 	device.Get("/:id", c.DeviceController.FindByID)
 	device.Put("/:id", c.DeviceController.Update)
 	device.Delete("/:id", c.DeviceController.Delete)
+	device.Get("/:id/config", c.DeviceConfigController.FindCurrent)
+	device.Put("/:id/config/desired", c.DeviceConfigController.SetDesired)
+	device.Get("/:id/config/history", c.DeviceConfigController.FindHistory)
+	device.Get("/:id/config/diff", c.DeviceConfigController.Diff)
+	device.Post("/:id/check-in", c.DeviceConfigController.CheckIn)
+	device.Post("/:id/uplink", c.UplinkController.Uplink)
+	device.Get("/:id/readings/export", c.ReadingController.ExportDevice)
+	device.Put("/:id/labels", c.DeviceController.SetLabels)
+	device.Delete("/:id/labels/:key", c.DeviceController.RemoveLabel)
 
 	sensor := api.Group("/sensors")
 	sensor.Post("", c.SensorController.Create)
 	sensor.Get("", c.SensorController.FindAll)
+	sensor.Post("/import", c.SensorController.Import)
+	sensor.Get("/export.csv", c.SensorController.Export)
 	sensor.Get("/:id", c.SensorController.FindByID)
 	sensor.Put("/:id", c.SensorController.Update)
 	sensor.Delete("/:id", c.SensorController.Delete)
+	sensor.Put("/:id/labels", c.SensorController.SetLabels)
+	sensor.Delete("/:id/labels/:key", c.SensorController.RemoveLabel)
+	sensor.Post("/:id/readings", c.ReadingController.Create)
+	sensor.Get("/:id/readings", c.ReadingController.FindAll)
+	sensor.Get("/:id/readings/aggregate", c.ReadingController.Aggregate)
+	sensor.Get("/:id/readings/export", c.ReadingController.Export)
+	sensor.Post("/:id/calibrations", c.SensorCalibrationController.Create)
+	sensor.Get("/:id/calibrations", c.SensorCalibrationController.FindAll)
+	sensor.Post("/:id/calibrations/reprocess", c.SensorCalibrationController.Reprocess)
+	sensor.Get("/:id/anomalies", c.AnomalyController.FindBySensor)
+	sensor.Get("/:id/quality", c.ReadingController.Quality)
+
+	sensorType := api.Group("/sensor-types")
+	sensorType.Post("", c.SensorTypeController.Create)
+	sensorType.Get("", c.SensorTypeController.FindAll)
+	sensorType.Get("/:id", c.SensorTypeController.FindByID)
+	sensorType.Put("/:id", c.SensorTypeController.Update)
+	sensorType.Delete("/:id", c.SensorTypeController.Delete)
+
+	site := api.Group("/sites")
+	site.Post("", c.SiteController.Create)
+	site.Get("", c.SiteController.FindAll)
+	site.Get("/:id", c.SiteController.FindByID)
+	site.Put("/:id", c.SiteController.Update)
+	site.Delete("/:id", c.SiteController.Delete)
+	site.Get("/:id/devices", c.SiteController.FindDevices)
+	site.Post("/:id/devices", c.SiteController.MoveDevices)
+	site.Delete("/:id/devices", c.SiteController.RemoveDevices)
+	site.Get("/:id/stats", c.SiteController.Stats)
+
+	configSchema := api.Group("/config-schemas")
+	configSchema.Post("", c.ConfigSchemaController.Create)
+	configSchema.Get("", c.ConfigSchemaController.FindAll)
+	configSchema.Get("/:id", c.ConfigSchemaController.FindByID)
+	configSchema.Put("/:id", c.ConfigSchemaController.Update)
+	configSchema.Delete("/:id", c.ConfigSchemaController.Delete)
+
+	payloadDecoder := api.Group("/payload-decoders")
+	payloadDecoder.Post("", c.PayloadDecoderController.Create)
+	payloadDecoder.Post("/test", c.PayloadDecoderController.Test)
+	payloadDecoder.Get("", c.PayloadDecoderController.FindAll)
+	payloadDecoder.Get("/:id", c.PayloadDecoderController.FindByID)
+	payloadDecoder.Put("/:id", c.PayloadDecoderController.Update)
+	payloadDecoder.Delete("/:id", c.PayloadDecoderController.Delete)
+
+	exportJob := api.Group("/exports")
+	exportJob.Post("", c.ExportJobController.Create)
+	exportJob.Get("/:id", c.ExportJobController.FindByID)
+	exportJob.Get("/:id/download", c.ExportJobController.Download)
+
+	webhook := api.Group("/webhooks")
+	webhook.Post("", c.WebhookController.Create)
+	webhook.Get("", c.WebhookController.FindAll)
+	webhook.Get("/:id", c.WebhookController.FindByID)
+	webhook.Put("/:id", c.WebhookController.Update)
+	webhook.Delete("/:id", c.WebhookController.Delete)
+	webhook.Get("/:id/deliveries", c.WebhookController.FindDeliveries)
+	webhook.Post("/:id/deliveries/:deliveryId/redeliver", c.WebhookController.Redeliver)
+
+	notificationChannel := api.Group("/notification-channels")
+	notificationChannel.Post("", c.NotificationController.CreateChannel)
+	notificationChannel.Get("", c.NotificationController.FindAllChannels)
+	notificationChannel.Get("/:id", c.NotificationController.FindChannelByID)
+	notificationChannel.Put("/:id", c.NotificationController.UpdateChannel)
+	notificationChannel.Delete("/:id", c.NotificationController.DeleteChannel)
+	notificationChannel.Post("/:id/test", c.NotificationController.TestChannel)
+
+	notificationRule := api.Group("/notification-rules")
+	notificationRule.Post("", c.NotificationController.CreateRule)
+	notificationRule.Get("", c.NotificationController.FindAllRules)
+	notificationRule.Get("/:id", c.NotificationController.FindRuleByID)
+	notificationRule.Put("/:id", c.NotificationController.UpdateRule)
+	notificationRule.Delete("/:id", c.NotificationController.DeleteRule)
+
+	api.Get("/notifications", c.NotificationController.FindAll)
+
+	alert := api.Group("/alerts")
+	alert.Post("", c.AlertController.Raise)
+	alert.Get("", c.AlertController.FindAll)
+	alert.Get("/:id", c.AlertController.FindByID)
+	alert.Post("/:id/acknowledge", c.AlertController.Acknowledge)
+	alert.Post("/:id/resolve", c.AlertController.Resolve)
+	alert.Post("/:id/comments", c.AlertController.AddComment)
+
+	silence := api.Group("/silences")
+	silence.Post("", c.AlertController.CreateSilence)
+	silence.Get("", c.AlertController.FindAllSilences)
+	silence.Get("/:id", c.AlertController.FindSilenceByID)
+	silence.Delete("/:id", c.AlertController.DeleteSilence)
+
+	escalationPolicy := api.Group("/escalation-policies")
+	escalationPolicy.Post("", c.AlertController.CreateEscalationPolicy)
+	escalationPolicy.Get("", c.AlertController.FindAllEscalationPolicies)
+	escalationPolicy.Get("/:id", c.AlertController.FindEscalationPolicyByID)
+	escalationPolicy.Put("/:id", c.AlertController.UpdateEscalationPolicy)
+	escalationPolicy.Delete("/:id", c.AlertController.DeleteEscalationPolicy)
+
+	anomalyDetector := api.Group("/anomaly-detectors")
+	anomalyDetector.Post("", c.AnomalyController.CreateDetector)
+	anomalyDetector.Get("", c.AnomalyController.FindAllDetectors)
+	anomalyDetector.Get("/:id", c.AnomalyController.FindDetectorByID)
+	anomalyDetector.Put("/:id", c.AnomalyController.UpdateDetector)
+	anomalyDetector.Delete("/:id", c.AnomalyController.DeleteDetector)
+
+	retentionPolicy := api.Group("/retention-policies")
+	retentionPolicy.Post("", c.RetentionController.Create)
+	retentionPolicy.Get("", c.RetentionController.FindAll)
+	retentionPolicy.Get("/:id", c.RetentionController.FindByID)
+	retentionPolicy.Put("/:id", c.RetentionController.Update)
+	retentionPolicy.Delete("/:id", c.RetentionController.Delete)
 	
 }
\ No newline at end of file
@@ -0,0 +1,144 @@
+package http
+
+import (
+	"errors"
+	"mertani_test/internal/model"
+	"mertani_test/internal/usecase"
+	"mertani_test/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type SensorCalibrationController struct {
+	Log     *logrus.Logger
+	UseCase *usecase.SensorCalibrationUseCase
+}
+
+func NewSensorCalibrationController(useCase *usecase.SensorCalibrationUseCase, logger *logrus.Logger) *SensorCalibrationController {
+	return &SensorCalibrationController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+// CreateSensorCalibration godoc
+// @Summary Create Sensor Calibration
+// @Description Add a calibration version (offset_gain, polynomial or lookup) in force from effective_from
+// @Tags Sensor Calibrations
+// @Accept json
+// @Produce json
+// @Param id path string true "Sensor ID"
+// @Param request body model.CreateSensorCalibrationRequest true "Calibration Request"
+// @Success 200 {object} model.SensorCalibrationResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /sensors/{id}/calibrations [post]
+func (c *SensorCalibrationController) Create(ctx *fiber.Ctx) error {
+	request := new(model.CreateSensorCalibrationRequest)
+	id := ctx.Params("id")
+
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to parse request body"))
+	}
+
+	calibration, err := c.UseCase.Create(ctx.UserContext(), id, request)
+	if err != nil {
+		c.Log.Warnf("Failed to create sensor calibration : %+v", err)
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrNotFound):
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "sensor not found"))
+
+		case errors.Is(err, utils.ErrConflict):
+			return ctx.Status(fiber.StatusConflict).
+				JSON(utils.ErrorResponse(fiber.StatusConflict, err.Error()))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusCreated).
+		JSON(utils.SuccessResponse(fiber.StatusCreated, "sensor calibration created successfully", calibration))
+}
+
+// FindAll godoc
+// @Summary Get Sensor Calibration History
+// @Description Get all calibration versions of a sensor, oldest first
+// @Tags Sensor Calibrations
+// @Accept json
+// @Produce json
+// @Param id path string true "Sensor ID"
+// @Success 200 {object} model.SensorCalibrationResponse
+// @Failure 404 {object} map[string]interface{}
+// @Router /sensors/{id}/calibrations [get]
+func (c *SensorCalibrationController) FindAll(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	calibrations, err := c.UseCase.FindAll(ctx.Context(), id)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "sensor not found"))
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponse(fiber.StatusOK, "get list sensor calibration successfully", calibrations))
+}
+
+// Reprocess godoc
+// @Summary Reprocess Sensor Readings
+// @Description Recompute calibrated values of readings in a time range from their raw values
+// @Tags Sensor Calibrations
+// @Accept json
+// @Produce json
+// @Param id path string true "Sensor ID"
+// @Param request body model.ReprocessReadingsRequest true "Reprocess Request"
+// @Success 200 {object} model.ReprocessReadingsResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /sensors/{id}/calibrations/reprocess [post]
+func (c *SensorCalibrationController) Reprocess(ctx *fiber.Ctx) error {
+	request := new(model.ReprocessReadingsRequest)
+	id := ctx.Params("id")
+
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to parse request body"))
+	}
+
+	result, err := c.UseCase.Reprocess(ctx.UserContext(), id, request)
+	if err != nil {
+		c.Log.Warnf("Failed to reprocess readings : %+v", err)
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrNotFound):
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "sensor not found"))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponse(fiber.StatusOK, "reprocess readings successfully", result))
+}
@@ -2,6 +2,7 @@ package http
 
 import (
 	"errors"
+	"fmt"
 	"mertani_test/internal/model"
 	"mertani_test/internal/usecase"
 	"mertani_test/internal/utils"
@@ -24,7 +25,7 @@ func NewSensorController(useCase *usecase.SensorUseCase, logger *logrus.Logger)
 
 // CreateSensor godoc
 // @Summary Create Sensor
-// @Description Create new sensor
+// @Description Create new sensor; kind virtual computes readings from an expression over input sensors
 // @Tags Sensors
 // @Accept json
 // @Produce json
@@ -74,7 +75,10 @@ func (c *SensorController) Create(ctx *fiber.Ctx) error {
 // @Param order_by query string false "Order by field"
 // @Param sort_by query string false "Sort by direction (asc/desc)"
 // @Param search query string false "Search term"
+// @Param selector query string false "Label selector, e.g. crop=rice,field in (A,B),!deprecated"
+// @Param unit query string false "Serve values in this unit where the sensor unit is compatible"
 // @Success 200 {object} model.SensorResponse
+// @Failure 400 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /sensors [get]
 func (c *SensorController) FindAll(ctx *fiber.Ctx) error {
@@ -86,7 +90,13 @@ func (c *SensorController) FindAll(ctx *fiber.Ctx) error {
 		Search:  ctx.Query("search", ""),
 	}
 
-	sensors, pagination, err := c.UseCase.FindAll(ctx.Context(), req)
+	filter, err := parseSensorFilter(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).
+			JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	sensors, pagination, err := c.UseCase.FindAll(ctx.Context(), req, filter)
 	if err != nil {
 		return ctx.Status(fiber.StatusInternalServerError).
 			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, err.Error()))
@@ -103,18 +113,24 @@ func (c *SensorController) FindAll(ctx *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path string true "Sensor ID"
+// @Param unit query string false "Serve values in this unit, e.g. °F or in"
 // @Success 200 {object} model.SensorResponse
+// @Failure 400 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
 // @Router /sensors/{id} [get]
 func (c *SensorController) FindByID(ctx *fiber.Ctx) error {
 	id := ctx.Params("id")
 
-	sensor, err := c.UseCase.FindByID(ctx.Context(), id)
+	sensor, err := c.UseCase.FindByID(ctx.Context(), id, ctx.Query("unit"))
 	if err != nil {
 		if errors.Is(err, utils.ErrNotFound) {
 			return ctx.Status(fiber.StatusNotFound).
 				JSON(utils.ErrorResponse(fiber.StatusNotFound, "sensor not found"))
 		}
+		if errors.Is(err, utils.ErrValidation) {
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+		}
 
 		return ctx.Status(fiber.StatusInternalServerError).
 			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
@@ -148,13 +164,19 @@ func (c *SensorController) Update(ctx *fiber.Ctx) error {
 
 	err = c.UseCase.Update(ctx.Context(), id, request)
 	if err != nil {
-		if errors.Is(err, utils.ErrNotFound) {
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrNotFound):
 			return ctx.Status(fiber.StatusNotFound).
 				JSON(utils.ErrorResponse(fiber.StatusNotFound, "sensor not found"))
-		}
 
-		return ctx.Status(fiber.StatusInternalServerError).
-			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
 	}
 
 	return ctx.Status(fiber.StatusOK).
@@ -170,6 +192,7 @@ func (c *SensorController) Update(ctx *fiber.Ctx) error {
 // @Param id path string true "Sensor ID"
 // @Success 200 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
 // @Router /sensors/{id} [delete]
 func (c *SensorController) Delete(ctx *fiber.Ctx) error {
 	id := ctx.Params("id")
@@ -180,6 +203,10 @@ func (c *SensorController) Delete(ctx *fiber.Ctx) error {
 			return ctx.Status(fiber.StatusNotFound).
 				JSON(utils.ErrorResponse(fiber.StatusNotFound, "sensor not found"))
 		}
+		if errors.Is(err, utils.ErrConflict) {
+			return ctx.Status(fiber.StatusConflict).
+				JSON(utils.ErrorResponse(fiber.StatusConflict, err.Error()))
+		}
 
 		return ctx.Status(fiber.StatusInternalServerError).
 			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
@@ -188,3 +215,160 @@ func (c *SensorController) Delete(ctx *fiber.Ctx) error {
 	return ctx.Status(fiber.StatusOK).
 		JSON(utils.DefaultSuccessResponse(fiber.StatusOK, "delete sensor successfully"))
 }
+
+// SetLabels godoc
+// @Summary Set Sensor Labels
+// @Description Add or overwrite labels on a sensor
+// @Tags Sensors
+// @Accept json
+// @Produce json
+// @Param id path string true "Sensor ID"
+// @Param request body model.UpdateLabelsRequest true "Labels Request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /sensors/{id}/labels [put]
+func (c *SensorController) SetLabels(ctx *fiber.Ctx) error {
+	request := new(model.UpdateLabelsRequest)
+	id := ctx.Params("id")
+
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to parse request body"))
+	}
+
+	err = c.UseCase.SetLabels(ctx.Context(), id, request)
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrNotFound):
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "sensor not found"))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.DefaultSuccessResponse(fiber.StatusOK, "set sensor labels successfully"))
+}
+
+// RemoveLabel godoc
+// @Summary Remove Sensor Label
+// @Description Remove a label from a sensor
+// @Tags Sensors
+// @Accept json
+// @Produce json
+// @Param id path string true "Sensor ID"
+// @Param key path string true "Label key"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /sensors/{id}/labels/{key} [delete]
+func (c *SensorController) RemoveLabel(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	err := c.UseCase.RemoveLabel(ctx.Context(), id, ctx.Params("key"))
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, err.Error()))
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.DefaultSuccessResponse(fiber.StatusOK, "remove sensor label successfully"))
+}
+
+// Import godoc
+// @Summary Import Sensors from CSV
+// @Description Create or update sensors by name from CSV with the columns device (device name), name, type, unit, is_active and labels (key=value;key=value). Only name is required; new sensors also need device and type, and columns left out are kept on update. Nothing is written when any row fails.
+// @Tags Sensors
+// @Accept text/csv,mpfd
+// @Produce json
+// @Param dry_run query bool false "Validate only"
+// @Param file formData file false "CSV file, or send the CSV as the request body"
+// @Success 200 {object} model.ImportResponse
+// @Failure 400 {object} map[string]interface{}
+// @Router /sensors/import [post]
+func (c *SensorController) Import(ctx *fiber.Ctx) error {
+	reader, err := importReader(ctx)
+	if err != nil {
+		c.Log.Warnf("Failed to read import file : %+v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to read import file"))
+	}
+
+	result, err := c.UseCase.Import(ctx.UserContext(), reader, ctx.QueryBool("dry_run", false))
+	if err != nil {
+		c.Log.Warnf("Failed to import sensors : %+v", err)
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	if result.Failed > 0 {
+		return ctx.Status(fiber.StatusBadRequest).
+			JSON(utils.ErrorResponseWithData(fiber.StatusBadRequest, fmt.Sprintf("%d of %d rows are invalid", result.Failed, result.Rows), result))
+	}
+
+	message := "import sensors successfully"
+	if result.DryRun {
+		message = "import sensors validated, nothing written"
+	}
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponse(fiber.StatusOK, message, result))
+}
+
+// Export godoc
+// @Summary Export Sensors to CSV
+// @Description Stream sensors as CSV in the import layout. Honors the list search and selector; rows come in id order.
+// @Tags Sensors
+// @Produce text/csv
+// @Param search query string false "Search term"
+// @Param selector query string false "Label selector, e.g. crop=rice,field in (A,B),!deprecated"
+// @Success 200 {string} string "CSV"
+// @Failure 400 {object} map[string]interface{}
+// @Router /sensors/export.csv [get]
+func (c *SensorController) Export(ctx *fiber.Ctx) error {
+	filter, err := parseSensorFilter(ctx)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).
+			JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	export, err := c.UseCase.Export(ctx.UserContext(), ctx.Query("search", ""), filter)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+	}
+
+	return streamAttachment(ctx, "sensors.csv", "text/csv; charset=utf-8", export)
+}
+
+func parseSensorFilter(ctx *fiber.Ctx) (*model.SensorFilter, error) {
+	filter := &model.SensorFilter{Unit: ctx.Query("unit")}
+
+	if selector := ctx.Query("selector"); selector != "" {
+		requirements, err := utils.ParseLabelSelector(selector)
+		if err != nil {
+			return nil, fmt.Errorf("%w: selector: %s", utils.ErrValidation, err.Error())
+		}
+		filter.Selector = requirements
+	}
+
+	return filter, nil
+}
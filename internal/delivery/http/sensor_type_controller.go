@@ -0,0 +1,206 @@
+package http
+
+import (
+	"errors"
+	"mertani_test/internal/model"
+	"mertani_test/internal/usecase"
+	"mertani_test/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type SensorTypeController struct {
+	Log     *logrus.Logger
+	UseCase *usecase.SensorTypeUseCase
+}
+
+func NewSensorTypeController(useCase *usecase.SensorTypeUseCase, logger *logrus.Logger) *SensorTypeController {
+	return &SensorTypeController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+// CreateConfigSchema godoc
+// @Summary Create Sensor Type
+// @Description Create a sensor type with its canonical unit, allowed units and physical range
+// @Tags Sensor Types
+// @Accept json
+// @Produce json
+// @Param request body model.CreateSensorTypeRequest true "Sensor Type Request"
+// @Success 200 {object} model.SensorTypeResponse
+// @Failure 400 {object} map[string]interface{}
+// @Router /sensor-types [post]
+func (c *SensorTypeController) Create(ctx *fiber.Ctx) error {
+	request := new(model.CreateSensorTypeRequest)
+
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to parse request body"))
+	}
+
+	err = c.UseCase.Create(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to create sensor type : %+v", err)
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrConflict):
+			return ctx.Status(fiber.StatusConflict).
+				JSON(utils.ErrorResponse(fiber.StatusConflict, err.Error()))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusCreated).
+		JSON(utils.DefaultSuccessResponse(fiber.StatusCreated, "sensor type created successfully"))
+}
+
+// FindAll godoc
+// @Summary Get Sensor Types List
+// @Description Get list of sensor types with pagination
+// @Tags Sensor Types
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Param order_by query string false "Order by field"
+// @Param sort_by query string false "Sort by direction (asc/desc)"
+// @Success 200 {object} model.SensorTypeResponse
+// @Failure 500 {object} map[string]interface{}
+// @Router /sensor-types [get]
+func (c *SensorTypeController) FindAll(ctx *fiber.Ctx) error {
+	req := &utils.PaginationRequest{
+		Page:    ctx.QueryInt("page", 1),
+		Limit:   ctx.QueryInt("limit", 10),
+		OrderBy: ctx.Query("order_by", "created_at"),
+		SortBy:  ctx.Query("sort_by", "desc"),
+	}
+
+	sensorTypes, pagination, err := c.UseCase.FindAll(ctx.Context(), req)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponseWithPagination(fiber.StatusOK, "get list sensor type successfully", sensorTypes, pagination))
+}
+
+// FindByID godoc
+// @Summary Get Sensor Type by ID
+// @Description Get sensor type details by ID
+// @Tags Sensor Types
+// @Accept json
+// @Produce json
+// @Param id path string true "Sensor Type ID"
+// @Success 200 {object} model.SensorTypeResponse
+// @Failure 404 {object} map[string]interface{}
+// @Router /sensor-types/{id} [get]
+func (c *SensorTypeController) FindByID(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	sensorType, err := c.UseCase.FindByID(ctx.Context(), id)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "sensor type not found"))
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponse(fiber.StatusOK, "get detail sensor type successfully", sensorType))
+}
+
+// UpdateConfigSchema godoc
+// @Summary Update Sensor Type
+// @Description Update sensor type by ID
+// @Tags Sensor Types
+// @Accept json
+// @Produce json
+// @Param id path string true "Sensor Type ID"
+// @Param request body model.UpdateSensorTypeRequest true "Sensor Type Request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /sensor-types/{id} [put]
+func (c *SensorTypeController) Update(ctx *fiber.Ctx) error {
+	request := new(model.UpdateSensorTypeRequest)
+	id := ctx.Params("id")
+
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to parse request body"))
+	}
+
+	err = c.UseCase.Update(ctx.Context(), id, request)
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrConflict):
+			return ctx.Status(fiber.StatusConflict).
+				JSON(utils.ErrorResponse(fiber.StatusConflict, err.Error()))
+
+		case errors.Is(err, utils.ErrNotFound):
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "sensor type not found"))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.DefaultSuccessResponse(fiber.StatusOK, "update sensor type successfully"))
+}
+
+// DeleteConfigSchema godoc
+// @Summary Delete Sensor Type
+// @Description Delete sensor type by ID
+// @Tags Sensor Types
+// @Accept json
+// @Produce json
+// @Param id path string true "Sensor Type ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /sensor-types/{id} [delete]
+func (c *SensorTypeController) Delete(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	err := c.UseCase.Delete(ctx.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrNotFound):
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "sensor type not found"))
+
+		case errors.Is(err, utils.ErrConflict):
+			return ctx.Status(fiber.StatusConflict).
+				JSON(utils.ErrorResponse(fiber.StatusConflict, err.Error()))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.DefaultSuccessResponse(fiber.StatusOK, "delete sensor type successfully"))
+}
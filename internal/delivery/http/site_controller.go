@@ -0,0 +1,392 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"mertani_test/internal/model"
+	"mertani_test/internal/usecase"
+	"mertani_test/internal/utils"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type SiteController struct {
+	Log     *logrus.Logger
+	UseCase *usecase.SiteUseCase
+}
+
+func NewSiteController(useCase *usecase.SiteUseCase, logger *logrus.Logger) *SiteController {
+	return &SiteController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+// CreateSite godoc
+// @Summary Create Site
+// @Description Create a site, field, plot or zone node, optionally under a parent
+// @Tags Sites
+// @Accept json
+// @Produce json
+// @Param request body model.CreateSiteRequest true "Site Request"
+// @Success 200 {object} model.SiteResponse
+// @Failure 400 {object} map[string]interface{}
+// @Router /sites [post]
+func (c *SiteController) Create(ctx *fiber.Ctx) error {
+	request := new(model.CreateSiteRequest)
+
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to parse request body"))
+	}
+
+	err = c.UseCase.Create(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to create site : %+v", err)
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusCreated).
+		JSON(utils.DefaultSuccessResponse(fiber.StatusCreated, "site created successfully"))
+}
+
+// FindAll godoc
+// @Summary Get Sites List
+// @Description Get list of sites with pagination
+// @Tags Sites
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Param order_by query string false "Order by field"
+// @Param sort_by query string false "Sort by direction (asc/desc)"
+// @Success 200 {object} model.SiteResponse
+// @Failure 500 {object} map[string]interface{}
+// @Router /sites [get]
+func (c *SiteController) FindAll(ctx *fiber.Ctx) error {
+	req := &utils.PaginationRequest{
+		Page:    ctx.QueryInt("page", 1),
+		Limit:   ctx.QueryInt("limit", 10),
+		OrderBy: ctx.Query("order_by", "created_at"),
+		SortBy:  ctx.Query("sort_by", "desc"),
+	}
+
+	sites, pagination, err := c.UseCase.FindAll(ctx.Context(), req)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponseWithPagination(fiber.StatusOK, "get list site successfully", sites, pagination))
+}
+
+// FindByID godoc
+// @Summary Get Site by ID
+// @Description Get site details with its direct children
+// @Tags Sites
+// @Accept json
+// @Produce json
+// @Param id path string true "Site ID"
+// @Success 200 {object} model.SiteResponse
+// @Failure 404 {object} map[string]interface{}
+// @Router /sites/{id} [get]
+func (c *SiteController) FindByID(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	site, err := c.UseCase.FindByID(ctx.Context(), id)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "site not found"))
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponse(fiber.StatusOK, "get detail site successfully", site))
+}
+
+// UpdateSite godoc
+// @Summary Update Site
+// @Description Update site by ID; setting parent_id moves the node within the tree
+// @Tags Sites
+// @Accept json
+// @Produce json
+// @Param id path string true "Site ID"
+// @Param request body model.UpdateSiteRequest true "Site Request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /sites/{id} [put]
+func (c *SiteController) Update(ctx *fiber.Ctx) error {
+	request := new(model.UpdateSiteRequest)
+	id := ctx.Params("id")
+
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to parse request body"))
+	}
+
+	err = c.UseCase.Update(ctx.Context(), id, request)
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrNotFound):
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "site not found"))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.DefaultSuccessResponse(fiber.StatusOK, "update site successfully"))
+}
+
+// DeleteSite godoc
+// @Summary Delete Site
+// @Description Delete a site without children; its devices are detached
+// @Tags Sites
+// @Accept json
+// @Produce json
+// @Param id path string true "Site ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /sites/{id} [delete]
+func (c *SiteController) Delete(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	err := c.UseCase.Delete(ctx.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrNotFound):
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "site not found"))
+
+		case errors.Is(err, utils.ErrConflict):
+			return ctx.Status(fiber.StatusConflict).
+				JSON(utils.ErrorResponse(fiber.StatusConflict, err.Error()))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.DefaultSuccessResponse(fiber.StatusOK, "delete site successfully"))
+}
+
+// MoveDevices godoc
+// @Summary Move Devices to Site
+// @Description Attach devices to this site, moving them from their current node
+// @Tags Sites
+// @Accept json
+// @Produce json
+// @Param id path string true "Site ID"
+// @Param request body model.MoveDevicesRequest true "Move Devices Request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /sites/{id}/devices [post]
+func (c *SiteController) MoveDevices(ctx *fiber.Ctx) error {
+	request := new(model.MoveDevicesRequest)
+	id := ctx.Params("id")
+
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to parse request body"))
+	}
+
+	err = c.UseCase.MoveDevices(ctx.Context(), id, request)
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrNotFound):
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, err.Error()))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.DefaultSuccessResponse(fiber.StatusOK, "move devices successfully"))
+}
+
+// RemoveDevices godoc
+// @Summary Remove Devices from Site
+// @Description Detach devices from this site, leaving them without one
+// @Tags Sites
+// @Accept json
+// @Produce json
+// @Param id path string true "Site ID"
+// @Param request body model.MoveDevicesRequest true "Remove Devices Request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /sites/{id}/devices [delete]
+func (c *SiteController) RemoveDevices(ctx *fiber.Ctx) error {
+	request := new(model.MoveDevicesRequest)
+	id := ctx.Params("id")
+
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to parse request body"))
+	}
+
+	err = c.UseCase.RemoveDevices(ctx.Context(), id, request)
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrNotFound):
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, err.Error()))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.DefaultSuccessResponse(fiber.StatusOK, "remove devices successfully"))
+}
+
+// FindDevices godoc
+// @Summary Get Site Devices
+// @Description Get devices attached to a site, optionally including all descendant nodes
+// @Tags Sites
+// @Accept json
+// @Produce json
+// @Param id path string true "Site ID"
+// @Param recursive query bool false "Include devices of descendant nodes"
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Param order_by query string false "Order by field"
+// @Param sort_by query string false "Sort by direction (asc/desc)"
+// @Success 200 {object} model.DeviceResponse
+// @Failure 404 {object} map[string]interface{}
+// @Router /sites/{id}/devices [get]
+func (c *SiteController) FindDevices(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	req := &utils.PaginationRequest{
+		Page:    ctx.QueryInt("page", 1),
+		Limit:   ctx.QueryInt("limit", 10),
+		OrderBy: ctx.Query("order_by", "created_at"),
+		SortBy:  ctx.Query("sort_by", "desc"),
+	}
+
+	devices, pagination, err := c.UseCase.FindDevices(ctx.Context(), id, ctx.QueryBool("recursive", false), req)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "site not found"))
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponseWithPagination(fiber.StatusOK, "get list site device successfully", devices, pagination))
+}
+
+// Stats godoc
+// @Summary Get Site Statistics
+// @Description Aggregate device, sensor and reading statistics for a site subtree
+// @Tags Sites
+// @Accept json
+// @Produce json
+// @Param id path string true "Site ID"
+// @Param recursive query bool false "Include descendant nodes (default true)"
+// @Param from query string false "Readings from (RFC3339)"
+// @Param to query string false "Readings to (RFC3339)"
+// @Success 200 {object} model.SiteStatsResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /sites/{id}/stats [get]
+func (c *SiteController) Stats(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	from, err := parseTimeQuery(ctx, "from")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).
+			JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+	to, err := parseTimeQuery(ctx, "to")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).
+			JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+	}
+
+	filter := &model.SiteStatsFilter{
+		Recursive: ctx.QueryBool("recursive", true),
+		From:      from,
+		To:        to,
+	}
+
+	stats, err := c.UseCase.Stats(ctx.Context(), id, filter)
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrNotFound):
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "site not found"))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponse(fiber.StatusOK, "get site statistics successfully", stats))
+}
+
+// parseTimeQuery reads an optional RFC3339 timestamp from the query string.
+func parseTimeQuery(ctx *fiber.Ctx, name string) (*time.Time, error) {
+	value := ctx.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s must be an RFC3339 timestamp", utils.ErrValidation, name)
+	}
+	return &parsed, nil
+}
@@ -0,0 +1,268 @@
+package http
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mertani_test/internal/model"
+	"mertani_test/internal/stream"
+	"mertani_test/internal/usecase"
+	"mertani_test/internal/utils"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// streamHeartbeat is how often an idle SSE stream sends a comment, which
+	// keeps proxies from timing it out and detects gone clients.
+	streamHeartbeat = 15 * time.Second
+	// websocketPingInterval is how often WebSocket clients are pinged; one
+	// that sends nothing, not even a pong, for websocketIdleTimeout is dropped.
+	websocketPingInterval = 30 * time.Second
+	websocketIdleTimeout  = 75 * time.Second
+	websocketWriteTimeout = 10 * time.Second
+)
+
+type StreamController struct {
+	Log     *logrus.Logger
+	UseCase *usecase.StreamUseCase
+}
+
+func NewStreamController(useCase *usecase.StreamUseCase, logger *logrus.Logger) *StreamController {
+	return &StreamController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+// Events godoc
+// @Summary Live Event Stream
+// @Description Server-Sent Events stream of readings, alerts, device status changes and device lifecycle events. Subscribe with device_ids, sensor_ids (comma separated or repeated) or a label selector; an event matching any of them is sent, and none of them means everything. types narrows by event type. A client that falls behind gets a dropped event with the number of events it missed and is disconnected if it keeps falling behind.
+// @Tags Stream
+// @Produce text/event-stream
+// @Param types query string false "Event types: reading, alert, alert_escalated, anomaly, device_status, device_created, device_updated, device_deleted, sensor_created, sensor_updated, sensor_deleted, sensor_activated, sensor_deactivated"
+// @Param device_ids query string false "Device IDs"
+// @Param sensor_ids query string false "Sensor IDs"
+// @Param selector query string false "Label selector, e.g. crop=rice,field in (A,B)"
+// @Success 200 {string} string "event stream"
+// @Failure 400 {object} map[string]interface{}
+// @Router /stream [get]
+func (c *StreamController) Events(ctx *fiber.Ctx) error {
+	subscriber, err := c.UseCase.Subscribe(parseStreamSubscribeRequest(ctx))
+	if err != nil {
+		return c.subscribeError(ctx, err)
+	}
+
+	ctx.Set(fiber.HeaderContentType, "text/event-stream")
+	ctx.Set(fiber.HeaderCacheControl, "no-cache")
+	ctx.Set("X-Accel-Buffering", "no")
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer c.UseCase.Unsubscribe(subscriber)
+
+		heartbeat := time.NewTicker(streamHeartbeat)
+		defer heartbeat.Stop()
+
+		// a write error means the client went away
+		fmt.Fprintf(w, "retry: %d\n\n", (3 * time.Second).Milliseconds())
+		if err := w.Flush(); err != nil {
+			return
+		}
+		for {
+			select {
+			case event := <-subscriber.Events():
+				if dropped := subscriber.TakeDropped(); dropped > 0 {
+					writeSSE(w, 0, "dropped", &model.StreamNotice{Type: "dropped", Dropped: dropped})
+				}
+				writeSSE(w, event.ID, event.Type, &event)
+			case <-heartbeat.C:
+				w.WriteString(": ping\n\n")
+			case <-subscriber.Done():
+				writeSSE(w, 0, "closed", &model.StreamNotice{Type: "closed", Message: "client fell too far behind"})
+				w.Flush()
+				return
+			}
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	})
+	return nil
+}
+
+// WebSocket godoc
+// @Summary Live Event WebSocket
+// @Description WebSocket stream of the same events as /stream, one JSON text message per event. The initial subscription is taken from the query like /stream; send {"action":"subscribe", "types":[...], "device_ids":[...], "sensor_ids":[...], "selector":"..."} at any time to replace it. Clients falling behind receive a dropped notice and are closed with 1008 if they keep falling behind.
+// @Tags Stream
+// @Param types query string false "Event types: reading, alert, alert_escalated, anomaly, device_status, device_created, device_updated, device_deleted, sensor_created, sensor_updated, sensor_deleted, sensor_activated, sensor_deactivated"
+// @Param device_ids query string false "Device IDs"
+// @Param sensor_ids query string false "Sensor IDs"
+// @Param selector query string false "Label selector"
+// @Success 101 {string} string "switching protocols"
+// @Failure 400 {object} map[string]interface{}
+// @Failure 426 {object} map[string]interface{}
+// @Router /stream/ws [get]
+func (c *StreamController) WebSocket(ctx *fiber.Ctx) error {
+	key := ctx.Get("Sec-WebSocket-Key")
+	if !strings.EqualFold(ctx.Get(fiber.HeaderUpgrade), "websocket") ||
+		!headerHasToken(ctx.Get(fiber.HeaderConnection), "upgrade") || key == "" {
+		return ctx.Status(fiber.StatusUpgradeRequired).
+			JSON(utils.ErrorResponse(fiber.StatusUpgradeRequired, "websocket upgrade required"))
+	}
+	if ctx.Get("Sec-WebSocket-Version") != "13" {
+		ctx.Set("Sec-WebSocket-Version", "13")
+		return ctx.Status(fiber.StatusUpgradeRequired).
+			JSON(utils.ErrorResponse(fiber.StatusUpgradeRequired, "unsupported websocket version"))
+	}
+
+	subscriber, err := c.UseCase.Subscribe(parseStreamSubscribeRequest(ctx))
+	if err != nil {
+		return c.subscribeError(ctx, err)
+	}
+
+	ctx.Status(fiber.StatusSwitchingProtocols)
+	ctx.Set(fiber.HeaderUpgrade, "websocket")
+	ctx.Set(fiber.HeaderConnection, "Upgrade")
+	ctx.Set("Sec-WebSocket-Accept", stream.AcceptKey(key))
+	ctx.Context().Hijack(func(conn net.Conn) {
+		ws := stream.NewWebSocket(conn, websocketWriteTimeout)
+		defer ws.Close()
+		defer c.UseCase.Unsubscribe(subscriber)
+		c.serveWebSocket(ws, subscriber)
+	})
+	return nil
+}
+
+// serveWebSocket reads subscription changes on its own goroutine and writes
+// events, pings and notices from this one until either side stops.
+func (c *StreamController) serveWebSocket(ws *stream.WebSocket, subscriber *stream.Subscriber) {
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			opcode, message, err := ws.ReadMessage(websocketIdleTimeout)
+			if err != nil {
+				return
+			}
+			if opcode != stream.OpText {
+				writeWebSocketJSON(ws, &model.StreamNotice{Type: "error", Message: "expected a JSON text message"})
+				continue
+			}
+			writeWebSocketJSON(ws, c.resubscribe(subscriber, message))
+		}
+	}()
+
+	ping := time.NewTicker(websocketPingInterval)
+	defer ping.Stop()
+
+	for {
+		var err error
+		select {
+		case event := <-subscriber.Events():
+			if dropped := subscriber.TakeDropped(); dropped > 0 {
+				err = writeWebSocketJSON(ws, &model.StreamNotice{Type: "dropped", Dropped: dropped})
+			}
+			if err == nil {
+				err = writeWebSocketJSON(ws, &event)
+			}
+		case <-ping.C:
+			err = ws.WritePing()
+		case <-subscriber.Done():
+			ws.WriteClose(stream.ClosePolicyViolation, "client fell too far behind")
+			return
+		case <-closed:
+			ws.WriteClose(stream.CloseGoingAway, "")
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// resubscribe applies a subscribe message and returns the notice answering it.
+func (c *StreamController) resubscribe(subscriber *stream.Subscriber, message []byte) *model.StreamNotice {
+	request := new(model.StreamSubscribeRequest)
+	if err := json.Unmarshal(message, request); err != nil {
+		return &model.StreamNotice{Type: "error", Message: "failed to parse message"}
+	}
+	if request.Action != "" && request.Action != "subscribe" {
+		return &model.StreamNotice{Type: "error", Message: fmt.Sprintf("unknown action %q", request.Action)}
+	}
+
+	filter, err := c.UseCase.Filter(request)
+	if err != nil {
+		return &model.StreamNotice{Type: "error", Message: err.Error()}
+	}
+	subscriber.SetFilter(filter)
+	return &model.StreamNotice{Type: "subscribed"}
+}
+
+func (c *StreamController) subscribeError(ctx *fiber.Ctx, err error) error {
+	c.Log.Warnf("Failed to subscribe to stream : %+v", err)
+	switch {
+	case errors.Is(err, utils.ErrValidation):
+		return ctx.Status(fiber.StatusBadRequest).
+			JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+	default: // internal error
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+	}
+}
+
+// parseStreamSubscribeRequest reads a subscription from the query, where
+// lists may be comma separated, repeated, or both.
+func parseStreamSubscribeRequest(ctx *fiber.Ctx) *model.StreamSubscribeRequest {
+	list := func(key string) []string {
+		var values []string
+		for _, raw := range ctx.Context().QueryArgs().PeekMulti(key) {
+			for _, value := range strings.Split(string(raw), ",") {
+				if value = strings.TrimSpace(value); value != "" {
+					values = append(values, value)
+				}
+			}
+		}
+		return values
+	}
+
+	return &model.StreamSubscribeRequest{
+		Types:     list("types"),
+		DeviceIDs: list("device_ids"),
+		SensorIDs: list("sensor_ids"),
+		Selector:  ctx.Query("selector"),
+	}
+}
+
+func writeSSE(w *bufio.Writer, id uint64, event string, data any) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	if id > 0 {
+		fmt.Fprintf(w, "id: %d\n", id)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, encoded)
+}
+
+func writeWebSocketJSON(ws *stream.WebSocket, data any) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return ws.WriteText(encoded)
+}
+
+// headerHasToken reports whether a comma separated header lists token.
+func headerHasToken(header string, token string) bool {
+	for _, part := range strings.Split(header, ",") {
+		if strings.EqualFold(strings.TrimSpace(part), token) {
+			return true
+		}
+	}
+	return false
+}
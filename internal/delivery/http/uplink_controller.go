@@ -0,0 +1,67 @@
+package http
+
+import (
+	"errors"
+	"mertani_test/internal/model"
+	"mertani_test/internal/usecase"
+	"mertani_test/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type UplinkController struct {
+	Log     *logrus.Logger
+	UseCase *usecase.UplinkUseCase
+}
+
+func NewUplinkController(useCase *usecase.UplinkUseCase, logger *logrus.Logger) *UplinkController {
+	return &UplinkController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+// Uplink godoc
+// @Summary Device Uplink
+// @Description Decode a raw hex or base64 payload with the decoder of the device's hardware model and store the measurements as readings of sensors matched by channel label or name
+// @Tags Devices
+// @Accept json
+// @Produce json
+// @Param id path string true "Device ID"
+// @Param request body model.UplinkRequest true "Uplink Request"
+// @Success 200 {object} model.UplinkResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /devices/{id}/uplink [post]
+func (c *UplinkController) Uplink(ctx *fiber.Ctx) error {
+	request := new(model.UplinkRequest)
+	id := ctx.Params("id")
+
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to parse request body"))
+	}
+
+	result, err := c.UseCase.Uplink(ctx.UserContext(), id, request)
+	if err != nil {
+		c.Log.Warnf("Failed to process uplink : %+v", err)
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrNotFound):
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "device not found"))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponse(fiber.StatusOK, "uplink processed successfully", result))
+}
@@ -0,0 +1,263 @@
+package http
+
+import (
+	"errors"
+	"mertani_test/internal/model"
+	"mertani_test/internal/usecase"
+	"mertani_test/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type WebhookController struct {
+	Log     *logrus.Logger
+	UseCase *usecase.WebhookUseCase
+}
+
+func NewWebhookController(useCase *usecase.WebhookUseCase, logger *logrus.Logger) *WebhookController {
+	return &WebhookController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+// CreateWebhook godoc
+// @Summary Create Webhook
+// @Description Subscribe a URL to events: reading, alert, alert_escalated, anomaly, device_status, device_created, device_updated, device_deleted, sensor_created, sensor_updated, sensor_deleted, sensor_activated, sensor_deactivated. Each delivery is a JSON POST signed in X-Webhook-Signature as sha256=hex(HMAC-SHA256(secret, "<X-Webhook-Timestamp>.<body>")). The secret is generated when omitted and only returned by this call.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param request body model.CreateWebhookRequest true "Webhook Request"
+// @Success 201 {object} model.WebhookResponse
+// @Failure 400 {object} map[string]interface{}
+// @Router /webhooks [post]
+func (c *WebhookController) Create(ctx *fiber.Ctx) error {
+	request := new(model.CreateWebhookRequest)
+
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to parse request body"))
+	}
+
+	webhook, err := c.UseCase.Create(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.Warnf("Failed to create webhook : %+v", err)
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusCreated).
+		JSON(utils.SuccessResponse(fiber.StatusCreated, "webhook created successfully", webhook))
+}
+
+// FindAll godoc
+// @Summary Get Webhooks List
+// @Description Get list of webhooks with pagination
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Param order_by query string false "Order by field"
+// @Param sort_by query string false "Sort by direction (asc/desc)"
+// @Success 200 {object} model.WebhookResponse
+// @Failure 500 {object} map[string]interface{}
+// @Router /webhooks [get]
+func (c *WebhookController) FindAll(ctx *fiber.Ctx) error {
+	req := &utils.PaginationRequest{
+		Page:    ctx.QueryInt("page", 1),
+		Limit:   ctx.QueryInt("limit", 10),
+		OrderBy: ctx.Query("order_by", "created_at"),
+		SortBy:  ctx.Query("sort_by", "desc"),
+	}
+
+	webhooks, pagination, err := c.UseCase.FindAll(ctx.Context(), req)
+	if err != nil {
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, err.Error()))
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponseWithPagination(fiber.StatusOK, "get list webhook successfully", webhooks, pagination))
+}
+
+// FindByID godoc
+// @Summary Get Webhook by ID
+// @Description Get webhook details by ID
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} model.WebhookResponse
+// @Failure 404 {object} map[string]interface{}
+// @Router /webhooks/{id} [get]
+func (c *WebhookController) FindByID(ctx *fiber.Ctx) error {
+	webhook, err := c.UseCase.FindByID(ctx.Context(), ctx.Params("id"))
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "webhook not found"))
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponse(fiber.StatusOK, "get detail webhook successfully", webhook))
+}
+
+// UpdateWebhook godoc
+// @Summary Update Webhook
+// @Description Update webhook by ID; setting secret rotates it
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param request body model.UpdateWebhookRequest true "Webhook Request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /webhooks/{id} [put]
+func (c *WebhookController) Update(ctx *fiber.Ctx) error {
+	request := new(model.UpdateWebhookRequest)
+	id := ctx.Params("id")
+
+	err := ctx.BodyParser(request)
+	if err != nil {
+		c.Log.Warnf("Failed to parse request body : %+v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse(fiber.StatusBadRequest, "Failed to parse request body"))
+	}
+
+	err = c.UseCase.Update(ctx.Context(), id, request)
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrNotFound):
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "webhook not found"))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.DefaultSuccessResponse(fiber.StatusOK, "update webhook successfully"))
+}
+
+// DeleteWebhook godoc
+// @Summary Delete Webhook
+// @Description Delete webhook by ID together with its delivery log
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /webhooks/{id} [delete]
+func (c *WebhookController) Delete(ctx *fiber.Ctx) error {
+	err := c.UseCase.Delete(ctx.Context(), ctx.Params("id"))
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "webhook not found"))
+		}
+
+		return ctx.Status(fiber.StatusInternalServerError).
+			JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.DefaultSuccessResponse(fiber.StatusOK, "delete webhook successfully"))
+}
+
+// FindDeliveries godoc
+// @Summary Get Webhook Deliveries
+// @Description Delivery log of a webhook: payload, attempts, next retry and the outcome of the latest attempt
+// @Tags Webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param status query string false "pending, succeeded or failed"
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Param order_by query string false "Order by field"
+// @Param sort_by query string false "Sort by direction (asc/desc)"
+// @Success 200 {object} model.WebhookDeliveryResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /webhooks/{id}/deliveries [get]
+func (c *WebhookController) FindDeliveries(ctx *fiber.Ctx) error {
+	req := &utils.PaginationRequest{
+		Page:    ctx.QueryInt("page", 1),
+		Limit:   ctx.QueryInt("limit", 10),
+		OrderBy: ctx.Query("order_by", "created_at"),
+		SortBy:  ctx.Query("sort_by", "desc"),
+	}
+
+	deliveries, pagination, err := c.UseCase.FindDeliveries(ctx.Context(), ctx.Params("id"), ctx.Query("status"), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrValidation):
+			return ctx.Status(fiber.StatusBadRequest).
+				JSON(utils.ErrorResponse(fiber.StatusBadRequest, err.Error()))
+
+		case errors.Is(err, utils.ErrNotFound):
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "webhook not found"))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusOK).
+		JSON(utils.SuccessResponseWithPagination(fiber.StatusOK, "get list webhook delivery successfully", deliveries, pagination))
+}
+
+// Redeliver godoc
+// @Summary Redeliver Webhook Event
+// @Description Queue the event of a past delivery again as a new delivery with the same event id
+// @Tags Webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param deliveryId path string true "Delivery ID"
+// @Success 202 {object} model.WebhookDeliveryResponse
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /webhooks/{id}/deliveries/{deliveryId}/redeliver [post]
+func (c *WebhookController) Redeliver(ctx *fiber.Ctx) error {
+	delivery, err := c.UseCase.Redeliver(ctx.UserContext(), ctx.Params("id"), ctx.Params("deliveryId"))
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrNotFound):
+			return ctx.Status(fiber.StatusNotFound).
+				JSON(utils.ErrorResponse(fiber.StatusNotFound, "webhook delivery not found"))
+
+		case errors.Is(err, utils.ErrConflict):
+			return ctx.Status(fiber.StatusConflict).
+				JSON(utils.ErrorResponse(fiber.StatusConflict, err.Error()))
+
+		default: // internal error
+			return ctx.Status(fiber.StatusInternalServerError).
+				JSON(utils.ErrorResponse(fiber.StatusInternalServerError, "internal server error"))
+		}
+	}
+
+	return ctx.Status(fiber.StatusAccepted).
+		JSON(utils.SuccessResponse(fiber.StatusAccepted, "webhook delivery queued", delivery))
+}
@@ -0,0 +1,123 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Alert is a condition raised against a device or sensor. Only one alert
+// per Fingerprint fires at a time; raising it again updates that alert.
+// An alert matched by an active silence is stored with SilenceID and not
+// notified until the silence ends. While it fires unacknowledged, its
+// escalation policy re-notifies at NextEscalationAt.
+type Alert struct {
+	ID                 uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	Fingerprint        string     `gorm:"size:200;not null;index;uniqueIndex:idx_alert_firing,where:status = 'firing'"`
+	Status             string     `gorm:"size:20;not null;default:firing;index"`
+	Severity           string     `gorm:"size:20;not null"`
+	Title              string     `gorm:"size:200;not null"`
+	Message            string     `gorm:"type:text"`
+	DeviceID           *uuid.UUID `gorm:"type:uuid;index"`
+	DeviceName         string     `gorm:"size:100"`
+	SensorID           *uuid.UUID `gorm:"type:uuid;index"`
+	SensorName         string     `gorm:"size:100"`
+	SensorType         string     `gorm:"size:50"`
+	Value              *float64
+	Unit               string `gorm:"size:20"`
+	Labels             Labels `gorm:"type:jsonb;not null;default:'{}'"`
+	StartsAt           time.Time
+	EndsAt             *time.Time
+	AcknowledgedAt     *time.Time
+	AcknowledgedBy     string     `gorm:"size:100"`
+	ResolvedBy         string     `gorm:"size:100"`
+	SilenceID          *uuid.UUID `gorm:"type:uuid;index"`
+	EscalationPolicyID *uuid.UUID `gorm:"type:uuid"`
+	EscalationLevel    int        `gorm:"not null;default:0"`
+	NextEscalationAt   *time.Time `gorm:"index"`
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+
+	Device           *Device           `gorm:"foreignKey:DeviceID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+	Sensor           *Sensor           `gorm:"foreignKey:SensorID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+	EscalationPolicy *EscalationPolicy `gorm:"foreignKey:EscalationPolicyID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+	Comments         []AlertComment    `gorm:"foreignKey:AlertID"`
+}
+
+type AlertComment struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	AlertID   uuid.UUID `gorm:"type:uuid;not null;index"`
+	Author    string    `gorm:"size:100"`
+	Body      string    `gorm:"type:text;not null"`
+	CreatedAt time.Time
+
+	Alert Alert `gorm:"foreignKey:AlertID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// Silence mutes firing alerts from StartsAt to EndsAt whose labels match
+// Selector and, when set, whose sensor is of SensorType.
+type Silence struct {
+	ID         uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	Selector   string    `gorm:"size:500"`
+	SensorType string    `gorm:"size:50"`
+	StartsAt   time.Time `gorm:"not null"`
+	EndsAt     time.Time `gorm:"not null;index"`
+	Comment    string    `gorm:"size:500"`
+	CreatedBy  string    `gorm:"size:100"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// EscalationPolicy applies to alerts of at least MinSeverity whose labels
+// match Selector and, when set, whose sensor is of SensorType. Each step
+// notifies its channels once the alert has stayed unacknowledged for
+// AfterMinutes since it fired or since the previous step.
+type EscalationPolicy struct {
+	ID          uuid.UUID       `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	Name        string          `gorm:"size:100;not null"`
+	MinSeverity string          `gorm:"size:20;not null;default:info"`
+	Selector    string          `gorm:"size:500"`
+	SensorType  string          `gorm:"size:50"`
+	Steps       EscalationSteps `gorm:"type:jsonb;not null;default:'[]'"`
+	IsActive    bool            `gorm:"default:true"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type EscalationStep struct {
+	AfterMinutes int      `json:"after_minutes"`
+	ChannelIDs   []string `json:"channel_ids"`
+}
+
+// EscalationSteps stores the steps of a policy in a jsonb column.
+type EscalationSteps []EscalationStep
+
+func (s EscalationSteps) Value() (driver.Value, error) {
+	if s == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (s *EscalationSteps) Scan(value any) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*s = EscalationSteps{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into EscalationSteps", value)
+	}
+	return json.Unmarshal(data, s)
+}
@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AnomalyDetector runs a detector of Type with Params on the readings of
+// one sensor, or of every sensor of SensorType. A detector set on a sensor
+// replaces one of the same type set on its sensor type. With RaiseAlert,
+// every anomaly found also fires an alert of Severity.
+type AnomalyDetector struct {
+	ID         uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	SensorID   *uuid.UUID `gorm:"type:uuid;index"`
+	SensorType string     `gorm:"size:50;index"`
+	Type       string     `gorm:"size:30;not null"`
+	Params     JSONB      `gorm:"type:jsonb;not null;default:'{}'"`
+	Severity   string     `gorm:"size:20;not null;default:warning"`
+	RaiseAlert bool       `gorm:"default:true"`
+	IsActive   bool       `gorm:"default:true"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+
+	Sensor *Sensor `gorm:"foreignKey:SensorID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// Anomaly is a reading a detector found unusual. AlertID is the alert it
+// raised, if any.
+type Anomaly struct {
+	ID         uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	SensorID   uuid.UUID  `gorm:"type:uuid;not null;index:idx_anomaly_sensor_time,priority:1"`
+	DetectorID *uuid.UUID `gorm:"type:uuid;index"`
+	Detector   string     `gorm:"size:30;not null"`
+	ReadingID  uuid.UUID  `gorm:"type:uuid;not null"`
+	Value      float64    `gorm:"not null"`
+	Expected   *float64
+	Score      float64    `gorm:"not null"`
+	Severity   string     `gorm:"size:20;not null"`
+	Message    string     `gorm:"size:500"`
+	RecordedAt time.Time  `gorm:"not null;index:idx_anomaly_sensor_time,priority:2"`
+	AlertID    *uuid.UUID `gorm:"type:uuid;index"`
+	CreatedAt  time.Time
+
+	Sensor          Sensor           `gorm:"foreignKey:SensorID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	AnomalyDetector *AnomalyDetector `gorm:"foreignKey:DetectorID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+	Alert           *Alert           `gorm:"foreignKey:AlertID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+}
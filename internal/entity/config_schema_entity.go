@@ -0,0 +1,15 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ConfigSchema struct {
+	ID            uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	HardwareModel string    `gorm:"size:100;not null;uniqueIndex"`
+	Schema        JSONB     `gorm:"type:jsonb;not null"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
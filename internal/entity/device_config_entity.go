@@ -0,0 +1,24 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DeviceConfigDesired  = "desired"
+	DeviceConfigReported = "reported"
+)
+
+type DeviceConfig struct {
+	ID             uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	DeviceID       uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_device_config_version"`
+	Kind           string    `gorm:"size:20;not null;uniqueIndex:idx_device_config_version"`
+	Version        int       `gorm:"not null;uniqueIndex:idx_device_config_version"`
+	Document       JSONB     `gorm:"type:jsonb;not null"`
+	AppliedVersion int       `gorm:"not null;default:0"`
+	CreatedAt      time.Time
+
+	Device Device `gorm:"foreignKey:DeviceID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
@@ -7,12 +7,20 @@ import (
 )
 
 type Device struct {
-	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
-	Name      string    `gorm:"size:100;not null"`
-	Location  string    `gorm:"size:150"`
-	Status    string    `gorm:"size:50;default:'active'"`
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID            uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	Name          string     `gorm:"size:100;not null"`
+	Location      string     `gorm:"size:150"`
+	Status        string     `gorm:"size:50;default:'active'"`
+	HardwareModel string     `gorm:"size:100;index"`
+	SiteID        *uuid.UUID `gorm:"type:uuid;index"`
+	Latitude      *float64   `gorm:"index:idx_device_coordinates"`
+	Longitude     *float64   `gorm:"index:idx_device_coordinates"`
+	Boundary      GeoPolygon `gorm:"type:jsonb"`
+	Labels        Labels     `gorm:"type:jsonb;not null;default:'{}';index:,type:gin"`
+	LastCheckInAt *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
 
+	Site    *Site    `gorm:"foreignKey:SiteID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
 	Sensors []Sensor `gorm:"foreignKey:DeviceID"`
-}
\ No newline at end of file
+}
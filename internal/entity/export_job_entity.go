@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ExportJobSensorReadings = "sensor_readings"
+	ExportJobDeviceReadings = "device_readings"
+
+	ExportJobPending   = "pending"
+	ExportJobRunning   = "running"
+	ExportJobCompleted = "completed"
+	ExportJobFailed    = "failed"
+	ExportJobExpired   = "expired"
+)
+
+// ExportJob is a readings export produced in the background. TargetID is a
+// sensor or a device depending on Kind; the finished file lives at FilePath
+// until ExpiresAt.
+type ExportJob struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	Kind        string    `gorm:"size:30;not null"`
+	TargetID    uuid.UUID `gorm:"type:uuid;not null"`
+	Format      string    `gorm:"size:10;not null"`
+	RangeFrom   *time.Time
+	RangeTo     *time.Time
+	Interval    string     `gorm:"size:10"`
+	Unit        string     `gorm:"size:20"`
+	Status      string     `gorm:"size:20;not null;default:pending;index"`
+	Rows        int64      `gorm:"not null;default:0"`
+	Size        int64      `gorm:"not null;default:0"`
+	Error       string     `gorm:"size:500"`
+	FileName    string     `gorm:"size:255"`
+	ContentType string     `gorm:"size:100"`
+	FilePath    string     `gorm:"size:500"`
+	ExpiresAt   *time.Time `gorm:"index"`
+	StartedAt   *time.Time
+	FinishedAt  *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
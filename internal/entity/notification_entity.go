@@ -0,0 +1,108 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Alert severities, from least to most severe.
+const (
+	AlertSeverityInfo     = "info"
+	AlertSeverityWarning  = "warning"
+	AlertSeverityCritical = "critical"
+)
+
+const (
+	AlertStatusFiring   = "firing"
+	AlertStatusResolved = "resolved"
+)
+
+// AlertSeverityRank orders severities; unknown ones rank below info.
+func AlertSeverityRank(severity string) int {
+	switch severity {
+	case AlertSeverityInfo:
+		return 1
+	case AlertSeverityWarning:
+		return 2
+	case AlertSeverityCritical:
+		return 3
+	}
+	return 0
+}
+
+const (
+	NotificationPending    = "pending"
+	NotificationSent       = "sent"
+	NotificationFailed     = "failed"
+	NotificationSuppressed = "suppressed"
+	NotificationQuiet      = "quiet"
+)
+
+// NotificationChannel is a destination for notifications. Config holds the
+// settings of its Type, secrets included.
+type NotificationChannel struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	Name      string    `gorm:"size:100;not null"`
+	Type      string    `gorm:"size:20;not null"`
+	Config    JSONB     `gorm:"type:jsonb;not null;default:'{}'"`
+	IsActive  bool      `gorm:"default:true"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NotificationRule routes alerts of at least MinSeverity whose labels match
+// Selector to its channels. During quiet hours, from QuietStart to QuietEnd
+// in Timezone, only critical alerts are sent right away and others once
+// they end, each alert once however often it repeats meanwhile. Repeats of
+// an alert within GroupWindowSeconds of the last notification about it are
+// held back too, and counted in the next one.
+type NotificationRule struct {
+	ID                 uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	Name               string     `gorm:"size:100;not null"`
+	MinSeverity        string     `gorm:"size:20;not null;default:info"`
+	Selector           string     `gorm:"size:500"`
+	ChannelIDs         StringList `gorm:"type:jsonb;not null;default:'[]'"`
+	SubjectTemplate    string     `gorm:"type:text"`
+	BodyTemplate       string     `gorm:"type:text"`
+	QuietStart         string     `gorm:"size:5"`
+	QuietEnd           string     `gorm:"size:5"`
+	Timezone           string     `gorm:"size:50"`
+	GroupWindowSeconds int        `gorm:"not null;default:0"`
+	NotifyResolved     bool       `gorm:"default:true"`
+	IsActive           bool       `gorm:"default:true"`
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+// Notification is one alert routed by one rule, or escalated by one policy,
+// to one channel, kept as the delivery log. Pending notifications are
+// attempted at NextAttemptAt; quiet ones are held until their quiet hours
+// end at NextAttemptAt, and dropped with it cleared when they have become
+// pointless by then; suppressed ones were never sent.
+type Notification struct {
+	ID                 uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	EventID            uuid.UUID  `gorm:"type:uuid;not null;index"`
+	RuleID             *uuid.UUID `gorm:"type:uuid;index:idx_notification_group,priority:1"`
+	EscalationPolicyID *uuid.UUID `gorm:"type:uuid;index"`
+	EscalationLevel    int        `gorm:"not null;default:0"`
+	ChannelID          uuid.UUID  `gorm:"type:uuid;not null;index:idx_notification_group,priority:2"`
+	Fingerprint        string     `gorm:"size:200;not null;index:idx_notification_group,priority:3"`
+	AlertID            string     `gorm:"size:36"`
+	AlertStatus        string     `gorm:"size:20"`
+	Severity           string     `gorm:"size:20;not null"`
+	Subject            string     `gorm:"size:500"`
+	Body               string     `gorm:"type:text"`
+	Payload            string     `gorm:"type:text"`
+	Status             string     `gorm:"size:20;not null;default:pending;index:idx_notification_due,priority:1"`
+	Attempts           int        `gorm:"not null;default:0"`
+	NextAttemptAt      *time.Time `gorm:"index:idx_notification_due,priority:2"`
+	LastError          string     `gorm:"size:500"`
+	SentAt             *time.Time
+	CreatedAt          time.Time `gorm:"index:idx_notification_group,priority:4"`
+	UpdatedAt          time.Time
+
+	Channel          NotificationChannel `gorm:"foreignKey:ChannelID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Rule             *NotificationRule   `gorm:"foreignKey:RuleID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	EscalationPolicy *EscalationPolicy   `gorm:"foreignKey:EscalationPolicyID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxEvent is a domain event written in the same transaction as the
+// change it describes and published by the relay afterwards. ID orders the
+// events; unpublished ones wait for NextAttemptAt after a failure, and are
+// not published again to the subscribers in Delivered. An event that keeps
+// failing is given up on at DeadAt.
+type OutboxEvent struct {
+	ID            int64      `gorm:"primaryKey;autoIncrement"`
+	EventID       uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex"`
+	Type          string     `gorm:"size:30;not null"`
+	DeviceID      string     `gorm:"size:36"`
+	SensorID      string     `gorm:"size:36"`
+	Labels        Labels     `gorm:"type:jsonb"`
+	Payload       string     `gorm:"type:text;not null"`
+	OccurredAt    time.Time  `gorm:"not null"`
+	PublishedAt   *time.Time `gorm:"index"`
+	Attempts      int        `gorm:"not null;default:0"`
+	NextAttemptAt *time.Time
+	Delivered     StringList `gorm:"type:jsonb;not null;default:'[]'"`
+	DeadAt        *time.Time `gorm:"index"`
+	LastError     string     `gorm:"size:500"`
+}
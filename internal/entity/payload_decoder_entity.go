@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"mertani_test/internal/decoder"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PayloadDecoderLayout selects the declarative Layout instead of a built-in.
+const PayloadDecoderLayout = "layout"
+
+// PayloadDecoder tells how uplinks of devices of a hardware model are decoded:
+// either a built-in decoder by name or the stored byte Layout.
+type PayloadDecoder struct {
+	ID            uuid.UUID      `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	HardwareModel string         `gorm:"size:100;not null;uniqueIndex"`
+	Decoder       string         `gorm:"size:50;not null"`
+	Layout        decoder.Layout `gorm:"type:jsonb"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
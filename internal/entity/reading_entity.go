@@ -0,0 +1,57 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Reading quality flags, combined in Reading.Quality. A reading without
+// flags is good.
+const (
+	QualityOutOfRange = 1 << iota
+	QualityDuplicate
+)
+
+// QualityCodes names the quality flags.
+var QualityCodes = map[int]string{
+	QualityOutOfRange: "out_of_range",
+	QualityDuplicate:  "duplicate",
+}
+
+// Reading keeps the raw Value as reported. CalibratedValue holds Value with the
+// calibration in force at RecordedAt applied, or nil when none was. Quality
+// holds the flags set on the reading when it was stored. On Postgres the
+// table is partitioned by month on RecordedAt, which is why it is part of
+// the primary key.
+type Reading struct {
+	ID              uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	SensorID        uuid.UUID `gorm:"type:uuid;not null;index:idx_reading_sensor_time,priority:1"`
+	Value           float64   `gorm:"not null"`
+	CalibratedValue *float64
+	RecordedAt      time.Time `gorm:"primaryKey;not null;index:idx_reading_sensor_time,priority:2"`
+	Quality         int       `gorm:"not null;default:0"`
+	CreatedAt       time.Time
+
+	Sensor Sensor `gorm:"foreignKey:SensorID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// QualityFlags lists the codes of the flags set on the reading, in flag
+// order.
+func (r *Reading) QualityFlags() []string {
+	var flags []string
+	for flag := QualityOutOfRange; flag <= QualityDuplicate; flag <<= 1 {
+		if r.Quality&flag != 0 {
+			flags = append(flags, QualityCodes[flag])
+		}
+	}
+	return flags
+}
+
+// EffectiveValue is the value served to clients: calibrated when available.
+func (r *Reading) EffectiveValue() float64 {
+	if r.CalibratedValue != nil {
+		return *r.CalibratedValue
+	}
+	return r.Value
+}
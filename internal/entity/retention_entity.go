@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Rollup resolutions.
+const (
+	RollupHour = "hour"
+	RollupDay  = "day"
+)
+
+// RetentionPolicy sets how long the readings of the sensors of SensorType
+// are kept at each resolution, in days, zero keeping them forever: raw, then
+// rolled up per hour, then per day. The policy without a sensor type applies
+// to sensor types that have none; sensors under no policy keep every raw
+// reading.
+type RetentionPolicy struct {
+	ID         uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	SensorType string    `gorm:"size:50;not null;default:'';uniqueIndex"`
+	RawDays    int       `gorm:"not null;default:0"`
+	HourlyDays int       `gorm:"not null;default:0"`
+	DailyDays  int       `gorm:"not null;default:0"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// ReadingRollup sums up the readings of a sensor in the bucket of
+// Resolution starting at Bucket. Rollups only hold readings compacted away
+// from the tier before them, so a sensor's raw readings, hourly and daily
+// rollups never cover the same reading.
+type ReadingRollup struct {
+	SensorID   uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Resolution string    `gorm:"size:10;primaryKey"`
+	Bucket     time.Time `gorm:"primaryKey"`
+	Count      int64     `gorm:"not null"`
+	Min        float64   `gorm:"not null"`
+	Max        float64   `gorm:"not null"`
+	Sum        float64   `gorm:"not null"`
+
+	Sensor Sensor `gorm:"foreignKey:SensorID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
@@ -0,0 +1,78 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	CalibrationOffsetGain = "offset_gain"
+	CalibrationPolynomial = "polynomial"
+	CalibrationLookup     = "lookup"
+)
+
+// SensorCalibration is one version of a sensor's calibration. It is in force
+// from EffectiveFrom until the next version of the same sensor.
+type SensorCalibration struct {
+	ID            uuid.UUID   `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	SensorID      uuid.UUID   `gorm:"type:uuid;not null;uniqueIndex:idx_calibration_sensor_from,priority:1"`
+	Method        string      `gorm:"size:20;not null"`
+	Offset        float64     `gorm:"not null"`
+	Gain          float64     `gorm:"not null"`
+	Polynomial    Float64List `gorm:"type:jsonb"`
+	Table         PointList   `gorm:"type:jsonb"`
+	EffectiveFrom time.Time   `gorm:"not null;uniqueIndex:idx_calibration_sensor_from,priority:2"`
+	Note          string      `gorm:"size:255"`
+	CreatedAt     time.Time
+
+	Sensor Sensor `gorm:"foreignKey:SensorID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// Apply maps a raw value to its calibrated value.
+func (c *SensorCalibration) Apply(raw float64) float64 {
+	switch c.Method {
+	case CalibrationPolynomial:
+		// coefficients are ordered from the constant term upwards
+		result := 0.0
+		for i := len(c.Polynomial) - 1; i >= 0; i-- {
+			result = result*raw + c.Polynomial[i]
+		}
+		return result
+
+	case CalibrationLookup:
+		return c.interpolate(raw)
+
+	default:
+		return raw*c.Gain + c.Offset
+	}
+}
+
+// interpolate is piecewise linear over the table, extending the first and
+// last segments beyond its ends. Table x values are strictly increasing.
+func (c *SensorCalibration) interpolate(raw float64) float64 {
+	if len(c.Table) < 2 {
+		return raw
+	}
+
+	i := 1
+	for i < len(c.Table)-1 && raw > c.Table[i][0] {
+		i++
+	}
+	x0, y0 := c.Table[i-1][0], c.Table[i-1][1]
+	x1, y1 := c.Table[i][0], c.Table[i][1]
+	return y0 + (raw-x0)*(y1-y0)/(x1-x0)
+}
+
+// CalibrationAt returns the calibration in force at the given time from a
+// list ordered by EffectiveFrom, or nil when none applies yet.
+func CalibrationAt(calibrations []SensorCalibration, at time.Time) *SensorCalibration {
+	var current *SensorCalibration
+	for i := range calibrations {
+		if calibrations[i].EffectiveFrom.After(at) {
+			break
+		}
+		current = &calibrations[i]
+	}
+	return current
+}
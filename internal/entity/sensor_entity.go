@@ -6,15 +6,45 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	SensorPhysical = "physical"
+	SensorVirtual  = "virtual"
+)
+
+// Sensor of kind virtual has no hardware; its readings are computed from
+// Expression over the latest readings of its Inputs. ExpectedInterval is how
+// often the sensor reports, in seconds, or zero to estimate it from its
+// readings; readings outside ValidMin..ValidMax, in the sensor's unit, are
+// kept but flagged out of range. Latest is its latest reading, when loaded.
 type Sensor struct {
-	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
-	DeviceID  uuid.UUID `gorm:"type:uuid;not null;index"`
-	Name      string    `gorm:"size:100;not null"`
-	Type      string    `gorm:"size:50;not null"`
-	Unit      string    `gorm:"size:20"`
-	IsActive  bool      `gorm:"default:true"`
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID               uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	DeviceID         uuid.UUID `gorm:"type:uuid;not null;index"`
+	Name             string    `gorm:"size:100;not null"`
+	Type             string    `gorm:"size:50;not null"`
+	Unit             string    `gorm:"size:20"`
+	IsActive         bool      `gorm:"default:true"`
+	Labels           Labels    `gorm:"type:jsonb;not null;default:'{}';index:,type:gin"`
+	Kind             string    `gorm:"size:20;not null;default:physical"`
+	Expression       string    `gorm:"size:1000"`
+	ExpectedInterval int       `gorm:"not null;default:0"`
+	ValidMin         *float64
+	ValidMax         *float64
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+
+	Device Device        `gorm:"foreignKey:DeviceID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Inputs []SensorInput `gorm:"foreignKey:SensorID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Latest *SensorLatest `gorm:"foreignKey:SensorID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
 
-	Device Device `gorm:"foreignKey:DeviceID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
-}
\ No newline at end of file
+// InValidRange reports whether a value in the sensor's unit is within the
+// sensor's operating range.
+func (s *Sensor) InValidRange(value float64) bool {
+	if s.ValidMin != nil && value < *s.ValidMin {
+		return false
+	}
+	if s.ValidMax != nil && value > *s.ValidMax {
+		return false
+	}
+	return true
+}
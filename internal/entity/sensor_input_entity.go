@@ -0,0 +1,13 @@
+package entity
+
+import "github.com/google/uuid"
+
+// SensorInput binds a variable of a virtual sensor's expression to the
+// sensor whose readings supply it.
+type SensorInput struct {
+	SensorID      uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Alias         string    `gorm:"size:50;primaryKey"`
+	InputSensorID uuid.UUID `gorm:"type:uuid;not null;index"`
+
+	InputSensor Sensor `gorm:"foreignKey:InputSensorID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+}
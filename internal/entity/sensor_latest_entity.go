@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SensorLatest holds the latest reading of a sensor by RecordedAt. It is
+// written in the transaction storing each reading, so current values are
+// served without scanning the readings table.
+type SensorLatest struct {
+	SensorID        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	ReadingID       uuid.UUID `gorm:"type:uuid;not null"`
+	Value           float64   `gorm:"not null"`
+	CalibratedValue *float64
+	RecordedAt      time.Time `gorm:"not null"`
+	UpdatedAt       time.Time
+}
+
+func (SensorLatest) TableName() string {
+	return "sensor_latest"
+}
+
+// EffectiveValue is the value served to clients: calibrated when available.
+func (l *SensorLatest) EffectiveValue() float64 {
+	if l.CalibratedValue != nil {
+		return *l.CalibratedValue
+	}
+	return l.Value
+}
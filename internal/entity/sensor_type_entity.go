@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SensorType struct {
+	ID            uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	Code          string     `gorm:"size:50;not null;uniqueIndex"`
+	DisplayName   string     `gorm:"size:100;not null"`
+	CanonicalUnit string     `gorm:"size:20;not null"`
+	AllowedUnits  StringList `gorm:"type:jsonb;not null;default:'[]'"`
+	PhysicalMin   *float64
+	PhysicalMax   *float64
+	Precision     int `gorm:"not null;default:2"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// InRange reports whether a value in the canonical unit is physically possible.
+func (t *SensorType) InRange(value float64) bool {
+	if t.PhysicalMin != nil && value < *t.PhysicalMin {
+		return false
+	}
+	if t.PhysicalMax != nil && value > *t.PhysicalMax {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,19 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Site struct {
+	ID        uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	ParentID  *uuid.UUID `gorm:"type:uuid;index"`
+	Name      string     `gorm:"size:100;not null"`
+	Kind      string     `gorm:"size:20;not null;default:'site'"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Parent   *Site  `gorm:"foreignKey:ParentID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Children []Site `gorm:"foreignKey:ParentID"`
+}
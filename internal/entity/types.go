@@ -0,0 +1,197 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// JSONB stores an arbitrary JSON object in a jsonb column.
+type JSONB map[string]any
+
+func (j JSONB) Value() (driver.Value, error) {
+	if j == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(j)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (j *JSONB) Scan(value any) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*j = JSONB{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into JSONB", value)
+	}
+	return json.Unmarshal(data, j)
+}
+
+// GeoPolygon stores a single closed ring of [longitude, latitude] pairs,
+// following GeoJSON coordinate order.
+type GeoPolygon [][]float64
+
+func (p GeoPolygon) Value() (driver.Value, error) {
+	if p == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (p *GeoPolygon) Scan(value any) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*p = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into GeoPolygon", value)
+	}
+	return json.Unmarshal(data, p)
+}
+
+// Labels stores free-form key/value tags in a jsonb column.
+type Labels map[string]string
+
+func (l Labels) Value() (driver.Value, error) {
+	if l == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (l *Labels) Scan(value any) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*l = Labels{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into Labels", value)
+	}
+	return json.Unmarshal(data, l)
+}
+
+// StringList stores a list of strings in a jsonb column.
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (l *StringList) Scan(value any) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*l = StringList{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into StringList", value)
+	}
+	return json.Unmarshal(data, l)
+}
+
+func (l StringList) Contains(value string) bool {
+	for _, item := range l {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Float64List stores a list of numbers in a jsonb column.
+type Float64List []float64
+
+func (l Float64List) Value() (driver.Value, error) {
+	if l == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (l *Float64List) Scan(value any) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into Float64List", value)
+	}
+	return json.Unmarshal(data, l)
+}
+
+// PointList stores [x, y] pairs in a jsonb column.
+type PointList [][]float64
+
+func (l PointList) Value() (driver.Value, error) {
+	if l == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (l *PointList) Scan(value any) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into PointList", value)
+	}
+	return json.Unmarshal(data, l)
+}
@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// Webhook subscribes an external URL to events of the given types. Payloads
+// are signed with Secret.
+type Webhook struct {
+	ID         uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	Name       string     `gorm:"size:100;not null"`
+	URL        string     `gorm:"size:500;not null"`
+	EventTypes StringList `gorm:"type:jsonb;not null;default:'[]'"`
+	Secret     string     `gorm:"size:100;not null"`
+	IsActive   bool       `gorm:"default:true"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// WebhookDelivery is one event sent to one webhook, kept as the delivery
+// log. Pending deliveries are attempted at NextAttemptAt; the Last* fields
+// describe the latest attempt. A redelivery is a new delivery of the same
+// event, so EventID stays the same and receivers can deduplicate.
+type WebhookDelivery struct {
+	ID             uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	WebhookID      uuid.UUID  `gorm:"type:uuid;not null;index"`
+	EventID        uuid.UUID  `gorm:"type:uuid;not null;index"`
+	EventType      string     `gorm:"size:30;not null"`
+	Payload        string     `gorm:"type:text;not null"`
+	Status         string     `gorm:"size:20;not null;default:pending;index:idx_webhook_delivery_due,priority:1"`
+	Attempts       int        `gorm:"not null;default:0"`
+	NextAttemptAt  *time.Time `gorm:"index:idx_webhook_delivery_due,priority:2"`
+	LastStatusCode int
+	LastError      string `gorm:"size:500"`
+	LastResponse   string `gorm:"size:500"`
+	LastDurationMs int64
+	RedeliveryOf   *uuid.UUID `gorm:"type:uuid"`
+	DeliveredAt    *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+
+	Webhook Webhook `gorm:"foreignKey:WebhookID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
@@ -0,0 +1,148 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Publisher hands an event on, to in-process handlers or an external broker.
+// An event is only marked published once Publish returns nil, and may be
+// published again after a failure or a crash, so everything downstream has
+// to tolerate duplicates; Envelope.ID tells them apart.
+type Publisher interface {
+	Publish(ctx context.Context, envelope Envelope) error
+}
+
+// DeliveryError is returned by Publish when some subscribers failed to
+// handle an event. Delivered names those that handled it, so a retry under
+// WithDelivered reaches only the others.
+type DeliveryError struct {
+	Delivered []string
+	Err       error
+}
+
+func (e *DeliveryError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *DeliveryError) Unwrap() error {
+	return e.Err
+}
+
+type deliveredKey struct{}
+
+// WithDelivered returns a context under which Publish skips the named
+// subscribers, which handled the event on an earlier attempt.
+func WithDelivered(ctx context.Context, names []string) context.Context {
+	if len(names) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, deliveredKey{}, names)
+}
+
+func delivered(ctx context.Context) map[string]bool {
+	names, _ := ctx.Value(deliveredKey{}).([]string)
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	return set
+}
+
+type Handler func(ctx context.Context, envelope Envelope) error
+
+type subscription struct {
+	name    string
+	types   map[string]bool
+	handler Handler
+}
+
+// Bus publishes events to in-process subscribers, synchronously and in
+// subscription order.
+type Bus struct {
+	Log           *logrus.Logger
+	mu            sync.RWMutex
+	subscriptions []subscription
+}
+
+func NewBus(log *logrus.Logger) *Bus {
+	return &Bus{
+		Log: log,
+	}
+}
+
+// Subscribe registers handler for the given event types, or for all of them
+// when none are given. Name identifies the subscriber in logs and in
+// DeliveryError, so it has to be unique.
+func (b *Bus) Subscribe(name string, handler Handler, types ...string) {
+	var set map[string]bool
+	if len(types) > 0 {
+		set = make(map[string]bool, len(types))
+		for _, t := range types {
+			set[t] = true
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscriptions = append(b.subscriptions, subscription{name: name, types: set, handler: handler})
+}
+
+// Publish calls every matching handler not delivered to yet, even after one
+// fails. When any fails it returns a DeliveryError with their errors joined.
+func (b *Bus) Publish(ctx context.Context, envelope Envelope) error {
+	b.mu.RLock()
+	subscriptions := b.subscriptions
+	b.mu.RUnlock()
+
+	skip := delivered(ctx)
+	var names []string
+	var errs []error
+	for _, s := range subscriptions {
+		if s.types != nil && !s.types[envelope.Type] {
+			continue
+		}
+		if skip[s.name] {
+			names = append(names, s.name)
+			continue
+		}
+		if err := s.handler(ctx, envelope); err != nil {
+			b.Log.Warnf("Failed to handle %s event %s in %s : %+v", envelope.Type, envelope.ID, s.name, err)
+			errs = append(errs, err)
+			continue
+		}
+		names = append(names, s.name)
+	}
+	if len(errs) > 0 {
+		return &DeliveryError{Delivered: names, Err: errors.Join(errs...)}
+	}
+	return nil
+}
+
+// Fanout publishes to several publishers, such as the bus and a broker.
+// The subscribers of a DeliveryError are merged, so their names have to be
+// unique across the publishers.
+type Fanout []Publisher
+
+func (f Fanout) Publish(ctx context.Context, envelope Envelope) error {
+	var names []string
+	var errs []error
+	for _, publisher := range f {
+		err := publisher.Publish(ctx, envelope)
+		if err == nil {
+			continue
+		}
+		var deliveryError *DeliveryError
+		if errors.As(err, &deliveryError) {
+			names = append(names, deliveryError.Delivered...)
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return &DeliveryError{Delivered: names, Err: errors.Join(errs...)}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestBusRetriesOnlyFailedSubscribers(t *testing.T) {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	bus := NewBus(log)
+	calls := map[string]int{}
+	failing := true
+	bus.Subscribe("stream", func(ctx context.Context, envelope Envelope) error {
+		calls["stream"]++
+		return nil
+	})
+	bus.Subscribe("webhook", func(ctx context.Context, envelope Envelope) error {
+		calls["webhook"]++
+		if failing {
+			return errors.New("webhook down")
+		}
+		return nil
+	})
+	bus.Subscribe("alert", func(ctx context.Context, envelope Envelope) error {
+		calls["alert"]++
+		return nil
+	}, "anomaly")
+
+	envelope := Envelope{Type: "reading"}
+	err := Fanout{bus}.Publish(context.Background(), envelope)
+	var deliveryError *DeliveryError
+	if !errors.As(err, &deliveryError) {
+		t.Fatalf("err = %v, want a DeliveryError", err)
+	}
+	if !reflect.DeepEqual(deliveryError.Delivered, []string{"stream"}) {
+		t.Fatalf("delivered %v, want [stream]", deliveryError.Delivered)
+	}
+
+	// the retry skips stream, and still fails in webhook
+	err = bus.Publish(WithDelivered(context.Background(), deliveryError.Delivered), envelope)
+	if !errors.As(err, &deliveryError) || !reflect.DeepEqual(deliveryError.Delivered, []string{"stream"}) {
+		t.Fatalf("retry err = %v", err)
+	}
+
+	failing = false
+	if err := bus.Publish(WithDelivered(context.Background(), deliveryError.Delivered), envelope); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]int{"stream": 1, "webhook": 3}; !reflect.DeepEqual(calls, want) {
+		t.Fatalf("calls %v, want %v", calls, want)
+	}
+}
@@ -0,0 +1,123 @@
+package event
+
+import "mertani_test/internal/model"
+
+// Type names are shared with the live stream and webhooks, which carry
+// these events to clients under the same names.
+const (
+	TypeDeviceCreated     = "device_created"
+	TypeDeviceUpdated     = "device_updated"
+	TypeDeviceDeleted     = "device_deleted"
+	TypeDeviceStatus      = "device_status"
+	TypeSensorCreated     = "sensor_created"
+	TypeSensorUpdated     = "sensor_updated"
+	TypeSensorDeleted     = "sensor_deleted"
+	TypeSensorActivated   = "sensor_activated"
+	TypeSensorDeactivated = "sensor_deactivated"
+	TypeAlert             = "alert"
+	TypeAlertEscalated    = "alert_escalated"
+	TypeAnomaly           = "anomaly"
+)
+
+// Types lists every domain event type.
+var Types = []string{
+	TypeDeviceCreated, TypeDeviceUpdated, TypeDeviceDeleted, TypeDeviceStatus,
+	TypeSensorCreated, TypeSensorUpdated, TypeSensorDeleted, TypeSensorActivated, TypeSensorDeactivated,
+	TypeAlert, TypeAlertEscalated, TypeAnomaly,
+}
+
+type DeviceCreated struct{ model.DeviceResponse }
+
+func (e *DeviceCreated) EventType() string { return TypeDeviceCreated }
+func (e *DeviceCreated) Subject() Subject  { return deviceSubject(&e.DeviceResponse) }
+
+type DeviceUpdated struct{ model.DeviceResponse }
+
+func (e *DeviceUpdated) EventType() string { return TypeDeviceUpdated }
+func (e *DeviceUpdated) Subject() Subject  { return deviceSubject(&e.DeviceResponse) }
+
+type DeviceDeleted struct{ model.DeviceResponse }
+
+func (e *DeviceDeleted) EventType() string { return TypeDeviceDeleted }
+func (e *DeviceDeleted) Subject() Subject  { return deviceSubject(&e.DeviceResponse) }
+
+// DeviceStatusChanged is recorded only when the status actually changes.
+type DeviceStatusChanged struct {
+	model.DeviceStatusEvent
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+func (e *DeviceStatusChanged) EventType() string { return TypeDeviceStatus }
+func (e *DeviceStatusChanged) Subject() Subject {
+	return Subject{DeviceID: e.DeviceID, Labels: e.Labels}
+}
+
+type SensorCreated struct{ model.SensorResponse }
+
+func (e *SensorCreated) EventType() string { return TypeSensorCreated }
+func (e *SensorCreated) Subject() Subject  { return sensorSubject(&e.SensorResponse) }
+
+type SensorUpdated struct{ model.SensorResponse }
+
+func (e *SensorUpdated) EventType() string { return TypeSensorUpdated }
+func (e *SensorUpdated) Subject() Subject  { return sensorSubject(&e.SensorResponse) }
+
+type SensorDeleted struct{ model.SensorResponse }
+
+func (e *SensorDeleted) EventType() string { return TypeSensorDeleted }
+func (e *SensorDeleted) Subject() Subject  { return sensorSubject(&e.SensorResponse) }
+
+// SensorState is the payload of sensor activation changes, which are
+// recorded next to sensor_updated when is_active flips.
+type SensorState struct {
+	SensorID string            `json:"sensor_id"`
+	DeviceID string            `json:"device_id"`
+	Name     string            `json:"name"`
+	Labels   map[string]string `json:"labels,omitempty"`
+}
+
+func (s *SensorState) Subject() Subject {
+	return Subject{DeviceID: s.DeviceID, SensorID: s.SensorID, Labels: s.Labels}
+}
+
+type SensorActivated struct{ SensorState }
+
+func (e *SensorActivated) EventType() string { return TypeSensorActivated }
+
+type SensorDeactivated struct{ SensorState }
+
+func (e *SensorDeactivated) EventType() string { return TypeSensorDeactivated }
+
+// Alert is recorded when an alert fires and when it resolves; Status tells
+// which.
+type Alert struct{ model.AlertEvent }
+
+func (e *Alert) EventType() string { return TypeAlert }
+func (e *Alert) Subject() Subject {
+	return Subject{DeviceID: e.DeviceID, SensorID: e.SensorID, Labels: e.Labels}
+}
+
+// AlertEscalated is recorded when a step of an escalation policy is due
+// for an alert nobody acknowledged.
+type AlertEscalated struct{ model.AlertEscalationEvent }
+
+func (e *AlertEscalated) EventType() string { return TypeAlertEscalated }
+func (e *AlertEscalated) Subject() Subject {
+	return Subject{DeviceID: e.DeviceID, SensorID: e.SensorID, Labels: e.Labels}
+}
+
+// Anomaly is recorded when a detector finds a reading unusual.
+type Anomaly struct{ model.AnomalyEvent }
+
+func (e *Anomaly) EventType() string { return TypeAnomaly }
+func (e *Anomaly) Subject() Subject {
+	return Subject{DeviceID: e.DeviceID, SensorID: e.SensorID, Labels: e.Labels}
+}
+
+func deviceSubject(device *model.DeviceResponse) Subject {
+	return Subject{DeviceID: device.ID, Labels: device.Labels}
+}
+
+func sensorSubject(sensor *model.SensorResponse) Subject {
+	return Subject{DeviceID: sensor.DeviceID, SensorID: sensor.ID, Labels: sensor.Labels}
+}
@@ -0,0 +1,58 @@
+package event
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event is a typed domain event. Its type names it on the wire; its
+// subject tells which device or sensor it is about so subscribers can
+// filter without decoding the payload.
+type Event interface {
+	EventType() string
+	Subject() Subject
+}
+
+type Subject struct {
+	DeviceID string
+	SensorID string
+	Labels   map[string]string
+}
+
+// Envelope is an event as stored in the outbox and handed to publishers:
+// the JSON payload of the typed event plus its metadata. ID identifies the
+// event across redeliveries, so consumers can deduplicate.
+type Envelope struct {
+	ID         uuid.UUID         `json:"id"`
+	Type       string            `json:"type"`
+	DeviceID   string            `json:"device_id,omitempty"`
+	SensorID   string            `json:"sensor_id,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	OccurredAt time.Time         `json:"occurred_at"`
+	Payload    json.RawMessage   `json:"payload"`
+}
+
+func NewEnvelope(event Event, occurredAt time.Time) (Envelope, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	subject := event.Subject()
+	return Envelope{
+		ID:         uuid.New(),
+		Type:       event.EventType(),
+		DeviceID:   subject.DeviceID,
+		SensorID:   subject.SensorID,
+		Labels:     subject.Labels,
+		OccurredAt: occurredAt,
+		Payload:    payload,
+	}, nil
+}
+
+// Decode unmarshals the payload into the typed event it was built from.
+func (e *Envelope) Decode(event Event) error {
+	return json.Unmarshal(e.Payload, event)
+}
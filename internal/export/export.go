@@ -0,0 +1,139 @@
+// Package export writes tabular data row by row in the formats offered for
+// bulk downloads. Writers never hold more than one row group in memory, so
+// exports can stream straight from a database cursor to the client.
+package export
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	FormatCSV     = "csv"
+	FormatNDJSON  = "ndjson"
+	FormatParquet = "parquet"
+)
+
+// Formats lists the supported export formats.
+var Formats = []string{FormatCSV, FormatNDJSON, FormatParquet}
+
+type ColumnType int
+
+const (
+	String ColumnType = iota
+	Double
+	Int64
+	Timestamp
+)
+
+// Column describes one field of every row. Optional columns accept nil.
+type Column struct {
+	Name     string
+	Type     ColumnType
+	Optional bool
+}
+
+// Writer receives rows whose values line up with the columns it was created
+// with: string for String, float64 for Double, int64 for Int64 and
+// time.Time for Timestamp. Optional columns also take nil or a nil pointer
+// of those types. Close finishes the output but leaves the underlying
+// io.Writer open.
+type Writer interface {
+	WriteRow(values ...any) error
+	Close() error
+}
+
+// NewWriter returns a writer for the given format.
+func NewWriter(format string, w io.Writer, columns []Column) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, columns)
+	case FormatNDJSON:
+		return newNDJSONWriter(w, columns), nil
+	case FormatParquet:
+		return newParquetWriter(w, columns), nil
+	}
+	return nil, fmt.Errorf("unknown export format %q", format)
+}
+
+// ValidFormat reports whether format is one of Formats.
+func ValidFormat(format string) bool {
+	for _, candidate := range Formats {
+		if candidate == format {
+			return true
+		}
+	}
+	return false
+}
+
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatParquet:
+		return "application/vnd.apache.parquet"
+	}
+	return "application/octet-stream"
+}
+
+// normalize dereferences pointer values and checks them against the column
+// type. It returns nil for null values.
+func normalize(column Column, value any) (any, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, checkNull(column)
+	case *string:
+		if v == nil {
+			return nil, checkNull(column)
+		}
+		value = *v
+	case *float64:
+		if v == nil {
+			return nil, checkNull(column)
+		}
+		value = *v
+	case *int64:
+		if v == nil {
+			return nil, checkNull(column)
+		}
+		value = *v
+	case *time.Time:
+		if v == nil {
+			return nil, checkNull(column)
+		}
+		value = *v
+	}
+
+	var ok bool
+	switch column.Type {
+	case String:
+		_, ok = value.(string)
+	case Double:
+		_, ok = value.(float64)
+	case Int64:
+		_, ok = value.(int64)
+	case Timestamp:
+		_, ok = value.(time.Time)
+	}
+	if !ok {
+		return nil, fmt.Errorf("column %s: unexpected value of type %T", column.Name, value)
+	}
+	return value, nil
+}
+
+func checkNull(column Column) error {
+	if !column.Optional {
+		return fmt.Errorf("column %s: value is required", column.Name)
+	}
+	return nil
+}
+
+func checkArity(columns []Column, values []any) error {
+	if len(values) != len(columns) {
+		return fmt.Errorf("expected %d values, got %d", len(columns), len(values))
+	}
+	return nil
+}
@@ -0,0 +1,304 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"time"
+)
+
+// parquetRowGroupSize is the number of rows buffered before a row group is
+// written out.
+const parquetRowGroupSize = 64 * 1024
+
+var parquetMagic = []byte("PAR1")
+
+// Parquet physical types, repetitions, converted types, encodings and page
+// types as numbered in parquet.thrift.
+const (
+	parquetInt64     = 2
+	parquetDouble    = 5
+	parquetByteArray = 6
+
+	parquetRequired = 0
+	parquetOptional = 1
+
+	parquetUTF8            = 0
+	parquetTimestampMillis = 9
+
+	parquetPlain = 0
+	parquetRLE   = 3
+
+	parquetDataPage = 0
+)
+
+// parquetWriter writes an uncompressed Parquet file with one PLAIN encoded
+// data page per column chunk and a row group every parquetRowGroupSize
+// rows. The footer carries the schema and chunk offsets, so nothing but
+// the current row group is kept in memory.
+type parquetWriter struct {
+	columns   []Column
+	w         io.Writer
+	offset    int64
+	started   bool
+	chunks    []parquetChunk
+	rows      int64
+	totalRows int64
+	rowGroups []parquetRowGroup
+}
+
+// parquetChunk buffers the current row group of one column.
+type parquetChunk struct {
+	values  bytes.Buffer
+	defined []bool
+}
+
+type parquetRowGroup struct {
+	rows    int64
+	size    int64
+	columns []parquetColumnMeta
+}
+
+type parquetColumnMeta struct {
+	offset int64
+	values int64
+	size   int64
+}
+
+func newParquetWriter(w io.Writer, columns []Column) *parquetWriter {
+	return &parquetWriter{
+		columns: columns,
+		w:       w,
+		chunks:  make([]parquetChunk, len(columns)),
+	}
+}
+
+func (w *parquetWriter) WriteRow(values ...any) error {
+	if err := checkArity(w.columns, values); err != nil {
+		return err
+	}
+
+	// check the whole row first so a bad value leaves the chunks aligned
+	normalized := make([]any, len(values))
+	for i, column := range w.columns {
+		value, err := normalize(column, values[i])
+		if err != nil {
+			return err
+		}
+		normalized[i] = value
+	}
+
+	for i, value := range normalized {
+		chunk := &w.chunks[i]
+		chunk.defined = append(chunk.defined, value != nil)
+
+		var scratch [8]byte
+		switch v := value.(type) {
+		case string:
+			binary.LittleEndian.PutUint32(scratch[:4], uint32(len(v)))
+			chunk.values.Write(scratch[:4])
+			chunk.values.WriteString(v)
+		case float64:
+			binary.LittleEndian.PutUint64(scratch[:], math.Float64bits(v))
+			chunk.values.Write(scratch[:])
+		case int64:
+			binary.LittleEndian.PutUint64(scratch[:], uint64(v))
+			chunk.values.Write(scratch[:])
+		case time.Time:
+			binary.LittleEndian.PutUint64(scratch[:], uint64(v.UnixMilli()))
+			chunk.values.Write(scratch[:])
+		}
+	}
+
+	w.rows++
+	if w.rows == parquetRowGroupSize {
+		return w.flushRowGroup()
+	}
+	return nil
+}
+
+func (w *parquetWriter) Close() error {
+	if err := w.flushRowGroup(); err != nil {
+		return err
+	}
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	footer := w.footer()
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(footer)))
+	for _, part := range [][]byte{footer, length[:], parquetMagic} {
+		if err := w.write(part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *parquetWriter) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+	return w.write(parquetMagic)
+}
+
+func (w *parquetWriter) write(p []byte) error {
+	n, err := w.w.Write(p)
+	w.offset += int64(n)
+	return err
+}
+
+func (w *parquetWriter) flushRowGroup() error {
+	if w.rows == 0 {
+		return nil
+	}
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	group := parquetRowGroup{rows: w.rows, columns: make([]parquetColumnMeta, len(w.columns))}
+	for i, column := range w.columns {
+		chunk := &w.chunks[i]
+
+		var page bytes.Buffer
+		if column.Optional {
+			levels := encodeDefinitionLevels(chunk.defined)
+			var length [4]byte
+			binary.LittleEndian.PutUint32(length[:], uint32(len(levels)))
+			page.Write(length[:])
+			page.Write(levels)
+		}
+		page.Write(chunk.values.Bytes())
+
+		header := newThriftWriter()
+		header.i32Field(1, parquetDataPage)
+		header.i32Field(2, int32(page.Len()))
+		header.i32Field(3, int32(page.Len()))
+		header.structField(5)
+		header.i32Field(1, int32(w.rows))
+		header.i32Field(2, parquetPlain)
+		header.i32Field(3, parquetRLE)
+		header.i32Field(4, parquetRLE)
+		header.endStruct()
+		header.endStruct()
+
+		meta := parquetColumnMeta{
+			offset: w.offset,
+			values: w.rows,
+			size:   int64(header.buf.Len() + page.Len()),
+		}
+		if err := w.write(header.buf.Bytes()); err != nil {
+			return err
+		}
+		if err := w.write(page.Bytes()); err != nil {
+			return err
+		}
+
+		group.columns[i] = meta
+		group.size += meta.size
+		chunk.values.Reset()
+		chunk.defined = chunk.defined[:0]
+	}
+
+	w.rowGroups = append(w.rowGroups, group)
+	w.totalRows += w.rows
+	w.rows = 0
+	return nil
+}
+
+// footer encodes the FileMetaData struct.
+func (w *parquetWriter) footer() []byte {
+	t := newThriftWriter()
+	t.i32Field(1, 1)
+
+	t.listField(2, thriftStruct, len(w.columns)+1)
+	t.beginStruct()
+	t.binaryField(4, "schema")
+	t.i32Field(5, int32(len(w.columns)))
+	t.endStruct()
+	for _, column := range w.columns {
+		t.beginStruct()
+		t.i32Field(1, parquetPhysicalType(column.Type))
+		repetition := int32(parquetRequired)
+		if column.Optional {
+			repetition = parquetOptional
+		}
+		t.i32Field(3, repetition)
+		t.binaryField(4, column.Name)
+		switch column.Type {
+		case String:
+			t.i32Field(6, parquetUTF8)
+		case Timestamp:
+			t.i32Field(6, parquetTimestampMillis)
+		}
+		t.endStruct()
+	}
+
+	t.i64Field(3, w.totalRows)
+
+	t.listField(4, thriftStruct, len(w.rowGroups))
+	for _, group := range w.rowGroups {
+		t.beginStruct()
+		t.listField(1, thriftStruct, len(group.columns))
+		for i, meta := range group.columns {
+			column := w.columns[i]
+			t.beginStruct()
+			t.i64Field(2, meta.offset)
+			t.structField(3)
+			t.i32Field(1, parquetPhysicalType(column.Type))
+			t.listField(2, thriftI32, 2)
+			t.i32(parquetPlain)
+			t.i32(parquetRLE)
+			t.listField(3, thriftBinary, 1)
+			t.binary(column.Name)
+			t.i32Field(4, 0) // uncompressed
+			t.i64Field(5, meta.values)
+			t.i64Field(6, meta.size)
+			t.i64Field(7, meta.size)
+			t.i64Field(9, meta.offset)
+			t.endStruct()
+			t.endStruct()
+		}
+		t.i64Field(2, group.size)
+		t.i64Field(3, group.rows)
+		t.endStruct()
+	}
+
+	t.binaryField(6, "mertani_test")
+	t.endStruct()
+	return t.buf.Bytes()
+}
+
+func parquetPhysicalType(columnType ColumnType) int32 {
+	switch columnType {
+	case Double:
+		return parquetDouble
+	case Int64, Timestamp:
+		return parquetInt64
+	}
+	return parquetByteArray
+}
+
+// encodeDefinitionLevels writes 0/1 definition levels with the RLE side of
+// the RLE/bit-packing hybrid at bit width 1: a varint run header of
+// count<<1 followed by the value in one byte.
+func encodeDefinitionLevels(defined []bool) []byte {
+	var buf []byte
+	for start := 0; start < len(defined); {
+		end := start + 1
+		for end < len(defined) && defined[end] == defined[start] {
+			end++
+		}
+		buf = binary.AppendUvarint(buf, uint64(end-start)<<1)
+		if defined[start] {
+			buf = append(buf, 1)
+		} else {
+			buf = append(buf, 0)
+		}
+		start = end
+	}
+	return buf
+}
@@ -0,0 +1,394 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// thriftReader decodes the Thrift compact protocol into maps of field id to
+// value: int64 for integers, string for binary, []any for lists and
+// map[int16]any for structs.
+type thriftReader struct {
+	t   *testing.T
+	buf []byte
+	pos int
+}
+
+func (r *thriftReader) byte() byte {
+	if r.pos >= len(r.buf) {
+		r.t.Fatalf("thrift: read past the end at %d", r.pos)
+	}
+	b := r.buf[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.buf[r.pos:])
+	if n <= 0 {
+		r.t.Fatalf("thrift: bad varint at %d", r.pos)
+	}
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) varint() int64 {
+	u := r.uvarint()
+	return int64(u>>1) ^ -int64(u&1)
+}
+
+func (r *thriftReader) value(kind byte) any {
+	switch kind {
+	case thriftI32, thriftI64:
+		return r.varint()
+	case thriftBinary:
+		size := int(r.uvarint())
+		value := string(r.buf[r.pos : r.pos+size])
+		r.pos += size
+		return value
+	case thriftList:
+		header := r.byte()
+		size, element := int(header>>4), header&0x0f
+		if size == 15 {
+			size = int(r.uvarint())
+		}
+		values := make([]any, size)
+		for i := range values {
+			values[i] = r.value(element)
+		}
+		return values
+	case thriftStruct:
+		return r.readStruct()
+	}
+	r.t.Fatalf("thrift: unexpected type %d at %d", kind, r.pos)
+	return nil
+}
+
+func (r *thriftReader) readStruct() map[int16]any {
+	fields := map[int16]any{}
+	var last int16
+	for {
+		header := r.byte()
+		if header == 0 {
+			return fields
+		}
+		kind := header & 0x0f
+		if delta := int16(header >> 4); delta != 0 {
+			last += delta
+		} else {
+			last = int16(r.varint())
+		}
+		fields[last] = r.value(kind)
+	}
+}
+
+func (r *thriftReader) structAt(pos int) (map[int16]any, int) {
+	r.pos = pos
+	return r.readStruct(), r.pos
+}
+
+// parquetColumn is one column chunk read back: the value of every row, nil
+// for nulls.
+type parquetColumn []any
+
+// readParquet checks the framing and metadata of a Parquet file written by
+// parquetWriter and returns the footer and every column, one row group
+// after the other.
+func readParquet(t *testing.T, file []byte, columns []Column) (map[int16]any, []parquetColumn) {
+	t.Helper()
+
+	if !bytes.HasPrefix(file, parquetMagic) || !bytes.HasSuffix(file, parquetMagic) {
+		t.Fatalf("missing magic: % x", file)
+	}
+	footerLength := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	footerStart := len(file) - 8 - footerLength
+	reader := &thriftReader{t: t, buf: file}
+	footer, end := reader.structAt(footerStart)
+	if end != len(file)-8 {
+		t.Fatalf("footer ends at %d, want %d", end, len(file)-8)
+	}
+
+	schema := footer[2].([]any)
+	root := schema[0].(map[int16]any)
+	if root[4] != "schema" || root[5] != int64(len(columns)) {
+		t.Fatalf("schema root = %v", root)
+	}
+	for i, column := range columns {
+		element := schema[i+1].(map[int16]any)
+		repetition := int64(parquetRequired)
+		if column.Optional {
+			repetition = parquetOptional
+		}
+		if element[1] != int64(parquetPhysicalType(column.Type)) || element[3] != repetition || element[4] != column.Name {
+			t.Fatalf("schema of %s = %v", column.Name, element)
+		}
+	}
+
+	values := make([]parquetColumn, len(columns))
+	var rows int64
+	for _, group := range footer[4].([]any) {
+		group := group.(map[int16]any)
+		groupRows := group[3].(int64)
+		var groupSize int64
+
+		for i, chunk := range group[1].([]any) {
+			meta := chunk.(map[int16]any)[3].(map[int16]any)
+			offset := meta[9].(int64)
+			if chunk.(map[int16]any)[2] != offset || meta[5] != groupRows || meta[3].([]any)[0] != columns[i].Name {
+				t.Fatalf("column chunk %s = %v", columns[i].Name, chunk)
+			}
+
+			header, pageStart := reader.structAt(int(offset))
+			pageSize := int(header[3].(int64))
+			if header[1] != int64(parquetDataPage) || header[2] != int64(pageSize) {
+				t.Fatalf("page header = %v", header)
+			}
+			if chunkSize := int64(pageStart - int(offset) + pageSize); meta[6] != chunkSize || meta[7] != chunkSize {
+				t.Fatalf("chunk size = %v/%v, want %d", meta[6], meta[7], chunkSize)
+			}
+			groupSize += meta[7].(int64)
+			if data := header[5].(map[int16]any); data[1] != groupRows || data[2] != int64(parquetPlain) {
+				t.Fatalf("data page header = %v", data)
+			}
+
+			page := file[pageStart : pageStart+pageSize]
+			values[i] = append(values[i], decodePage(t, page, columns[i], int(groupRows))...)
+		}
+
+		if group[2] != groupSize {
+			t.Fatalf("row group size = %v, want %d", group[2], groupSize)
+		}
+		rows += groupRows
+	}
+	if footer[3] != rows {
+		t.Fatalf("num_rows = %v, row groups hold %d", footer[3], rows)
+	}
+	return footer, values
+}
+
+// decodePage reads the definition levels of an optional column, RLE runs
+// only, and the PLAIN values of the defined rows.
+func decodePage(t *testing.T, page []byte, column Column, rows int) parquetColumn {
+	t.Helper()
+
+	defined := make([]bool, rows)
+	for i := range defined {
+		defined[i] = true
+	}
+	if column.Optional {
+		length := int(binary.LittleEndian.Uint32(page))
+		levels := page[4 : 4+length]
+		page = page[4+length:]
+
+		row := 0
+		for len(levels) > 0 {
+			header, n := binary.Uvarint(levels)
+			if header&1 != 0 {
+				t.Fatalf("%s: bit-packed run", column.Name)
+			}
+			count, level := int(header>>1), levels[n]
+			for i := 0; i < count; i++ {
+				defined[row+i] = level == 1
+			}
+			row += count
+			levels = levels[n+1:]
+		}
+		if row != rows {
+			t.Fatalf("%s: %d definition levels for %d rows", column.Name, row, rows)
+		}
+	}
+
+	values := make(parquetColumn, rows)
+	for i := range values {
+		if !defined[i] {
+			continue
+		}
+		switch column.Type {
+		case String:
+			size := int(binary.LittleEndian.Uint32(page))
+			values[i] = string(page[4 : 4+size])
+			page = page[4+size:]
+		case Double:
+			values[i] = math.Float64frombits(binary.LittleEndian.Uint64(page))
+			page = page[8:]
+		case Int64:
+			values[i] = int64(binary.LittleEndian.Uint64(page))
+			page = page[8:]
+		case Timestamp:
+			values[i] = time.UnixMilli(int64(binary.LittleEndian.Uint64(page))).UTC()
+			page = page[8:]
+		}
+	}
+	if len(page) != 0 {
+		t.Fatalf("%s: %d bytes left after the values", column.Name, len(page))
+	}
+	return values
+}
+
+var parquetTestColumns = []Column{
+	{Name: "recorded_at", Type: Timestamp},
+	{Name: "sensor_id", Type: String},
+	{Name: "value", Type: Double},
+	{Name: "raw_value", Type: Double, Optional: true},
+	{Name: "quality", Type: Int64},
+	{Name: "note", Type: String, Optional: true},
+}
+
+func parquetTestRow(i int) []any {
+	// raw_value is null on every third row and note on every other one, so
+	// runs of both levels cross the row group boundary
+	var raw *float64
+	if i%3 != 0 {
+		value := float64(i) / 2
+		raw = &value
+	}
+	var note any
+	if i%2 == 0 {
+		note = fmt.Sprintf("note %d", i)
+	}
+	recordedAt := time.Date(2026, time.March, 10, 0, 0, 0, 0, time.UTC).Add(time.Duration(i) * time.Second)
+	return []any{recordedAt, fmt.Sprintf("sensor-%d", i%7), float64(i) * 1.5, raw, int64(i % 4), note}
+}
+
+func TestParquetRoundTrip(t *testing.T) {
+	for _, rows := range []int{1, 3, parquetRowGroupSize, parquetRowGroupSize + 5} {
+		var buf bytes.Buffer
+		w, err := NewWriter(FormatParquet, &buf, parquetTestColumns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < rows; i++ {
+			if err := w.WriteRow(parquetTestRow(i)...); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		footer, columns := readParquet(t, buf.Bytes(), parquetTestColumns)
+		wantGroups := (rows + parquetRowGroupSize - 1) / parquetRowGroupSize
+		if groups := len(footer[4].([]any)); groups != wantGroups {
+			t.Fatalf("%d rows: %d row groups, want %d", rows, groups, wantGroups)
+		}
+
+		for i := 0; i < rows; i++ {
+			want := parquetTestRow(i)
+			if raw := want[3].(*float64); raw != nil {
+				want[3] = *raw
+			} else {
+				want[3] = nil
+			}
+			for c := range parquetTestColumns {
+				if !reflect.DeepEqual(columns[c][i], want[c]) {
+					t.Fatalf("%d rows: row %d %s = %v, want %v", rows, i, parquetTestColumns[c].Name, columns[c][i], want[c])
+				}
+			}
+		}
+	}
+}
+
+func TestParquetEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	w := newParquetWriter(&buf, parquetTestColumns)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	footer, columns := readParquet(t, buf.Bytes(), parquetTestColumns)
+	if footer[3] != int64(0) || len(footer[4].([]any)) != 0 {
+		t.Fatalf("footer = %v", footer)
+	}
+	for i, column := range columns {
+		if len(column) != 0 {
+			t.Fatalf("column %d has %d values", i, len(column))
+		}
+	}
+}
+
+func TestParquetRejectedRowKeepsColumnsAligned(t *testing.T) {
+	var buf bytes.Buffer
+	w := newParquetWriter(&buf, parquetTestColumns)
+
+	w.WriteRow(parquetTestRow(0)...)
+	bad := parquetTestRow(1)
+	bad[4] = "not a number"
+	if err := w.WriteRow(bad...); err == nil {
+		t.Fatal("accepted a string for an int64 column")
+	}
+	bad[4], bad[2] = int64(1), nil
+	if err := w.WriteRow(bad...); err == nil {
+		t.Fatal("accepted null for a required column")
+	}
+	w.WriteRow(parquetTestRow(2)...)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	_, columns := readParquet(t, buf.Bytes(), parquetTestColumns)
+	if len(columns[0]) != 2 || columns[2][1] != float64(3) {
+		t.Fatalf("columns = %v", columns)
+	}
+}
+
+func TestThriftWriter(t *testing.T) {
+	w := newThriftWriter()
+	w.i32Field(1, -5)
+	w.i64Field(2, math.MinInt64)
+	// a jump of more than 15 ids takes the long field header
+	w.binaryField(40, "long jump")
+	w.listField(41, thriftI32, 20)
+	for i := int32(0); i < 20; i++ {
+		w.i32(i * 1000)
+	}
+	w.structField(42)
+	w.i32Field(3, 7)
+	w.endStruct()
+	w.listField(43, thriftStruct, 2)
+	for i := 0; i < 2; i++ {
+		w.beginStruct()
+		w.i64Field(1, int64(i))
+		w.endStruct()
+	}
+	w.endStruct()
+
+	reader := &thriftReader{t: t, buf: w.buf.Bytes()}
+	got, end := reader.structAt(0)
+	if end != w.buf.Len() {
+		t.Fatalf("read %d of %d bytes", end, w.buf.Len())
+	}
+
+	list := make([]any, 20)
+	for i := range list {
+		list[i] = int64(i * 1000)
+	}
+	want := map[int16]any{
+		1:  int64(-5),
+		2:  int64(math.MinInt64),
+		40: "long jump",
+		41: list,
+		42: map[int16]any{3: int64(7)},
+		43: []any{map[int16]any{1: int64(0)}, map[int16]any{1: int64(1)}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestEncodeDefinitionLevels(t *testing.T) {
+	defined := []bool{true, true, true, false, true}
+	if got := encodeDefinitionLevels(defined); !bytes.Equal(got, []byte{3 << 1, 1, 1 << 1, 0, 1 << 1, 1}) {
+		t.Fatalf("levels = % x", got)
+	}
+
+	// runs of 64 or more take a multi-byte varint header
+	long := make([]bool, 300)
+	if got := encodeDefinitionLevels(long); !bytes.Equal(got, []byte{0xd8, 0x04, 0}) {
+		t.Fatalf("levels = % x", got)
+	}
+}
@@ -0,0 +1,130 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math"
+	"strconv"
+	"time"
+)
+
+// csvWriter writes a header row followed by one record per row. Nulls are
+// empty cells and timestamps are RFC 3339 in UTC.
+type csvWriter struct {
+	columns []Column
+	writer  *csv.Writer
+	record  []string
+}
+
+func newCSVWriter(w io.Writer, columns []Column) (*csvWriter, error) {
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.Name
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return nil, err
+	}
+	return &csvWriter{columns: columns, writer: writer, record: make([]string, len(columns))}, nil
+}
+
+func (w *csvWriter) WriteRow(values ...any) error {
+	if err := checkArity(w.columns, values); err != nil {
+		return err
+	}
+
+	for i, column := range w.columns {
+		value, err := normalize(column, values[i])
+		if err != nil {
+			return err
+		}
+		w.record[i] = formatText(value)
+	}
+	return w.writer.Write(w.record)
+}
+
+func (w *csvWriter) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+func formatText(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	}
+	return ""
+}
+
+// ndjsonWriter writes one JSON object per line with keys in column order.
+// Nulls, and doubles JSON cannot represent, are written as null.
+type ndjsonWriter struct {
+	columns []Column
+	w       io.Writer
+	keys    [][]byte
+	buf     bytes.Buffer
+}
+
+func newNDJSONWriter(w io.Writer, columns []Column) *ndjsonWriter {
+	keys := make([][]byte, len(columns))
+	for i, column := range columns {
+		keys[i], _ = json.Marshal(column.Name)
+	}
+	return &ndjsonWriter{columns: columns, w: w, keys: keys}
+}
+
+func (w *ndjsonWriter) WriteRow(values ...any) error {
+	if err := checkArity(w.columns, values); err != nil {
+		return err
+	}
+
+	w.buf.Reset()
+	w.buf.WriteByte('{')
+	for i, column := range w.columns {
+		value, err := normalize(column, values[i])
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			w.buf.WriteByte(',')
+		}
+		w.buf.Write(w.keys[i])
+		w.buf.WriteByte(':')
+
+		switch v := value.(type) {
+		case nil:
+			w.buf.WriteString("null")
+		case float64:
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				w.buf.WriteString("null")
+			} else {
+				w.buf.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+			}
+		case int64:
+			w.buf.WriteString(strconv.FormatInt(v, 10))
+		default:
+			encoded, err := json.Marshal(formatText(v))
+			if err != nil {
+				return err
+			}
+			w.buf.Write(encoded)
+		}
+	}
+	w.buf.WriteString("}\n")
+
+	_, err := w.w.Write(w.buf.Bytes())
+	return err
+}
+
+func (w *ndjsonWriter) Close() error {
+	return nil
+}
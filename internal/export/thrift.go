@@ -0,0 +1,100 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Thrift compact protocol type ids.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes the subset of the Thrift compact protocol needed for
+// Parquet metadata: structs with i32, i64, binary, list and struct fields.
+// Field ids must increase within a struct.
+type thriftWriter struct {
+	buf       bytes.Buffer
+	lastField []int16
+}
+
+func newThriftWriter() *thriftWriter {
+	return &thriftWriter{lastField: []int16{0}}
+}
+
+func (t *thriftWriter) fieldHeader(id int16, fieldType byte) {
+	last := &t.lastField[len(t.lastField)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | fieldType)
+	} else {
+		t.buf.WriteByte(fieldType)
+		t.varint(int64(id))
+	}
+	*last = id
+}
+
+func (t *thriftWriter) varint(v int64) {
+	// zigzag encoding
+	t.uvarint(uint64((v << 1) ^ (v >> 63)))
+}
+
+func (t *thriftWriter) uvarint(v uint64) {
+	var scratch [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(scratch[:], v)
+	t.buf.Write(scratch[:n])
+}
+
+func (t *thriftWriter) i32(v int32) {
+	t.varint(int64(v))
+}
+
+func (t *thriftWriter) binary(v string) {
+	t.uvarint(uint64(len(v)))
+	t.buf.WriteString(v)
+}
+
+func (t *thriftWriter) i32Field(id int16, v int32) {
+	t.fieldHeader(id, thriftI32)
+	t.i32(v)
+}
+
+func (t *thriftWriter) i64Field(id int16, v int64) {
+	t.fieldHeader(id, thriftI64)
+	t.varint(v)
+}
+
+func (t *thriftWriter) binaryField(id int16, v string) {
+	t.fieldHeader(id, thriftBinary)
+	t.binary(v)
+}
+
+// listField writes a list header; the caller writes size elements next.
+// Struct elements are written between beginStruct and endStruct.
+func (t *thriftWriter) listField(id int16, elementType byte, size int) {
+	t.fieldHeader(id, thriftList)
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | elementType)
+		return
+	}
+	t.buf.WriteByte(0xf0 | elementType)
+	t.uvarint(uint64(size))
+}
+
+// structField opens a nested struct field, closed by endStruct.
+func (t *thriftWriter) structField(id int16) {
+	t.fieldHeader(id, thriftStruct)
+	t.beginStruct()
+}
+
+func (t *thriftWriter) beginStruct() {
+	t.lastField = append(t.lastField, 0)
+}
+
+func (t *thriftWriter) endStruct() {
+	t.buf.WriteByte(0)
+	t.lastField = t.lastField[:len(t.lastField)-1]
+}
@@ -2,19 +2,105 @@ package migration
 
 import (
 	"mertani_test/internal/entity"
+	"mertani_test/internal/repository"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
 func Run(db *gorm.DB, log *logrus.Logger) {
+	backfillLatest := !db.Migrator().HasTable(&entity.SensorLatest{})
+
 	err := db.AutoMigrate(
+		&entity.Site{},
+		&entity.SensorType{},
 		&entity.Device{},
 		&entity.Sensor{},
+		&entity.SensorInput{},
+		&entity.ConfigSchema{},
+		&entity.DeviceConfig{},
+		&entity.Reading{},
+		&entity.SensorLatest{},
+		&entity.SensorCalibration{},
+		&entity.PayloadDecoder{},
+		&entity.ExportJob{},
+		&entity.Webhook{},
+		&entity.WebhookDelivery{},
+		&entity.OutboxEvent{},
+		&entity.NotificationChannel{},
+		&entity.NotificationRule{},
+		&entity.EscalationPolicy{},
+		&entity.Notification{},
+		&entity.Silence{},
+		&entity.Alert{},
+		&entity.AlertComment{},
+		&entity.AnomalyDetector{},
+		&entity.Anomaly{},
+		&entity.RetentionPolicy{},
+		&entity.ReadingRollup{},
 	)
 
 	if err != nil {
 		log.Fatalf("Migration failed: %v", err)
 	}
+
+	runReadingPartitions(db, log)
+	if backfillLatest {
+		runSensorLatest(db, log)
+	}
+	runPostGIS(db, log)
+
 	log.Info("Migration success ✅")
 }
+
+// runReadingPartitions converts the readings table to monthly range
+// partitions on Postgres; later months are added by the reading partition
+// job. Other backends keep readings in a single table.
+func runReadingPartitions(db *gorm.DB, log *logrus.Logger) {
+	if db.Dialector.Name() != "postgres" {
+		return
+	}
+
+	partitions := repository.NewReadingPartitionRepository(log)
+	partitioned, err := partitions.IsPartitioned(db)
+	if err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
+	if partitioned {
+		return
+	}
+
+	if err := partitions.Convert(db, time.Now()); err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
+	log.Info("Readings table partitioned by month")
+}
+
+// runSensorLatest fills the latest reading of every sensor from the stored
+// readings when the sensor_latest table is first created; ingest keeps it
+// up to date from then on.
+func runSensorLatest(db *gorm.DB, log *logrus.Logger) {
+	if err := repository.NewSensorLatestRepository(log).Backfill(db); err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
+}
+
+// runPostGIS adds a geography index for device coordinates when the PostGIS
+// extension is installed; without it spatial queries use the haversine path.
+func runPostGIS(db *gorm.DB, log *logrus.Logger) {
+	var installed bool
+	if err := db.Raw("SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'postgis')").Scan(&installed).Error; err != nil {
+		log.Warnf("Failed to detect PostGIS extension : %+v", err)
+		return
+	}
+	if !installed {
+		return
+	}
+
+	err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_device_geography ON devices
+		USING GIST ((ST_MakePoint(longitude, latitude)::geography))`).Error
+	if err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
+}
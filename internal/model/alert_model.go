@@ -0,0 +1,175 @@
+package model
+
+// AlertEvent is the payload of an alert event, raised when an alert fires
+// and again when it resolves. Alerts with the same fingerprint describe the
+// same condition, which notifications group by.
+type AlertEvent struct {
+	ID          string            `json:"id"`
+	Fingerprint string            `json:"fingerprint"`
+	Status      string            `json:"status"`
+	Severity    string            `json:"severity"`
+	Title       string            `json:"title"`
+	Message     string            `json:"message,omitempty"`
+	DeviceID    string            `json:"device_id,omitempty"`
+	DeviceName  string            `json:"device_name,omitempty"`
+	SensorID    string            `json:"sensor_id,omitempty"`
+	SensorName  string            `json:"sensor_name,omitempty"`
+	SensorType  string            `json:"sensor_type,omitempty"`
+	Value       *float64          `json:"value,omitempty"`
+	Unit        string            `json:"unit,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	StartsAt    string            `json:"starts_at"`
+	EndsAt      string            `json:"ends_at,omitempty"`
+}
+
+// AlertEscalationEvent is the payload of an alert_escalated event, raised
+// when an escalation policy step is due for an unacknowledged alert.
+type AlertEscalationEvent struct {
+	AlertEvent
+	PolicyID   string   `json:"policy_id"`
+	PolicyName string   `json:"policy_name"`
+	Level      int      `json:"level"`
+	ChannelIDs []string `json:"channel_ids"`
+}
+
+// RaiseAlertRequest fires an alert, or updates the firing one with the same
+// fingerprint. The fingerprint defaults to one derived from the title,
+// device and sensor. Labels add to those of the device and sensor.
+type RaiseAlertRequest struct {
+	Fingerprint string            `json:"fingerprint,omitempty" validate:"omitempty,max=200"`
+	Severity    string            `json:"severity" validate:"required,oneof=info warning critical"`
+	Title       string            `json:"title" validate:"required,max=200"`
+	Message     string            `json:"message,omitempty" validate:"omitempty,max=5000"`
+	DeviceID    string            `json:"device_id,omitempty" validate:"omitempty,uuid"`
+	SensorID    string            `json:"sensor_id,omitempty" validate:"omitempty,uuid"`
+	Value       *float64          `json:"value,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+}
+
+type AcknowledgeAlertRequest struct {
+	By      string `json:"by,omitempty" validate:"omitempty,max=100"`
+	Comment string `json:"comment,omitempty" validate:"omitempty,max=5000"`
+}
+
+type ResolveAlertRequest struct {
+	By      string `json:"by,omitempty" validate:"omitempty,max=100"`
+	Comment string `json:"comment,omitempty" validate:"omitempty,max=5000"`
+}
+
+type CreateAlertCommentRequest struct {
+	Author string `json:"author,omitempty" validate:"omitempty,max=100"`
+	Body   string `json:"body" validate:"required,max=5000"`
+}
+
+// AlertFilter narrows the alert list; empty fields do not filter.
+type AlertFilter struct {
+	Status       string
+	Severity     string
+	Acknowledged *bool
+	DeviceID     string
+	SensorID     string
+}
+
+type AlertCommentResponse struct {
+	ID        string `json:"id,omitempty"`
+	AlertID   string `json:"alert_id,omitempty"`
+	Author    string `json:"author,omitempty"`
+	Body      string `json:"body,omitempty"`
+	CreatedAt string `json:"created_at,omitempty"`
+}
+
+type AlertResponse struct {
+	ID                 string                 `json:"id,omitempty"`
+	Fingerprint        string                 `json:"fingerprint,omitempty"`
+	Status             string                 `json:"status,omitempty"`
+	Severity           string                 `json:"severity,omitempty"`
+	Title              string                 `json:"title,omitempty"`
+	Message            string                 `json:"message,omitempty"`
+	DeviceID           string                 `json:"device_id,omitempty"`
+	DeviceName         string                 `json:"device_name,omitempty"`
+	SensorID           string                 `json:"sensor_id,omitempty"`
+	SensorName         string                 `json:"sensor_name,omitempty"`
+	SensorType         string                 `json:"sensor_type,omitempty"`
+	Value              *float64               `json:"value,omitempty"`
+	Unit               string                 `json:"unit,omitempty"`
+	Labels             map[string]string      `json:"labels"`
+	StartsAt           string                 `json:"starts_at,omitempty"`
+	EndsAt             string                 `json:"ends_at,omitempty"`
+	AcknowledgedAt     string                 `json:"acknowledged_at,omitempty"`
+	AcknowledgedBy     string                 `json:"acknowledged_by,omitempty"`
+	ResolvedBy         string                 `json:"resolved_by,omitempty"`
+	SilenceID          string                 `json:"silence_id,omitempty"`
+	EscalationPolicyID string                 `json:"escalation_policy_id,omitempty"`
+	EscalationLevel    int                    `json:"escalation_level"`
+	NextEscalationAt   string                 `json:"next_escalation_at,omitempty"`
+	Comments           []AlertCommentResponse `json:"comments,omitempty"`
+	CreatedAt          string                 `json:"created_at,omitempty"`
+	UpdatedAt          string                 `json:"updated_at,omitempty"`
+}
+
+// CreateSilenceRequest needs a selector, a sensor type or both. It starts
+// now unless starts_at is given and ends at ends_at or after
+// duration_minutes.
+type CreateSilenceRequest struct {
+	Selector        string `json:"selector,omitempty" validate:"omitempty,max=500"`
+	SensorType      string `json:"sensor_type,omitempty" validate:"omitempty,max=50"`
+	StartsAt        string `json:"starts_at,omitempty" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	EndsAt          string `json:"ends_at,omitempty" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	DurationMinutes int    `json:"duration_minutes,omitempty" validate:"omitempty,min=1,max=525600"`
+	Comment         string `json:"comment,omitempty" validate:"omitempty,max=500"`
+	CreatedBy       string `json:"created_by,omitempty" validate:"omitempty,max=100"`
+}
+
+// SilenceFilter narrows the silence list by state: pending, active or
+// expired.
+type SilenceFilter struct {
+	State string
+}
+
+type SilenceResponse struct {
+	ID         string `json:"id,omitempty"`
+	Selector   string `json:"selector,omitempty"`
+	SensorType string `json:"sensor_type,omitempty"`
+	StartsAt   string `json:"starts_at,omitempty"`
+	EndsAt     string `json:"ends_at,omitempty"`
+	State      string `json:"state,omitempty"`
+	Comment    string `json:"comment,omitempty"`
+	CreatedBy  string `json:"created_by,omitempty"`
+	CreatedAt  string `json:"created_at,omitempty"`
+	UpdatedAt  string `json:"updated_at,omitempty"`
+}
+
+type EscalationStep struct {
+	AfterMinutes int      `json:"after_minutes" validate:"required,min=1,max=10080"`
+	ChannelIDs   []string `json:"channel_ids" validate:"required,min=1,dive,uuid"`
+}
+
+type CreateEscalationPolicyRequest struct {
+	Name        string           `json:"name" validate:"required,max=100"`
+	MinSeverity string           `json:"min_severity,omitempty" validate:"omitempty,oneof=info warning critical"`
+	Selector    string           `json:"selector,omitempty" validate:"omitempty,max=500"`
+	SensorType  string           `json:"sensor_type,omitempty" validate:"omitempty,max=50"`
+	Steps       []EscalationStep `json:"steps" validate:"required,min=1,max=10,dive"`
+	IsActive    *bool            `json:"is_active,omitempty"`
+}
+
+type UpdateEscalationPolicyRequest struct {
+	Name        *string          `json:"name,omitempty" validate:"omitempty,max=100"`
+	MinSeverity *string          `json:"min_severity,omitempty" validate:"omitempty,oneof=info warning critical"`
+	Selector    *string          `json:"selector,omitempty" validate:"omitempty,max=500"`
+	SensorType  *string          `json:"sensor_type,omitempty" validate:"omitempty,max=50"`
+	Steps       []EscalationStep `json:"steps,omitempty" validate:"omitempty,min=1,max=10,dive"`
+	IsActive    *bool            `json:"is_active,omitempty"`
+}
+
+type EscalationPolicyResponse struct {
+	ID          string           `json:"id,omitempty"`
+	Name        string           `json:"name,omitempty"`
+	MinSeverity string           `json:"min_severity,omitempty"`
+	Selector    string           `json:"selector,omitempty"`
+	SensorType  string           `json:"sensor_type,omitempty"`
+	Steps       []EscalationStep `json:"steps"`
+	IsActive    bool             `json:"is_active"`
+	CreatedAt   string           `json:"created_at,omitempty"`
+	UpdatedAt   string           `json:"updated_at,omitempty"`
+}
@@ -0,0 +1,93 @@
+package model
+
+import "time"
+
+// CreateAnomalyDetectorRequest sets a detector on a sensor or on every
+// sensor of a sensor type, one of the two. Params depend on the type:
+//   - zscore: window, threshold, min_samples
+//   - flatline: count, tolerance
+//   - spike: window, ratio, delta
+//   - rate_of_change: max_rate, per_seconds
+type CreateAnomalyDetectorRequest struct {
+	SensorID   string         `json:"sensor_id,omitempty" validate:"omitempty,uuid"`
+	SensorType string         `json:"sensor_type,omitempty" validate:"omitempty,max=50"`
+	Type       string         `json:"type" validate:"required,oneof=zscore flatline spike rate_of_change"`
+	Params     map[string]any `json:"params,omitempty"`
+	Severity   string         `json:"severity,omitempty" validate:"omitempty,oneof=info warning critical"`
+	RaiseAlert *bool          `json:"raise_alert,omitempty"`
+	IsActive   *bool          `json:"is_active,omitempty"`
+}
+
+// UpdateAnomalyDetectorRequest replaces the whole params when given.
+type UpdateAnomalyDetectorRequest struct {
+	Params     map[string]any `json:"params,omitempty"`
+	Severity   *string        `json:"severity,omitempty" validate:"omitempty,oneof=info warning critical"`
+	RaiseAlert *bool          `json:"raise_alert,omitempty"`
+	IsActive   *bool          `json:"is_active,omitempty"`
+}
+
+// AnomalyDetectorFilter narrows the detector list; empty fields do not
+// filter.
+type AnomalyDetectorFilter struct {
+	SensorID   string
+	SensorType string
+}
+
+type AnomalyDetectorResponse struct {
+	ID         string         `json:"id,omitempty"`
+	SensorID   string         `json:"sensor_id,omitempty"`
+	SensorType string         `json:"sensor_type,omitempty"`
+	Type       string         `json:"type,omitempty"`
+	Params     map[string]any `json:"params"`
+	Severity   string         `json:"severity,omitempty"`
+	RaiseAlert bool           `json:"raise_alert"`
+	IsActive   bool           `json:"is_active"`
+	CreatedAt  string         `json:"created_at,omitempty"`
+	UpdatedAt  string         `json:"updated_at,omitempty"`
+}
+
+// AnomalyFilter narrows the anomalies of a sensor by time and detector
+// type; empty fields do not filter.
+type AnomalyFilter struct {
+	From     *time.Time
+	To       *time.Time
+	Detector string
+}
+
+type AnomalyResponse struct {
+	ID         string   `json:"id,omitempty"`
+	SensorID   string   `json:"sensor_id,omitempty"`
+	DetectorID string   `json:"detector_id,omitempty"`
+	Detector   string   `json:"detector,omitempty"`
+	ReadingID  string   `json:"reading_id,omitempty"`
+	Value      float64  `json:"value"`
+	Expected   *float64 `json:"expected,omitempty"`
+	Score      float64  `json:"score"`
+	Severity   string   `json:"severity,omitempty"`
+	Message    string   `json:"message,omitempty"`
+	AlertID    string   `json:"alert_id,omitempty"`
+	RecordedAt string   `json:"recorded_at,omitempty"`
+	CreatedAt  string   `json:"created_at,omitempty"`
+}
+
+// AnomalyEvent is the payload of an anomaly event. RaiseAlert tells whether
+// the detector fires an alert for it.
+type AnomalyEvent struct {
+	ID         string            `json:"id"`
+	DeviceID   string            `json:"device_id"`
+	SensorID   string            `json:"sensor_id"`
+	SensorName string            `json:"sensor_name"`
+	SensorType string            `json:"sensor_type"`
+	Unit       string            `json:"unit,omitempty"`
+	DetectorID string            `json:"detector_id"`
+	Detector   string            `json:"detector"`
+	ReadingID  string            `json:"reading_id"`
+	Value      float64           `json:"value"`
+	Expected   *float64          `json:"expected,omitempty"`
+	Score      float64           `json:"score"`
+	Severity   string            `json:"severity"`
+	Message    string            `json:"message"`
+	RaiseAlert bool              `json:"raise_alert"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	RecordedAt string            `json:"recorded_at"`
+}
@@ -0,0 +1,16 @@
+package model
+
+// CacheStatsResponse reports the lookups served by the device and sensor
+// cache since the application started, by cached record.
+type CacheStatsResponse struct {
+	Lookups map[string]CacheLookupStats `json:"lookups"`
+}
+
+// CacheLookupStats counts lookups of one record. Errors are failures of
+// the cache store, which fall through to the database.
+type CacheLookupStats struct {
+	Hits     int64   `json:"hits"`
+	Misses   int64   `json:"misses"`
+	Errors   int64   `json:"errors"`
+	HitRatio float64 `json:"hit_ratio"`
+}
@@ -0,0 +1,18 @@
+package model
+
+type ConfigSchemaResponse struct {
+	ID            string         `json:"id,omitempty"`
+	HardwareModel string         `json:"hardware_model,omitempty"`
+	Schema        map[string]any `json:"schema,omitempty"`
+	CreatedAt     string         `json:"created_at,omitempty"`
+	UpdatedAt     string         `json:"updated_at,omitempty"`
+}
+
+type CreateConfigSchemaRequest struct {
+	HardwareModel string         `json:"hardware_model" validate:"required,max=100"`
+	Schema        map[string]any `json:"schema" validate:"required"`
+}
+
+type UpdateConfigSchemaRequest struct {
+	Schema map[string]any `json:"schema" validate:"required"`
+}
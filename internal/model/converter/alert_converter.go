@@ -0,0 +1,146 @@
+package converter
+
+import (
+	"mertani_test/internal/entity"
+	"mertani_test/internal/model"
+	"time"
+)
+
+func AlertToResponse(alert *entity.Alert) *model.AlertResponse {
+	labels := map[string]string(alert.Labels)
+	if labels == nil {
+		labels = map[string]string{}
+	}
+
+	response := &model.AlertResponse{
+		ID:               alert.ID.String(),
+		Fingerprint:      alert.Fingerprint,
+		Status:           alert.Status,
+		Severity:         alert.Severity,
+		Title:            alert.Title,
+		Message:          alert.Message,
+		DeviceName:       alert.DeviceName,
+		SensorName:       alert.SensorName,
+		SensorType:       alert.SensorType,
+		Value:            alert.Value,
+		Unit:             alert.Unit,
+		Labels:           labels,
+		StartsAt:         alert.StartsAt.Format("2006-01-02 15:04:05"),
+		EndsAt:           formatOptionalTime(alert.EndsAt, "2006-01-02 15:04:05"),
+		AcknowledgedAt:   formatOptionalTime(alert.AcknowledgedAt, "2006-01-02 15:04:05"),
+		AcknowledgedBy:   alert.AcknowledgedBy,
+		ResolvedBy:       alert.ResolvedBy,
+		EscalationLevel:  alert.EscalationLevel,
+		NextEscalationAt: formatOptionalTime(alert.NextEscalationAt, "2006-01-02 15:04:05"),
+		CreatedAt:        alert.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:        alert.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	if alert.DeviceID != nil {
+		response.DeviceID = alert.DeviceID.String()
+	}
+
+	if alert.SensorID != nil {
+		response.SensorID = alert.SensorID.String()
+	}
+
+	if alert.SilenceID != nil {
+		response.SilenceID = alert.SilenceID.String()
+	}
+
+	if alert.EscalationPolicyID != nil {
+		response.EscalationPolicyID = alert.EscalationPolicyID.String()
+	}
+
+	for _, comment := range alert.Comments {
+		response.Comments = append(response.Comments, *AlertCommentToResponse(&comment))
+	}
+
+	return response
+}
+
+// AlertToEvent is the payload of the alert events of an alert.
+func AlertToEvent(alert *entity.Alert) *model.AlertEvent {
+	event := &model.AlertEvent{
+		ID:          alert.ID.String(),
+		Fingerprint: alert.Fingerprint,
+		Status:      alert.Status,
+		Severity:    alert.Severity,
+		Title:       alert.Title,
+		Message:     alert.Message,
+		DeviceName:  alert.DeviceName,
+		SensorName:  alert.SensorName,
+		SensorType:  alert.SensorType,
+		Value:       alert.Value,
+		Unit:        alert.Unit,
+		Labels:      alert.Labels,
+		StartsAt:    alert.StartsAt.UTC().Format(time.RFC3339),
+	}
+
+	if alert.DeviceID != nil {
+		event.DeviceID = alert.DeviceID.String()
+	}
+
+	if alert.SensorID != nil {
+		event.SensorID = alert.SensorID.String()
+	}
+
+	if alert.EndsAt != nil {
+		event.EndsAt = alert.EndsAt.UTC().Format(time.RFC3339)
+	}
+
+	return event
+}
+
+func AlertCommentToResponse(comment *entity.AlertComment) *model.AlertCommentResponse {
+	return &model.AlertCommentResponse{
+		ID:        comment.ID.String(),
+		AlertID:   comment.AlertID.String(),
+		Author:    comment.Author,
+		Body:      comment.Body,
+		CreatedAt: comment.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// SilenceToResponse reports the state of the silence at now.
+func SilenceToResponse(silence *entity.Silence, now time.Time) *model.SilenceResponse {
+	state := "active"
+	switch {
+	case now.Before(silence.StartsAt):
+		state = "pending"
+	case !now.Before(silence.EndsAt):
+		state = "expired"
+	}
+
+	return &model.SilenceResponse{
+		ID:         silence.ID.String(),
+		Selector:   silence.Selector,
+		SensorType: silence.SensorType,
+		StartsAt:   silence.StartsAt.Format("2006-01-02 15:04:05"),
+		EndsAt:     silence.EndsAt.Format("2006-01-02 15:04:05"),
+		State:      state,
+		Comment:    silence.Comment,
+		CreatedBy:  silence.CreatedBy,
+		CreatedAt:  silence.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:  silence.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func EscalationPolicyToResponse(policy *entity.EscalationPolicy) *model.EscalationPolicyResponse {
+	steps := make([]model.EscalationStep, len(policy.Steps))
+	for i, step := range policy.Steps {
+		steps[i] = model.EscalationStep{AfterMinutes: step.AfterMinutes, ChannelIDs: step.ChannelIDs}
+	}
+
+	return &model.EscalationPolicyResponse{
+		ID:          policy.ID.String(),
+		Name:        policy.Name,
+		MinSeverity: policy.MinSeverity,
+		Selector:    policy.Selector,
+		SensorType:  policy.SensorType,
+		Steps:       steps,
+		IsActive:    policy.IsActive,
+		CreatedAt:   policy.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:   policy.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,56 @@
+package converter
+
+import (
+	"mertani_test/internal/entity"
+	"mertani_test/internal/model"
+)
+
+func AnomalyDetectorToResponse(detector *entity.AnomalyDetector) *model.AnomalyDetectorResponse {
+	params := map[string]any(detector.Params)
+	if params == nil {
+		params = map[string]any{}
+	}
+
+	response := &model.AnomalyDetectorResponse{
+		ID:         detector.ID.String(),
+		SensorType: detector.SensorType,
+		Type:       detector.Type,
+		Params:     params,
+		Severity:   detector.Severity,
+		RaiseAlert: detector.RaiseAlert,
+		IsActive:   detector.IsActive,
+		CreatedAt:  detector.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:  detector.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	if detector.SensorID != nil {
+		response.SensorID = detector.SensorID.String()
+	}
+
+	return response
+}
+
+func AnomalyToResponse(anomaly *entity.Anomaly) *model.AnomalyResponse {
+	response := &model.AnomalyResponse{
+		ID:         anomaly.ID.String(),
+		SensorID:   anomaly.SensorID.String(),
+		Detector:   anomaly.Detector,
+		ReadingID:  anomaly.ReadingID.String(),
+		Value:      anomaly.Value,
+		Expected:   anomaly.Expected,
+		Score:      anomaly.Score,
+		Severity:   anomaly.Severity,
+		Message:    anomaly.Message,
+		RecordedAt: anomaly.RecordedAt.Format("2006-01-02 15:04:05"),
+		CreatedAt:  anomaly.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	if anomaly.DetectorID != nil {
+		response.DetectorID = anomaly.DetectorID.String()
+	}
+	if anomaly.AlertID != nil {
+		response.AlertID = anomaly.AlertID.String()
+	}
+
+	return response
+}
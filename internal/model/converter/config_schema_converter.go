@@ -0,0 +1,16 @@
+package converter
+
+import (
+	"mertani_test/internal/entity"
+	"mertani_test/internal/model"
+)
+
+func ConfigSchemaToResponse(schema *entity.ConfigSchema) *model.ConfigSchemaResponse {
+	return &model.ConfigSchemaResponse{
+		ID:            schema.ID.String(),
+		HardwareModel: schema.HardwareModel,
+		Schema:        schema.Schema,
+		CreatedAt:     schema.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:     schema.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,18 @@
+package converter
+
+import (
+	"mertani_test/internal/entity"
+	"mertani_test/internal/model"
+)
+
+func DeviceConfigToResponse(config *entity.DeviceConfig) *model.DeviceConfigResponse {
+	return &model.DeviceConfigResponse{
+		ID:             config.ID.String(),
+		DeviceID:       config.DeviceID.String(),
+		Kind:           config.Kind,
+		Version:        config.Version,
+		AppliedVersion: config.AppliedVersion,
+		Config:         config.Document,
+		CreatedAt:      config.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
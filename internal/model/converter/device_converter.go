@@ -12,13 +12,28 @@ func DeviceToResponse(device *entity.Device) *model.DeviceResponse {
 		sensors = append(sensors, *SensorToResponse(&sensor))
 	}
 
-	return &model.DeviceResponse{
-		ID:        device.ID.String(),
-		Name:      device.Name,
-		Location:  device.Location,
-		Status:    device.Status,
-		Sensors:   sensors,
-		CreatedAt: device.CreatedAt.Format("2006-01-02 15:04:05"),
-		UpdatedAt: device.UpdatedAt.Format("2006-01-02 15:04:05"),
+	response := &model.DeviceResponse{
+		ID:            device.ID.String(),
+		Name:          device.Name,
+		Location:      device.Location,
+		Status:        device.Status,
+		HardwareModel: device.HardwareModel,
+		Latitude:      device.Latitude,
+		Longitude:     device.Longitude,
+		Boundary:      device.Boundary,
+		Labels:        device.Labels,
+		Sensors:       sensors,
+		CreatedAt:     device.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:     device.UpdatedAt.Format("2006-01-02 15:04:05"),
 	}
+
+	if device.SiteID != nil {
+		response.SiteID = device.SiteID.String()
+	}
+
+	if device.LastCheckInAt != nil {
+		response.LastCheckInAt = device.LastCheckInAt.Format("2006-01-02 15:04:05")
+	}
+
+	return response
 }
@@ -0,0 +1,43 @@
+package converter
+
+import (
+	"mertani_test/internal/entity"
+	"mertani_test/internal/model"
+	"time"
+)
+
+func ExportJobToResponse(job *entity.ExportJob) *model.ExportJobResponse {
+	response := &model.ExportJobResponse{
+		ID:         job.ID.String(),
+		Kind:       job.Kind,
+		TargetID:   job.TargetID.String(),
+		Format:     job.Format,
+		From:       formatOptionalTime(job.RangeFrom, time.RFC3339),
+		To:         formatOptionalTime(job.RangeTo, time.RFC3339),
+		Interval:   job.Interval,
+		Unit:       job.Unit,
+		Status:     job.Status,
+		Rows:       job.Rows,
+		Size:       job.Size,
+		Error:      job.Error,
+		FileName:   job.FileName,
+		ExpiresAt:  formatOptionalTime(job.ExpiresAt, "2006-01-02 15:04:05"),
+		StartedAt:  formatOptionalTime(job.StartedAt, "2006-01-02 15:04:05"),
+		FinishedAt: formatOptionalTime(job.FinishedAt, "2006-01-02 15:04:05"),
+		CreatedAt:  job.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:  job.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	if job.Status == entity.ExportJobCompleted {
+		response.DownloadURL = "/api/v1/exports/" + job.ID.String() + "/download"
+	}
+
+	return response
+}
+
+func formatOptionalTime(t *time.Time, layout string) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(layout)
+}
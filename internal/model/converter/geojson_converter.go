@@ -0,0 +1,77 @@
+package converter
+
+import (
+	"mertani_test/internal/entity"
+	"mertani_test/internal/model"
+
+	"github.com/google/uuid"
+)
+
+func DeviceToGeoJSONFeature(device *entity.Device, latest map[uuid.UUID]entity.SensorLatest) model.GeoJSONFeature {
+	sensors := make([]model.GeoJSONSensorProperties, 0, len(device.Sensors))
+	for _, sensor := range device.Sensors {
+		properties := model.GeoJSONSensorProperties{
+			ID:       sensor.ID.String(),
+			Name:     sensor.Name,
+			Type:     sensor.Type,
+			Unit:     sensor.Unit,
+			IsActive: sensor.IsActive,
+			Labels:   sensor.Labels,
+		}
+		if reading, ok := latest[sensor.ID]; ok {
+			properties.LatestReading = SensorLatestToResponse(&reading)
+		}
+		sensors = append(sensors, properties)
+	}
+
+	feature := model.GeoJSONFeature{
+		Type:     "Feature",
+		ID:       device.ID.String(),
+		Geometry: deviceGeometry(device),
+		Properties: model.GeoJSONDeviceProperties{
+			Name:          device.Name,
+			Location:      device.Location,
+			Status:        device.Status,
+			HardwareModel: device.HardwareModel,
+			Labels:        device.Labels,
+			Sensors:       sensors,
+		},
+	}
+
+	if device.LastCheckInAt != nil {
+		feature.Properties.LastCheckInAt = device.LastCheckInAt.Format("2006-01-02 15:04:05")
+	}
+
+	return feature
+}
+
+// deviceGeometry returns a Point, a Polygon, a GeometryCollection of both,
+// or nil for devices without any coordinates.
+func deviceGeometry(device *entity.Device) *model.GeoJSONGeometry {
+	var geometries []model.GeoJSONGeometry
+
+	if device.Latitude != nil && device.Longitude != nil {
+		geometries = append(geometries, model.GeoJSONGeometry{
+			Type:        "Point",
+			Coordinates: []float64{*device.Longitude, *device.Latitude},
+		})
+	}
+	if len(device.Boundary) > 0 {
+		geometries = append(geometries, model.GeoJSONGeometry{
+			Type:        "Polygon",
+			Coordinates: [][][]float64{device.Boundary},
+		})
+	}
+
+	switch len(geometries) {
+	case 0:
+		return nil
+	case 1:
+		return &geometries[0]
+	default:
+		return &model.GeoJSONGeometry{
+			Type:       "GeometryCollection",
+			Geometries: geometries,
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package converter
+
+import (
+	"encoding/json"
+	"mertani_test/internal/entity"
+	"mertani_test/internal/model"
+)
+
+// MaskedSecret replaces secret channel settings in responses.
+const MaskedSecret = "********"
+
+// notificationSecrets are the config keys of channel types that hold
+// credentials.
+var notificationSecrets = []string{"password", "token"}
+
+func NotificationChannelToResponse(channel *entity.NotificationChannel) *model.NotificationChannelResponse {
+	config := make(map[string]any, len(channel.Config))
+	for key, value := range channel.Config {
+		config[key] = value
+	}
+	for _, key := range notificationSecrets {
+		if value, ok := config[key]; ok && value != "" {
+			config[key] = MaskedSecret
+		}
+	}
+	if headers, ok := config["headers"].(map[string]any); ok && len(headers) > 0 {
+		masked := make(map[string]any, len(headers))
+		for key := range headers {
+			masked[key] = MaskedSecret
+		}
+		config["headers"] = masked
+	}
+
+	return &model.NotificationChannelResponse{
+		ID:        channel.ID.String(),
+		Name:      channel.Name,
+		Type:      channel.Type,
+		Config:    config,
+		IsActive:  channel.IsActive,
+		CreatedAt: channel.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt: channel.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func NotificationRuleToResponse(rule *entity.NotificationRule) *model.NotificationRuleResponse {
+	channelIDs := []string(rule.ChannelIDs)
+	if channelIDs == nil {
+		channelIDs = []string{}
+	}
+
+	return &model.NotificationRuleResponse{
+		ID:                 rule.ID.String(),
+		Name:               rule.Name,
+		MinSeverity:        rule.MinSeverity,
+		Selector:           rule.Selector,
+		ChannelIDs:         channelIDs,
+		SubjectTemplate:    rule.SubjectTemplate,
+		BodyTemplate:       rule.BodyTemplate,
+		QuietStart:         rule.QuietStart,
+		QuietEnd:           rule.QuietEnd,
+		Timezone:           rule.Timezone,
+		GroupWindowSeconds: rule.GroupWindowSeconds,
+		NotifyResolved:     rule.NotifyResolved,
+		IsActive:           rule.IsActive,
+		CreatedAt:          rule.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:          rule.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func NotificationToResponse(notification *entity.Notification) *model.NotificationResponse {
+	response := &model.NotificationResponse{
+		ID:            notification.ID.String(),
+		EventID:       notification.EventID.String(),
+		ChannelID:     notification.ChannelID.String(),
+		AlertID:       notification.AlertID,
+		AlertStatus:   notification.AlertStatus,
+		Fingerprint:   notification.Fingerprint,
+		Severity:      notification.Severity,
+		Subject:       notification.Subject,
+		Body:          notification.Body,
+		Status:        notification.Status,
+		Attempts:      notification.Attempts,
+		NextAttemptAt: formatOptionalTime(notification.NextAttemptAt, "2006-01-02 15:04:05"),
+		LastError:     notification.LastError,
+		SentAt:        formatOptionalTime(notification.SentAt, "2006-01-02 15:04:05"),
+		CreatedAt:     notification.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:     notification.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	if notification.RuleID != nil {
+		response.RuleID = notification.RuleID.String()
+	}
+
+	if notification.EscalationPolicyID != nil {
+		response.EscalationPolicyID = notification.EscalationPolicyID.String()
+		response.EscalationLevel = notification.EscalationLevel
+	}
+
+	if json.Valid([]byte(notification.Payload)) {
+		response.Alert = json.RawMessage(notification.Payload)
+	}
+
+	return response
+}
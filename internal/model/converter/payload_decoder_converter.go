@@ -0,0 +1,17 @@
+package converter
+
+import (
+	"mertani_test/internal/entity"
+	"mertani_test/internal/model"
+)
+
+func PayloadDecoderToResponse(payloadDecoder *entity.PayloadDecoder) *model.PayloadDecoderResponse {
+	return &model.PayloadDecoderResponse{
+		ID:            payloadDecoder.ID.String(),
+		HardwareModel: payloadDecoder.HardwareModel,
+		Decoder:       payloadDecoder.Decoder,
+		Layout:        payloadDecoder.Layout,
+		CreatedAt:     payloadDecoder.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:     payloadDecoder.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,47 @@
+package converter
+
+import (
+	"mertani_test/internal/entity"
+	"mertani_test/internal/model"
+)
+
+func ReadingToResponse(reading *entity.Reading) *model.ReadingResponse {
+	response := &model.ReadingResponse{
+		ID:         reading.ID.String(),
+		SensorID:   reading.SensorID.String(),
+		Value:      reading.EffectiveValue(),
+		Quality:    reading.QualityFlags(),
+		RecordedAt: reading.RecordedAt.Format("2006-01-02 15:04:05"),
+	}
+	if reading.CalibratedValue != nil {
+		raw := reading.Value
+		response.RawValue = &raw
+	}
+	return response
+}
+
+// SensorLatestToResponse serves the latest reading of a sensor, which does
+// not keep its quality flags.
+func SensorLatestToResponse(latest *entity.SensorLatest) *model.ReadingResponse {
+	response := &model.ReadingResponse{
+		ID:         latest.ReadingID.String(),
+		SensorID:   latest.SensorID.String(),
+		Value:      latest.EffectiveValue(),
+		RecordedAt: latest.RecordedAt.Format("2006-01-02 15:04:05"),
+	}
+	if latest.CalibratedValue != nil {
+		raw := latest.Value
+		response.RawValue = &raw
+	}
+	return response
+}
+
+func ReadingAggregateToResponse(aggregate *model.ReadingAggregate) *model.ReadingAggregateResponse {
+	return &model.ReadingAggregateResponse{
+		Bucket: aggregate.Bucket.Format("2006-01-02 15:04:05"),
+		Count:  aggregate.Count,
+		Min:    aggregate.Min,
+		Max:    aggregate.Max,
+		Avg:    aggregate.Avg,
+	}
+}
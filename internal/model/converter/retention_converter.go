@@ -0,0 +1,18 @@
+package converter
+
+import (
+	"mertani_test/internal/entity"
+	"mertani_test/internal/model"
+)
+
+func RetentionPolicyToResponse(policy *entity.RetentionPolicy) *model.RetentionPolicyResponse {
+	return &model.RetentionPolicyResponse{
+		ID:         policy.ID.String(),
+		SensorType: policy.SensorType,
+		RawDays:    policy.RawDays,
+		HourlyDays: policy.HourlyDays,
+		DailyDays:  policy.DailyDays,
+		CreatedAt:  policy.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:  policy.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,24 @@
+package converter
+
+import (
+	"mertani_test/internal/entity"
+	"mertani_test/internal/model"
+)
+
+func SensorCalibrationToResponse(calibration *entity.SensorCalibration) *model.SensorCalibrationResponse {
+	response := &model.SensorCalibrationResponse{
+		ID:            calibration.ID.String(),
+		SensorID:      calibration.SensorID.String(),
+		Method:        calibration.Method,
+		Polynomial:    calibration.Polynomial,
+		Table:         calibration.Table,
+		EffectiveFrom: calibration.EffectiveFrom.Format("2006-01-02 15:04:05"),
+		Note:          calibration.Note,
+		CreatedAt:     calibration.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if calibration.Method == entity.CalibrationOffsetGain {
+		response.Offset = &calibration.Offset
+		response.Gain = &calibration.Gain
+	}
+	return response
+}
@@ -6,15 +6,38 @@ import (
 )
 
 func SensorToResponse(sensor *entity.Sensor) *model.SensorResponse {
-	return &model.SensorResponse{
-		ID:        sensor.ID.String(),
-		DeviceID:  sensor.DeviceID.String(),
-		DeviceName: sensor.Device.Name,
-		Name:      sensor.Name,
-		Type:      sensor.Type,
-		Unit:      sensor.Unit,
-		IsActive:  sensor.IsActive,
-		CreatedAt: sensor.CreatedAt.Format("2006-01-02 15:04:05"),
-		UpdatedAt: sensor.UpdatedAt.Format("2006-01-02 15:04:05"),
+	var inputs map[string]string
+	if len(sensor.Inputs) > 0 {
+		inputs = make(map[string]string, len(sensor.Inputs))
+		for _, input := range sensor.Inputs {
+			inputs[input.Alias] = input.InputSensorID.String()
+		}
 	}
+
+	response := &model.SensorResponse{
+		ID:               sensor.ID.String(),
+		DeviceID:         sensor.DeviceID.String(),
+		DeviceName:       sensor.Device.Name,
+		Name:             sensor.Name,
+		Type:             sensor.Type,
+		Unit:             sensor.Unit,
+		IsActive:         sensor.IsActive,
+		Labels:           sensor.Labels,
+		Kind:             sensor.Kind,
+		Expression:       sensor.Expression,
+		Inputs:           inputs,
+		ExpectedInterval: sensor.ExpectedInterval,
+		ValidMin:         sensor.ValidMin,
+		ValidMax:         sensor.ValidMax,
+		CreatedAt:        sensor.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:        sensor.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	if sensor.Latest != nil {
+		value := sensor.Latest.EffectiveValue()
+		response.LastValue = &value
+		response.LastReadingAt = sensor.Latest.RecordedAt.Format("2006-01-02 15:04:05")
+	}
+
+	return response
 }
@@ -0,0 +1,21 @@
+package converter
+
+import (
+	"mertani_test/internal/entity"
+	"mertani_test/internal/model"
+)
+
+func SensorTypeToResponse(sensorType *entity.SensorType) *model.SensorTypeResponse {
+	return &model.SensorTypeResponse{
+		ID:            sensorType.ID.String(),
+		Code:          sensorType.Code,
+		DisplayName:   sensorType.DisplayName,
+		CanonicalUnit: sensorType.CanonicalUnit,
+		AllowedUnits:  sensorType.AllowedUnits,
+		PhysicalMin:   sensorType.PhysicalMin,
+		PhysicalMax:   sensorType.PhysicalMax,
+		Precision:     sensorType.Precision,
+		CreatedAt:     sensorType.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:     sensorType.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
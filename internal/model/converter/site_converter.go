@@ -0,0 +1,28 @@
+package converter
+
+import (
+	"mertani_test/internal/entity"
+	"mertani_test/internal/model"
+)
+
+func SiteToResponse(site *entity.Site) *model.SiteResponse {
+	children := make([]model.SiteResponse, 0, len(site.Children))
+	for _, child := range site.Children {
+		children = append(children, *SiteToResponse(&child))
+	}
+
+	response := &model.SiteResponse{
+		ID:        site.ID.String(),
+		Name:      site.Name,
+		Kind:      site.Kind,
+		Children:  children,
+		CreatedAt: site.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt: site.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	if site.ParentID != nil {
+		response.ParentID = site.ParentID.String()
+	}
+
+	return response
+}
@@ -0,0 +1,53 @@
+package converter
+
+import (
+	"encoding/json"
+	"mertani_test/internal/entity"
+	"mertani_test/internal/model"
+)
+
+func WebhookToResponse(webhook *entity.Webhook) *model.WebhookResponse {
+	eventTypes := []string(webhook.EventTypes)
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+
+	return &model.WebhookResponse{
+		ID:         webhook.ID.String(),
+		Name:       webhook.Name,
+		URL:        webhook.URL,
+		EventTypes: eventTypes,
+		IsActive:   webhook.IsActive,
+		CreatedAt:  webhook.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:  webhook.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func WebhookDeliveryToResponse(delivery *entity.WebhookDelivery) *model.WebhookDeliveryResponse {
+	response := &model.WebhookDeliveryResponse{
+		ID:             delivery.ID.String(),
+		WebhookID:      delivery.WebhookID.String(),
+		EventID:        delivery.EventID.String(),
+		EventType:      delivery.EventType,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		LastResponse:   delivery.LastResponse,
+		LastDurationMs: delivery.LastDurationMs,
+		NextAttemptAt:  formatOptionalTime(delivery.NextAttemptAt, "2006-01-02 15:04:05"),
+		DeliveredAt:    formatOptionalTime(delivery.DeliveredAt, "2006-01-02 15:04:05"),
+		CreatedAt:      delivery.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:      delivery.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	if json.Valid([]byte(delivery.Payload)) {
+		response.Payload = json.RawMessage(delivery.Payload)
+	}
+
+	if delivery.RedeliveryOf != nil {
+		response.RedeliveryOf = delivery.RedeliveryOf.String()
+	}
+
+	return response
+}
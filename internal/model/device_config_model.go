@@ -0,0 +1,49 @@
+package model
+
+type DeviceConfigResponse struct {
+	ID             string         `json:"id,omitempty"`
+	DeviceID       string         `json:"device_id,omitempty"`
+	Kind           string         `json:"kind,omitempty"`
+	Version        int            `json:"version"`
+	AppliedVersion int            `json:"applied_version,omitempty"`
+	Config         map[string]any `json:"config"`
+	CreatedAt      string         `json:"created_at,omitempty"`
+}
+
+type DeviceConfigStateResponse struct {
+	DeviceID string                `json:"device_id"`
+	Desired  *DeviceConfigResponse `json:"desired,omitempty"`
+	Reported *DeviceConfigResponse `json:"reported,omitempty"`
+	Pending  bool                  `json:"pending"`
+}
+
+type ConfigDriftResponse struct {
+	Key      string `json:"key"`
+	Status   string `json:"status"`
+	Desired  any    `json:"desired,omitempty"`
+	Reported any    `json:"reported,omitempty"`
+}
+
+type DeviceConfigDiffResponse struct {
+	DeviceID        string                `json:"device_id"`
+	DesiredVersion  int                   `json:"desired_version"`
+	ReportedVersion int                   `json:"reported_version"`
+	AppliedVersion  int                   `json:"applied_version"`
+	InSync          bool                  `json:"in_sync"`
+	Drift           []ConfigDriftResponse `json:"drift"`
+}
+
+type UpdateDesiredConfigRequest struct {
+	Config map[string]any `json:"config" validate:"required"`
+}
+
+type DeviceCheckInRequest struct {
+	AppliedVersion int            `json:"applied_version" validate:"min=0"`
+	Reported       map[string]any `json:"reported,omitempty"`
+}
+
+type DeviceCheckInResponse struct {
+	Pending        bool           `json:"pending"`
+	DesiredVersion int            `json:"desired_version"`
+	Config         map[string]any `json:"config,omitempty"`
+}
@@ -1,23 +1,56 @@
 package model
 
+import (
+	"mertani_test/internal/utils"
+
+	"github.com/google/uuid"
+)
+
 type DeviceResponse struct {
-	ID        string `json:"id,omitempty"`
-	Name      string `json:"name,omitempty"`
-	Location  string `json:"location,omitempty"`
-	Status    string `json:"status,omitempty"`
-	Sensors   []SensorResponse `json:"sensors,omitempty"`
-	CreatedAt string `json:"created_at,omitempty"`
-	UpdatedAt string `json:"updated_at,omitempty"`
+	ID            string            `json:"id,omitempty"`
+	Name          string            `json:"name,omitempty"`
+	Location      string            `json:"location,omitempty"`
+	Status        string            `json:"status,omitempty"`
+	HardwareModel string            `json:"hardware_model,omitempty"`
+	SiteID        string            `json:"site_id,omitempty"`
+	Latitude      *float64          `json:"latitude,omitempty"`
+	Longitude     *float64          `json:"longitude,omitempty"`
+	Boundary      [][]float64       `json:"boundary,omitempty"`
+	DistanceKm    *float64          `json:"distance_km,omitempty"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	LastCheckInAt string            `json:"last_check_in_at,omitempty"`
+	Sensors       []SensorResponse  `json:"sensors,omitempty"`
+	CreatedAt     string            `json:"created_at,omitempty"`
+	UpdatedAt     string            `json:"updated_at,omitempty"`
 }
 
 type CreateDeviceRequest struct {
-	Name     string `json:"name" validate:"required,max=100"`
-	Location string `json:"location,omitempty"`
-	Status   string `json:"status,omitempty"`
+	Name          string            `json:"name" validate:"required,max=100"`
+	Location      string            `json:"location,omitempty"`
+	Status        string            `json:"status,omitempty"`
+	HardwareModel string            `json:"hardware_model,omitempty" validate:"omitempty,max=100"`
+	SiteID        string            `json:"site_id,omitempty" validate:"omitempty,uuid"`
+	Latitude      *float64          `json:"latitude,omitempty" validate:"required_with=Longitude,omitempty,latitude"`
+	Longitude     *float64          `json:"longitude,omitempty" validate:"required_with=Latitude,omitempty,longitude"`
+	Boundary      [][]float64       `json:"boundary,omitempty"`
+	Labels        map[string]string `json:"labels,omitempty"`
 }
 
 type UpdateDeviceRequest struct {
-	Name     *string `json:"name,omitempty"`
-	Location *string `json:"location,omitempty"`
-	Status   *string `json:"status,omitempty"`
-}
\ No newline at end of file
+	Name          *string      `json:"name,omitempty"`
+	Location      *string      `json:"location,omitempty"`
+	Status        *string      `json:"status,omitempty"`
+	HardwareModel *string      `json:"hardware_model,omitempty" validate:"omitempty,max=100"`
+	Latitude      *float64     `json:"latitude,omitempty" validate:"required_with=Longitude,omitempty,latitude"`
+	Longitude     *float64     `json:"longitude,omitempty" validate:"required_with=Latitude,omitempty,longitude"`
+	Boundary      *[][]float64 `json:"boundary,omitempty"`
+}
+
+// DeviceFilter narrows device list queries. Near and BBox are optional.
+type DeviceFilter struct {
+	Near     []float64 // latitude, longitude
+	RadiusKm float64
+	BBox     []float64 // min longitude, min latitude, max longitude, max latitude
+	Selector utils.LabelSelector
+	SiteIDs  []uuid.UUID
+}
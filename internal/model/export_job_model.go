@@ -0,0 +1,31 @@
+package model
+
+// CreateExportJobRequest queues a readings export of a sensor or of all
+// sensors of a device; the export options are those of the streaming export.
+type CreateExportJobRequest struct {
+	Kind     string `json:"kind" validate:"required,oneof=sensor_readings device_readings"`
+	TargetID string `json:"target_id" validate:"required,uuid"`
+	ReadingExportRequest
+}
+
+type ExportJobResponse struct {
+	ID          string `json:"id,omitempty"`
+	Kind        string `json:"kind,omitempty"`
+	TargetID    string `json:"target_id,omitempty"`
+	Format      string `json:"format,omitempty"`
+	From        string `json:"from,omitempty"`
+	To          string `json:"to,omitempty"`
+	Interval    string `json:"interval,omitempty"`
+	Unit        string `json:"unit,omitempty"`
+	Status      string `json:"status,omitempty"`
+	Rows        int64  `json:"rows"`
+	Size        int64  `json:"size,omitempty"`
+	Error       string `json:"error,omitempty"`
+	FileName    string `json:"file_name,omitempty"`
+	DownloadURL string `json:"download_url,omitempty"`
+	ExpiresAt   string `json:"expires_at,omitempty"`
+	StartedAt   string `json:"started_at,omitempty"`
+	FinishedAt  string `json:"finished_at,omitempty"`
+	CreatedAt   string `json:"created_at,omitempty"`
+	UpdatedAt   string `json:"updated_at,omitempty"`
+}
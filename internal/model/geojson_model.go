@@ -0,0 +1,39 @@
+package model
+
+type GeoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []GeoJSONFeature `json:"features"`
+}
+
+type GeoJSONFeature struct {
+	Type       string                  `json:"type"`
+	ID         string                  `json:"id"`
+	Geometry   *GeoJSONGeometry        `json:"geometry"`
+	Properties GeoJSONDeviceProperties `json:"properties"`
+}
+
+type GeoJSONGeometry struct {
+	Type        string            `json:"type"`
+	Coordinates any               `json:"coordinates,omitempty"`
+	Geometries  []GeoJSONGeometry `json:"geometries,omitempty"`
+}
+
+type GeoJSONDeviceProperties struct {
+	Name          string                    `json:"name"`
+	Location      string                    `json:"location,omitempty"`
+	Status        string                    `json:"status,omitempty"`
+	HardwareModel string                    `json:"hardware_model,omitempty"`
+	LastCheckInAt string                    `json:"last_check_in_at,omitempty"`
+	Labels        map[string]string         `json:"labels,omitempty"`
+	Sensors       []GeoJSONSensorProperties `json:"sensors"`
+}
+
+type GeoJSONSensorProperties struct {
+	ID            string            `json:"id"`
+	Name          string            `json:"name"`
+	Type          string            `json:"type"`
+	Unit          string            `json:"unit,omitempty"`
+	IsActive      bool              `json:"is_active"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	LatestReading *ReadingResponse  `json:"latest_reading"`
+}
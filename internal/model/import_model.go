@@ -0,0 +1,20 @@
+package model
+
+// ImportRowError lists what is wrong with one CSV row; Row is the line
+// number in the uploaded file.
+type ImportRowError struct {
+	Row    int      `json:"row"`
+	Name   string   `json:"name,omitempty"`
+	Errors []string `json:"errors"`
+}
+
+// ImportResponse summarises a CSV import. Nothing is written when DryRun is
+// set or when any row failed.
+type ImportResponse struct {
+	DryRun  bool             `json:"dry_run"`
+	Rows    int              `json:"rows"`
+	Created int              `json:"created"`
+	Updated int              `json:"updated"`
+	Failed  int              `json:"failed"`
+	Errors  []ImportRowError `json:"errors,omitempty"`
+}
@@ -0,0 +1,5 @@
+package model
+
+type UpdateLabelsRequest struct {
+	Labels map[string]string `json:"labels" validate:"required"`
+}
@@ -0,0 +1,17 @@
+package model
+
+type LineWriteError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// LineWriteResponse summarises a line protocol write. Lines with errors are
+// skipped, the others are stored.
+type LineWriteResponse struct {
+	Lines          int              `json:"lines"`
+	Written        int              `json:"written"`
+	Failed         int              `json:"failed"`
+	Readings       int              `json:"readings"`
+	SensorsCreated int              `json:"sensors_created"`
+	Errors         []LineWriteError `json:"errors,omitempty"`
+}
@@ -0,0 +1,113 @@
+package model
+
+import "encoding/json"
+
+// CreateNotificationChannelRequest configures a channel. Config depends on
+// the type:
+//   - email: host, port, username, password, from, to, starttls
+//   - chat: api_url, token, chat_id
+//   - webhook: url, headers
+type CreateNotificationChannelRequest struct {
+	Name     string         `json:"name" validate:"required,max=100"`
+	Type     string         `json:"type" validate:"required,oneof=email chat webhook"`
+	Config   map[string]any `json:"config" validate:"required"`
+	IsActive *bool          `json:"is_active,omitempty"`
+}
+
+// UpdateNotificationChannelRequest replaces the whole config when given;
+// secrets sent back masked keep their stored value.
+type UpdateNotificationChannelRequest struct {
+	Name     *string        `json:"name,omitempty" validate:"omitempty,max=100"`
+	Config   map[string]any `json:"config,omitempty"`
+	IsActive *bool          `json:"is_active,omitempty"`
+}
+
+// NotificationChannelResponse masks secrets in Config.
+type NotificationChannelResponse struct {
+	ID        string         `json:"id,omitempty"`
+	Name      string         `json:"name,omitempty"`
+	Type      string         `json:"type,omitempty"`
+	Config    map[string]any `json:"config"`
+	IsActive  bool           `json:"is_active"`
+	CreatedAt string         `json:"created_at,omitempty"`
+	UpdatedAt string         `json:"updated_at,omitempty"`
+}
+
+type CreateNotificationRuleRequest struct {
+	Name               string   `json:"name" validate:"required,max=100"`
+	MinSeverity        string   `json:"min_severity,omitempty" validate:"omitempty,oneof=info warning critical"`
+	Selector           string   `json:"selector,omitempty" validate:"omitempty,max=500"`
+	ChannelIDs         []string `json:"channel_ids" validate:"required,min=1,dive,uuid"`
+	SubjectTemplate    string   `json:"subject_template,omitempty" validate:"omitempty,max=500"`
+	BodyTemplate       string   `json:"body_template,omitempty" validate:"omitempty,max=5000"`
+	QuietStart         string   `json:"quiet_start,omitempty" validate:"omitempty,datetime=15:04"`
+	QuietEnd           string   `json:"quiet_end,omitempty" validate:"omitempty,datetime=15:04"`
+	Timezone           string   `json:"timezone,omitempty" validate:"omitempty,max=50"`
+	GroupWindowSeconds int      `json:"group_window_seconds,omitempty" validate:"omitempty,min=0,max=86400"`
+	NotifyResolved     *bool    `json:"notify_resolved,omitempty"`
+	IsActive           *bool    `json:"is_active,omitempty"`
+}
+
+type UpdateNotificationRuleRequest struct {
+	Name               *string  `json:"name,omitempty" validate:"omitempty,max=100"`
+	MinSeverity        *string  `json:"min_severity,omitempty" validate:"omitempty,oneof=info warning critical"`
+	Selector           *string  `json:"selector,omitempty" validate:"omitempty,max=500"`
+	ChannelIDs         []string `json:"channel_ids,omitempty" validate:"omitempty,min=1,dive,uuid"`
+	SubjectTemplate    *string  `json:"subject_template,omitempty" validate:"omitempty,max=500"`
+	BodyTemplate       *string  `json:"body_template,omitempty" validate:"omitempty,max=5000"`
+	QuietStart         *string  `json:"quiet_start,omitempty" validate:"omitempty,datetime=15:04"`
+	QuietEnd           *string  `json:"quiet_end,omitempty" validate:"omitempty,datetime=15:04"`
+	Timezone           *string  `json:"timezone,omitempty" validate:"omitempty,max=50"`
+	GroupWindowSeconds *int     `json:"group_window_seconds,omitempty" validate:"omitempty,min=0,max=86400"`
+	NotifyResolved     *bool    `json:"notify_resolved,omitempty"`
+	IsActive           *bool    `json:"is_active,omitempty"`
+}
+
+type NotificationRuleResponse struct {
+	ID                 string   `json:"id,omitempty"`
+	Name               string   `json:"name,omitempty"`
+	MinSeverity        string   `json:"min_severity,omitempty"`
+	Selector           string   `json:"selector,omitempty"`
+	ChannelIDs         []string `json:"channel_ids"`
+	SubjectTemplate    string   `json:"subject_template,omitempty"`
+	BodyTemplate       string   `json:"body_template,omitempty"`
+	QuietStart         string   `json:"quiet_start,omitempty"`
+	QuietEnd           string   `json:"quiet_end,omitempty"`
+	Timezone           string   `json:"timezone,omitempty"`
+	GroupWindowSeconds int      `json:"group_window_seconds"`
+	NotifyResolved     bool     `json:"notify_resolved"`
+	IsActive           bool     `json:"is_active"`
+	CreatedAt          string   `json:"created_at,omitempty"`
+	UpdatedAt          string   `json:"updated_at,omitempty"`
+}
+
+// NotificationFilter narrows the delivery log; empty fields do not filter.
+type NotificationFilter struct {
+	Status    string
+	ChannelID string
+	RuleID    string
+	AlertID   string
+}
+
+type NotificationResponse struct {
+	ID                 string          `json:"id,omitempty"`
+	EventID            string          `json:"event_id,omitempty"`
+	RuleID             string          `json:"rule_id,omitempty"`
+	EscalationPolicyID string          `json:"escalation_policy_id,omitempty"`
+	EscalationLevel    int             `json:"escalation_level,omitempty"`
+	ChannelID          string          `json:"channel_id,omitempty"`
+	AlertID            string          `json:"alert_id,omitempty"`
+	AlertStatus        string          `json:"alert_status,omitempty"`
+	Fingerprint        string          `json:"fingerprint,omitempty"`
+	Severity           string          `json:"severity,omitempty"`
+	Subject            string          `json:"subject,omitempty"`
+	Body               string          `json:"body,omitempty"`
+	Alert              json.RawMessage `json:"alert,omitempty" swaggertype:"object"`
+	Status             string          `json:"status,omitempty"`
+	Attempts           int             `json:"attempts"`
+	NextAttemptAt      string          `json:"next_attempt_at,omitempty"`
+	LastError          string          `json:"last_error,omitempty"`
+	SentAt             string          `json:"sent_at,omitempty"`
+	CreatedAt          string          `json:"created_at,omitempty"`
+	UpdatedAt          string          `json:"updated_at,omitempty"`
+}
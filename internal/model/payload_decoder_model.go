@@ -0,0 +1,60 @@
+package model
+
+import "mertani_test/internal/decoder"
+
+type PayloadDecoderResponse struct {
+	ID            string          `json:"id,omitempty"`
+	HardwareModel string          `json:"hardware_model,omitempty"`
+	Decoder       string          `json:"decoder,omitempty"`
+	Layout        []decoder.Field `json:"layout,omitempty"`
+	CreatedAt     string          `json:"created_at,omitempty"`
+	UpdatedAt     string          `json:"updated_at,omitempty"`
+}
+
+// CreatePayloadDecoderRequest names a built-in decoder, or "layout" together
+// with a byte layout.
+type CreatePayloadDecoderRequest struct {
+	HardwareModel string         `json:"hardware_model" validate:"required,max=100"`
+	Decoder       string         `json:"decoder" validate:"required,max=50"`
+	Layout        decoder.Layout `json:"layout,omitempty" validate:"required_if=Decoder layout"`
+}
+
+type UpdatePayloadDecoderRequest struct {
+	Decoder string         `json:"decoder" validate:"required,max=50"`
+	Layout  decoder.Layout `json:"layout,omitempty" validate:"required_if=Decoder layout"`
+}
+
+// TestPayloadDecoderRequest decodes a sample payload with the decoder stored
+// for HardwareModel, or with the inline Decoder and Layout when given.
+type TestPayloadDecoderRequest struct {
+	HardwareModel string         `json:"hardware_model,omitempty" validate:"required_without=Decoder,max=100"`
+	Decoder       string         `json:"decoder,omitempty" validate:"max=50"`
+	Layout        decoder.Layout `json:"layout,omitempty" validate:"required_if=Decoder layout"`
+	Payload       string         `json:"payload" validate:"required"`
+	Encoding      string         `json:"encoding,omitempty" validate:"omitempty,oneof=hex base64"`
+}
+
+type DecodedPayloadResponse struct {
+	Decoder      string                `json:"decoder"`
+	Measurements []decoder.Measurement `json:"measurements"`
+}
+
+type UplinkRequest struct {
+	Payload    string `json:"payload" validate:"required"`
+	Encoding   string `json:"encoding,omitempty" validate:"omitempty,oneof=hex base64"`
+	ReceivedAt string `json:"received_at,omitempty" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+}
+
+type UplinkMeasurementResponse struct {
+	Name     string  `json:"name"`
+	Value    float64 `json:"value"`
+	SensorID string  `json:"sensor_id,omitempty"`
+	Stored   bool    `json:"stored"`
+	Error    string  `json:"error,omitempty"`
+}
+
+type UplinkResponse struct {
+	DeviceID     string                      `json:"device_id"`
+	Decoder      string                      `json:"decoder"`
+	Measurements []UplinkMeasurementResponse `json:"measurements"`
+}
@@ -0,0 +1,115 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReadingResponse struct {
+	ID         string   `json:"id,omitempty"`
+	SensorID   string   `json:"sensor_id,omitempty"`
+	Value      float64  `json:"value"`
+	RawValue   *float64 `json:"raw_value,omitempty"`
+	Unit       string   `json:"unit,omitempty"`
+	Quality    []string `json:"quality,omitempty"`
+	RecordedAt string   `json:"recorded_at,omitempty"`
+}
+
+type CreateReadingRequest struct {
+	Value      *float64 `json:"value" validate:"required"`
+	RecordedAt string   `json:"recorded_at,omitempty" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+}
+
+// ReadingFilter bounds reading queries by recorded time and optionally
+// requests values converted to another unit.
+type ReadingFilter struct {
+	From *time.Time
+	To   *time.Time
+	Unit string
+}
+
+type ReadingAggregateResponse struct {
+	Bucket string  `json:"bucket"`
+	Count  int64   `json:"count"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	Avg    float64 `json:"avg"`
+	Unit   string  `json:"unit,omitempty"`
+}
+
+// ReadingAggregate is a single time bucket as returned by the database.
+// SensorID is only set by queries spanning several sensors.
+type ReadingAggregate struct {
+	SensorID uuid.UUID
+	Bucket   time.Time
+	Count    int64
+	Min      float64
+	Max      float64
+	Avg      float64
+}
+
+// ReadingExportRequest selects the readings of a bulk export. Interval
+// resamples them into min/max/avg buckets.
+type ReadingExportRequest struct {
+	Format   string `json:"format" validate:"required,oneof=csv ndjson parquet"`
+	From     string `json:"from,omitempty" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	To       string `json:"to,omitempty" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Interval string `json:"interval,omitempty" validate:"omitempty,oneof=minute hour day week month"`
+	Unit     string `json:"unit,omitempty"`
+}
+
+// ReadingQualityResponse reports how well a sensor reported over a time
+// range. ExpectedInterval and gap durations are in seconds. Completeness is
+// the share of expected readings received, in percent; it is null and gaps
+// are empty while the expected interval is unknown. Readings compacted by
+// retention are only kept as rollups, so a range starting before
+// CompactedUntil is reported from CompactedUntil on.
+type ReadingQualityResponse struct {
+	SensorID         string               `json:"sensor_id"`
+	From             string               `json:"from"`
+	To               string               `json:"to"`
+	CompactedUntil   string               `json:"compacted_until,omitempty"`
+	ExpectedInterval float64              `json:"expected_interval"`
+	IntervalSource   string               `json:"interval_source"`
+	ExpectedReadings int64                `json:"expected_readings"`
+	ReceivedReadings int64                `json:"received_readings"`
+	TotalReadings    int64                `json:"total_readings"`
+	Completeness     *float64             `json:"completeness"`
+	Gaps             []ReadingGapResponse `json:"gaps"`
+	Flagged          map[string]int64     `json:"flagged"`
+}
+
+// ReadingGapResponse is a stretch of time in which a sensor reported
+// nothing for longer than expected.
+type ReadingGapResponse struct {
+	From            string  `json:"from"`
+	To              string  `json:"to"`
+	Duration        float64 `json:"duration"`
+	MissingReadings int64   `json:"missing_readings"`
+}
+
+// ReadingQualitySummary counts the readings of a sensor over a time range
+// as returned by the database. Received counts distinct timestamps.
+type ReadingQualitySummary struct {
+	Total      int64
+	Received   int64
+	First      *time.Time
+	Last       *time.Time
+	OutOfRange int64
+	Duplicate  int64
+}
+
+// ReadingGap is a gap between two consecutive readings as returned by the
+// database.
+type ReadingGap struct {
+	From time.Time
+	To   time.Time
+}
+
+// ReadingPartition is a monthly partition of the readings table as found in
+// the database; Month is the first instant of its month in UTC.
+type ReadingPartition struct {
+	Name  string
+	Month time.Time
+}
@@ -0,0 +1,28 @@
+package model
+
+// CreateRetentionPolicyRequest sets how long readings of a sensor type are
+// kept at each resolution, in days, zero keeping them forever. Without a
+// sensor type it sets the default policy. Each resolution must be kept at
+// least as long as the finer one before it.
+type CreateRetentionPolicyRequest struct {
+	SensorType string `json:"sensor_type,omitempty" validate:"omitempty,max=50"`
+	RawDays    int    `json:"raw_days" validate:"min=0,max=36500"`
+	HourlyDays int    `json:"hourly_days" validate:"min=0,max=36500"`
+	DailyDays  int    `json:"daily_days" validate:"min=0,max=36500"`
+}
+
+type UpdateRetentionPolicyRequest struct {
+	RawDays    *int `json:"raw_days,omitempty" validate:"omitempty,min=0,max=36500"`
+	HourlyDays *int `json:"hourly_days,omitempty" validate:"omitempty,min=0,max=36500"`
+	DailyDays  *int `json:"daily_days,omitempty" validate:"omitempty,min=0,max=36500"`
+}
+
+type RetentionPolicyResponse struct {
+	ID         string `json:"id,omitempty"`
+	SensorType string `json:"sensor_type,omitempty"`
+	RawDays    int    `json:"raw_days"`
+	HourlyDays int    `json:"hourly_days"`
+	DailyDays  int    `json:"daily_days"`
+	CreatedAt  string `json:"created_at,omitempty"`
+	UpdatedAt  string `json:"updated_at,omitempty"`
+}
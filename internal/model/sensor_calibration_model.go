@@ -0,0 +1,37 @@
+package model
+
+type SensorCalibrationResponse struct {
+	ID            string      `json:"id,omitempty"`
+	SensorID      string      `json:"sensor_id,omitempty"`
+	Method        string      `json:"method,omitempty"`
+	Offset        *float64    `json:"offset,omitempty"`
+	Gain          *float64    `json:"gain,omitempty"`
+	Polynomial    []float64   `json:"polynomial,omitempty"`
+	Table         [][]float64 `json:"table,omitempty"`
+	EffectiveFrom string      `json:"effective_from,omitempty"`
+	Note          string      `json:"note,omitempty"`
+	CreatedAt     string      `json:"created_at,omitempty"`
+}
+
+// CreateSensorCalibrationRequest adds a calibration version. offset_gain maps
+// raw*gain+offset, polynomial takes coefficients from the constant term up and
+// lookup interpolates linearly between [raw, calibrated] points.
+type CreateSensorCalibrationRequest struct {
+	Method        string      `json:"method" validate:"required,oneof=offset_gain polynomial lookup"`
+	Offset        *float64    `json:"offset,omitempty"`
+	Gain          *float64    `json:"gain,omitempty"`
+	Polynomial    []float64   `json:"polynomial,omitempty" validate:"required_if=Method polynomial,omitempty,max=10"`
+	Table         [][]float64 `json:"table,omitempty" validate:"required_if=Method lookup,omitempty,min=2,dive,len=2"`
+	EffectiveFrom string      `json:"effective_from,omitempty" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Note          string      `json:"note,omitempty" validate:"max=255"`
+}
+
+type ReprocessReadingsRequest struct {
+	From string `json:"from" validate:"required,datetime=2006-01-02T15:04:05Z07:00"`
+	To   string `json:"to,omitempty" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+}
+
+type ReprocessReadingsResponse struct {
+	Reprocessed int64 `json:"reprocessed"`
+	Rederived   int64 `json:"rederived"`
+}
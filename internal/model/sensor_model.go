@@ -1,28 +1,69 @@
 package model
 
+import "mertani_test/internal/utils"
+
 type SensorResponse struct {
-	ID        string `json:"id,omitempty"`
-	DeviceID  string `json:"device_id,omitempty"`
-	DeviceName string `json:"device_name,omitempty"`
-	Name      string `json:"name,omitempty"`
-	Type      string `json:"type,omitempty"`
-	Unit      string `json:"unit,omitempty"`
-	IsActive  bool   `json:"is_active,omitempty"`
-	CreatedAt string `json:"created_at,omitempty"`
-	UpdatedAt string `json:"updated_at,omitempty"`
+	ID            string            `json:"id,omitempty"`
+	DeviceID      string            `json:"device_id,omitempty"`
+	DeviceName    string            `json:"device_name,omitempty"`
+	Name          string            `json:"name,omitempty"`
+	Type          string            `json:"type,omitempty"`
+	Unit          string            `json:"unit,omitempty"`
+	EffectiveUnit string            `json:"effective_unit,omitempty"`
+	IsActive      bool              `json:"is_active,omitempty"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	Kind          string            `json:"kind,omitempty"`
+	Expression    string            `json:"expression,omitempty"`
+	Inputs        map[string]string `json:"inputs,omitempty"`
+	// ExpectedInterval is in seconds; zero means estimated from readings.
+	ExpectedInterval int      `json:"expected_interval"`
+	ValidMin         *float64 `json:"valid_min,omitempty"`
+	ValidMax         *float64 `json:"valid_max,omitempty"`
+	// LastValue is the value of the sensor's latest reading, in
+	// EffectiveUnit when given.
+	LastValue     *float64 `json:"last_value,omitempty"`
+	LastReadingAt string   `json:"last_reading_at,omitempty"`
+	CreatedAt     string   `json:"created_at,omitempty"`
+	UpdatedAt     string   `json:"updated_at,omitempty"`
 }
 
+// CreateSensorRequest of kind virtual computes readings from Expression, whose
+// variables are bound to other sensors through Inputs (variable -> sensor id).
+// ExpectedInterval is in seconds, zero to estimate it from readings.
 type CreateSensorRequest struct {
-	DeviceID string `json:"device_id" validate:"required,uuid"`
-	Name     string `json:"name" validate:"required,max=100"`
-	Type     string `json:"type" validate:"required,max=50"`
-	Unit     string `json:"unit,omitempty"`
-	IsActive *bool  `json:"is_active,omitempty"`
+	DeviceID         string            `json:"device_id" validate:"required,uuid"`
+	Name             string            `json:"name" validate:"required,max=100"`
+	Type             string            `json:"type" validate:"required,max=50"`
+	Unit             string            `json:"unit,omitempty"`
+	IsActive         *bool             `json:"is_active,omitempty"`
+	Labels           map[string]string `json:"labels,omitempty"`
+	Kind             string            `json:"kind,omitempty" validate:"omitempty,oneof=physical virtual"`
+	Expression       string            `json:"expression,omitempty" validate:"required_if=Kind virtual,max=1000"`
+	Inputs           map[string]string `json:"inputs,omitempty" validate:"required_if=Kind virtual,dive,keys,required,max=50,endkeys,required,uuid"`
+	ExpectedInterval int               `json:"expected_interval,omitempty" validate:"omitempty,min=1,max=2592000"`
+	ValidMin         *float64          `json:"valid_min,omitempty"`
+	ValidMax         *float64          `json:"valid_max,omitempty"`
 }
 
+// UpdateSensorRequest accepts Expression and Inputs for virtual sensors only.
+// An ExpectedInterval of zero goes back to estimating it; ClearValidRange
+// removes the operating range.
 type UpdateSensorRequest struct {
-	Name     *string `json:"name,omitempty" validate:"omitempty,max=100"`
-	Type     *string `json:"type,omitempty" validate:"omitempty,max=50"`
-	Unit     *string `json:"unit,omitempty"`
-	IsActive *bool   `json:"is_active,omitempty"`
-}
\ No newline at end of file
+	Name             *string           `json:"name,omitempty" validate:"omitempty,max=100"`
+	Type             *string           `json:"type,omitempty" validate:"omitempty,max=50"`
+	Unit             *string           `json:"unit,omitempty"`
+	IsActive         *bool             `json:"is_active,omitempty"`
+	Expression       *string           `json:"expression,omitempty" validate:"omitempty,max=1000"`
+	Inputs           map[string]string `json:"inputs,omitempty" validate:"omitempty,dive,keys,required,max=50,endkeys,required,uuid"`
+	ExpectedInterval *int              `json:"expected_interval,omitempty" validate:"omitempty,min=0,max=2592000"`
+	ValidMin         *float64          `json:"valid_min,omitempty"`
+	ValidMax         *float64          `json:"valid_max,omitempty"`
+	ClearValidRange  bool              `json:"clear_valid_range,omitempty"`
+}
+
+// SensorFilter narrows sensor list queries.
+type SensorFilter struct {
+	Selector utils.LabelSelector
+	// Unit requests conversion of each sensor that has a compatible unit.
+	Unit string
+}
@@ -0,0 +1,38 @@
+package model
+
+type SensorTypeResponse struct {
+	ID            string   `json:"id,omitempty"`
+	Code          string   `json:"code,omitempty"`
+	DisplayName   string   `json:"display_name,omitempty"`
+	CanonicalUnit string   `json:"canonical_unit,omitempty"`
+	AllowedUnits  []string `json:"allowed_units,omitempty"`
+	PhysicalMin   *float64 `json:"physical_min,omitempty"`
+	PhysicalMax   *float64 `json:"physical_max,omitempty"`
+	Precision     int      `json:"precision"`
+	CreatedAt     string   `json:"created_at,omitempty"`
+	UpdatedAt     string   `json:"updated_at,omitempty"`
+}
+
+type CreateSensorTypeRequest struct {
+	Code          string   `json:"code" validate:"required,max=50,lowercase"`
+	DisplayName   string   `json:"display_name" validate:"required,max=100"`
+	CanonicalUnit string   `json:"canonical_unit" validate:"required,max=20"`
+	AllowedUnits  []string `json:"allowed_units,omitempty" validate:"omitempty,dive,required,max=20"`
+	PhysicalMin   *float64 `json:"physical_min,omitempty"`
+	PhysicalMax   *float64 `json:"physical_max,omitempty"`
+	Precision     *int     `json:"precision,omitempty" validate:"omitempty,min=0,max=10"`
+}
+
+// UpdateSensorTypeRequest changes the fields that are set. A physical bound
+// is removed with its clear flag, since null cannot be told apart from an
+// absent field.
+type UpdateSensorTypeRequest struct {
+	DisplayName      *string   `json:"display_name,omitempty" validate:"omitempty,max=100"`
+	CanonicalUnit    *string   `json:"canonical_unit,omitempty" validate:"omitempty,max=20"`
+	AllowedUnits     *[]string `json:"allowed_units,omitempty" validate:"omitempty,dive,required,max=20"`
+	PhysicalMin      *float64  `json:"physical_min,omitempty"`
+	PhysicalMax      *float64  `json:"physical_max,omitempty"`
+	ClearPhysicalMin bool      `json:"clear_physical_min,omitempty" validate:"excluded_with=PhysicalMin"`
+	ClearPhysicalMax bool      `json:"clear_physical_max,omitempty" validate:"excluded_with=PhysicalMax"`
+	Precision        *int      `json:"precision,omitempty" validate:"omitempty,min=0,max=10"`
+}
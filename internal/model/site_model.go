@@ -0,0 +1,62 @@
+package model
+
+import "time"
+
+type SiteResponse struct {
+	ID        string         `json:"id,omitempty"`
+	ParentID  string         `json:"parent_id,omitempty"`
+	Name      string         `json:"name,omitempty"`
+	Kind      string         `json:"kind,omitempty"`
+	Children  []SiteResponse `json:"children,omitempty"`
+	CreatedAt string         `json:"created_at,omitempty"`
+	UpdatedAt string         `json:"updated_at,omitempty"`
+}
+
+type CreateSiteRequest struct {
+	ParentID string `json:"parent_id,omitempty" validate:"omitempty,uuid"`
+	Name     string `json:"name" validate:"required,max=100"`
+	Kind     string `json:"kind" validate:"required,oneof=site field plot zone"`
+}
+
+// UpdateSiteRequest moves a node when ParentID is set; an empty ParentID
+// turns the node into a root.
+type UpdateSiteRequest struct {
+	ParentID *string `json:"parent_id,omitempty" validate:"omitempty,uuid|eq="`
+	Name     *string `json:"name,omitempty" validate:"omitempty,max=100"`
+	Kind     *string `json:"kind,omitempty" validate:"omitempty,oneof=site field plot zone"`
+}
+
+// MoveDevicesRequest lists the devices attached to or detached from a site;
+// repeated ids count once.
+type MoveDevicesRequest struct {
+	DeviceIDs []string `json:"device_ids" validate:"required,min=1,dive,uuid"`
+}
+
+type SensorTypeStatsResponse struct {
+	Type              string   `json:"type"`
+	Unit              string   `json:"unit,omitempty"`
+	SensorCount       int64    `json:"sensor_count"`
+	ActiveSensorCount int64    `json:"active_sensor_count"`
+	ReadingCount      int64    `json:"reading_count"`
+	MinValue          *float64 `json:"min_value,omitempty"`
+	MaxValue          *float64 `json:"max_value,omitempty"`
+	AvgValue          *float64 `json:"avg_value,omitempty"`
+	LastReadingAt     *string  `json:"last_reading_at,omitempty"`
+}
+
+type SiteStatsResponse struct {
+	SiteID            string                    `json:"site_id"`
+	Recursive         bool                      `json:"recursive"`
+	SiteCount         int                       `json:"site_count"`
+	DeviceCount       int64                     `json:"device_count"`
+	SensorCount       int64                     `json:"sensor_count"`
+	ActiveSensorCount int64                     `json:"active_sensor_count"`
+	SensorTypes       []SensorTypeStatsResponse `json:"sensor_types"`
+}
+
+// SiteStatsFilter bounds the readings included in site statistics.
+type SiteStatsFilter struct {
+	Recursive bool
+	From      *time.Time
+	To        *time.Time
+}
@@ -0,0 +1,28 @@
+package model
+
+// StreamSubscribeRequest selects the live events a client receives. Types
+// narrows by event type; devices, sensors and the label selector are
+// alternatives and none of them means everything.
+type StreamSubscribeRequest struct {
+	Action    string   `json:"action,omitempty"`
+	Types     []string `json:"types,omitempty" validate:"omitempty,dive,oneof=reading alert alert_escalated anomaly device_status device_created device_updated device_deleted sensor_created sensor_updated sensor_deleted sensor_activated sensor_deactivated"`
+	DeviceIDs []string `json:"device_ids,omitempty" validate:"omitempty,dive,uuid"`
+	SensorIDs []string `json:"sensor_ids,omitempty" validate:"omitempty,dive,uuid"`
+	Selector  string   `json:"selector,omitempty"`
+}
+
+// DeviceStatusEvent is the payload of a device_status stream event.
+type DeviceStatusEvent struct {
+	DeviceID       string `json:"device_id"`
+	Name           string `json:"name"`
+	Status         string `json:"status"`
+	PreviousStatus string `json:"previous_status"`
+}
+
+// StreamNotice is sent to a client about its own stream rather than data,
+// such as a confirmed subscription or events dropped because it lagged.
+type StreamNotice struct {
+	Type    string `json:"type"`
+	Message string `json:"message,omitempty"`
+	Dropped int64  `json:"dropped,omitempty"`
+}
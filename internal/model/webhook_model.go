@@ -0,0 +1,58 @@
+package model
+
+import "encoding/json"
+
+type CreateWebhookRequest struct {
+	Name       string   `json:"name" validate:"required,max=100"`
+	URL        string   `json:"url" validate:"required,http_url,max=500"`
+	EventTypes []string `json:"event_types" validate:"required,min=1,dive,oneof=reading alert alert_escalated anomaly device_status device_created device_updated device_deleted sensor_created sensor_updated sensor_deleted sensor_activated sensor_deactivated"`
+	Secret     string   `json:"secret,omitempty" validate:"omitempty,min=16,max=100"`
+	IsActive   *bool    `json:"is_active,omitempty"`
+}
+
+type UpdateWebhookRequest struct {
+	Name       *string  `json:"name,omitempty" validate:"omitempty,max=100"`
+	URL        *string  `json:"url,omitempty" validate:"omitempty,http_url,max=500"`
+	EventTypes []string `json:"event_types,omitempty" validate:"omitempty,min=1,dive,oneof=reading alert alert_escalated anomaly device_status device_created device_updated device_deleted sensor_created sensor_updated sensor_deleted sensor_activated sensor_deactivated"`
+	Secret     *string  `json:"secret,omitempty" validate:"omitempty,min=16,max=100"`
+	IsActive   *bool    `json:"is_active,omitempty"`
+}
+
+// WebhookResponse carries the secret only when the webhook is created.
+type WebhookResponse struct {
+	ID         string   `json:"id,omitempty"`
+	Name       string   `json:"name,omitempty"`
+	URL        string   `json:"url,omitempty"`
+	EventTypes []string `json:"event_types"`
+	Secret     string   `json:"secret,omitempty"`
+	IsActive   bool     `json:"is_active"`
+	CreatedAt  string   `json:"created_at,omitempty"`
+	UpdatedAt  string   `json:"updated_at,omitempty"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             string          `json:"id,omitempty"`
+	WebhookID      string          `json:"webhook_id,omitempty"`
+	EventID        string          `json:"event_id,omitempty"`
+	EventType      string          `json:"event_type,omitempty"`
+	Status         string          `json:"status,omitempty"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  string          `json:"next_attempt_at,omitempty"`
+	LastStatusCode int             `json:"last_status_code,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	LastResponse   string          `json:"last_response,omitempty"`
+	LastDurationMs int64           `json:"last_duration_ms,omitempty"`
+	RedeliveryOf   string          `json:"redelivery_of,omitempty"`
+	DeliveredAt    string          `json:"delivered_at,omitempty"`
+	Payload        json.RawMessage `json:"payload,omitempty" swaggertype:"object"`
+	CreatedAt      string          `json:"created_at,omitempty"`
+	UpdatedAt      string          `json:"updated_at,omitempty"`
+}
+
+// WebhookPayload is the JSON body POSTed to webhooks.
+type WebhookPayload struct {
+	ID         string `json:"id"`
+	Type       string `json:"type"`
+	OccurredAt string `json:"occurred_at"`
+	Data       any    `json:"data"`
+}
@@ -0,0 +1,123 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EmailChannel sends plain text mail over SMTP. STARTTLS is used when the
+// server offers it, and required when StartTLS is set; credentials are only
+// sent when a username is configured.
+type EmailChannel struct {
+	Host     string        `json:"host"`
+	Port     int           `json:"port"`
+	Username string        `json:"username,omitempty"`
+	Password string        `json:"password,omitempty"`
+	From     string        `json:"from"`
+	To       []string      `json:"to"`
+	StartTLS bool          `json:"starttls,omitempty"`
+	Timeout  time.Duration `json:"-"`
+}
+
+func (c *EmailChannel) validate() error {
+	switch {
+	case c.Host == "":
+		return errors.New("email channel needs a host")
+	case c.Port <= 0 || c.Port > 65535:
+		return errors.New("email channel port must be between 1 and 65535")
+	case c.From == "":
+		return errors.New("email channel needs a from address")
+	case len(c.To) == 0:
+		return errors.New("email channel needs at least one recipient")
+	}
+	for _, address := range append([]string{c.From}, c.To...) {
+		if strings.ContainsAny(address, "\r\n") || !strings.Contains(address, "@") {
+			return fmt.Errorf("invalid email address %q", address)
+		}
+	}
+	return nil
+}
+
+func (c *EmailChannel) Send(ctx context.Context, message *Message) error {
+	ctx, cancel := deadline(ctx, c.Timeout)
+	defer cancel()
+
+	address := net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, c.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: c.Host}); err != nil {
+			return err
+		}
+	} else if c.StartTLS {
+		return errors.New("smtp server does not support STARTTLS")
+	}
+
+	if c.Username != "" {
+		// PlainAuth refuses to send credentials unencrypted except to localhost
+		if err := client.Auth(smtp.PlainAuth("", c.Username, c.Password, c.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(c.From); err != nil {
+		return err
+	}
+	for _, to := range c.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(c.compose(message)); err != nil {
+		writer.Close()
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// compose builds the RFC 5322 message; the subject is Q-encoded so that
+// non-ASCII device names survive.
+func (c *EmailChannel) compose(message *Message) []byte {
+	var b bytes.Buffer
+	b.WriteString("From: " + c.From + "\r\n")
+	b.WriteString("To: " + strings.Join(c.To, ", ") + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", strings.ReplaceAll(message.Subject, "\n", " ")) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(message.Body, "\r\n", "\n"), "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return b.Bytes()
+}
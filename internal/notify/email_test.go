@@ -0,0 +1,250 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"net"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// smtpSession is what a fake SMTP server received from one client.
+type smtpSession struct {
+	auth string
+	from string
+	to   []string
+	data string
+}
+
+// fakeSMTP serves one SMTP session on loopback and reports it once the
+// client quits. Extensions are advertised in the EHLO reply.
+func fakeSMTP(t *testing.T, extensions ...string) (string, int, <-chan smtpSession) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	sessions := make(chan smtpSession, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+		text := textproto.NewConn(conn)
+		session := smtpSession{}
+		text.PrintfLine("220 localhost ESMTP fake")
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				return
+			}
+			verb, arg, _ := strings.Cut(line, " ")
+			switch strings.ToUpper(verb) {
+			case "EHLO", "HELO":
+				lines := append([]string{"localhost"}, extensions...)
+				for i, ext := range lines {
+					separator := "-"
+					if i == len(lines)-1 {
+						separator = " "
+					}
+					text.PrintfLine("250%s%s", separator, ext)
+				}
+			case "AUTH":
+				session.auth = arg
+				text.PrintfLine("235 2.7.0 authenticated")
+			case "MAIL":
+				session.from = smtpPath(arg)
+				text.PrintfLine("250 2.1.0 ok")
+			case "RCPT":
+				session.to = append(session.to, smtpPath(arg))
+				text.PrintfLine("250 2.1.5 ok")
+			case "DATA":
+				text.PrintfLine("354 go ahead")
+				data, err := text.ReadDotBytes()
+				if err != nil {
+					return
+				}
+				session.data = string(data)
+				text.PrintfLine("250 2.0.0 queued")
+			case "QUIT":
+				text.PrintfLine("221 2.0.0 bye")
+				sessions <- session
+				return
+			default:
+				text.PrintfLine("502 5.5.2 unknown command")
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+	return host, portNumber, sessions
+}
+
+// smtpPath returns the address of a MAIL FROM or RCPT TO argument, without
+// the parameters following it.
+func smtpPath(arg string) string {
+	_, path, _ := strings.Cut(arg, "<")
+	path, _, _ = strings.Cut(path, ">")
+	return path
+}
+
+func newEmailChannel(t *testing.T, config map[string]any) Channel {
+	t.Helper()
+
+	channel, err := New(TypeEmail, config, &http.Client{Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return channel
+}
+
+func TestEmailSend(t *testing.T) {
+	host, port, sessions := fakeSMTP(t, "8BITMIME", "AUTH PLAIN")
+	channel := newEmailChannel(t, map[string]any{
+		"host":     host,
+		"port":     port,
+		"username": "alerts",
+		"password": "secret",
+		"from":     "alerts@example.com",
+		"to":       []string{"ops@example.com", "oncall@example.com"},
+	})
+
+	err := channel.Send(context.Background(), &Message{
+		Subject: "[warning] Suhu tinggi di Kebun Bawah",
+		Body:    "Sensor temp-1 read 41.2 °C\nsince 22:00",
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	session := <-sessions
+	credentials, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(session.auth, "PLAIN "))
+	if string(credentials) != "\x00alerts\x00secret" {
+		t.Fatalf("auth = %q", credentials)
+	}
+	if session.from != "alerts@example.com" {
+		t.Fatalf("from = %q", session.from)
+	}
+	if strings.Join(session.to, ",") != "ops@example.com,oncall@example.com" {
+		t.Fatalf("to = %v", session.to)
+	}
+
+	header, body, _ := strings.Cut(session.data, "\n\n")
+	if !strings.Contains(header, "Subject: [warning] Suhu tinggi di Kebun Bawah") {
+		t.Fatalf("header = %q", header)
+	}
+	if !strings.Contains(header, "Content-Type: text/plain; charset=utf-8") {
+		t.Fatalf("header = %q", header)
+	}
+	if body != "Sensor temp-1 read 41.2 °C\nsince 22:00\n" {
+		t.Fatalf("body = %q", body)
+	}
+}
+
+func TestEmailSendEncodesSubject(t *testing.T) {
+	host, port, sessions := fakeSMTP(t)
+	channel := newEmailChannel(t, map[string]any{
+		"host": host,
+		"port": port,
+		"from": "alerts@example.com",
+		"to":   []string{"ops@example.com"},
+	})
+
+	if err := channel.Send(context.Background(), &Message{Subject: "Kelembapan °C\nsecond line", Body: "x"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	session := <-sessions
+	if session.auth != "" {
+		t.Fatalf("credentials sent without a username: %q", session.auth)
+	}
+	if !strings.Contains(session.data, "Subject: =?utf-8?q?Kelembapan_=C2=B0C_second_line?=") {
+		t.Fatalf("data = %q", session.data)
+	}
+}
+
+func TestEmailSendRequiresStartTLS(t *testing.T) {
+	host, port, _ := fakeSMTP(t)
+	channel := newEmailChannel(t, map[string]any{
+		"host":     host,
+		"port":     port,
+		"from":     "alerts@example.com",
+		"to":       []string{"ops@example.com"},
+		"starttls": true,
+	})
+
+	err := channel.Send(context.Background(), &Message{Subject: "s", Body: "b"})
+	if err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Fatalf("err = %v, want STARTTLS refusal", err)
+	}
+}
+
+func TestEmailSendRejectedRecipient(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		conn.Write([]byte("220 localhost ESMTP fake\r\n"))
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch {
+			case strings.HasPrefix(line, "EHLO"):
+				conn.Write([]byte("250 localhost\r\n"))
+			case strings.HasPrefix(line, "RCPT"):
+				conn.Write([]byte("550 5.1.1 no such user\r\n"))
+			default:
+				conn.Write([]byte("250 ok\r\n"))
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+	channel := newEmailChannel(t, map[string]any{
+		"host": host,
+		"port": portNumber,
+		"from": "alerts@example.com",
+		"to":   []string{"nobody@example.com"},
+	})
+
+	err = channel.Send(context.Background(), &Message{Subject: "s", Body: "b"})
+	if err == nil || !strings.Contains(err.Error(), "no such user") {
+		t.Fatalf("err = %v, want the rejection", err)
+	}
+}
+
+func TestEmailConfigValidation(t *testing.T) {
+	for name, config := range map[string]map[string]any{
+		"no host":      {"from": "a@example.com", "to": []string{"b@example.com"}},
+		"bad port":     {"host": "mail", "port": 70000, "from": "a@example.com", "to": []string{"b@example.com"}},
+		"no recipient": {"host": "mail", "from": "a@example.com"},
+		"header break": {"host": "mail", "from": "a@example.com\r\nBcc: x@example.com", "to": []string{"b@example.com"}},
+	} {
+		if _, err := New(TypeEmail, config, &http.Client{}); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
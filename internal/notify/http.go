@@ -0,0 +1,142 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultChatAPIURL is the Telegram Bot API; other bots exposing the same
+// sendMessage method, or a local fake, are configured with api_url.
+const DefaultChatAPIURL = "https://api.telegram.org"
+
+// responseLimit bounds the part of a response body quoted in errors.
+const responseLimit = 300
+
+// ChatChannel posts the message to a chat through a bot's sendMessage
+// method: POST {api_url}/bot{token}/sendMessage.
+type ChatChannel struct {
+	APIURL string `json:"api_url,omitempty"`
+	Token  string `json:"token"`
+	ChatID string `json:"chat_id"`
+	client *http.Client
+}
+
+func (c *ChatChannel) validate() error {
+	switch {
+	case c.Token == "":
+		return errors.New("chat channel needs a bot token")
+	case c.ChatID == "":
+		return errors.New("chat channel needs a chat_id")
+	}
+	return validateURL(c.APIURL)
+}
+
+func (c *ChatChannel) Send(ctx context.Context, message *Message) error {
+	text := message.Body
+	if message.Subject != "" {
+		text = message.Subject + "\n\n" + text
+	}
+	body, err := json.Marshal(map[string]any{
+		"chat_id":                  c.ChatID,
+		"text":                     text,
+		"disable_web_page_preview": true,
+	})
+	if err != nil {
+		return err
+	}
+
+	token := url.PathEscape(c.Token)
+	answer, err := postJSON(ctx, c.client, strings.TrimRight(c.APIURL, "/")+"/bot"+token+"/sendMessage", nil, body)
+	if err != nil {
+		// the token is part of the URL, keep it out of the delivery log
+		return errors.New(strings.ReplaceAll(strings.ReplaceAll(err.Error(), token, "***"), c.Token, "***"))
+	}
+
+	var result struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	if err := json.Unmarshal(answer, &result); err != nil {
+		return fmt.Errorf("unreadable chat api answer: %w", err)
+	}
+	if !result.OK {
+		return fmt.Errorf("chat api refused the message: %s", result.Description)
+	}
+	return nil
+}
+
+// WebhookChannel POSTs the message and its alert as JSON to a URL, with
+// optional extra headers such as an authorization token.
+type WebhookChannel struct {
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	client  *http.Client
+}
+
+// WebhookMessage is the JSON body sent by a webhook channel.
+type WebhookMessage struct {
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+	Alert   any    `json:"alert,omitempty"`
+}
+
+func (c *WebhookChannel) validate() error {
+	if c.URL == "" {
+		return errors.New("webhook channel needs a url")
+	}
+	return validateURL(c.URL)
+}
+
+func (c *WebhookChannel) Send(ctx context.Context, message *Message) error {
+	body, err := json.Marshal(&WebhookMessage{Subject: message.Subject, Body: message.Body, Alert: message.Data})
+	if err != nil {
+		return err
+	}
+	_, err = postJSON(ctx, c.client, c.URL, c.Headers, body)
+	return err
+}
+
+// postJSON returns the start of the response body of a 2xx answer; any
+// other status is an error quoting it.
+func postJSON(ctx context.Context, client *http.Client, endpoint string, headers map[string]string, body []byte) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "mertani_test-notify")
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	answer, _ := io.ReadAll(io.LimitReader(response.Body, 64*1024))
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		snippet := answer
+		if len(snippet) > responseLimit {
+			snippet = snippet[:responseLimit]
+		}
+		return nil, fmt.Errorf("receiver answered %d: %s", response.StatusCode, strings.ToValidUTF8(string(snippet), ""))
+	}
+	return answer, nil
+}
+
+func validateURL(value string) error {
+	parsed, err := url.Parse(value)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("invalid url %q", value)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestChatSend(t *testing.T) {
+	var got struct {
+		path string
+		body map[string]any
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got.path = r.URL.EscapedPath()
+		json.NewDecoder(r.Body).Decode(&got.body)
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	}))
+	defer server.Close()
+
+	channel, err := New(TypeChat, map[string]any{
+		"api_url": server.URL + "/",
+		"token":   "123:ABC",
+		"chat_id": "-1001",
+	}, server.Client())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if err := channel.Send(context.Background(), &Message{Subject: "[critical] Pump offline", Body: "since 03:12"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if got.path != "/bot123:ABC/sendMessage" {
+		t.Fatalf("path = %q", got.path)
+	}
+	if got.body["chat_id"] != "-1001" || got.body["text"] != "[critical] Pump offline\n\nsince 03:12" {
+		t.Fatalf("body = %v", got.body)
+	}
+}
+
+func TestChatSendRefused(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":false,"description":"Bad Request: chat not found"}`))
+	}))
+	defer server.Close()
+
+	channel, err := New(TypeChat, map[string]any{"api_url": server.URL, "token": "t", "chat_id": "1"}, server.Client())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	err = channel.Send(context.Background(), &Message{Body: "x"})
+	if err == nil || !strings.Contains(err.Error(), "chat not found") {
+		t.Fatalf("err = %v, want the refusal", err)
+	}
+}
+
+func TestChatSendHidesToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	url := server.URL
+	client := server.Client()
+	// nothing listens anymore, so the client fails with the URL in its error
+	server.Close()
+
+	channel, err := New(TypeChat, map[string]any{"api_url": url, "token": "123:SECRET", "chat_id": "1"}, client)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	err = channel.Send(context.Background(), &Message{Body: "x"})
+	if err == nil {
+		t.Fatal("Send succeeded without a server")
+	}
+	if strings.Contains(err.Error(), "SECRET") || !strings.Contains(err.Error(), "***") {
+		t.Fatalf("err = %v, want the token masked", err)
+	}
+}
+
+func TestWebhookSend(t *testing.T) {
+	var (
+		header http.Header
+		body   WebhookMessage
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	channel, err := New(TypeWebhook, map[string]any{
+		"url":     server.URL + "/hooks/alerts",
+		"headers": map[string]string{"Authorization": "Bearer abc"},
+	}, server.Client())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	alert := json.RawMessage(`{"id":"a1","severity":"warning"}`)
+	if err := channel.Send(context.Background(), &Message{Subject: "s", Body: "b", Data: alert}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if header.Get("Authorization") != "Bearer abc" || header.Get("Content-Type") != "application/json" {
+		t.Fatalf("headers = %v", header)
+	}
+	if body.Subject != "s" || body.Body != "b" {
+		t.Fatalf("body = %+v", body)
+	}
+	if alert, _ := json.Marshal(body.Alert); string(alert) != `{"id":"a1","severity":"warning"}` {
+		t.Fatalf("alert = %s", alert)
+	}
+}
+
+func TestWebhookSendFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		http.Error(w, strings.Repeat("overloaded ", 100), http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	channel, err := New(TypeWebhook, map[string]any{"url": server.URL}, server.Client())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	err = channel.Send(context.Background(), &Message{Subject: "s", Body: "b"})
+	if err == nil || !strings.Contains(err.Error(), "receiver answered 503: overloaded") {
+		t.Fatalf("err = %v, want the 503", err)
+	}
+	if len(err.Error()) > responseLimit+100 {
+		t.Fatalf("error quotes %d bytes of the response", len(err.Error()))
+	}
+}
+
+func TestHTTPConfigValidation(t *testing.T) {
+	for name, test := range map[string]struct {
+		kind   string
+		config map[string]any
+	}{
+		"chat without token":   {TypeChat, map[string]any{"chat_id": "1"}},
+		"chat with bad api":    {TypeChat, map[string]any{"token": "t", "chat_id": "1", "api_url": "ftp://bot"}},
+		"webhook without url":  {TypeWebhook, map[string]any{}},
+		"webhook with bad url": {TypeWebhook, map[string]any{"url": "localhost:8080"}},
+	} {
+		if _, err := New(test.kind, test.config, &http.Client{}); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
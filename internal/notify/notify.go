@@ -0,0 +1,83 @@
+// Package notify sends alert notifications to people over a channel: SMTP
+// email, a chat bot HTTP API in the style of Telegram's, or a generic JSON
+// webhook. Every channel takes its endpoint from its configuration, so it
+// can be pointed at a local fake server.
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Channel types.
+const (
+	TypeEmail   = "email"
+	TypeChat    = "chat"
+	TypeWebhook = "webhook"
+)
+
+// Types lists the supported channel types.
+var Types = []string{TypeEmail, TypeChat, TypeWebhook}
+
+// Message is a rendered notification. Data is the alert it is about, sent
+// as is by channels that carry structured payloads.
+type Message struct {
+	Subject string
+	Body    string
+	Data    any
+}
+
+type Channel interface {
+	Send(ctx context.Context, message *Message) error
+}
+
+// New builds a channel of the given type from its stored configuration and
+// checks the configuration is complete. HTTP channels use client.
+func New(kind string, config map[string]any, client *http.Client) (Channel, error) {
+	switch kind {
+	case TypeEmail:
+		email := &EmailChannel{Port: 25, Timeout: client.Timeout}
+		if err := decodeConfig(config, email); err != nil {
+			return nil, err
+		}
+		return email, email.validate()
+	case TypeChat:
+		chat := &ChatChannel{APIURL: DefaultChatAPIURL, client: client}
+		if err := decodeConfig(config, chat); err != nil {
+			return nil, err
+		}
+		return chat, chat.validate()
+	case TypeWebhook:
+		webhook := &WebhookChannel{client: client}
+		if err := decodeConfig(config, webhook); err != nil {
+			return nil, err
+		}
+		return webhook, webhook.validate()
+	default:
+		return nil, fmt.Errorf("unknown channel type %q", kind)
+	}
+}
+
+// decodeConfig reads a stored configuration into a channel through JSON,
+// keeping the defaults of fields it does not set.
+func decodeConfig(config map[string]any, channel any) error {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, channel); err != nil {
+		return fmt.Errorf("invalid channel config: %w", err)
+	}
+	return nil
+}
+
+// deadline bounds an attempt by timeout unless ctx ends sooner.
+func deadline(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
@@ -0,0 +1,85 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"mertani_test/internal/model"
+	"strings"
+	"text/template"
+)
+
+// Default templates, used when a rule leaves its own empty.
+const (
+	DefaultSubjectTemplate = `[{{upper .Severity}}] {{if .Escalation}}Escalated: {{end}}{{.Title}}{{if eq .Status "resolved"}} (resolved){{end}}`
+	DefaultBodyTemplate    = `{{.Title}}
+{{with .Message}}{{.}}
+{{end}}
+Severity: {{.Severity}}
+Status: {{.Status}}
+{{with .DeviceName}}Device: {{.}}
+{{end}}{{with .SensorName}}Sensor: {{.}}
+{{end}}{{with .Value}}Value: {{.}}{{with $.Unit}} {{.}}{{end}}
+{{end}}Since: {{.StartsAt}}
+{{if .Repeats}}Repeated {{.Repeats}} times since the last notification.
+{{end}}{{if .Escalation}}Not acknowledged, escalated by {{.Rule}} (step {{.Escalation}}).
+{{end}}`
+)
+
+// TemplateData is what subject and body templates are executed with: the
+// alert's fields, the rule that routed it or the escalation policy that
+// escalated it, how many repeats of it were held back since the last
+// notification, and the escalation step, zero unless escalated.
+type TemplateData struct {
+	model.AlertEvent
+	Rule       string
+	Repeats    int
+	Escalation int
+}
+
+var templateFuncs = template.FuncMap{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"label": func(labels map[string]string, key string) string { return labels[key] },
+}
+
+// Template is a parsed subject and body pair.
+type Template struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+// ParseTemplate parses the templates of a rule, falling back to the
+// defaults for empty ones.
+func ParseTemplate(subject string, body string) (*Template, error) {
+	if subject == "" {
+		subject = DefaultSubjectTemplate
+	}
+	if body == "" {
+		body = DefaultBodyTemplate
+	}
+
+	parsedSubject, err := template.New("subject").Funcs(templateFuncs).Option("missingkey=zero").Parse(subject)
+	if err != nil {
+		return nil, fmt.Errorf("subject template: %w", err)
+	}
+	parsedBody, err := template.New("body").Funcs(templateFuncs).Option("missingkey=zero").Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("body template: %w", err)
+	}
+	return &Template{subject: parsedSubject, body: parsedBody}, nil
+}
+
+func (t *Template) Render(data *TemplateData) (*Message, error) {
+	var subject, body bytes.Buffer
+	if err := t.subject.Execute(&subject, data); err != nil {
+		return nil, fmt.Errorf("subject template: %w", err)
+	}
+	if err := t.body.Execute(&body, data); err != nil {
+		return nil, fmt.Errorf("body template: %w", err)
+	}
+	return &Message{
+		Subject: strings.TrimSpace(subject.String()),
+		Body:    strings.TrimSpace(body.String()),
+		Data:    &data.AlertEvent,
+	}, nil
+}
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"mertani_test/internal/entity"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type AlertRepository struct {
+	Repository[entity.Alert]
+	Log *logrus.Logger
+}
+
+func NewAlertRepository(log *logrus.Logger) *AlertRepository {
+	return &AlertRepository{
+		Log: log,
+	}
+}
+
+func (r *AlertRepository) FindByIdWithComments(db *gorm.DB, alert *entity.Alert, id any) (*entity.Alert, error) {
+	err := db.Preload("Comments", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at")
+	}).First(alert, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return alert, nil
+}
+
+// FindFiringByFingerprint returns the firing alert with the fingerprint, or
+// nil when none fires.
+func (r *AlertRepository) FindFiringByFingerprint(db *gorm.DB, fingerprint string) (*entity.Alert, error) {
+	var alerts []entity.Alert
+	err := db.Where("fingerprint = ? AND status = ?", fingerprint, entity.AlertStatusFiring).Limit(1).Find(&alerts).Error
+	if err != nil || len(alerts) == 0 {
+		return nil, err
+	}
+	return &alerts[0], nil
+}
+
+func (r *AlertRepository) FindFiringUnsilenced(db *gorm.DB) ([]entity.Alert, error) {
+	var alerts []entity.Alert
+	err := db.Where("status = ? AND silence_id IS NULL", entity.AlertStatusFiring).Find(&alerts).Error
+	return alerts, err
+}
+
+// FindSilenceEnded returns the firing alerts whose silence has ended or was
+// deleted.
+func (r *AlertRepository) FindSilenceEnded(db *gorm.DB, now time.Time) ([]entity.Alert, error) {
+	var alerts []entity.Alert
+	err := db.Where("status = ? AND silence_id IS NOT NULL", entity.AlertStatusFiring).
+		Where("NOT EXISTS (SELECT 1 FROM silences WHERE silences.id = alerts.silence_id AND silences.ends_at > ?)", now).
+		Find(&alerts).Error
+	return alerts, err
+}
+
+// FindDueEscalations returns firing alerts nobody acknowledged whose next
+// escalation step is due.
+func (r *AlertRepository) FindDueEscalations(db *gorm.DB, now time.Time, limit int) ([]entity.Alert, error) {
+	var alerts []entity.Alert
+	err := db.Where("status = ? AND acknowledged_at IS NULL AND silence_id IS NULL AND next_escalation_at <= ?", entity.AlertStatusFiring, now).
+		Order("next_escalation_at").
+		Limit(limit).
+		Find(&alerts).Error
+	return alerts, err
+}
+
+// Silence mutes a firing alert that is not muted yet and reports whether it
+// did; another instance may have got there first.
+func (r *AlertRepository) Silence(db *gorm.DB, id any, silenceID any) (bool, error) {
+	result := db.Model(&entity.Alert{}).
+		Where("id = ? AND status = ? AND silence_id IS NULL", id, entity.AlertStatusFiring).
+		Updates(map[string]any{"silence_id": silenceID, "next_escalation_at": nil})
+	return result.RowsAffected == 1, result.Error
+}
+
+// Unsilence lifts the silence of a firing alert, restarting its escalation
+// at nextEscalationAt, and reports whether it did.
+func (r *AlertRepository) Unsilence(db *gorm.DB, id any, silenceID any, nextEscalationAt *time.Time) (bool, error) {
+	result := db.Model(&entity.Alert{}).
+		Where("id = ? AND status = ? AND silence_id = ?", id, entity.AlertStatusFiring, silenceID).
+		Updates(map[string]any{"silence_id": nil, "escalation_level": 0, "next_escalation_at": nextEscalationAt})
+	return result.RowsAffected == 1, result.Error
+}
+
+// MoveSilence hands a firing alert over from an ended silence to another
+// active one.
+func (r *AlertRepository) MoveSilence(db *gorm.DB, id any, from any, to any) error {
+	return db.Model(&entity.Alert{}).
+		Where("id = ? AND silence_id = ?", id, from).
+		Update("silence_id", to).Error
+}
+
+// StopEscalation leaves an alert at its current escalation level.
+func (r *AlertRepository) StopEscalation(db *gorm.DB, id any) error {
+	return db.Model(&entity.Alert{}).Where("id = ?", id).Update("next_escalation_at", nil).Error
+}
+
+// Escalate moves an alert still waiting at level to the next one and
+// reports whether it did.
+func (r *AlertRepository) Escalate(db *gorm.DB, id any, level int, nextEscalationAt *time.Time) (bool, error) {
+	result := db.Model(&entity.Alert{}).
+		Where("id = ? AND status = ? AND acknowledged_at IS NULL AND silence_id IS NULL AND escalation_level = ?",
+			id, entity.AlertStatusFiring, level).
+		Updates(map[string]any{"escalation_level": level + 1, "next_escalation_at": nextEscalationAt})
+	return result.RowsAffected == 1, result.Error
+}
+
+// ByAlertFilter narrows alerts by status, severity, acknowledgement, device
+// and sensor; empty values do not filter.
+func ByAlertFilter(status string, severity string, acknowledged *bool, deviceID string, sensorID string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if status != "" {
+			db = db.Where("status = ?", status)
+		}
+		if severity != "" {
+			db = db.Where("severity = ?", severity)
+		}
+		if acknowledged != nil && *acknowledged {
+			db = db.Where("acknowledged_at IS NOT NULL")
+		}
+		if acknowledged != nil && !*acknowledged {
+			db = db.Where("acknowledged_at IS NULL")
+		}
+		if deviceID != "" {
+			db = db.Where("device_id = ?", deviceID)
+		}
+		if sensorID != "" {
+			db = db.Where("sensor_id = ?", sensorID)
+		}
+		return db
+	}
+}
+
+type AlertCommentRepository struct {
+	Repository[entity.AlertComment]
+	Log *logrus.Logger
+}
+
+func NewAlertCommentRepository(log *logrus.Logger) *AlertCommentRepository {
+	return &AlertCommentRepository{
+		Log: log,
+	}
+}
+
+type SilenceRepository struct {
+	Repository[entity.Silence]
+	Log *logrus.Logger
+}
+
+func NewSilenceRepository(log *logrus.Logger) *SilenceRepository {
+	return &SilenceRepository{
+		Log: log,
+	}
+}
+
+func (r *SilenceRepository) FindActive(db *gorm.DB, now time.Time) ([]entity.Silence, error) {
+	var silences []entity.Silence
+	err := db.Where("starts_at <= ? AND ends_at > ?", now, now).Order("created_at").Find(&silences).Error
+	return silences, err
+}
+
+// BySilenceState narrows silences to those pending, active or expired at
+// now; an empty state does not filter.
+func BySilenceState(state string, now time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		switch state {
+		case "pending":
+			db = db.Where("starts_at > ?", now)
+		case "active":
+			db = db.Where("starts_at <= ? AND ends_at > ?", now, now)
+		case "expired":
+			db = db.Where("ends_at <= ?", now)
+		}
+		return db
+	}
+}
+
+type EscalationPolicyRepository struct {
+	Repository[entity.EscalationPolicy]
+	Log *logrus.Logger
+}
+
+func NewEscalationPolicyRepository(log *logrus.Logger) *EscalationPolicyRepository {
+	return &EscalationPolicyRepository{
+		Log: log,
+	}
+}
+
+func (r *EscalationPolicyRepository) FindActive(db *gorm.DB) ([]entity.EscalationPolicy, error) {
+	var policies []entity.EscalationPolicy
+	err := db.Where("is_active = ?", true).Order("created_at").Find(&policies).Error
+	return policies, err
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"mertani_test/internal/entity"
+	"mertani_test/internal/model"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type AnomalyDetectorRepository struct {
+	Repository[entity.AnomalyDetector]
+	Log *logrus.Logger
+}
+
+func NewAnomalyDetectorRepository(log *logrus.Logger) *AnomalyDetectorRepository {
+	return &AnomalyDetectorRepository{
+		Log: log,
+	}
+}
+
+func (r *AnomalyDetectorRepository) FindActive(db *gorm.DB) ([]entity.AnomalyDetector, error) {
+	var detectors []entity.AnomalyDetector
+	err := db.Where("is_active = ?", true).Order("created_at").Find(&detectors).Error
+	return detectors, err
+}
+
+// CountByTarget counts the detectors of a type set on the same sensor, or
+// sensor type, as detector, other than detector itself.
+func (r *AnomalyDetectorRepository) CountByTarget(db *gorm.DB, detector *entity.AnomalyDetector) (int64, error) {
+	var count int64
+	query := db.Model(&entity.AnomalyDetector{}).Where("type = ? AND id <> ?", detector.Type, detector.ID)
+	if detector.SensorID != nil {
+		query = query.Where("sensor_id = ?", *detector.SensorID)
+	} else {
+		query = query.Where("sensor_id IS NULL AND sensor_type = ?", detector.SensorType)
+	}
+	err := query.Count(&count).Error
+	return count, err
+}
+
+// ByAnomalyDetectorFilter narrows detectors to those set on a sensor or a
+// sensor type; empty values do not filter.
+func ByAnomalyDetectorFilter(filter *model.AnomalyDetectorFilter) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if filter.SensorID != "" {
+			db = db.Where("sensor_id = ?", filter.SensorID)
+		}
+		if filter.SensorType != "" {
+			db = db.Where("sensor_type = ?", filter.SensorType)
+		}
+		return db
+	}
+}
+
+type AnomalyRepository struct {
+	Repository[entity.Anomaly]
+	Log *logrus.Logger
+}
+
+func NewAnomalyRepository(log *logrus.Logger) *AnomalyRepository {
+	return &AnomalyRepository{
+		Log: log,
+	}
+}
+
+// LinkAlert records the alert an anomaly raised unless it has one already,
+// reporting whether it did.
+func (r *AnomalyRepository) LinkAlert(db *gorm.DB, id any, alertID any) (bool, error) {
+	result := db.Model(&entity.Anomaly{}).
+		Where("id = ? AND alert_id IS NULL", id).
+		Update("alert_id", alertID)
+	return result.RowsAffected > 0, result.Error
+}
+
+func ByAnomalyFilter(sensorID any, filter *model.AnomalyFilter) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Where("sensor_id = ?", sensorID)
+		if filter.From != nil {
+			db = db.Where("recorded_at >= ?", *filter.From)
+		}
+		if filter.To != nil {
+			db = db.Where("recorded_at <= ?", *filter.To)
+		}
+		if filter.Detector != "" {
+			db = db.Where("detector = ?", filter.Detector)
+		}
+		return db
+	}
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"mertani_test/internal/entity"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type ConfigSchemaRepository struct {
+	Repository[entity.ConfigSchema]
+	Log *logrus.Logger
+}
+
+func NewConfigSchemaRepository(log *logrus.Logger) *ConfigSchemaRepository {
+	return &ConfigSchemaRepository{
+		Log: log,
+	}
+}
+
+func (r *ConfigSchemaRepository) FindByHardwareModel(db *gorm.DB, schema *entity.ConfigSchema, hardwareModel string) (*entity.ConfigSchema, error) {
+	if err := db.Where("hardware_model = ?", hardwareModel).Take(schema).Error; err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+func (r *ConfigSchemaRepository) ExistsByHardwareModel(db *gorm.DB, hardwareModel string) (bool, error) {
+	var count int64
+	err := db.Model(&entity.ConfigSchema{}).Where("hardware_model = ?", hardwareModel).Count(&count).Error
+	return count > 0, err
+}
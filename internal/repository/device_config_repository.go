@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"mertani_test/internal/entity"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type DeviceConfigRepository struct {
+	Repository[entity.DeviceConfig]
+	Log *logrus.Logger
+}
+
+func NewDeviceConfigRepository(log *logrus.Logger) *DeviceConfigRepository {
+	return &DeviceConfigRepository{
+		Log: log,
+	}
+}
+
+func (r *DeviceConfigRepository) FindLatest(db *gorm.DB, config *entity.DeviceConfig, deviceID any, kind string) (*entity.DeviceConfig, error) {
+	if err := db.Where("device_id = ? AND kind = ?", deviceID, kind).
+		Order("version DESC").
+		Take(config).Error; err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (r *DeviceConfigRepository) LatestVersion(db *gorm.DB, deviceID any, kind string) (int, error) {
+	var version int
+	err := db.Model(&entity.DeviceConfig{}).
+		Where("device_id = ? AND kind = ?", deviceID, kind).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&version).Error
+	return version, err
+}
+
+func ByDeviceConfigKind(deviceID any, kind string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Where("device_id = ?", deviceID)
+		if kind != "" {
+			db = db.Where("kind = ?", kind)
+		}
+		return db
+	}
+}
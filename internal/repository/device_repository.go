@@ -1,25 +1,36 @@
 package repository
 
 import (
+	"math"
 	"mertani_test/internal/entity"
+	"mertani_test/internal/model"
+	"mertani_test/internal/utils"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type DeviceRepository struct {
 	Repository[entity.Device]
-	Log *logrus.Logger
+	Log     *logrus.Logger
+	PostGIS bool
 }
 
-func NewDeviceRepository(log *logrus.Logger) *DeviceRepository {
+func NewDeviceRepository(log *logrus.Logger, postGIS bool) *DeviceRepository {
 	return &DeviceRepository{
-		Log: log,
+		Log:     log,
+		PostGIS: postGIS,
 	}
 }
 
+// FindByIdWithSensors loads a device with its sensors and their latest
+// readings.
 func (r *DeviceRepository) FindByIdWithSensors(db *gorm.DB, device *entity.Device, id any) (*entity.Device, error) {
 	if err := db.Preload("Sensors").
+		Preload("Sensors.Latest").
 		Where("id = ?", id).
 		Take(device).Error; err != nil {
 		return nil, err
@@ -27,6 +38,23 @@ func (r *DeviceRepository) FindByIdWithSensors(db *gorm.DB, device *entity.Devic
 	return device, nil
 }
 
+// FindByIdForUpdate loads a device and locks its row until the transaction
+// ends, so changes derived from it are made one at a time.
+func (r *DeviceRepository) FindByIdForUpdate(db *gorm.DB, device *entity.Device, id any) (*entity.Device, error) {
+	if err := db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).
+		Take(device).Error; err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+// UpdateLastCheckIn sets only the check-in time of a device, leaving the
+// rest of the row to concurrent edits.
+func (r *DeviceRepository) UpdateLastCheckIn(db *gorm.DB, id any, at time.Time) error {
+	return db.Model(&entity.Device{}).Where("id = ?", id).Update("last_check_in_at", at).Error
+}
+
 func (r *DeviceRepository) CountByName(db *gorm.DB, name string) (int64, error) {
 	var count int64
 	err := db.Model(&entity.Device{}).Where("name = ?", name).Count(&count).Error
@@ -37,3 +65,120 @@ func (r *DeviceRepository) ExistsByName(db *gorm.DB, name string) (bool, error)
 	count, err := r.CountByName(db, name)
 	return count > 0, err
 }
+
+func WithSensors(db *gorm.DB) *gorm.DB {
+	return db.Preload("Sensors")
+}
+
+// Filter turns a DeviceFilter into query scopes. Spatial predicates use
+// PostGIS when enabled and fall back to a portable haversine expression
+// behind a bounding-box prefilter otherwise.
+func (r *DeviceRepository) Filter(filter *model.DeviceFilter) []func(*gorm.DB) *gorm.DB {
+	var scopes []func(*gorm.DB) *gorm.DB
+	if filter == nil {
+		return scopes
+	}
+
+	if len(filter.Near) == 2 {
+		scopes = append(scopes, r.near(filter.Near[0], filter.Near[1], filter.RadiusKm))
+	}
+	if len(filter.BBox) == 4 {
+		scopes = append(scopes, r.withinBBox(filter.BBox[0], filter.BBox[1], filter.BBox[2], filter.BBox[3]))
+	}
+	if len(filter.Selector) > 0 {
+		scopes = append(scopes, WithLabelSelector(filter.Selector))
+	}
+	if filter.SiteIDs != nil {
+		siteIDs := filter.SiteIDs
+		scopes = append(scopes, func(db *gorm.DB) *gorm.DB {
+			return db.Where("site_id IN ?", siteIDs)
+		})
+	}
+
+	return scopes
+}
+
+func (r *DeviceRepository) near(lat, lng, radiusKm float64) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if r.PostGIS {
+			return db.Where(
+				"ST_DWithin(ST_MakePoint(longitude, latitude)::geography, ST_MakePoint(?, ?)::geography, ?)",
+				lng, lat, radiusKm*1000,
+			)
+		}
+
+		// The circle spans the radius in latitude and, away from the poles,
+		// asin(sin(d)/cos(lat)) in longitude for an angular radius d. When
+		// it reaches a pole every longitude may be inside it.
+		angle := radiusKm / utils.EarthRadiusKm
+		latDelta := angle * 180 / math.Pi
+		db = db.Where("latitude BETWEEN ? AND ?", lat-latDelta, lat+latDelta)
+		if math.Abs(lat)+latDelta < 90 {
+			lngDelta := math.Asin(math.Sin(angle)/math.Cos(lat*math.Pi/180)) * 180 / math.Pi
+			db = withinLongitudes(db, lng-lngDelta, lng+lngDelta)
+		}
+
+		// the argument of ASIN is clamped, as rounding can push it past 1
+		return db.Where(
+			"2 * ? * ASIN(LEAST(1, SQRT(POWER(SIN(RADIANS(latitude - ?) / 2), 2) + "+
+				"COS(RADIANS(?)) * COS(RADIANS(latitude)) * POWER(SIN(RADIANS(longitude - ?) / 2), 2)))) <= ?",
+			utils.EarthRadiusKm, lat, lat, lng, radiusKm,
+		)
+	}
+}
+
+// withinLongitudes keeps longitudes from minLng to maxLng, wrapping the
+// range around the antimeridian when it goes past ±180.
+func withinLongitudes(db *gorm.DB, minLng, maxLng float64) *gorm.DB {
+	switch {
+	case maxLng-minLng >= 360:
+		return db
+	case minLng < -180:
+		return db.Where("(longitude >= ? OR longitude <= ?)", minLng+360, maxLng)
+	case maxLng > 180:
+		return db.Where("(longitude >= ? OR longitude <= ?)", minLng, maxLng-360)
+	default:
+		return db.Where("longitude BETWEEN ? AND ?", minLng, maxLng)
+	}
+}
+
+func (r *DeviceRepository) withinBBox(minLng, minLat, maxLng, maxLat float64) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if r.PostGIS {
+			return db.Where(
+				"ST_SetSRID(ST_MakePoint(longitude, latitude), 4326) && ST_MakeEnvelope(?, ?, ?, ?, 4326)",
+				minLng, minLat, maxLng, maxLat,
+			)
+		}
+		return db.
+			Where("latitude BETWEEN ? AND ?", minLat, maxLat).
+			Where("longitude BETWEEN ? AND ?", minLng, maxLng)
+	}
+}
+
+func (r *DeviceRepository) FindByNameWithSensors(db *gorm.DB, device *entity.Device, name string) (*entity.Device, error) {
+	if err := db.Preload("Sensors").
+		Where("name = ?", name).
+		Take(device).Error; err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+func (r *DeviceRepository) FindByIds(db *gorm.DB, ids []uuid.UUID) ([]entity.Device, error) {
+	var devices []entity.Device
+	if len(ids) == 0 {
+		return devices, nil
+	}
+	err := db.Where("id IN ?", ids).Order("id").Find(&devices).Error
+	return devices, err
+}
+
+func (r *DeviceRepository) FindByNames(db *gorm.DB, names []string) ([]entity.Device, error) {
+	var devices []entity.Device
+	if len(names) == 0 {
+		return devices, nil
+	}
+	err := db.Where("name IN ?", names).Find(&devices).Error
+	return devices, err
+}
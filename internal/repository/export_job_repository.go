@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"mertani_test/internal/entity"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type ExportJobRepository struct {
+	Repository[entity.ExportJob]
+	Log *logrus.Logger
+}
+
+func NewExportJobRepository(log *logrus.Logger) *ExportJobRepository {
+	return &ExportJobRepository{
+		Log: log,
+	}
+}
+
+// ClaimNext marks the oldest pending job as running and returns it, or nil
+// when there is none. A running job whose worker has not reported since
+// staleBefore is taken over like a pending one. SKIP LOCKED lets several
+// workers, also across instances, claim jobs concurrently without handing
+// one out twice.
+func (r *ExportJobRepository) ClaimNext(db *gorm.DB, staleBefore time.Time) (*entity.ExportJob, error) {
+	now := time.Now()
+	var jobs []entity.ExportJob
+	err := db.Raw(`UPDATE export_jobs SET status = ?, rows = 0, started_at = ?, updated_at = ?
+		WHERE id = (
+			SELECT id FROM export_jobs
+			WHERE status = ? OR (status = ? AND updated_at < ?)
+			ORDER BY created_at
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING *`, entity.ExportJobRunning, now, now,
+		entity.ExportJobPending, entity.ExportJobRunning, staleBefore).
+		Scan(&jobs).Error
+	if err != nil || len(jobs) == 0 {
+		return nil, err
+	}
+	return &jobs[0], nil
+}
+
+// UpdateProgress saves the row count of a running job and renews its claim.
+// It reports false when the claim was lost, the job having been taken over
+// since it was claimed at job.StartedAt.
+func (r *ExportJobRepository) UpdateProgress(db *gorm.DB, job *entity.ExportJob, rows int64) (bool, error) {
+	result := db.Model(&entity.ExportJob{}).
+		Where("id = ? AND status = ? AND started_at = ?", job.ID, entity.ExportJobRunning, job.StartedAt).
+		Update("rows", rows)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Finish saves the outcome of a job, unless it was taken over since it was
+// claimed at job.StartedAt, which it reports as false.
+func (r *ExportJobRepository) Finish(db *gorm.DB, job *entity.ExportJob) (bool, error) {
+	result := db.Model(&entity.ExportJob{}).
+		Where("id = ? AND status = ? AND started_at = ?", job.ID, entity.ExportJobRunning, job.StartedAt).
+		Updates(map[string]any{
+			"status":      job.Status,
+			"rows":        job.Rows,
+			"size":        job.Size,
+			"error":       job.Error,
+			"file_path":   job.FilePath,
+			"expires_at":  job.ExpiresAt,
+			"finished_at": job.FinishedAt,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *ExportJobRepository) FindExpired(db *gorm.DB, now time.Time) ([]entity.ExportJob, error) {
+	var jobs []entity.ExportJob
+	err := db.Where("status = ? AND expires_at <= ?", entity.ExportJobCompleted, now).Find(&jobs).Error
+	return jobs, err
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"mertani_test/internal/entity"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationChannelRepository struct {
+	Repository[entity.NotificationChannel]
+	Log *logrus.Logger
+}
+
+func NewNotificationChannelRepository(log *logrus.Logger) *NotificationChannelRepository {
+	return &NotificationChannelRepository{
+		Log: log,
+	}
+}
+
+func (r *NotificationChannelRepository) FindByIds(db *gorm.DB, ids []string) ([]entity.NotificationChannel, error) {
+	var channels []entity.NotificationChannel
+	err := db.Where("id IN ?", ids).Find(&channels).Error
+	return channels, err
+}
+
+type NotificationRuleRepository struct {
+	Repository[entity.NotificationRule]
+	Log *logrus.Logger
+}
+
+func NewNotificationRuleRepository(log *logrus.Logger) *NotificationRuleRepository {
+	return &NotificationRuleRepository{
+		Log: log,
+	}
+}
+
+func (r *NotificationRuleRepository) FindActive(db *gorm.DB) ([]entity.NotificationRule, error) {
+	var rules []entity.NotificationRule
+	err := db.Where("is_active = ?", true).Order("created_at").Find(&rules).Error
+	return rules, err
+}
+
+type NotificationRepository struct {
+	Repository[entity.Notification]
+	Log *logrus.Logger
+}
+
+func NewNotificationRepository(log *logrus.Logger) *NotificationRepository {
+	return &NotificationRepository{
+		Log: log,
+	}
+}
+
+func (r *NotificationRepository) CreateAll(db *gorm.DB, notifications []entity.Notification) error {
+	return db.Omit(clause.Associations).Create(&notifications).Error
+}
+
+func (r *NotificationRepository) ExistsByEventID(db *gorm.DB, eventID any) (bool, error) {
+	var count int64
+	err := db.Model(&entity.Notification{}).Where("event_id = ?", eventID).Count(&count).Error
+	return count > 0, err
+}
+
+// FindLastNotified returns the latest notification about an alert that was
+// sent or is still being sent by a rule to a channel, or nil when none is.
+func (r *NotificationRepository) FindLastNotified(db *gorm.DB, ruleID any, channelID any, fingerprint string) (*entity.Notification, error) {
+	var notifications []entity.Notification
+	err := db.Where("rule_id = ? AND channel_id = ? AND fingerprint = ? AND status IN ?",
+		ruleID, channelID, fingerprint, []string{entity.NotificationPending, entity.NotificationSent}).
+		Order("created_at DESC").
+		Limit(1).
+		Find(&notifications).Error
+	if err != nil || len(notifications) == 0 {
+		return nil, err
+	}
+	return &notifications[0], nil
+}
+
+// CountSuppressedSince counts the repeats of an alert held back for a rule
+// and channel after since.
+func (r *NotificationRepository) CountSuppressedSince(db *gorm.DB, ruleID any, channelID any, fingerprint string, since time.Time) (int64, error) {
+	var count int64
+	err := db.Model(&entity.Notification{}).
+		Where("rule_id = ? AND channel_id = ? AND fingerprint = ? AND status = ? AND created_at > ?",
+			ruleID, channelID, fingerprint, entity.NotificationSuppressed, since).
+		Count(&count).Error
+	return count, err
+}
+
+// ExistsDeferredForAlert reports whether a notification about an alert is
+// held by the quiet hours of a rule for a channel, waiting for their end.
+func (r *NotificationRepository) ExistsDeferredForAlert(db *gorm.DB, ruleID any, channelID any, alertID string) (bool, error) {
+	var count int64
+	err := db.Model(&entity.Notification{}).
+		Where("rule_id = ? AND channel_id = ? AND alert_id = ? AND status = ? AND next_attempt_at IS NOT NULL",
+			ruleID, channelID, alertID, entity.NotificationQuiet).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ClaimDue takes the pending or quiet notification that is due first,
+// counts the attempt and leases it until leaseUntil, or returns nil when
+// none is due. Quiet notifications are due when their quiet hours end, in
+// the order they were created.
+func (r *NotificationRepository) ClaimDue(db *gorm.DB, now time.Time, leaseUntil time.Time) (*entity.Notification, error) {
+	var notifications []entity.Notification
+	err := db.Raw(`UPDATE notifications SET attempts = attempts + 1, next_attempt_at = ?, updated_at = ?
+		WHERE id = (
+			SELECT id FROM notifications WHERE status IN ? AND next_attempt_at <= ?
+			ORDER BY next_attempt_at, created_at
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING *`, leaseUntil, now, []string{entity.NotificationPending, entity.NotificationQuiet}, now).
+		Scan(&notifications).Error
+	if err != nil || len(notifications) == 0 {
+		return nil, err
+	}
+	return &notifications[0], nil
+}
+
+// IsAlertFiring reports whether the alert a notification is about still
+// fires.
+func (r *NotificationRepository) IsAlertFiring(db *gorm.DB, alertID string) (bool, error) {
+	var count int64
+	err := db.Model(&entity.Alert{}).Where("id = ? AND status = ?", alertID, entity.AlertStatusFiring).Count(&count).Error
+	return count > 0, err
+}
+
+// ExistsSentForAlert reports whether a rule sent a channel anything about
+// the alert.
+func (r *NotificationRepository) ExistsSentForAlert(db *gorm.DB, ruleID any, channelID any, alertID string) (bool, error) {
+	var count int64
+	err := db.Model(&entity.Notification{}).
+		Where("rule_id = ? AND channel_id = ? AND alert_id = ? AND status = ?", ruleID, channelID, alertID, entity.NotificationSent).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ByNotificationFilter narrows the delivery log by status, channel, rule
+// and alert; empty values do not filter.
+func ByNotificationFilter(status string, channelID string, ruleID string, alertID string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if status != "" {
+			db = db.Where("status = ?", status)
+		}
+		if channelID != "" {
+			db = db.Where("channel_id = ?", channelID)
+		}
+		if ruleID != "" {
+			db = db.Where("rule_id = ?", ruleID)
+		}
+		if alertID != "" {
+			db = db.Where("alert_id = ?", alertID)
+		}
+		return db
+	}
+}
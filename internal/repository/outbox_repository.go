@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"mertani_test/internal/entity"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// outboxRelayLock is the advisory lock key held by the instance relaying
+// the outbox, so events are published by one relay at a time and in order.
+const outboxRelayLock = 4_207_310_042
+
+type OutboxRepository struct {
+	Repository[entity.OutboxEvent]
+	Log *logrus.Logger
+}
+
+func NewOutboxRepository(log *logrus.Logger) *OutboxRepository {
+	return &OutboxRepository{
+		Log: log,
+	}
+}
+
+func (r *OutboxRepository) Append(db *gorm.DB, events []entity.OutboxEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return db.Omit(clause.Associations).Create(&events).Error
+}
+
+// TryLock takes the relay lock for the rest of the transaction and reports
+// whether it got it; another instance holding it is relaying already.
+func (r *OutboxRepository) TryLock(tx *gorm.DB) (bool, error) {
+	var locked bool
+	err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", outboxRelayLock).Scan(&locked).Error
+	return locked, err
+}
+
+// FindUnpublished returns up to limit unpublished events that were not given
+// up on, oldest first, whether or not their retry is due.
+func (r *OutboxRepository) FindUnpublished(db *gorm.DB, limit int) ([]entity.OutboxEvent, error) {
+	var events []entity.OutboxEvent
+	err := db.Where("published_at IS NULL AND dead_at IS NULL").
+		Order("id").
+		Limit(limit).
+		Find(&events).Error
+	return events, err
+}
+
+func (r *OutboxRepository) MarkPublished(db *gorm.DB, id int64, now time.Time) error {
+	return db.Model(&entity.OutboxEvent{}).Where("id = ?", id).
+		Updates(map[string]any{"published_at": now, "attempts": gorm.Expr("attempts + 1"), "last_error": ""}).Error
+}
+
+func (r *OutboxRepository) MarkFailed(db *gorm.DB, id int64, nextAttemptAt time.Time, delivered entity.StringList, lastError string) error {
+	return db.Model(&entity.OutboxEvent{}).Where("id = ?", id).
+		Updates(map[string]any{"attempts": gorm.Expr("attempts + 1"), "next_attempt_at": nextAttemptAt,
+			"delivered": delivered, "last_error": lastError}).Error
+}
+
+// MarkDead gives up on an event, so the relay moves on to those after it.
+func (r *OutboxRepository) MarkDead(db *gorm.DB, id int64, now time.Time, delivered entity.StringList, lastError string) error {
+	return db.Model(&entity.OutboxEvent{}).Where("id = ?", id).
+		Updates(map[string]any{"attempts": gorm.Expr("attempts + 1"), "next_attempt_at": nil, "dead_at": now,
+			"delivered": delivered, "last_error": lastError}).Error
+}
+
+// DeletePublishedBefore deletes the events published or given up on before
+// the given time.
+func (r *OutboxRepository) DeletePublishedBefore(db *gorm.DB, before time.Time) (int64, error) {
+	result := db.Where("published_at < ? OR dead_at < ?", before, before).Delete(&entity.OutboxEvent{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"mertani_test/internal/entity"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type PayloadDecoderRepository struct {
+	Repository[entity.PayloadDecoder]
+	Log *logrus.Logger
+}
+
+func NewPayloadDecoderRepository(log *logrus.Logger) *PayloadDecoderRepository {
+	return &PayloadDecoderRepository{
+		Log: log,
+	}
+}
+
+func (r *PayloadDecoderRepository) FindByHardwareModel(db *gorm.DB, payloadDecoder *entity.PayloadDecoder, hardwareModel string) (*entity.PayloadDecoder, error) {
+	if err := db.Where("hardware_model = ?", hardwareModel).Take(payloadDecoder).Error; err != nil {
+		return nil, err
+	}
+	return payloadDecoder, nil
+}
+
+func (r *PayloadDecoderRepository) ExistsByHardwareModel(db *gorm.DB, hardwareModel string) (bool, error) {
+	var count int64
+	err := db.Model(&entity.PayloadDecoder{}).Where("hardware_model = ?", hardwareModel).Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,238 @@
+package repository
+
+import (
+	"fmt"
+	"mertani_test/internal/entity"
+	"mertani_test/internal/model"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	// readingPartitionPrefix names the monthly partitions of the readings
+	// table, followed by their month as YYYYMM.
+	readingPartitionPrefix = "readings_p"
+	// readingPartitionDefault catches readings outside every monthly
+	// partition.
+	readingPartitionDefault = "readings_default"
+	// readingUnpartitioned is the name a plain readings table is moved to
+	// while it is converted.
+	readingUnpartitioned = "readings_unpartitioned"
+	// readingPartitionLock is the advisory lock key held while partitions
+	// change, so instances maintaining them at the same time take turns.
+	readingPartitionLock = 4_207_310_048
+)
+
+// ReadingPartitionRepository manages the monthly range partitions of the
+// readings table on Postgres. Partition DDL cannot take bind parameters, so
+// statements are built from names and bounds derived from time values only.
+// Changes are made under an advisory lock and skip what another instance
+// did meanwhile, so several instances may maintain the partitions at once.
+type ReadingPartitionRepository struct {
+	Log *logrus.Logger
+}
+
+func NewReadingPartitionRepository(log *logrus.Logger) *ReadingPartitionRepository {
+	return &ReadingPartitionRepository{
+		Log: log,
+	}
+}
+
+// IsPartitioned reports whether the readings table is partitioned.
+func (r *ReadingPartitionRepository) IsPartitioned(db *gorm.DB) (bool, error) {
+	var partitioned bool
+	err := db.Raw(`SELECT EXISTS (SELECT 1 FROM pg_partitioned_table
+		WHERE partrelid = to_regclass(?::text))`, "readings").
+		Scan(&partitioned).Error
+	return partitioned, err
+}
+
+// FindAll returns the monthly partitions of the readings table, oldest
+// first. The default partition is left out.
+func (r *ReadingPartitionRepository) FindAll(db *gorm.DB) ([]model.ReadingPartition, error) {
+	var names []string
+	err := db.Raw(`SELECT c.relname FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = to_regclass(?::text)
+		ORDER BY c.relname`, "readings").
+		Scan(&names).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var partitions []model.ReadingPartition
+	for _, name := range names {
+		if !strings.HasPrefix(name, readingPartitionPrefix) {
+			continue
+		}
+		month, err := time.Parse("200601", strings.TrimPrefix(name, readingPartitionPrefix))
+		if err != nil {
+			continue
+		}
+		partitions = append(partitions, model.ReadingPartition{Name: name, Month: month})
+	}
+	return partitions, nil
+}
+
+// FindDefaultMonths returns the months, in UTC, of the readings the default
+// partition caught.
+func (r *ReadingPartitionRepository) FindDefaultMonths(db *gorm.DB) ([]time.Time, error) {
+	return r.findMonths(db, readingPartitionDefault)
+}
+
+// Create adds the partition of the month holding month, unless it exists.
+// It is built apart and attached, which unlike CREATE TABLE ... PARTITION
+// OF does not block readings being written meanwhile, after moving in the
+// readings of the month the default partition caught.
+func (r *ReadingPartitionRepository) Create(db *gorm.DB, month time.Time) error {
+	from := readingPartitionMonth(month)
+	to := from.AddDate(0, 1, 0)
+	name := readingPartitionPrefix + from.Format("200601")
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		exists, err := r.lockAndCheck(tx, `SELECT to_regclass(?::text) IS NOT NULL`, name)
+		if err != nil || exists {
+			return err
+		}
+
+		err = tx.Exec(fmt.Sprintf(`CREATE TABLE %s (LIKE readings INCLUDING DEFAULTS INCLUDING CONSTRAINTS)`, name)).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Exec(fmt.Sprintf(`WITH moved AS (
+				DELETE FROM %s WHERE recorded_at >= ? AND recorded_at < ? RETURNING *
+			)
+			INSERT INTO %s SELECT * FROM moved`, readingPartitionDefault, name), from, to).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec(fmt.Sprintf(`ALTER TABLE readings ATTACH PARTITION %s FOR VALUES FROM ('%s') TO ('%s')`,
+			name, from.Format(time.RFC3339), to.Format(time.RFC3339))).Error
+	})
+}
+
+// Detach takes a partition out of the readings table, leaving it a plain
+// table, unless it is not a partition anymore.
+func (r *ReadingPartitionRepository) Detach(db *gorm.DB, name string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		attached, err := r.lockAndCheck(tx, `SELECT EXISTS (SELECT 1 FROM pg_inherits
+			WHERE inhrelid = to_regclass(?::text) AND inhparent = to_regclass('readings'))`, name)
+		if err != nil || !attached {
+			return err
+		}
+		return tx.Exec(fmt.Sprintf(`ALTER TABLE readings DETACH PARTITION %s`, name)).Error
+	})
+}
+
+// Drop drops a detached partition along with its readings.
+func (r *ReadingPartitionRepository) Drop(db *gorm.DB, name string) error {
+	return db.Exec(fmt.Sprintf(`DROP TABLE IF EXISTS %s`, name)).Error
+}
+
+// Convert turns a plain readings table into one partitioned by month on
+// recorded_at, with a partition for every month holding readings and for
+// the month of now, plus the default partition. The readings are copied in
+// one transaction, so the conversion takes as long as the table is large
+// and leaves it untouched when it fails.
+func (r *ReadingPartitionRepository) Convert(db *gorm.DB, now time.Time) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		partitioned, err := r.lockAndCheck(tx, `SELECT EXISTS (SELECT 1 FROM pg_partitioned_table
+			WHERE partrelid = to_regclass(?::text))`, "readings")
+		if err != nil || partitioned {
+			return err
+		}
+
+		err = tx.Exec(fmt.Sprintf(`ALTER TABLE readings RENAME TO %s`, readingUnpartitioned)).Error
+		if err != nil {
+			return err
+		}
+
+		// index names are unique per schema, so the old indexes make way
+		// for those of the new table
+		var indexes []string
+		err = tx.Raw(`SELECT indexname FROM pg_indexes
+			WHERE schemaname = current_schema() AND tablename = ?`, readingUnpartitioned).
+			Scan(&indexes).Error
+		if err != nil {
+			return err
+		}
+		for _, index := range indexes {
+			if err := tx.Exec(fmt.Sprintf(`ALTER INDEX %s RENAME TO %s_old`, index, index)).Error; err != nil {
+				return err
+			}
+		}
+
+		err = tx.Set("gorm:table_options", "PARTITION BY RANGE (recorded_at)").
+			Migrator().CreateTable(&entity.Reading{})
+		if err != nil {
+			return err
+		}
+		err = tx.Exec(fmt.Sprintf(`CREATE TABLE %s PARTITION OF readings DEFAULT`, readingPartitionDefault)).Error
+		if err != nil {
+			return err
+		}
+
+		months, err := r.findMonths(tx, readingUnpartitioned)
+		if err != nil {
+			return err
+		}
+		created := make(map[string]bool, len(months)+1)
+		for _, month := range append(months, now) {
+			key := readingPartitionMonth(month).Format("200601")
+			if created[key] {
+				continue
+			}
+			if err := r.Create(tx, month); err != nil {
+				return err
+			}
+			created[key] = true
+		}
+
+		var columns []string
+		err = tx.Raw(`SELECT column_name FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = ?
+			ORDER BY ordinal_position`, "readings").
+			Scan(&columns).Error
+		if err != nil {
+			return err
+		}
+		list := `"` + strings.Join(columns, `", "`) + `"`
+		err = tx.Exec(fmt.Sprintf(`INSERT INTO readings (%s) SELECT %s FROM %s`, list, list, readingUnpartitioned)).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec(fmt.Sprintf(`DROP TABLE %s`, readingUnpartitioned)).Error
+	})
+}
+
+// lockAndCheck waits for the partition lock, held until tx ends, then runs
+// a query reporting whether the change at hand was already made.
+func (r *ReadingPartitionRepository) lockAndCheck(tx *gorm.DB, query string, args ...any) (bool, error) {
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", readingPartitionLock).Error; err != nil {
+		return false, err
+	}
+	var done bool
+	err := tx.Raw(query, args...).Scan(&done).Error
+	return done, err
+}
+
+// findMonths returns the months, in UTC, table holds readings of.
+func (r *ReadingPartitionRepository) findMonths(db *gorm.DB, table string) ([]time.Time, error) {
+	var months []time.Time
+	err := db.Raw(fmt.Sprintf(`SELECT DISTINCT date_trunc('month', recorded_at AT TIME ZONE 'UTC') FROM %s`, table)).
+		Scan(&months).Error
+	return months, err
+}
+
+// readingPartitionMonth returns the first instant in UTC of the month
+// holding t, which partitions are bounded by.
+func readingPartitionMonth(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,340 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"mertani_test/internal/entity"
+	"mertani_test/internal/model"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type ReadingRepository struct {
+	Repository[entity.Reading]
+	Log *logrus.Logger
+}
+
+func NewReadingRepository(log *logrus.Logger) *ReadingRepository {
+	return &ReadingRepository{
+		Log: log,
+	}
+}
+
+// FindLatestBySensorIDsAt returns the most recent reading of each sensor
+// recorded at or before the given time.
+func (r *ReadingRepository) FindLatestBySensorIDsAt(db *gorm.DB, sensorIDs []uuid.UUID, at time.Time) ([]entity.Reading, error) {
+	var readings []entity.Reading
+	if len(sensorIDs) == 0 {
+		return readings, nil
+	}
+
+	err := db.Raw(`SELECT DISTINCT ON (sensor_id) * FROM readings
+		WHERE sensor_id IN ? AND recorded_at <= ?
+		ORDER BY sensor_id, recorded_at DESC`, sensorIDs, at).
+		Scan(&readings).Error
+	return readings, err
+}
+
+// FindBefore returns up to limit readings of a sensor recorded before the
+// given time, newest first.
+func (r *ReadingRepository) FindBefore(db *gorm.DB, sensorID any, before time.Time, limit int) ([]entity.Reading, error) {
+	var readings []entity.Reading
+	err := db.Where("sensor_id = ? AND recorded_at < ?", sensorID, before).
+		Order("recorded_at DESC").
+		Limit(limit).
+		Find(&readings).Error
+	return readings, err
+}
+
+// ExistsAt reports whether the sensor has a reading recorded at the given
+// time.
+func (r *ReadingRepository) ExistsAt(db *gorm.DB, sensorID any, at time.Time) (bool, error) {
+	var count int64
+	err := db.Model(&entity.Reading{}).
+		Where("sensor_id = ? AND recorded_at = ?", sensorID, at).
+		Limit(1).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// EstimateInterval returns the median time in seconds between the last
+// samples distinct timestamps of a sensor recorded at or before the given
+// time, or zero when it has fewer than two.
+func (r *ReadingRepository) EstimateInterval(db *gorm.DB, sensorID any, before time.Time, samples int) (float64, error) {
+	var interval sql.NullFloat64
+	err := db.Raw(`SELECT percentile_cont(0.5) WITHIN GROUP (ORDER BY delta) FROM (
+			SELECT EXTRACT(EPOCH FROM recorded_at - LAG(recorded_at) OVER (ORDER BY recorded_at)) AS delta
+			FROM (SELECT DISTINCT recorded_at FROM readings
+				WHERE sensor_id = ? AND recorded_at <= ?
+				ORDER BY recorded_at DESC LIMIT ?) AS recent
+		) AS deltas WHERE delta > 0`, sensorID, before, samples).
+		Scan(&interval).Error
+	return interval.Float64, err
+}
+
+// QualitySummary counts the readings of a sensor in a time range, with the
+// first and last time it reported and how many readings carry each flag.
+func (r *ReadingRepository) QualitySummary(db *gorm.DB, sensorID any, from time.Time, to time.Time) (*model.ReadingQualitySummary, error) {
+	summary := &model.ReadingQualitySummary{}
+	err := db.Model(&entity.Reading{}).
+		Where("sensor_id = ? AND recorded_at >= ? AND recorded_at <= ?", sensorID, from, to).
+		Select(`COUNT(*) AS total,
+			COUNT(DISTINCT recorded_at) AS received,
+			MIN(recorded_at) AS first,
+			MAX(recorded_at) AS last,
+			COUNT(*) FILTER (WHERE quality & ? <> 0) AS out_of_range,
+			COUNT(*) FILTER (WHERE quality & ? <> 0) AS duplicate`,
+			entity.QualityOutOfRange, entity.QualityDuplicate).
+		Scan(summary).Error
+	return summary, err
+}
+
+// FindGaps returns the stretches between consecutive readings of a sensor in
+// a time range that are longer than threshold seconds, oldest first.
+func (r *ReadingRepository) FindGaps(db *gorm.DB, sensorID any, from time.Time, to time.Time, threshold float64) ([]model.ReadingGap, error) {
+	var gaps []model.ReadingGap
+	err := db.Raw(`SELECT previous AS "from", recorded_at AS "to" FROM (
+			SELECT recorded_at, LAG(recorded_at) OVER (ORDER BY recorded_at) AS previous
+			FROM (SELECT DISTINCT recorded_at FROM readings
+				WHERE sensor_id = ? AND recorded_at >= ? AND recorded_at <= ?) AS stamps
+		) AS pairs
+		WHERE previous IS NOT NULL AND EXTRACT(EPOCH FROM recorded_at - previous) > ?
+		ORDER BY previous`, sensorID, from, to, threshold).
+		Scan(&gaps).Error
+	return gaps, err
+}
+
+func ByReadingFilter(sensorID any, filter *model.ReadingFilter) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("sensor_id = ?", sensorID).Scopes(byReadingRange(filter))
+	}
+}
+
+// BySensorsReadingFilter is ByReadingFilter over several sensors.
+func BySensorsReadingFilter(sensorIDs []uuid.UUID, filter *model.ReadingFilter) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("sensor_id IN ?", sensorIDs).Scopes(byReadingRange(filter))
+	}
+}
+
+func byReadingRange(filter *model.ReadingFilter) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if filter == nil {
+			return db
+		}
+		if filter.From != nil {
+			db = db.Where("recorded_at >= ?", *filter.From)
+		}
+		if filter.To != nil {
+			db = db.Where("recorded_at <= ?", *filter.To)
+		}
+		return db
+	}
+}
+
+// tieredReadings selects the readings of sensors in a time range as rows of
+// (sensor_id, recorded_at, count, min, max, sum), one per raw reading and, with
+// rollups, one per rollup bucket. Tiers never hold the same reading, so
+// aggregating the rows covers every reading once.
+func tieredReadings(db *gorm.DB, sensorIDs []uuid.UUID, filter *model.ReadingFilter, rollups bool) *gorm.DB {
+	raw := db.Model(&entity.Reading{}).
+		Scopes(BySensorsReadingFilter(sensorIDs, filter)).
+		Select(`sensor_id, recorded_at, 1 AS count,
+			COALESCE(calibrated_value, value) AS min,
+			COALESCE(calibrated_value, value) AS max,
+			COALESCE(calibrated_value, value) AS sum`)
+	if !rollups {
+		return db.Table("(?) AS tiers", raw)
+	}
+
+	rolled := db.Model(&entity.ReadingRollup{}).
+		Where("sensor_id IN ?", sensorIDs).
+		Scopes(byRollupRange(filter)).
+		Select("sensor_id, bucket AS recorded_at, count, min, max, sum")
+	return db.Table("(? UNION ALL ?) AS tiers", raw, rolled)
+}
+
+// Aggregate buckets readings of a sensor by the given date_trunc interval,
+// in UTC like the rollups, whatever the session time zone. With rollups,
+// readings compacted into rollups are included at the resolution they were
+// kept at.
+func (r *ReadingRepository) Aggregate(db *gorm.DB, sensorID uuid.UUID, filter *model.ReadingFilter, interval string, rollups bool) ([]model.ReadingAggregate, error) {
+	var buckets []model.ReadingAggregate
+	err := tieredReadings(db, []uuid.UUID{sensorID}, filter, rollups).
+		Select(`date_trunc(?, recorded_at AT TIME ZONE 'UTC') AS bucket,
+			SUM(count)::bigint AS count,
+			MIN(min) AS min,
+			MAX(max) AS max,
+			SUM(sum) / SUM(count) AS avg`, interval).
+		Group("bucket").
+		Order("bucket").
+		Scan(&buckets).Error
+	return buckets, err
+}
+
+// Recalibrate recomputes, in one statement, the calibrated value of the
+// readings in scope from their raw value, clearing it when calibration is
+// nil, and sets their out-of-range flag from the new value and the valid
+// range. It returns how many readings were updated.
+func (r *ReadingRepository) Recalibrate(db *gorm.DB, calibration *entity.SensorCalibration, validMin, validMax *float64, scopes ...func(*gorm.DB) *gorm.DB) (int64, error) {
+	calibrated, args := calibrationExpression(calibration)
+	effective := "COALESCE(" + calibrated + ", value)"
+
+	var conditions []string
+	var conditionArgs []any
+	if validMin != nil {
+		conditions = append(conditions, effective+" < ?")
+		conditionArgs = append(conditionArgs, append(append([]any{}, args...), *validMin)...)
+	}
+	if validMax != nil {
+		conditions = append(conditions, effective+" > ?")
+		conditionArgs = append(conditionArgs, append(append([]any{}, args...), *validMax)...)
+	}
+	outOfRange := "FALSE"
+	if len(conditions) > 0 {
+		outOfRange = strings.Join(conditions, " OR ")
+	}
+
+	result := db.Model(&entity.Reading{}).Scopes(scopes...).Updates(map[string]any{
+		"calibrated_value": gorm.Expr(calibrated, args...),
+		"quality": gorm.Expr(fmt.Sprintf("CASE WHEN %s THEN quality | %d ELSE quality & ~%d END",
+			outOfRange, entity.QualityOutOfRange, entity.QualityOutOfRange), conditionArgs...),
+	})
+	return result.RowsAffected, result.Error
+}
+
+// calibrationExpression is SensorCalibration.Apply written in SQL over the
+// raw value column, evaluated in the same order so both give the same
+// results.
+func calibrationExpression(calibration *entity.SensorCalibration) (string, []any) {
+	if calibration == nil {
+		return "CAST(NULL AS double precision)", nil
+	}
+
+	switch calibration.Method {
+	case entity.CalibrationPolynomial:
+		if len(calibration.Polynomial) == 0 {
+			return "CAST(0 AS double precision)", nil
+		}
+		last := len(calibration.Polynomial) - 1
+		expression := "CAST(? AS double precision)"
+		args := []any{calibration.Polynomial[last]}
+		for i := last - 1; i >= 0; i-- {
+			expression = "(" + expression + ") * value + ?"
+			args = append(args, calibration.Polynomial[i])
+		}
+		return expression, args
+
+	case entity.CalibrationLookup:
+		table := calibration.Table
+		if len(table) < 2 {
+			return "value", nil
+		}
+		segment := func(i int) (string, []any) {
+			return "? + (value - ?) * ? / ?",
+				[]any{table[i-1][1], table[i-1][0], table[i][1] - table[i-1][1], table[i][0] - table[i-1][0]}
+		}
+		var expression strings.Builder
+		var args []any
+		expression.WriteString("CASE")
+		for i := 1; i < len(table)-1; i++ {
+			value, valueArgs := segment(i)
+			expression.WriteString(" WHEN value <= ? THEN " + value)
+			args = append(append(args, table[i][0]), valueArgs...)
+		}
+		value, valueArgs := segment(len(table) - 1)
+		expression.WriteString(" ELSE " + value + " END")
+		return expression.String(), append(args, valueArgs...)
+
+	default:
+		return "value * ? + ?", []any{calibration.Gain, calibration.Offset}
+	}
+}
+
+// FindSeries returns the readings of the sensors in a range in time order,
+// with only what is needed to evaluate them.
+func (r *ReadingRepository) FindSeries(db *gorm.DB, sensorIDs []uuid.UUID, filter *model.ReadingFilter) ([]entity.Reading, error) {
+	var readings []entity.Reading
+	if len(sensorIDs) == 0 {
+		return readings, nil
+	}
+
+	err := db.Select("id, sensor_id, value, calibrated_value, recorded_at").
+		Scopes(BySensorsReadingFilter(sensorIDs, filter)).
+		Order("recorded_at").
+		Find(&readings).Error
+	return readings, err
+}
+
+// updateValuesBatchSize bounds the rows set by one UPDATE of UpdateValues.
+const updateValuesBatchSize = 1000
+
+// UpdateValues sets the raw value of readings by id, a batch of them per
+// statement.
+func (r *ReadingRepository) UpdateValues(db *gorm.DB, values map[uuid.UUID]float64) error {
+	rows := make([]string, 0, updateValuesBatchSize)
+	args := make([]any, 0, 2*updateValuesBatchSize)
+	flush := func() error {
+		if len(rows) == 0 {
+			return nil
+		}
+		err := db.Exec(`UPDATE readings SET value = v.value
+			FROM (VALUES `+strings.Join(rows, ", ")+`) AS v(id, value)
+			WHERE readings.id = v.id`, args...).Error
+		rows, args = rows[:0], args[:0]
+		return err
+	}
+
+	for id, value := range values {
+		rows = append(rows, "(CAST(? AS uuid), CAST(? AS double precision))")
+		args = append(args, id, value)
+		if len(rows) == updateValuesBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return flush()
+}
+
+// RecordedBetween keeps readings recorded from from, inclusive, to to,
+// exclusive; a nil bound is open.
+func RecordedBetween(from, to *time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if from != nil {
+			db = db.Where("recorded_at >= ?", *from)
+		}
+		if to != nil {
+			db = db.Where("recorded_at < ?", *to)
+		}
+		return db
+	}
+}
+
+// StreamBySensors opens a cursor over the readings of the sensors in time
+// order. Rows are read one at a time with ScanRows; the caller closes them.
+func (r *ReadingRepository) StreamBySensors(db *gorm.DB, sensorIDs []uuid.UUID, filter *model.ReadingFilter) (*sql.Rows, error) {
+	return db.Model(&entity.Reading{}).
+		Scopes(BySensorsReadingFilter(sensorIDs, filter)).
+		Order("recorded_at, sensor_id").
+		Rows()
+}
+
+// StreamAggregateBySensors is Aggregate over several sensors as a cursor of
+// ReadingAggregate rows, ordered by bucket and sensor.
+func (r *ReadingRepository) StreamAggregateBySensors(db *gorm.DB, sensorIDs []uuid.UUID, filter *model.ReadingFilter, interval string, rollups bool) (*sql.Rows, error) {
+	return tieredReadings(db, sensorIDs, filter, rollups).
+		Select(`sensor_id,
+			date_trunc(?, recorded_at AT TIME ZONE 'UTC') AS bucket,
+			SUM(count)::bigint AS count,
+			MIN(min) AS min,
+			MAX(max) AS max,
+			SUM(sum) / SUM(count) AS avg`, interval).
+		Group("sensor_id, bucket").
+		Order("bucket, sensor_id").
+		Rows()
+}
@@ -37,21 +37,10 @@ func (r *Repository[T]) FindById(db *gorm.DB, entity *T, id any) (*T, error) {
 }
 
 
-func (r *Repository[T]) FindAll(db *gorm.DB, entities *[]T, pagination *utils.PaginationRequest) (int64, error) {
+func (r *Repository[T]) FindAll(db *gorm.DB, entities *[]T, pagination *utils.PaginationRequest, scopes ...func(*gorm.DB) *gorm.DB) (int64, error) {
 	var total int64
 
-	query := db.Model(new(T))
-
-	if s, ok := any(new(T)).(utils.Searchable); ok && pagination.Search != "" {
-		fields := s.SearchFields()
-		conditions := make([]string, len(fields))
-		args := make([]interface{}, len(fields))
-		for i, f := range fields {
-			conditions[i] = f + " ILIKE ?"
-			args[i] = "%" + pagination.Search + "%"
-		}
-		query = query.Where(strings.Join(conditions, " OR "), args...)
-	}
+	query := db.Model(new(T)).Scopes(scopes...).Scopes(withSearch[T](pagination.Search))
 
 	if pagination.OrderBy != "" {
 		order := pagination.OrderBy
@@ -72,3 +61,57 @@ func (r *Repository[T]) FindAll(db *gorm.DB, entities *[]T, pagination *utils.Pa
 
 	return total, nil
 }
+
+// FindInBatches walks every row matching the search term and scopes in
+// primary key order, handing batchSize rows at a time to fn.
+func (r *Repository[T]) FindInBatches(db *gorm.DB, search string, batchSize int, fn func([]T) error, scopes ...func(*gorm.DB) *gorm.DB) error {
+	var batch []T
+	return db.Model(new(T)).Scopes(scopes...).Scopes(withSearch[T](search)).
+		FindInBatches(&batch, batchSize, func(_ *gorm.DB, _ int) error {
+			return fn(batch)
+		}).Error
+}
+
+// withSearch matches the search term against the fields of Searchable
+// entities and is a no-op for others.
+func withSearch[T any](search string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		s, ok := any(new(T)).(utils.Searchable)
+		if !ok || search == "" {
+			return db
+		}
+
+		fields := s.SearchFields()
+		conditions := make([]string, len(fields))
+		args := make([]interface{}, len(fields))
+		for i, f := range fields {
+			conditions[i] = f + " ILIKE ?"
+			args[i] = "%" + search + "%"
+		}
+		return db.Where(strings.Join(conditions, " OR "), args...)
+	}
+}
+
+// WithLabelSelector filters rows whose jsonb "labels" column satisfies the selector.
+func WithLabelSelector(selector utils.LabelSelector) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		for _, requirement := range selector {
+			key := requirement.Key
+			switch requirement.Operator {
+			case utils.SelectorExists:
+				db = db.Where("jsonb_exists(labels, ?)", key)
+			case utils.SelectorDoesNotExist:
+				db = db.Where("NOT jsonb_exists(labels, ?)", key)
+			case utils.SelectorEquals:
+				db = db.Where("labels @> jsonb_build_object(?::text, ?::text)", key, requirement.Values[0])
+			case utils.SelectorNotEquals:
+				db = db.Where("labels ->> ? IS DISTINCT FROM ?", key, requirement.Values[0])
+			case utils.SelectorIn:
+				db = db.Where("labels ->> ? IN ?", key, requirement.Values)
+			case utils.SelectorNotIn:
+				db = db.Where("(labels ->> ? IS NULL OR labels ->> ? NOT IN ?)", key, key, requirement.Values)
+			}
+		}
+		return db
+	}
+}
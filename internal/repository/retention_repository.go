@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"database/sql"
+	"mertani_test/internal/entity"
+	"mertani_test/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type RetentionPolicyRepository struct {
+	Repository[entity.RetentionPolicy]
+	Log *logrus.Logger
+}
+
+func NewRetentionPolicyRepository(log *logrus.Logger) *RetentionPolicyRepository {
+	return &RetentionPolicyRepository{
+		Log: log,
+	}
+}
+
+func (r *RetentionPolicyRepository) ExistsBySensorType(db *gorm.DB, sensorType string) (bool, error) {
+	var count int64
+	err := db.Model(&entity.RetentionPolicy{}).Where("sensor_type = ?", sensorType).Count(&count).Error
+	return count > 0, err
+}
+
+// FindBySensorTypes returns every policy keyed by sensor type, the default
+// one under the empty type.
+func (r *RetentionPolicyRepository) FindBySensorTypes(db *gorm.DB) (map[string]entity.RetentionPolicy, error) {
+	var policies []entity.RetentionPolicy
+	if err := db.Find(&policies).Error; err != nil {
+		return nil, err
+	}
+
+	bySensorType := make(map[string]entity.RetentionPolicy, len(policies))
+	for _, policy := range policies {
+		bySensorType[policy.SensorType] = policy
+	}
+	return bySensorType, nil
+}
+
+type ReadingRollupRepository struct {
+	Repository[entity.ReadingRollup]
+	Log *logrus.Logger
+}
+
+func NewReadingRollupRepository(log *logrus.Logger) *ReadingRollupRepository {
+	return &ReadingRollupRepository{
+		Log: log,
+	}
+}
+
+// rollupMerge folds rollups into those already stored for their bucket.
+// Buckets are truncated in UTC, like the cutoffs they are compared with,
+// whatever the TimeZone of the database session.
+const rollupMerge = `ON CONFLICT (sensor_id, resolution, bucket) DO UPDATE SET
+	count = reading_rollups.count + EXCLUDED.count,
+	min = LEAST(reading_rollups.min, EXCLUDED.min),
+	max = GREATEST(reading_rollups.max, EXCLUDED.max),
+	sum = reading_rollups.sum + EXCLUDED.sum`
+
+// CompactReadings moves the raw readings of a sensor recorded before the
+// given time into its hourly rollups and returns how many it moved. The
+// readings are rolled up from what the statement deletes, so readings
+// stored meanwhile are left for the next run and concurrent runs do not
+// count a reading twice.
+func (r *ReadingRollupRepository) CompactReadings(db *gorm.DB, sensorID uuid.UUID, before time.Time) (int64, error) {
+	var moved int64
+	err := db.Raw(`WITH moved AS (
+			DELETE FROM readings WHERE sensor_id = ? AND recorded_at < ?
+			RETURNING sensor_id, recorded_at, COALESCE(calibrated_value, value) AS value
+		), rolled AS (
+			INSERT INTO reading_rollups (sensor_id, resolution, bucket, count, min, max, sum)
+			SELECT sensor_id, ?, date_trunc('hour', recorded_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' AS bucket, COUNT(*), MIN(value), MAX(value), SUM(value)
+			FROM moved GROUP BY sensor_id, bucket
+			`+rollupMerge+`
+			RETURNING 1
+		)
+		SELECT COUNT(*) FROM moved`, sensorID, before, entity.RollupHour).
+		Scan(&moved).Error
+	return moved, err
+}
+
+// CompactHours moves the hourly rollups of a sensor starting before the
+// given time into its daily rollups and returns how many it moved.
+func (r *ReadingRollupRepository) CompactHours(db *gorm.DB, sensorID uuid.UUID, before time.Time) (int64, error) {
+	var moved int64
+	err := db.Raw(`WITH moved AS (
+			DELETE FROM reading_rollups WHERE sensor_id = ? AND resolution = ? AND bucket < ?
+			RETURNING sensor_id, bucket, count, min, max, sum
+		), rolled AS (
+			INSERT INTO reading_rollups (sensor_id, resolution, bucket, count, min, max, sum)
+			SELECT sensor_id, ?, date_trunc('day', bucket AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' AS day, SUM(count), MIN(min), MAX(max), SUM(sum)
+			FROM moved GROUP BY sensor_id, day
+			`+rollupMerge+`
+			RETURNING 1
+		)
+		SELECT COUNT(*) FROM moved`, sensorID, entity.RollupHour, before, entity.RollupDay).
+		Scan(&moved).Error
+	return moved, err
+}
+
+// DeleteDays deletes the daily rollups of a sensor starting before the
+// given time.
+func (r *ReadingRollupRepository) DeleteDays(db *gorm.DB, sensorID uuid.UUID, before time.Time) (int64, error) {
+	result := db.Where("sensor_id = ? AND resolution = ? AND bucket < ?", sensorID, entity.RollupDay, before).
+		Delete(&entity.ReadingRollup{})
+	return result.RowsAffected, result.Error
+}
+
+// CompactedUntil returns when the latest rollup of a sensor ends, or nil
+// when it has none. Raw readings before it were compacted by retention.
+func (r *ReadingRollupRepository) CompactedUntil(db *gorm.DB, sensorID uuid.UUID) (*time.Time, error) {
+	var until sql.NullTime
+	err := db.Model(&entity.ReadingRollup{}).
+		Select("MAX(bucket + CASE WHEN resolution = ? THEN INTERVAL '1 hour' ELSE INTERVAL '1 day' END)", entity.RollupHour).
+		Where("sensor_id = ?", sensorID).
+		Scan(&until).Error
+	if err != nil || !until.Valid {
+		return nil, err
+	}
+	return &until.Time, nil
+}
+
+// ExistsInRange reports whether any of the sensors has rollups in the time
+// range of the filter.
+func (r *ReadingRollupRepository) ExistsInRange(db *gorm.DB, sensorIDs []uuid.UUID, filter *model.ReadingFilter) (bool, error) {
+	var exists bool
+	err := db.Raw("SELECT EXISTS (?)", db.Model(&entity.ReadingRollup{}).
+		Select("1").
+		Where("sensor_id IN ?", sensorIDs).
+		Scopes(byRollupRange(filter))).
+		Scan(&exists).Error
+	return exists, err
+}
+
+// byRollupRange keeps the rollups whose bucket overlaps the time range of
+// the filter. A rollup cannot be split, so a bucket the range starts in is
+// kept whole, readings before the start included.
+func byRollupRange(filter *model.ReadingFilter) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if filter == nil {
+			return db
+		}
+		if filter.From != nil {
+			db = db.Where("((resolution = ? AND bucket > ?) OR (resolution = ? AND bucket > ?))",
+				entity.RollupHour, filter.From.Add(-time.Hour), entity.RollupDay, filter.From.Add(-24*time.Hour))
+		}
+		if filter.To != nil {
+			db = db.Where("bucket <= ?", *filter.To)
+		}
+		return db
+	}
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"mertani_test/internal/entity"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type SensorCalibrationRepository struct {
+	Repository[entity.SensorCalibration]
+	Log *logrus.Logger
+}
+
+func NewSensorCalibrationRepository(log *logrus.Logger) *SensorCalibrationRepository {
+	return &SensorCalibrationRepository{
+		Log: log,
+	}
+}
+
+// FindAllBySensor returns the calibration history of a sensor, oldest first.
+func (r *SensorCalibrationRepository) FindAllBySensor(db *gorm.DB, sensorID any) ([]entity.SensorCalibration, error) {
+	var calibrations []entity.SensorCalibration
+	err := db.Where("sensor_id = ?", sensorID).
+		Order("effective_from ASC").
+		Find(&calibrations).Error
+	return calibrations, err
+}
+
+// FindInForce returns the calibration in force at the given time.
+func (r *SensorCalibrationRepository) FindInForce(db *gorm.DB, calibration *entity.SensorCalibration, sensorID any, at time.Time) (*entity.SensorCalibration, error) {
+	err := db.Where("sensor_id = ? AND effective_from <= ?", sensorID, at).
+		Order("effective_from DESC").
+		Take(calibration).Error
+	if err != nil {
+		return nil, err
+	}
+	return calibration, nil
+}
+
+func (r *SensorCalibrationRepository) ExistsAt(db *gorm.DB, sensorID any, effectiveFrom time.Time) (bool, error) {
+	var count int64
+	err := db.Model(&entity.SensorCalibration{}).
+		Where("sensor_id = ? AND effective_from = ?", sensorID, effectiveFrom).
+		Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"mertani_test/internal/entity"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type SensorLatestRepository struct {
+	Repository[entity.SensorLatest]
+	Log *logrus.Logger
+}
+
+func NewSensorLatestRepository(log *logrus.Logger) *SensorLatestRepository {
+	return &SensorLatestRepository{
+		Log: log,
+	}
+}
+
+// sensorLatestMerge replaces the latest reading of a sensor.
+const sensorLatestMerge = `ON CONFLICT (sensor_id) DO UPDATE SET
+	reading_id = EXCLUDED.reading_id,
+	value = EXCLUDED.value,
+	calibrated_value = EXCLUDED.calibrated_value,
+	recorded_at = EXCLUDED.recorded_at,
+	updated_at = EXCLUDED.updated_at`
+
+// Save records a reading as the latest of its sensor unless one recorded
+// later is already, so readings arriving out of order never move it back.
+func (r *SensorLatestRepository) Save(db *gorm.DB, reading *entity.Reading) error {
+	return db.Exec(`INSERT INTO sensor_latest (sensor_id, reading_id, value, calibrated_value, recorded_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, now())
+		`+sensorLatestMerge+`
+		WHERE sensor_latest.recorded_at <= EXCLUDED.recorded_at`,
+		reading.SensorID, reading.ID, reading.Value, reading.CalibratedValue, reading.RecordedAt).Error
+}
+
+// Refresh reloads the latest reading of a sensor from its readings, for
+// when stored readings were changed in place.
+func (r *SensorLatestRepository) Refresh(db *gorm.DB, sensorID any) error {
+	return db.Exec(`INSERT INTO sensor_latest (sensor_id, reading_id, value, calibrated_value, recorded_at, updated_at)
+		SELECT sensor_id, id, value, calibrated_value, recorded_at, now() FROM readings
+		WHERE sensor_id = ?
+		ORDER BY recorded_at DESC
+		LIMIT 1
+		`+sensorLatestMerge, sensorID).Error
+}
+
+// Backfill records the latest reading of every sensor that has none yet.
+func (r *SensorLatestRepository) Backfill(db *gorm.DB) error {
+	return db.Exec(`INSERT INTO sensor_latest (sensor_id, reading_id, value, calibrated_value, recorded_at, updated_at)
+		SELECT DISTINCT ON (sensor_id) sensor_id, id, value, calibrated_value, recorded_at, now() FROM readings
+		ORDER BY sensor_id, recorded_at DESC
+		ON CONFLICT (sensor_id) DO NOTHING`).Error
+}
+
+// FindBySensorIDs returns the latest readings of the sensors that have one.
+func (r *SensorLatestRepository) FindBySensorIDs(db *gorm.DB, sensorIDs []uuid.UUID) ([]entity.SensorLatest, error) {
+	var latest []entity.SensorLatest
+	if len(sensorIDs) == 0 {
+		return latest, nil
+	}
+
+	err := db.Where("sensor_id IN ?", sensorIDs).Find(&latest).Error
+	return latest, err
+}
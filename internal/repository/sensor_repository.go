@@ -2,7 +2,9 @@ package repository
 
 import (
 	"mertani_test/internal/entity"
+	"mertani_test/internal/model"
 
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
@@ -20,6 +22,8 @@ func NewSensorRepository(log *logrus.Logger) *SensorRepository {
 
 func (r *SensorRepository) FindByIdWithDevice(db *gorm.DB, sensor *entity.Sensor, id any) (*entity.Sensor, error) {
 	if err := db.Preload("Device").
+		Preload("Inputs").
+		Preload("Latest").
 		Where("id = ?", id).
 		Take(sensor).Error; err != nil {
 		return nil, err
@@ -37,3 +41,96 @@ func (r *SensorRepository) ExistsByName(db *gorm.DB, name string) (bool, error)
 	count, err := r.CountByName(db, name)
 	return count > 0, err
 }
+
+func (r *SensorRepository) Filter(filter *model.SensorFilter) []func(*gorm.DB) *gorm.DB {
+	var scopes []func(*gorm.DB) *gorm.DB
+	if filter == nil {
+		return scopes
+	}
+
+	if len(filter.Selector) > 0 {
+		scopes = append(scopes, WithLabelSelector(filter.Selector))
+	}
+
+	return scopes
+}
+
+func WithInputs(db *gorm.DB) *gorm.DB {
+	return db.Preload("Inputs")
+}
+
+func WithLatest(db *gorm.DB) *gorm.DB {
+	return db.Preload("Latest")
+}
+
+// FindDependents returns the virtual sensors that take any of the given
+// sensors as input, with their inputs loaded.
+func (r *SensorRepository) FindDependents(db *gorm.DB, sensorIDs []uuid.UUID) ([]entity.Sensor, error) {
+	var sensors []entity.Sensor
+	err := db.Preload("Inputs").
+		Where("kind = ? AND id IN (SELECT sensor_id FROM sensor_inputs WHERE input_sensor_id IN ?)",
+			entity.SensorVirtual, sensorIDs).
+		Find(&sensors).Error
+	return sensors, err
+}
+
+func (r *SensorRepository) CountDependents(db *gorm.DB, sensorID any) (int64, error) {
+	var count int64
+	err := db.Model(&entity.SensorInput{}).Where("input_sensor_id = ?", sensorID).Count(&count).Error
+	return count, err
+}
+
+func (r *SensorRepository) FindInputs(db *gorm.DB, sensorID any) ([]entity.SensorInput, error) {
+	var inputs []entity.SensorInput
+	err := db.Where("sensor_id = ?", sensorID).Find(&inputs).Error
+	return inputs, err
+}
+
+// FindInputIDs returns the sensors feeding any of the given sensors.
+func (r *SensorRepository) FindInputIDs(db *gorm.DB, sensorIDs []uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := db.Model(&entity.SensorInput{}).
+		Where("sensor_id IN ?", sensorIDs).
+		Distinct().
+		Pluck("input_sensor_id", &ids).Error
+	return ids, err
+}
+
+func (r *SensorRepository) CountByIds(db *gorm.DB, ids []uuid.UUID) (int64, error) {
+	var count int64
+	err := db.Model(&entity.Sensor{}).Where("id IN ?", ids).Count(&count).Error
+	return count, err
+}
+
+// ReplaceInputs swaps the input bindings of a virtual sensor.
+func (r *SensorRepository) ReplaceInputs(db *gorm.DB, sensorID uuid.UUID, inputs []entity.SensorInput) error {
+	if err := db.Where("sensor_id = ?", sensorID).Delete(&entity.SensorInput{}).Error; err != nil {
+		return err
+	}
+	if len(inputs) == 0 {
+		return nil
+	}
+	for i := range inputs {
+		inputs[i].SensorID = sensorID
+	}
+	return db.Create(&inputs).Error
+}
+
+func (r *SensorRepository) FindByNames(db *gorm.DB, names []string) ([]entity.Sensor, error) {
+	var sensors []entity.Sensor
+	if len(names) == 0 {
+		return sensors, nil
+	}
+	err := db.Where("name IN ?", names).Find(&sensors).Error
+	return sensors, err
+}
+
+func WithDevice(db *gorm.DB) *gorm.DB {
+	return db.Preload("Device")
+}
+
+func (r *SensorRepository) FindByDeviceID(db *gorm.DB, deviceID any) ([]entity.Sensor, error) {
+	var sensors []entity.Sensor
+	err := db.Where("device_id = ?", deviceID).Order("name").Find(&sensors).Error
+	return sensors, err
+}
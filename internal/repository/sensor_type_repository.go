@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"mertani_test/internal/entity"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type SensorTypeRepository struct {
+	Repository[entity.SensorType]
+	Log *logrus.Logger
+}
+
+func NewSensorTypeRepository(log *logrus.Logger) *SensorTypeRepository {
+	return &SensorTypeRepository{
+		Log: log,
+	}
+}
+
+func (r *SensorTypeRepository) FindByCode(db *gorm.DB, sensorType *entity.SensorType, code string) (*entity.SensorType, error) {
+	if err := db.Where("code = ?", code).Take(sensorType).Error; err != nil {
+		return nil, err
+	}
+	return sensorType, nil
+}
+
+func (r *SensorTypeRepository) ExistsByCode(db *gorm.DB, code string) (bool, error) {
+	var count int64
+	err := db.Model(&entity.SensorType{}).Where("code = ?", code).Count(&count).Error
+	return count > 0, err
+}
+
+// FindSensorUnits returns the distinct units sensors of the type are read in.
+func (r *SensorTypeRepository) FindSensorUnits(db *gorm.DB, code string) ([]string, error) {
+	var units []string
+	err := db.Model(&entity.Sensor{}).Where("type = ?", code).Distinct().Pluck("unit", &units).Error
+	return units, err
+}
+
+func (r *SensorTypeRepository) CountSensors(db *gorm.DB, code string) (int64, error) {
+	var count int64
+	err := db.Model(&entity.Sensor{}).Where("type = ?", code).Count(&count).Error
+	return count, err
+}
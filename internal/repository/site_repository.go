@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"mertani_test/internal/entity"
+	"mertani_test/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type SiteRepository struct {
+	Repository[entity.Site]
+	Log *logrus.Logger
+}
+
+func NewSiteRepository(log *logrus.Logger) *SiteRepository {
+	return &SiteRepository{
+		Log: log,
+	}
+}
+
+func (r *SiteRepository) FindByIdWithChildren(db *gorm.DB, site *entity.Site, id any) (*entity.Site, error) {
+	if err := db.Preload("Children").
+		Where("id = ?", id).
+		Take(site).Error; err != nil {
+		return nil, err
+	}
+	return site, nil
+}
+
+// FindSubtreeIDs returns the id of the node and all of its descendants.
+func (r *SiteRepository) FindSubtreeIDs(db *gorm.DB, id any) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := db.Raw(`WITH RECURSIVE subtree AS (
+			SELECT id FROM sites WHERE id = ?
+			UNION ALL
+			SELECT s.id FROM sites s JOIN subtree t ON s.parent_id = t.id
+		)
+		SELECT id FROM subtree`, id).
+		Scan(&ids).Error
+	return ids, err
+}
+
+func (r *SiteRepository) CountChildren(db *gorm.DB, id any) (int64, error) {
+	var count int64
+	err := db.Model(&entity.Site{}).Where("parent_id = ?", id).Count(&count).Error
+	return count, err
+}
+
+func (r *SiteRepository) CountDevices(db *gorm.DB, siteIDs []uuid.UUID) (int64, error) {
+	var count int64
+	err := db.Model(&entity.Device{}).Where("site_id IN ?", siteIDs).Count(&count).Error
+	return count, err
+}
+
+func (r *SiteRepository) FindDeviceIDs(db *gorm.DB, siteIDs []uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := db.Model(&entity.Device{}).Where("site_id IN ?", siteIDs).Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *SiteRepository) MoveDevices(db *gorm.DB, siteID uuid.UUID, deviceIDs []uuid.UUID) (int64, error) {
+	result := db.Model(&entity.Device{}).Where("id IN ?", deviceIDs).Update("site_id", siteID)
+	return result.RowsAffected, result.Error
+}
+
+// DetachDevices clears the site of the given devices that belong to it.
+func (r *SiteRepository) DetachDevices(db *gorm.DB, siteID uuid.UUID, deviceIDs []uuid.UUID) (int64, error) {
+	result := db.Model(&entity.Device{}).Where("id IN ? AND site_id = ?", deviceIDs, siteID).Update("site_id", nil)
+	return result.RowsAffected, result.Error
+}
+
+// SensorTypeStats aggregates sensors of devices attached to the given sites,
+// grouped by sensor type and unit, with reading statistics in [from, to].
+func (r *SiteRepository) SensorTypeStats(db *gorm.DB, siteIDs []uuid.UUID, from *time.Time, to *time.Time) ([]model.SensorTypeStatsResponse, error) {
+	join := "LEFT JOIN readings r ON r.sensor_id = s.id"
+	var args []any
+	if from != nil {
+		join += " AND r.recorded_at >= ?"
+		args = append(args, *from)
+	}
+	if to != nil {
+		join += " AND r.recorded_at <= ?"
+		args = append(args, *to)
+	}
+
+	var stats []model.SensorTypeStatsResponse
+	err := db.Table("sensors s").
+		Select(`s.type AS type, s.unit AS unit,
+			COUNT(DISTINCT s.id) AS sensor_count,
+			COUNT(DISTINCT s.id) FILTER (WHERE s.is_active) AS active_sensor_count,
+			COUNT(r.id) AS reading_count,
+			MIN(COALESCE(r.calibrated_value, r.value)) AS min_value,
+			MAX(COALESCE(r.calibrated_value, r.value)) AS max_value,
+			AVG(COALESCE(r.calibrated_value, r.value)) AS avg_value,
+			TO_CHAR(MAX(r.recorded_at), 'YYYY-MM-DD HH24:MI:SS') AS last_reading_at`).
+		Joins("JOIN devices d ON d.id = s.device_id").
+		Joins(join, args...).
+		Where("d.site_id IN ?", siteIDs).
+		Group("s.type, s.unit").
+		Order("s.type, s.unit").
+		Scan(&stats).Error
+	return stats, err
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"mertani_test/internal/entity"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WebhookRepository struct {
+	Repository[entity.Webhook]
+	Log *logrus.Logger
+}
+
+func NewWebhookRepository(log *logrus.Logger) *WebhookRepository {
+	return &WebhookRepository{
+		Log: log,
+	}
+}
+
+func (r *WebhookRepository) FindActive(db *gorm.DB) ([]entity.Webhook, error) {
+	var webhooks []entity.Webhook
+	err := db.Where("is_active = ?", true).Find(&webhooks).Error
+	return webhooks, err
+}
+
+type WebhookDeliveryRepository struct {
+	Repository[entity.WebhookDelivery]
+	Log *logrus.Logger
+}
+
+func NewWebhookDeliveryRepository(log *logrus.Logger) *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{
+		Log: log,
+	}
+}
+
+func (r *WebhookDeliveryRepository) CreateAll(db *gorm.DB, deliveries []entity.WebhookDelivery) error {
+	return db.Omit(clause.Associations).Create(&deliveries).Error
+}
+
+func (r *WebhookDeliveryRepository) ExistsByEventID(db *gorm.DB, eventID any) (bool, error) {
+	var count int64
+	err := db.Model(&entity.WebhookDelivery{}).Where("event_id = ?", eventID).Count(&count).Error
+	return count > 0, err
+}
+
+// ClaimDue takes the pending delivery that is due first, counts the attempt
+// and leases it until leaseUntil, or returns nil when none is due. An
+// attempt that never reports back is simply retried once the lease ends.
+func (r *WebhookDeliveryRepository) ClaimDue(db *gorm.DB, now time.Time, leaseUntil time.Time) (*entity.WebhookDelivery, error) {
+	var deliveries []entity.WebhookDelivery
+	err := db.Raw(`UPDATE webhook_deliveries SET attempts = attempts + 1, next_attempt_at = ?, updated_at = ?
+		WHERE id = (
+			SELECT id FROM webhook_deliveries WHERE status = ? AND next_attempt_at <= ?
+			ORDER BY next_attempt_at
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING *`, leaseUntil, now, entity.WebhookDeliveryPending, now).
+		Scan(&deliveries).Error
+	if err != nil || len(deliveries) == 0 {
+		return nil, err
+	}
+	return &deliveries[0], nil
+}
+
+func (r *WebhookDeliveryRepository) FindByWebhook(db *gorm.DB, delivery *entity.WebhookDelivery, webhookID any, id any) (*entity.WebhookDelivery, error) {
+	if err := db.Where("webhook_id = ? AND id = ?", webhookID, id).Take(delivery).Error; err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// ByWebhookDeliveryStatus lists the deliveries of a webhook, optionally
+// only those in one status.
+func ByWebhookDeliveryStatus(webhookID any, status string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Where("webhook_id = ?", webhookID)
+		if status != "" {
+			db = db.Where("status = ?", status)
+		}
+		return db
+	}
+}
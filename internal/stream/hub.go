@@ -0,0 +1,188 @@
+package stream
+
+import (
+	"mertani_test/internal/utils"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Event types published to the hub.
+const (
+	EventReading       = "reading"
+	EventAlert         = "alert"
+	EventDeviceStatus  = "device_status"
+	EventDeviceCreated = "device_created"
+	EventDeviceUpdated = "device_updated"
+	EventDeviceDeleted = "device_deleted"
+)
+
+// DefaultBuffer is the number of events queued per subscriber when the hub
+// is created without a size.
+const DefaultBuffer = 256
+
+// Event is a change pushed to live subscribers. Labels are those of the
+// sensor for readings and of the device otherwise, and only serve matching.
+type Event struct {
+	ID       uint64            `json:"id"`
+	Type     string            `json:"type"`
+	DeviceID string            `json:"device_id,omitempty"`
+	SensorID string            `json:"sensor_id,omitempty"`
+	Time     time.Time         `json:"time"`
+	Data     any               `json:"data"`
+	Labels   map[string]string `json:"-"`
+}
+
+// Filter selects the events a subscriber receives. Types narrows by event
+// type; the ids and the selector are alternatives, so an event matching any
+// of them is delivered. A filter without ids or selector matches every
+// event of its types.
+type Filter struct {
+	Types     map[string]bool
+	DeviceIDs map[string]bool
+	SensorIDs map[string]bool
+	Selector  utils.LabelSelector
+}
+
+func (f *Filter) Matches(event *Event) bool {
+	if len(f.Types) > 0 && !f.Types[event.Type] {
+		return false
+	}
+	if len(f.DeviceIDs) == 0 && len(f.SensorIDs) == 0 && len(f.Selector) == 0 {
+		return true
+	}
+	if event.DeviceID != "" && f.DeviceIDs[event.DeviceID] {
+		return true
+	}
+	if event.SensorID != "" && f.SensorIDs[event.SensorID] {
+		return true
+	}
+	return len(f.Selector) > 0 && f.Selector.Matches(event.Labels)
+}
+
+// Hub fans events out to subscribers. Publishing never blocks: each
+// subscriber has a bounded queue, events that do not fit are dropped and
+// counted, and a subscriber that keeps its queue full for more than a
+// queue's worth of events is disconnected.
+type Hub struct {
+	mu          sync.RWMutex
+	subscribers map[*Subscriber]struct{}
+	buffer      int
+	sequence    atomic.Uint64
+}
+
+func NewHub(buffer int) *Hub {
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+	return &Hub{
+		subscribers: map[*Subscriber]struct{}{},
+		buffer:      buffer,
+	}
+}
+
+// Subscribe registers a subscriber with the hub's queue size; it must be
+// released with Unsubscribe.
+func (h *Hub) Subscribe(filter *Filter) *Subscriber {
+	return h.SubscribeSize(filter, h.buffer)
+}
+
+// SubscribeSize registers a subscriber with its own queue size, for
+// in-process consumers that absorb bursts larger than a client's.
+func (h *Hub) SubscribeSize(filter *Filter, buffer int) *Subscriber {
+	if buffer <= 0 {
+		buffer = h.buffer
+	}
+	subscriber := &Subscriber{
+		events: make(chan Event, buffer),
+		done:   make(chan struct{}),
+	}
+	subscriber.filter.Store(filter)
+
+	h.mu.Lock()
+	h.subscribers[subscriber] = struct{}{}
+	h.mu.Unlock()
+	return subscriber
+}
+
+func (h *Hub) Unsubscribe(subscriber *Subscriber) {
+	h.mu.Lock()
+	delete(h.subscribers, subscriber)
+	h.mu.Unlock()
+	subscriber.close()
+}
+
+// Subscribers returns the number of connected subscribers.
+func (h *Hub) Subscribers() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subscribers)
+}
+
+// Publish stamps the event with the next id and delivers it to every
+// matching subscriber without waiting on any of them.
+func (h *Hub) Publish(event Event) {
+	event.ID = h.sequence.Add(1)
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for subscriber := range h.subscribers {
+		if subscriber.filter.Load().Matches(&event) {
+			subscriber.offer(event)
+		}
+	}
+}
+
+// Subscriber receives the events matching its filter on Events until Done
+// is closed, either by Unsubscribe or because it fell too far behind.
+type Subscriber struct {
+	events  chan Event
+	done    chan struct{}
+	once    sync.Once
+	filter  atomic.Pointer[Filter]
+	dropped atomic.Int64
+	lagging atomic.Int64
+}
+
+func (s *Subscriber) Events() <-chan Event {
+	return s.events
+}
+
+func (s *Subscriber) Done() <-chan struct{} {
+	return s.done
+}
+
+// SetFilter replaces the filter for events published from now on.
+func (s *Subscriber) SetFilter(filter *Filter) {
+	s.filter.Store(filter)
+}
+
+// TakeDropped returns the number of events dropped since the last call.
+func (s *Subscriber) TakeDropped() int64 {
+	return s.dropped.Swap(0)
+}
+
+func (s *Subscriber) offer(event Event) {
+	select {
+	case <-s.done:
+		return
+	default:
+	}
+
+	select {
+	case s.events <- event:
+		s.lagging.Store(0)
+	default:
+		s.dropped.Add(1)
+		if s.lagging.Add(1) > int64(cap(s.events)) {
+			s.close()
+		}
+	}
+}
+
+func (s *Subscriber) close() {
+	s.once.Do(func() { close(s.done) })
+}
@@ -0,0 +1,237 @@
+package stream
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// WebSocket opcodes and close codes used here, see RFC 6455.
+const (
+	OpText   = 0x1
+	OpBinary = 0x2
+	opClose  = 0x8
+	opPing   = 0x9
+	opPong   = 0xa
+
+	CloseNormal          = 1000
+	CloseGoingAway       = 1001
+	CloseProtocolError   = 1002
+	ClosePolicyViolation = 1008
+	CloseTooBig          = 1009
+)
+
+// MaxMessageSize bounds messages read from clients, which only send small
+// subscription requests.
+const MaxMessageSize = 64 * 1024
+
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+var (
+	ErrWebSocketClosed   = errors.New("websocket closed")
+	errWebSocketProtocol = errors.New("websocket protocol error")
+	errWebSocketTooBig   = errors.New("websocket message too big")
+)
+
+// AcceptKey computes Sec-WebSocket-Accept for a client's Sec-WebSocket-Key.
+func AcceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// WebSocket is the server side of an upgraded connection. Reads must come
+// from a single goroutine; writes may come from any.
+type WebSocket struct {
+	conn         net.Conn
+	reader       *bufio.Reader
+	writeMu      sync.Mutex
+	writeTimeout time.Duration
+	closeSent    bool
+}
+
+func NewWebSocket(conn net.Conn, writeTimeout time.Duration) *WebSocket {
+	return &WebSocket{
+		conn:         conn,
+		reader:       bufio.NewReader(conn),
+		writeTimeout: writeTimeout,
+	}
+}
+
+// ReadMessage returns the next data message, reassembling fragments. Pings
+// are answered and pongs skipped along the way; a close frame is echoed and
+// reported as ErrWebSocketClosed. Every frame read extends the deadline by
+// idle, so clients answering pings stay connected.
+func (ws *WebSocket) ReadMessage(idle time.Duration) (int, []byte, error) {
+	var (
+		opcode  int
+		message []byte
+	)
+	for {
+		if err := ws.conn.SetReadDeadline(time.Now().Add(idle)); err != nil {
+			return 0, nil, err
+		}
+		fin, frameOpcode, payload, err := ws.readFrame()
+		if err != nil {
+			if errors.Is(err, errWebSocketTooBig) {
+				ws.WriteClose(CloseTooBig, "message too big")
+			} else if errors.Is(err, errWebSocketProtocol) {
+				ws.WriteClose(CloseProtocolError, "protocol error")
+			}
+			return 0, nil, err
+		}
+
+		switch frameOpcode {
+		case opPing:
+			if err := ws.writeFrame(opPong, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			code := CloseNormal
+			if len(payload) >= 2 {
+				code = int(binary.BigEndian.Uint16(payload))
+			}
+			ws.WriteClose(code, "")
+			return 0, nil, ErrWebSocketClosed
+		case 0:
+			// a continuation needs a message to continue
+			if opcode == 0 {
+				ws.WriteClose(CloseProtocolError, "protocol error")
+				return 0, nil, errWebSocketProtocol
+			}
+		default:
+			// a new message cannot start before the last one finished
+			if opcode != 0 {
+				ws.WriteClose(CloseProtocolError, "protocol error")
+				return 0, nil, errWebSocketProtocol
+			}
+			opcode = frameOpcode
+		}
+
+		if len(message)+len(payload) > MaxMessageSize {
+			ws.WriteClose(CloseTooBig, "message too big")
+			return 0, nil, errWebSocketTooBig
+		}
+		message = append(message, payload...)
+		if fin {
+			return opcode, message, nil
+		}
+	}
+}
+
+func (ws *WebSocket) readFrame() (bool, int, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(ws.reader, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin := header[0]&0x80 != 0
+	opcode := int(header[0] & 0x0f)
+	masked := header[1]&0x80 != 0
+	length := uint64(header[1] & 0x7f)
+
+	// no extensions are negotiated and clients must mask
+	if header[0]&0x70 != 0 || !masked {
+		return false, 0, nil, errWebSocketProtocol
+	}
+	if opcode >= opClose && (!fin || length > 125) {
+		return false, 0, nil, errWebSocketProtocol
+	}
+
+	switch length {
+	case 126:
+		var extended [2]byte
+		if _, err := io.ReadFull(ws.reader, extended[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		if _, err := io.ReadFull(ws.reader, extended[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(extended[:])
+	}
+	if length > MaxMessageSize {
+		return false, 0, nil, errWebSocketTooBig
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(ws.reader, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(ws.reader, payload); err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return fin, opcode, payload, nil
+}
+
+// WriteText sends a single-frame text message.
+func (ws *WebSocket) WriteText(p []byte) error {
+	return ws.writeFrame(OpText, p)
+}
+
+func (ws *WebSocket) WritePing() error {
+	return ws.writeFrame(opPing, nil)
+}
+
+// WriteClose starts, or answers, the closing handshake. Only the first
+// close frame is sent.
+func (ws *WebSocket) WriteClose(code int, reason string) error {
+	if len(reason) > 123 {
+		reason = reason[:123]
+	}
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	payload = append(payload, reason...)
+	return ws.writeFrame(opClose, payload)
+}
+
+func (ws *WebSocket) writeFrame(opcode int, payload []byte) error {
+	ws.writeMu.Lock()
+	defer ws.writeMu.Unlock()
+	if ws.closeSent {
+		return ErrWebSocketClosed
+	}
+	if opcode == opClose {
+		ws.closeSent = true
+	}
+
+	// server frames are never masked
+	frame := make([]byte, 0, 10+len(payload))
+	frame = append(frame, 0x80|byte(opcode))
+	switch {
+	case len(payload) <= 125:
+		frame = append(frame, byte(len(payload)))
+	case len(payload) <= 0xffff:
+		frame = append(frame, 126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(len(payload)))
+	default:
+		frame = append(frame, 127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(len(payload)))
+	}
+	frame = append(frame, payload...)
+
+	if ws.writeTimeout > 0 {
+		if err := ws.conn.SetWriteDeadline(time.Now().Add(ws.writeTimeout)); err != nil {
+			return err
+		}
+	}
+	_, err := ws.conn.Write(frame)
+	return err
+}
+
+func (ws *WebSocket) Close() error {
+	return ws.conn.Close()
+}
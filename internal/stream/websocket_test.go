@@ -0,0 +1,303 @@
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// websocketPair returns the server side of a connection and the client end
+// it reads from, over loopback TCP so writes are buffered like in practice.
+func websocketPair(t *testing.T) (*WebSocket, net.Conn, *bufio.Reader) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			close(accepted)
+			return
+		}
+		accepted <- conn
+	}()
+
+	client, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, ok := <-accepted
+	if !ok {
+		t.Fatal("accept failed")
+	}
+
+	ws := NewWebSocket(server, time.Second)
+	t.Cleanup(func() {
+		ws.Close()
+		client.Close()
+	})
+	client.SetDeadline(time.Now().Add(5 * time.Second))
+	return ws, client, bufio.NewReader(client)
+}
+
+// clientFrame encodes a frame the way a client must send it, masked.
+func clientFrame(fin bool, opcode int, payload []byte) []byte {
+	first := byte(opcode)
+	if fin {
+		first |= 0x80
+	}
+	frame := []byte{first}
+	switch {
+	case len(payload) <= 125:
+		frame = append(frame, 0x80|byte(len(payload)))
+	case len(payload) <= 0xffff:
+		frame = append(frame, 0x80|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(len(payload)))
+	default:
+		frame = append(frame, 0x80|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(len(payload)))
+	}
+
+	mask := [4]byte{0x37, 0xfa, 0x21, 0x3d}
+	frame = append(frame, mask[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	return frame
+}
+
+// readServerFrame decodes a frame sent by the server, which is never masked.
+func readServerFrame(t *testing.T, reader *bufio.Reader) (int, []byte) {
+	t.Helper()
+
+	var header [2]byte
+	if _, err := io.ReadFull(reader, header[:]); err != nil {
+		t.Fatalf("read frame: %v", err)
+	}
+	if header[0]&0x80 == 0 {
+		t.Fatalf("server frame without FIN: %#x", header[0])
+	}
+	if header[1]&0x80 != 0 {
+		t.Fatal("server frame is masked")
+	}
+	length := int(header[1] & 0x7f)
+	switch length {
+	case 126:
+		var extended [2]byte
+		io.ReadFull(reader, extended[:])
+		length = int(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		io.ReadFull(reader, extended[:])
+		length = int(binary.BigEndian.Uint64(extended[:]))
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		t.Fatalf("read payload: %v", err)
+	}
+	return int(header[0] & 0x0f), payload
+}
+
+func expectClose(t *testing.T, reader *bufio.Reader, code int) {
+	t.Helper()
+
+	opcode, payload := readServerFrame(t, reader)
+	if opcode != opClose {
+		t.Fatalf("opcode = %#x, want close", opcode)
+	}
+	if len(payload) < 2 || int(binary.BigEndian.Uint16(payload)) != code {
+		t.Fatalf("close payload = %v, want code %d", payload, code)
+	}
+}
+
+func TestAcceptKey(t *testing.T) {
+	// the example of RFC 6455, section 1.3
+	if got := AcceptKey("dGhlIHNhbXBsZSBub25jZQ=="); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("AcceptKey = %q", got)
+	}
+}
+
+func TestReadMessageMasked(t *testing.T) {
+	ws, client, _ := websocketPair(t)
+
+	// the masked "Hello" of RFC 6455, section 5.7
+	client.Write([]byte{0x81, 0x85, 0x37, 0xfa, 0x21, 0x3d, 0x7f, 0x9f, 0x4d, 0x51, 0x58})
+
+	opcode, message, err := ws.ReadMessage(time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opcode != OpText || string(message) != "Hello" {
+		t.Fatalf("got %#x %q", opcode, message)
+	}
+}
+
+func TestReadMessageExtendedLength(t *testing.T) {
+	ws, client, _ := websocketPair(t)
+
+	payload := bytes.Repeat([]byte("x"), 300)
+	client.Write(clientFrame(true, OpBinary, payload))
+
+	opcode, message, err := ws.ReadMessage(time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opcode != OpBinary || !bytes.Equal(message, payload) {
+		t.Fatalf("got %#x with %d bytes", opcode, len(message))
+	}
+}
+
+func TestReadMessageFragmented(t *testing.T) {
+	ws, client, reader := websocketPair(t)
+
+	// a ping may arrive between the fragments of a message
+	client.Write(clientFrame(false, OpText, []byte("Hel")))
+	client.Write(clientFrame(true, opPing, []byte("are you there")))
+	client.Write(clientFrame(true, 0, []byte("lo")))
+
+	opcode, message, err := ws.ReadMessage(time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opcode != OpText || string(message) != "Hello" {
+		t.Fatalf("got %#x %q", opcode, message)
+	}
+
+	opcode, payload := readServerFrame(t, reader)
+	if opcode != opPong || string(payload) != "are you there" {
+		t.Fatalf("got %#x %q, want the pong", opcode, payload)
+	}
+}
+
+func TestReadMessageContinuationWithoutStart(t *testing.T) {
+	ws, client, reader := websocketPair(t)
+
+	client.Write(clientFrame(true, 0, []byte("lo")))
+
+	if _, _, err := ws.ReadMessage(time.Second); !errors.Is(err, errWebSocketProtocol) {
+		t.Fatalf("err = %v, want protocol error", err)
+	}
+	expectClose(t, reader, CloseProtocolError)
+}
+
+func TestReadMessageInterleaved(t *testing.T) {
+	ws, client, reader := websocketPair(t)
+
+	client.Write(clientFrame(false, OpText, []byte("Hel")))
+	client.Write(clientFrame(true, OpText, []byte("lo")))
+
+	if _, _, err := ws.ReadMessage(time.Second); !errors.Is(err, errWebSocketProtocol) {
+		t.Fatalf("err = %v, want protocol error", err)
+	}
+	expectClose(t, reader, CloseProtocolError)
+}
+
+func TestReadMessageUnmasked(t *testing.T) {
+	ws, client, reader := websocketPair(t)
+
+	client.Write([]byte{0x81, 0x05, 'H', 'e', 'l', 'l', 'o'})
+
+	if _, _, err := ws.ReadMessage(time.Second); !errors.Is(err, errWebSocketProtocol) {
+		t.Fatalf("err = %v, want protocol error", err)
+	}
+	expectClose(t, reader, CloseProtocolError)
+}
+
+func TestReadMessageTooBig(t *testing.T) {
+	t.Run("frame", func(t *testing.T) {
+		ws, client, reader := websocketPair(t)
+
+		// rejected from the header, before any payload is read
+		header := []byte{0x82, 0x80 | 127}
+		header = binary.BigEndian.AppendUint64(header, MaxMessageSize+1)
+		client.Write(header)
+
+		if _, _, err := ws.ReadMessage(time.Second); !errors.Is(err, errWebSocketTooBig) {
+			t.Fatalf("err = %v, want too big", err)
+		}
+		expectClose(t, reader, CloseTooBig)
+	})
+
+	t.Run("fragments", func(t *testing.T) {
+		ws, client, reader := websocketPair(t)
+
+		fragment := bytes.Repeat([]byte("x"), MaxMessageSize/2+1)
+		client.Write(clientFrame(false, OpBinary, fragment))
+		client.Write(clientFrame(true, 0, fragment))
+
+		if _, _, err := ws.ReadMessage(time.Second); !errors.Is(err, errWebSocketTooBig) {
+			t.Fatalf("err = %v, want too big", err)
+		}
+		expectClose(t, reader, CloseTooBig)
+	})
+}
+
+func TestPingPong(t *testing.T) {
+	ws, client, reader := websocketPair(t)
+
+	if err := ws.WritePing(); err != nil {
+		t.Fatal(err)
+	}
+	if opcode, payload := readServerFrame(t, reader); opcode != opPing || len(payload) != 0 {
+		t.Fatalf("got %#x %q, want an empty ping", opcode, payload)
+	}
+
+	// the pong answering it is skipped
+	client.Write(clientFrame(true, opPong, nil))
+	client.Write(clientFrame(true, OpText, []byte("after pong")))
+
+	_, message, err := ws.ReadMessage(time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(message) != "after pong" {
+		t.Fatalf("message = %q", message)
+	}
+}
+
+func TestCloseHandshake(t *testing.T) {
+	ws, client, reader := websocketPair(t)
+
+	client.Write(clientFrame(true, opClose, binary.BigEndian.AppendUint16(nil, CloseGoingAway)))
+
+	if _, _, err := ws.ReadMessage(time.Second); !errors.Is(err, ErrWebSocketClosed) {
+		t.Fatalf("err = %v, want closed", err)
+	}
+	expectClose(t, reader, CloseGoingAway)
+
+	// only one close frame is sent, and nothing after it
+	if err := ws.WriteText([]byte("late")); !errors.Is(err, ErrWebSocketClosed) {
+		t.Fatalf("write after close: %v", err)
+	}
+	if err := ws.WriteClose(CloseNormal, ""); !errors.Is(err, ErrWebSocketClosed) {
+		t.Fatalf("second close: %v", err)
+	}
+}
+
+func TestWriteTextLengths(t *testing.T) {
+	for _, size := range []int{0, 125, 126, 0xffff, 0x10000} {
+		ws, _, reader := websocketPair(t)
+
+		payload := bytes.Repeat([]byte("y"), size)
+		done := make(chan error, 1)
+		go func() { done <- ws.WriteText(payload) }()
+
+		opcode, got := readServerFrame(t, reader)
+		if err := <-done; err != nil {
+			t.Fatal(err)
+		}
+		if opcode != OpText || !bytes.Equal(got, payload) {
+			t.Fatalf("size %d: got %#x with %d bytes", size, opcode, len(got))
+		}
+	}
+}
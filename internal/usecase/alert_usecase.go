@@ -0,0 +1,1076 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mertani_test/internal/entity"
+	"mertani_test/internal/event"
+	"mertani_test/internal/model"
+	"mertani_test/internal/model/converter"
+	"mertani_test/internal/repository"
+	"mertani_test/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	// alertSchedulerInterval is how often the scheduler applies silences
+	// and escalates alerts unless configured otherwise; new silences wake
+	// it right away.
+	alertSchedulerInterval = 30 * time.Second
+	// alertEscalationBatch bounds the escalations handled per round.
+	alertEscalationBatch = 100
+)
+
+// AlertUseCase keeps the lifecycle of alerts: raising, acknowledging,
+// resolving and commenting on them, silences, and escalation policies. A
+// scheduler goroutine mutes alerts matched by silences, notifies them again
+// once their silence ends, and escalates alerts left unacknowledged. Every
+// change notifications care about is recorded in the outbox as an alert or
+// alert_escalated event. Anomaly events relayed from the outbox raise
+// alerts for the detectors asking for them.
+type AlertUseCase struct {
+	DB                            *gorm.DB
+	Log                           *logrus.Logger
+	Validator                     *utils.Validator
+	AlertRepository               *repository.AlertRepository
+	AlertCommentRepository        *repository.AlertCommentRepository
+	SilenceRepository             *repository.SilenceRepository
+	EscalationPolicyRepository    *repository.EscalationPolicyRepository
+	NotificationChannelRepository *repository.NotificationChannelRepository
+	DeviceRepository              *repository.DeviceRepository
+	SensorRepository              *repository.SensorRepository
+	AnomalyRepository             *repository.AnomalyRepository
+	Outbox                        *OutboxUseCase
+	Interval                      time.Duration
+	wake                          chan struct{}
+}
+
+func NewAlertUseCase(db *gorm.DB, logger *logrus.Logger, validator *utils.Validator,
+	alertRepository *repository.AlertRepository, alertCommentRepository *repository.AlertCommentRepository,
+	silenceRepository *repository.SilenceRepository, escalationPolicyRepository *repository.EscalationPolicyRepository,
+	notificationChannelRepository *repository.NotificationChannelRepository,
+	deviceRepository *repository.DeviceRepository, sensorRepository *repository.SensorRepository,
+	anomalyRepository *repository.AnomalyRepository, outbox *OutboxUseCase, interval time.Duration) *AlertUseCase {
+	if interval <= 0 {
+		interval = alertSchedulerInterval
+	}
+	return &AlertUseCase{
+		DB:                            db,
+		Log:                           logger,
+		Validator:                     validator,
+		AlertRepository:               alertRepository,
+		AlertCommentRepository:        alertCommentRepository,
+		SilenceRepository:             silenceRepository,
+		EscalationPolicyRepository:    escalationPolicyRepository,
+		NotificationChannelRepository: notificationChannelRepository,
+		DeviceRepository:              deviceRepository,
+		SensorRepository:              sensorRepository,
+		AnomalyRepository:             anomalyRepository,
+		Outbox:                        outbox,
+		Interval:                      interval,
+		wake:                          make(chan struct{}, 1),
+	}
+}
+
+// Raise fires an alert about a device or sensor, or about neither. When an
+// alert with the same fingerprint is firing already it is updated instead,
+// and notified again unless it is acknowledged or silenced.
+func (c *AlertUseCase) Raise(ctx context.Context, request *model.RaiseAlertRequest) (*model.AlertResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := c.validate(request); err != nil {
+		return nil, err
+	}
+
+	alert, err := c.newAlert(c.DB.WithContext(ctx), request)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		raised, err := c.raise(tx, alert, alert.StartsAt)
+		alert = raised
+		return err
+	})
+	if err != nil {
+		c.Log.Warnf("Failed raise alert : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	c.Outbox.Notify()
+
+	return converter.AlertToResponse(alert), nil
+}
+
+// HandleEvent raises an alert for an anomaly event relayed from the outbox
+// when its detector asks for one. Each anomaly raises at most once; while
+// its alert fires, further anomalies of the same detector and sensor update
+// it. Anomalies whose sensor is gone are dropped.
+func (c *AlertUseCase) HandleEvent(ctx context.Context, envelope event.Envelope) error {
+	if envelope.Type != event.TypeAnomaly {
+		return nil
+	}
+
+	var found event.Anomaly
+	if err := envelope.Decode(&found); err != nil {
+		c.Log.Warnf("Failed decode anomaly event %s : %+v", envelope.ID, err)
+		return nil
+	}
+	if !found.RaiseAlert {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	value := found.Value
+	alert, err := c.newAlert(c.DB.WithContext(ctx), &model.RaiseAlertRequest{
+		Fingerprint: "anomaly:" + found.Detector + ":" + found.SensorID,
+		Severity:    found.Severity,
+		Title:       truncate(fmt.Sprintf("Anomaly on %s: %s", found.SensorName, found.Detector), 200),
+		Message:     found.Message,
+		SensorID:    found.SensorID,
+		Value:       &value,
+	})
+	if err != nil {
+		if errors.Is(err, utils.ErrValidation) {
+			c.Log.Infof("Dropping anomaly event %s : %+v", envelope.ID, err)
+			return nil
+		}
+		return err
+	}
+
+	err = c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		raised, err := c.raise(tx, alert, alert.StartsAt)
+		if err != nil {
+			return err
+		}
+		linked, err := c.AnomalyRepository.LinkAlert(tx, found.ID, raised.ID)
+		if err != nil {
+			return err
+		}
+		if !linked {
+			return errAlreadyRaised
+		}
+		return nil
+	})
+	if errors.Is(err, errAlreadyRaised) {
+		return nil
+	}
+	if err != nil {
+		c.Log.Warnf("Failed raise alert for anomaly %s : %+v", found.ID, err)
+		return err
+	}
+	c.Outbox.Notify()
+
+	return nil
+}
+
+// errAlreadyRaised rolls back raising an alert for an anomaly that raised
+// one already.
+var errAlreadyRaised = errors.New("anomaly already raised an alert")
+
+// newAlert builds a firing alert from a request, taking the device, sensor
+// and labels of the sensor or device it is about.
+func (c *AlertUseCase) newAlert(db *gorm.DB, request *model.RaiseAlertRequest) (*entity.Alert, error) {
+	alert := &entity.Alert{
+		Fingerprint: request.Fingerprint,
+		Status:      entity.AlertStatusFiring,
+		Severity:    request.Severity,
+		Title:       request.Title,
+		Message:     request.Message,
+		Value:       request.Value,
+		Labels:      entity.Labels{},
+		StartsAt:    time.Now(),
+	}
+
+	switch {
+	case request.SensorID != "":
+		sensor := &entity.Sensor{}
+		if _, err := c.SensorRepository.FindByIdWithDevice(db, sensor, request.SensorID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("%w: sensor not found", utils.ErrValidation)
+			}
+			c.Log.Warnf("Failed find sensor from database : %+v", err)
+			return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+		}
+		if request.DeviceID != "" && request.DeviceID != sensor.DeviceID.String() {
+			return nil, fmt.Errorf("%w: sensor does not belong to device", utils.ErrValidation)
+		}
+		alert.DeviceID = &sensor.DeviceID
+		alert.DeviceName = sensor.Device.Name
+		alert.SensorID = &sensor.ID
+		alert.SensorName = sensor.Name
+		alert.SensorType = sensor.Type
+		alert.Unit = sensor.Unit
+		for key, value := range sensor.Device.Labels {
+			alert.Labels[key] = value
+		}
+		for key, value := range sensor.Labels {
+			alert.Labels[key] = value
+		}
+
+	case request.DeviceID != "":
+		device := &entity.Device{}
+		if _, err := c.DeviceRepository.FindById(db, device, request.DeviceID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("%w: device not found", utils.ErrValidation)
+			}
+			c.Log.Warnf("Failed find device from database : %+v", err)
+			return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+		}
+		alert.DeviceID = &device.ID
+		alert.DeviceName = device.Name
+		for key, value := range device.Labels {
+			alert.Labels[key] = value
+		}
+	}
+
+	for key, value := range request.Labels {
+		alert.Labels[key] = value
+	}
+	if alert.Fingerprint == "" {
+		alert.Fingerprint = alertFingerprint(alert)
+	}
+
+	return alert, nil
+}
+
+// raise stores a new alert, or updates the firing one with its fingerprint,
+// and records the alert event unless nobody is to be notified.
+func (c *AlertUseCase) raise(tx *gorm.DB, alert *entity.Alert, now time.Time) (*entity.Alert, error) {
+	firing, err := c.AlertRepository.FindFiringByFingerprint(tx, alert.Fingerprint)
+	if err != nil {
+		return nil, err
+	}
+
+	if firing != nil {
+		firing.Severity = alert.Severity
+		firing.Title = alert.Title
+		firing.Message = alert.Message
+		firing.Value = alert.Value
+		firing.Labels = alert.Labels
+		if err := c.AlertRepository.Update(tx, firing); err != nil {
+			return nil, err
+		}
+		if firing.SilenceID != nil || firing.AcknowledgedAt != nil {
+			return firing, nil
+		}
+		return firing, c.Outbox.Record(tx, &event.Alert{AlertEvent: *converter.AlertToEvent(firing)})
+	}
+
+	silences, err := c.SilenceRepository.FindActive(tx, now)
+	if err != nil {
+		return nil, err
+	}
+	if silence := matchSilence(silences, alert); silence != nil {
+		alert.SilenceID = &silence.ID
+	}
+
+	policies, err := c.EscalationPolicyRepository.FindActive(tx)
+	if err != nil {
+		return nil, err
+	}
+	if policy := matchEscalationPolicy(policies, alert); policy != nil {
+		alert.EscalationPolicyID = &policy.ID
+		if alert.SilenceID == nil {
+			next := now.Add(time.Duration(policy.Steps[0].AfterMinutes) * time.Minute)
+			alert.NextEscalationAt = &next
+		}
+	}
+
+	if err := c.AlertRepository.Create(tx, alert); err != nil {
+		return nil, err
+	}
+	if alert.SilenceID != nil {
+		return alert, nil
+	}
+	return alert, c.Outbox.Record(tx, &event.Alert{AlertEvent: *converter.AlertToEvent(alert)})
+}
+
+// FindAll lists alerts, newest first unless asked otherwise.
+func (c *AlertUseCase) FindAll(ctx context.Context, pagination *utils.PaginationRequest, filter *model.AlertFilter) ([]model.AlertResponse, *utils.PaginationResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	switch filter.Status {
+	case "", entity.AlertStatusFiring, entity.AlertStatusResolved:
+	default:
+		return nil, nil, fmt.Errorf("%w: status must be one of firing, resolved", utils.ErrValidation)
+	}
+	if filter.Severity != "" && entity.AlertSeverityRank(filter.Severity) == 0 {
+		return nil, nil, fmt.Errorf("%w: severity must be one of info, warning, critical", utils.ErrValidation)
+	}
+	for _, id := range []string{filter.DeviceID, filter.SensorID} {
+		if _, err := uuid.Parse(id); id != "" && err != nil {
+			return nil, nil, fmt.Errorf("%w: invalid id %q", utils.ErrValidation, id)
+		}
+	}
+
+	var alerts []entity.Alert
+	total, err := c.AlertRepository.FindAll(c.DB.WithContext(ctx), &alerts, pagination,
+		repository.ByAlertFilter(filter.Status, filter.Severity, filter.Acknowledged, filter.DeviceID, filter.SensorID))
+	if err != nil {
+		c.Log.Warnf("Failed find all alert from database : %+v", err)
+		return nil, nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	responses := make([]model.AlertResponse, len(alerts))
+	for i, alert := range alerts {
+		responses[i] = *converter.AlertToResponse(&alert)
+	}
+
+	totalPage := int((total + int64(pagination.Limit) - 1) / int64(pagination.Limit))
+
+	paginationRes := &utils.PaginationResponse{
+		Page:      pagination.Page,
+		Limit:     pagination.Limit,
+		OrderBy:   pagination.OrderBy,
+		SortBy:    pagination.SortBy,
+		Search:    pagination.Search,
+		TotalData: total,
+		TotalPage: totalPage,
+	}
+
+	return responses, paginationRes, nil
+}
+
+// FindByID returns an alert with its comments, oldest first.
+func (c *AlertUseCase) FindByID(ctx context.Context, alertID string) (*model.AlertResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	alert := &entity.Alert{}
+	if _, err := c.AlertRepository.FindByIdWithComments(c.DB.WithContext(ctx), alert, alertID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Infof("Alert not found, id=%s", alertID)
+			return nil, utils.ErrNotFound
+		}
+		c.Log.Warnf("Failed find alert from database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	return converter.AlertToResponse(alert), nil
+}
+
+// Acknowledge marks a firing alert as being taken care of, which stops its
+// escalation. The optional comment is added to the alert.
+func (c *AlertUseCase) Acknowledge(ctx context.Context, alertID string, request *model.AcknowledgeAlertRequest) (*model.AlertResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := c.validate(request); err != nil {
+		return nil, err
+	}
+
+	alert, err := c.findAlert(c.DB.WithContext(ctx), alertID)
+	if err != nil {
+		return nil, err
+	}
+	if alert.Status == entity.AlertStatusResolved {
+		return nil, fmt.Errorf("%w: %s", utils.ErrConflict, "alert already resolved")
+	}
+	if alert.AcknowledgedAt != nil {
+		return nil, fmt.Errorf("%w: %s", utils.ErrConflict, "alert already acknowledged")
+	}
+
+	now := time.Now()
+	alert.AcknowledgedAt = &now
+	alert.AcknowledgedBy = request.By
+	alert.NextEscalationAt = nil
+
+	err = c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := c.AlertRepository.Update(tx, alert); err != nil {
+			return err
+		}
+		return c.comment(tx, alert, request.By, request.Comment)
+	})
+	if err != nil {
+		c.Log.Warnf("Failed acknowledge alert : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	return converter.AlertToResponse(alert), nil
+}
+
+// Resolve ends an alert, acknowledged or not, and notifies the resolution
+// unless the alert is silenced. The optional comment is added to the alert.
+func (c *AlertUseCase) Resolve(ctx context.Context, alertID string, request *model.ResolveAlertRequest) (*model.AlertResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := c.validate(request); err != nil {
+		return nil, err
+	}
+
+	alert, err := c.findAlert(c.DB.WithContext(ctx), alertID)
+	if err != nil {
+		return nil, err
+	}
+	if alert.Status == entity.AlertStatusResolved {
+		return nil, fmt.Errorf("%w: %s", utils.ErrConflict, "alert already resolved")
+	}
+
+	now := time.Now()
+	alert.Status = entity.AlertStatusResolved
+	alert.EndsAt = &now
+	alert.ResolvedBy = request.By
+	alert.NextEscalationAt = nil
+
+	err = c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := c.AlertRepository.Update(tx, alert); err != nil {
+			return err
+		}
+		if err := c.comment(tx, alert, request.By, request.Comment); err != nil {
+			return err
+		}
+		if alert.SilenceID != nil {
+			return nil
+		}
+		return c.Outbox.Record(tx, &event.Alert{AlertEvent: *converter.AlertToEvent(alert)})
+	})
+	if err != nil {
+		c.Log.Warnf("Failed resolve alert : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	c.Outbox.Notify()
+
+	return converter.AlertToResponse(alert), nil
+}
+
+func (c *AlertUseCase) AddComment(ctx context.Context, alertID string, request *model.CreateAlertCommentRequest) (*model.AlertCommentResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := c.validate(request); err != nil {
+		return nil, err
+	}
+
+	alert, err := c.findAlert(c.DB.WithContext(ctx), alertID)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &entity.AlertComment{
+		AlertID: alert.ID,
+		Author:  request.Author,
+		Body:    request.Body,
+	}
+	if err := c.AlertCommentRepository.Create(c.DB.WithContext(ctx), comment); err != nil {
+		c.Log.Warnf("Failed create alert comment to database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	return converter.AlertCommentToResponse(comment), nil
+}
+
+// comment adds the comment given with an acknowledgement or resolution, if
+// any.
+func (c *AlertUseCase) comment(tx *gorm.DB, alert *entity.Alert, author string, body string) error {
+	if body == "" {
+		return nil
+	}
+	comment := entity.AlertComment{AlertID: alert.ID, Author: author, Body: body}
+	if err := c.AlertCommentRepository.Create(tx, &comment); err != nil {
+		return err
+	}
+	alert.Comments = append(alert.Comments, comment)
+	return nil
+}
+
+func (c *AlertUseCase) CreateSilence(ctx context.Context, request *model.CreateSilenceRequest) (*model.SilenceResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := c.validate(request); err != nil {
+		return nil, err
+	}
+
+	if request.Selector == "" && request.SensorType == "" {
+		return nil, fmt.Errorf("%w: silence needs a selector or a sensor_type", utils.ErrValidation)
+	}
+	if _, err := utils.ParseLabelSelector(request.Selector); err != nil {
+		return nil, fmt.Errorf("%w: selector: %s", utils.ErrValidation, err.Error())
+	}
+
+	now := time.Now()
+	startsAt := now
+	if request.StartsAt != "" {
+		startsAt, _ = time.Parse(time.RFC3339, request.StartsAt)
+	}
+
+	var endsAt time.Time
+	switch {
+	case request.EndsAt != "" && request.DurationMinutes > 0:
+		return nil, fmt.Errorf("%w: give ends_at or duration_minutes, not both", utils.ErrValidation)
+	case request.EndsAt != "":
+		endsAt, _ = time.Parse(time.RFC3339, request.EndsAt)
+	case request.DurationMinutes > 0:
+		endsAt = startsAt.Add(time.Duration(request.DurationMinutes) * time.Minute)
+	default:
+		return nil, fmt.Errorf("%w: ends_at or duration_minutes is required", utils.ErrValidation)
+	}
+	if !endsAt.After(startsAt) || !endsAt.After(now) {
+		return nil, fmt.Errorf("%w: ends_at must be after starts_at and in the future", utils.ErrValidation)
+	}
+
+	silence := &entity.Silence{
+		Selector:   request.Selector,
+		SensorType: request.SensorType,
+		StartsAt:   startsAt,
+		EndsAt:     endsAt,
+		Comment:    request.Comment,
+		CreatedBy:  request.CreatedBy,
+	}
+	if err := c.SilenceRepository.Create(c.DB.WithContext(ctx), silence); err != nil {
+		c.Log.Warnf("Failed create silence to database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	c.notify()
+
+	return converter.SilenceToResponse(silence, now), nil
+}
+
+func (c *AlertUseCase) FindAllSilences(ctx context.Context, pagination *utils.PaginationRequest, filter *model.SilenceFilter) ([]model.SilenceResponse, *utils.PaginationResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	switch filter.State {
+	case "", "pending", "active", "expired":
+	default:
+		return nil, nil, fmt.Errorf("%w: state must be one of pending, active, expired", utils.ErrValidation)
+	}
+
+	now := time.Now()
+	var silences []entity.Silence
+	total, err := c.SilenceRepository.FindAll(c.DB.WithContext(ctx), &silences, pagination, repository.BySilenceState(filter.State, now))
+	if err != nil {
+		c.Log.Warnf("Failed find all silence from database : %+v", err)
+		return nil, nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	responses := make([]model.SilenceResponse, len(silences))
+	for i, silence := range silences {
+		responses[i] = *converter.SilenceToResponse(&silence, now)
+	}
+
+	totalPage := int((total + int64(pagination.Limit) - 1) / int64(pagination.Limit))
+
+	paginationRes := &utils.PaginationResponse{
+		Page:      pagination.Page,
+		Limit:     pagination.Limit,
+		OrderBy:   pagination.OrderBy,
+		SortBy:    pagination.SortBy,
+		Search:    pagination.Search,
+		TotalData: total,
+		TotalPage: totalPage,
+	}
+
+	return responses, paginationRes, nil
+}
+
+func (c *AlertUseCase) FindSilenceByID(ctx context.Context, silenceID string) (*model.SilenceResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	silence, err := c.findSilence(c.DB.WithContext(ctx), silenceID)
+	if err != nil {
+		return nil, err
+	}
+	return converter.SilenceToResponse(silence, time.Now()), nil
+}
+
+// DeleteSilence removes a silence; alerts it muted are notified by the
+// scheduler if they still fire.
+func (c *AlertUseCase) DeleteSilence(ctx context.Context, silenceID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	silence, err := c.findSilence(c.DB.WithContext(ctx), silenceID)
+	if err != nil {
+		return err
+	}
+
+	if err := c.SilenceRepository.Delete(c.DB.WithContext(ctx), silence); err != nil {
+		c.Log.Warnf("Failed delete silence from database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	c.notify()
+
+	return nil
+}
+
+func (c *AlertUseCase) CreateEscalationPolicy(ctx context.Context, request *model.CreateEscalationPolicyRequest) (*model.EscalationPolicyResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := c.validate(request); err != nil {
+		return nil, err
+	}
+
+	policy := &entity.EscalationPolicy{
+		Name:        request.Name,
+		MinSeverity: request.MinSeverity,
+		Selector:    request.Selector,
+		SensorType:  request.SensorType,
+		Steps:       escalationSteps(request.Steps),
+		IsActive:    true,
+	}
+	if policy.MinSeverity == "" {
+		policy.MinSeverity = entity.AlertSeverityInfo
+	}
+	if request.IsActive != nil {
+		policy.IsActive = *request.IsActive
+	}
+
+	if err := c.validatePolicy(c.DB.WithContext(ctx), policy); err != nil {
+		return nil, err
+	}
+
+	if err := c.EscalationPolicyRepository.Create(c.DB.WithContext(ctx), policy); err != nil {
+		c.Log.Warnf("Failed create escalation policy to database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	return converter.EscalationPolicyToResponse(policy), nil
+}
+
+func (c *AlertUseCase) FindAllEscalationPolicies(ctx context.Context, pagination *utils.PaginationRequest) ([]model.EscalationPolicyResponse, *utils.PaginationResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var policies []entity.EscalationPolicy
+	total, err := c.EscalationPolicyRepository.FindAll(c.DB.WithContext(ctx), &policies, pagination)
+	if err != nil {
+		c.Log.Warnf("Failed find all escalation policy from database : %+v", err)
+		return nil, nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	responses := make([]model.EscalationPolicyResponse, len(policies))
+	for i, policy := range policies {
+		responses[i] = *converter.EscalationPolicyToResponse(&policy)
+	}
+
+	totalPage := int((total + int64(pagination.Limit) - 1) / int64(pagination.Limit))
+
+	paginationRes := &utils.PaginationResponse{
+		Page:      pagination.Page,
+		Limit:     pagination.Limit,
+		OrderBy:   pagination.OrderBy,
+		SortBy:    pagination.SortBy,
+		Search:    pagination.Search,
+		TotalData: total,
+		TotalPage: totalPage,
+	}
+
+	return responses, paginationRes, nil
+}
+
+func (c *AlertUseCase) FindEscalationPolicyByID(ctx context.Context, policyID string) (*model.EscalationPolicyResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	policy, err := c.findPolicy(c.DB.WithContext(ctx), policyID)
+	if err != nil {
+		return nil, err
+	}
+	return converter.EscalationPolicyToResponse(policy), nil
+}
+
+// UpdateEscalationPolicy applies to new alerts right away; alerts already
+// following the policy take the new steps from their next escalation on.
+func (c *AlertUseCase) UpdateEscalationPolicy(ctx context.Context, policyID string, request *model.UpdateEscalationPolicyRequest) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	policy, err := c.findPolicy(c.DB.WithContext(ctx), policyID)
+	if err != nil {
+		return err
+	}
+
+	if err := c.validate(request); err != nil {
+		return err
+	}
+
+	if request.Name != nil {
+		policy.Name = *request.Name
+	}
+	if request.MinSeverity != nil {
+		policy.MinSeverity = *request.MinSeverity
+	}
+	if request.Selector != nil {
+		policy.Selector = *request.Selector
+	}
+	if request.SensorType != nil {
+		policy.SensorType = *request.SensorType
+	}
+	if request.Steps != nil {
+		policy.Steps = escalationSteps(request.Steps)
+	}
+	if request.IsActive != nil {
+		policy.IsActive = *request.IsActive
+	}
+
+	if err := c.validatePolicy(c.DB.WithContext(ctx), policy); err != nil {
+		return err
+	}
+
+	if err := c.EscalationPolicyRepository.Update(c.DB.WithContext(ctx), policy); err != nil {
+		c.Log.Warnf("Failed update escalation policy to database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	return nil
+}
+
+// DeleteEscalationPolicy stops the escalation of the alerts following it.
+func (c *AlertUseCase) DeleteEscalationPolicy(ctx context.Context, policyID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	policy, err := c.findPolicy(c.DB.WithContext(ctx), policyID)
+	if err != nil {
+		return err
+	}
+
+	if err := c.EscalationPolicyRepository.Delete(c.DB.WithContext(ctx), policy); err != nil {
+		c.Log.Warnf("Failed delete escalation policy from database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	return nil
+}
+
+// Start launches the scheduler. It stops when ctx is done.
+func (c *AlertUseCase) Start(ctx context.Context) error {
+	go c.schedule(ctx)
+	return nil
+}
+
+func (c *AlertUseCase) schedule(ctx context.Context) {
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+
+	for {
+		c.run(ctx, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-c.wake:
+		}
+	}
+}
+
+// run is one round of the scheduler. Every step changes an alert with a
+// conditional update, so instances running the scheduler side by side do
+// not notify twice.
+func (c *AlertUseCase) run(ctx context.Context, now time.Time) {
+	if err := c.applySilences(ctx, now); err != nil {
+		c.Log.Warnf("Failed apply silences : %+v", err)
+	}
+
+	lifted, err := c.liftSilences(ctx, now)
+	if err != nil {
+		c.Log.Warnf("Failed lift ended silences : %+v", err)
+	}
+
+	escalated, err := c.escalate(ctx, now)
+	if err != nil {
+		c.Log.Warnf("Failed escalate alerts : %+v", err)
+	}
+
+	if lifted+escalated > 0 {
+		c.Outbox.Notify()
+	}
+}
+
+// applySilences mutes the firing alerts matched by an active silence, which
+// also holds their escalation.
+func (c *AlertUseCase) applySilences(ctx context.Context, now time.Time) error {
+	silences, err := c.SilenceRepository.FindActive(c.DB.WithContext(ctx), now)
+	if err != nil || len(silences) == 0 {
+		return err
+	}
+
+	alerts, err := c.AlertRepository.FindFiringUnsilenced(c.DB.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	for _, alert := range alerts {
+		silence := matchSilence(silences, &alert)
+		if silence == nil {
+			continue
+		}
+		if _, err := c.AlertRepository.Silence(c.DB.WithContext(ctx), alert.ID, silence.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// liftSilences notifies the alerts still firing once their silence ended
+// or was deleted, and restarts their escalation, unless another active
+// silence matches them.
+func (c *AlertUseCase) liftSilences(ctx context.Context, now time.Time) (int, error) {
+	alerts, err := c.AlertRepository.FindSilenceEnded(c.DB.WithContext(ctx), now)
+	if err != nil || len(alerts) == 0 {
+		return 0, err
+	}
+
+	silences, err := c.SilenceRepository.FindActive(c.DB.WithContext(ctx), now)
+	if err != nil {
+		return 0, err
+	}
+
+	lifted := 0
+	for _, alert := range alerts {
+		if silence := matchSilence(silences, &alert); silence != nil {
+			if err := c.AlertRepository.MoveSilence(c.DB.WithContext(ctx), alert.ID, *alert.SilenceID, silence.ID); err != nil {
+				return lifted, err
+			}
+			continue
+		}
+
+		var next *time.Time
+		if alert.AcknowledgedAt == nil && alert.EscalationPolicyID != nil {
+			policy, err := c.EscalationPolicyRepository.FindById(c.DB.WithContext(ctx), &entity.EscalationPolicy{}, alert.EscalationPolicyID)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return lifted, err
+			}
+			if policy != nil && policy.IsActive && len(policy.Steps) > 0 {
+				at := now.Add(time.Duration(policy.Steps[0].AfterMinutes) * time.Minute)
+				next = &at
+			}
+		}
+
+		err = c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			ok, err := c.AlertRepository.Unsilence(tx, alert.ID, *alert.SilenceID, next)
+			if err != nil || !ok {
+				return err
+			}
+			lifted++
+			if alert.AcknowledgedAt != nil {
+				return nil
+			}
+			return c.Outbox.Record(tx, &event.Alert{AlertEvent: *converter.AlertToEvent(&alert)})
+		})
+		if err != nil {
+			return lifted, err
+		}
+	}
+	return lifted, nil
+}
+
+// escalate records the due escalation step of every alert left
+// unacknowledged and schedules the step after it, if any.
+func (c *AlertUseCase) escalate(ctx context.Context, now time.Time) (int, error) {
+	alerts, err := c.AlertRepository.FindDueEscalations(c.DB.WithContext(ctx), now, alertEscalationBatch)
+	if err != nil || len(alerts) == 0 {
+		return 0, err
+	}
+
+	policies := make(map[uuid.UUID]*entity.EscalationPolicy)
+	escalated := 0
+	for _, alert := range alerts {
+		var policy *entity.EscalationPolicy
+		if alert.EscalationPolicyID != nil {
+			var ok bool
+			if policy, ok = policies[*alert.EscalationPolicyID]; !ok {
+				policy, err = c.EscalationPolicyRepository.FindById(c.DB.WithContext(ctx), &entity.EscalationPolicy{}, alert.EscalationPolicyID)
+				if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+					return escalated, err
+				}
+				policies[*alert.EscalationPolicyID] = policy
+			}
+		}
+
+		level := alert.EscalationLevel
+		if policy == nil || !policy.IsActive || level >= len(policy.Steps) {
+			if err := c.AlertRepository.StopEscalation(c.DB.WithContext(ctx), alert.ID); err != nil {
+				return escalated, err
+			}
+			continue
+		}
+
+		step := policy.Steps[level]
+		var next *time.Time
+		if level+1 < len(policy.Steps) {
+			at := now.Add(time.Duration(policy.Steps[level+1].AfterMinutes) * time.Minute)
+			next = &at
+		}
+
+		err = c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			ok, err := c.AlertRepository.Escalate(tx, alert.ID, level, next)
+			if err != nil || !ok {
+				return err
+			}
+			escalated++
+			alert.EscalationLevel = level + 1
+			return c.Outbox.Record(tx, &event.AlertEscalated{AlertEscalationEvent: model.AlertEscalationEvent{
+				AlertEvent: *converter.AlertToEvent(&alert),
+				PolicyID:   policy.ID.String(),
+				PolicyName: policy.Name,
+				Level:      level + 1,
+				ChannelIDs: step.ChannelIDs,
+			}})
+		})
+		if err != nil {
+			return escalated, err
+		}
+	}
+	return escalated, nil
+}
+
+// notify wakes the scheduler without blocking.
+func (c *AlertUseCase) notify() {
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (c *AlertUseCase) validate(request any) error {
+	err := c.Validator.Validate.Struct(request)
+	if err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+
+			var messages []string
+			for _, e := range validationErrors {
+				messages = append(messages, e.Translate(c.Validator.Translator))
+			}
+			return fmt.Errorf("%w: %s", utils.ErrValidation, strings.Join(messages, ", "))
+		}
+		return fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+	return nil
+}
+
+// validatePolicy checks what the request tags cannot: the selector and that
+// every channel of every step exists.
+func (c *AlertUseCase) validatePolicy(db *gorm.DB, policy *entity.EscalationPolicy) error {
+	if _, err := utils.ParseLabelSelector(policy.Selector); err != nil {
+		return fmt.Errorf("%w: selector: %s", utils.ErrValidation, err.Error())
+	}
+
+	for i, step := range policy.Steps {
+		channels, err := c.NotificationChannelRepository.FindByIds(db, step.ChannelIDs)
+		if err != nil {
+			c.Log.Warnf("Failed find notification channels from database : %+v", err)
+			return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+		}
+		if len(channels) != len(step.ChannelIDs) {
+			return fmt.Errorf("%w: step %d: notification channel not found", utils.ErrValidation, i+1)
+		}
+	}
+	return nil
+}
+
+func (c *AlertUseCase) findAlert(db *gorm.DB, alertID string) (*entity.Alert, error) {
+	alert := &entity.Alert{}
+	_, err := c.AlertRepository.FindById(db, alert, alertID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Infof("Alert not found, id=%s", alertID)
+			return nil, utils.ErrNotFound
+		}
+		c.Log.Warnf("Failed find alert from database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	return alert, nil
+}
+
+func (c *AlertUseCase) findSilence(db *gorm.DB, silenceID string) (*entity.Silence, error) {
+	silence := &entity.Silence{}
+	_, err := c.SilenceRepository.FindById(db, silence, silenceID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Infof("Silence not found, id=%s", silenceID)
+			return nil, utils.ErrNotFound
+		}
+		c.Log.Warnf("Failed find silence from database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	return silence, nil
+}
+
+func (c *AlertUseCase) findPolicy(db *gorm.DB, policyID string) (*entity.EscalationPolicy, error) {
+	policy := &entity.EscalationPolicy{}
+	_, err := c.EscalationPolicyRepository.FindById(db, policy, policyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Infof("Escalation policy not found, id=%s", policyID)
+			return nil, utils.ErrNotFound
+		}
+		c.Log.Warnf("Failed find escalation policy from database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	return policy, nil
+}
+
+// matchSilence returns the first silence muting the alert, or nil.
+func matchSilence(silences []entity.Silence, alert *entity.Alert) *entity.Silence {
+	for i := range silences {
+		silence := &silences[i]
+		if silence.SensorType != "" && silence.SensorType != alert.SensorType {
+			continue
+		}
+		selector, err := utils.ParseLabelSelector(silence.Selector)
+		if err != nil || !selector.Matches(alert.Labels) {
+			continue
+		}
+		return silence
+	}
+	return nil
+}
+
+// matchEscalationPolicy returns the oldest policy applying to the alert, or
+// nil.
+func matchEscalationPolicy(policies []entity.EscalationPolicy, alert *entity.Alert) *entity.EscalationPolicy {
+	for i := range policies {
+		policy := &policies[i]
+		if len(policy.Steps) == 0 || entity.AlertSeverityRank(alert.Severity) < entity.AlertSeverityRank(policy.MinSeverity) {
+			continue
+		}
+		if policy.SensorType != "" && policy.SensorType != alert.SensorType {
+			continue
+		}
+		selector, err := utils.ParseLabelSelector(policy.Selector)
+		if err != nil || !selector.Matches(alert.Labels) {
+			continue
+		}
+		return policy
+	}
+	return nil
+}
+
+func escalationSteps(steps []model.EscalationStep) entity.EscalationSteps {
+	result := make(entity.EscalationSteps, len(steps))
+	for i, step := range steps {
+		result[i] = entity.EscalationStep{AfterMinutes: step.AfterMinutes, ChannelIDs: uniqueStrings(step.ChannelIDs)}
+	}
+	return result
+}
+
+// alertFingerprint identifies an alert by its title and what it is about.
+func alertFingerprint(alert *entity.Alert) string {
+	parts := []string{alert.Title}
+	if alert.DeviceID != nil {
+		parts = append(parts, alert.DeviceID.String())
+	}
+	if alert.SensorID != nil {
+		parts = append(parts, alert.SensorID.String())
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:16])
+}
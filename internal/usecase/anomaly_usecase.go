@@ -0,0 +1,480 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mertani_test/internal/anomaly"
+	"mertani_test/internal/entity"
+	"mertani_test/internal/event"
+	"mertani_test/internal/model"
+	"mertani_test/internal/model/converter"
+	"mertani_test/internal/repository"
+	"mertani_test/internal/utils"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// anomalyRefreshInterval is how often the active detectors are reloaded,
+// picking up changes made by other instances.
+const anomalyRefreshInterval = 30 * time.Second
+
+// AnomalyUseCase manages anomaly detectors and runs them on the ingest
+// path. Every reading stored for a sensor with detectors is checked against
+// the readings recorded before it, in the transaction that stores it; each
+// anomaly found is saved and recorded in the outbox as an anomaly event,
+// which the alert use case turns into an alert when the detector asks for
+// it.
+type AnomalyUseCase struct {
+	DB                        *gorm.DB
+	Log                       *logrus.Logger
+	Validator                 *utils.Validator
+	AnomalyDetectorRepository *repository.AnomalyDetectorRepository
+	AnomalyRepository         *repository.AnomalyRepository
+	ReadingRepository         *repository.ReadingRepository
+	SensorRepository          *repository.SensorRepository
+	SensorTypeRepository      *repository.SensorTypeRepository
+	Outbox                    *OutboxUseCase
+	detectors                 atomic.Pointer[[]activeDetector]
+}
+
+// activeDetector is an active detector configuration with its detector
+// built.
+type activeDetector struct {
+	entity.AnomalyDetector
+	detector anomaly.Detector
+}
+
+func NewAnomalyUseCase(db *gorm.DB, logger *logrus.Logger, validator *utils.Validator,
+	anomalyDetectorRepository *repository.AnomalyDetectorRepository, anomalyRepository *repository.AnomalyRepository,
+	readingRepository *repository.ReadingRepository, sensorRepository *repository.SensorRepository,
+	sensorTypeRepository *repository.SensorTypeRepository, outbox *OutboxUseCase) *AnomalyUseCase {
+	return &AnomalyUseCase{
+		DB:                        db,
+		Log:                       logger,
+		Validator:                 validator,
+		AnomalyDetectorRepository: anomalyDetectorRepository,
+		AnomalyRepository:         anomalyRepository,
+		ReadingRepository:         readingRepository,
+		SensorRepository:          sensorRepository,
+		SensorTypeRepository:      sensorTypeRepository,
+		Outbox:                    outbox,
+	}
+}
+
+func (c *AnomalyUseCase) CreateDetector(ctx context.Context, request *model.CreateAnomalyDetectorRequest) (*model.AnomalyDetectorResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := c.validate(request); err != nil {
+		return nil, err
+	}
+
+	detector := &entity.AnomalyDetector{
+		SensorType: request.SensorType,
+		Type:       request.Type,
+		Params:     request.Params,
+		Severity:   request.Severity,
+		RaiseAlert: true,
+		IsActive:   true,
+	}
+	if detector.Params == nil {
+		detector.Params = entity.JSONB{}
+	}
+	if detector.Severity == "" {
+		detector.Severity = entity.AlertSeverityWarning
+	}
+	if request.RaiseAlert != nil {
+		detector.RaiseAlert = *request.RaiseAlert
+	}
+	if request.IsActive != nil {
+		detector.IsActive = *request.IsActive
+	}
+
+	switch {
+	case (request.SensorID == "") == (request.SensorType == ""):
+		return nil, fmt.Errorf("%w: set either sensor_id or sensor_type", utils.ErrValidation)
+
+	case request.SensorID != "":
+		sensor := &entity.Sensor{}
+		if _, err := c.SensorRepository.FindById(c.DB.WithContext(ctx), sensor, request.SensorID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("%w: sensor not found", utils.ErrValidation)
+			}
+			c.Log.Warnf("Failed find sensor from database : %+v", err)
+			return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+		}
+		detector.SensorID = &sensor.ID
+
+	default:
+		exists, err := c.SensorTypeRepository.ExistsByCode(c.DB.WithContext(ctx), request.SensorType)
+		if err != nil {
+			c.Log.Warnf("Failed find sensor type from database : %+v", err)
+			return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+		}
+		if !exists {
+			return nil, fmt.Errorf("%w: sensor type not found", utils.ErrValidation)
+		}
+	}
+
+	if _, err := anomaly.New(detector.Type, detector.Params); err != nil {
+		return nil, fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
+	total, err := c.AnomalyDetectorRepository.CountByTarget(c.DB.WithContext(ctx), detector)
+	if err != nil {
+		c.Log.Warnf("Failed count anomaly detector from database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	if total > 0 {
+		return nil, fmt.Errorf("%w: %s", utils.ErrConflict, "a detector of this type is already set")
+	}
+
+	if err := c.AnomalyDetectorRepository.Create(c.DB.WithContext(ctx), detector); err != nil {
+		c.Log.Warnf("Failed create anomaly detector to database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	c.reload(ctx)
+
+	return converter.AnomalyDetectorToResponse(detector), nil
+}
+
+func (c *AnomalyUseCase) FindAllDetectors(ctx context.Context, pagination *utils.PaginationRequest, filter *model.AnomalyDetectorFilter) ([]model.AnomalyDetectorResponse, *utils.PaginationResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if _, err := uuid.Parse(filter.SensorID); filter.SensorID != "" && err != nil {
+		return nil, nil, fmt.Errorf("%w: invalid id %q", utils.ErrValidation, filter.SensorID)
+	}
+
+	var detectors []entity.AnomalyDetector
+	total, err := c.AnomalyDetectorRepository.FindAll(c.DB.WithContext(ctx), &detectors, pagination,
+		repository.ByAnomalyDetectorFilter(filter))
+	if err != nil {
+		c.Log.Warnf("Failed find all anomaly detector from database : %+v", err)
+		return nil, nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	responses := make([]model.AnomalyDetectorResponse, len(detectors))
+	for i, detector := range detectors {
+		responses[i] = *converter.AnomalyDetectorToResponse(&detector)
+	}
+
+	totalPage := int((total + int64(pagination.Limit) - 1) / int64(pagination.Limit))
+
+	paginationRes := &utils.PaginationResponse{
+		Page:      pagination.Page,
+		Limit:     pagination.Limit,
+		OrderBy:   pagination.OrderBy,
+		SortBy:    pagination.SortBy,
+		Search:    pagination.Search,
+		TotalData: total,
+		TotalPage: totalPage,
+	}
+
+	return responses, paginationRes, nil
+}
+
+func (c *AnomalyUseCase) FindDetectorByID(ctx context.Context, detectorID string) (*model.AnomalyDetectorResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	detector, err := c.findDetector(c.DB.WithContext(ctx), detectorID)
+	if err != nil {
+		return nil, err
+	}
+	return converter.AnomalyDetectorToResponse(detector), nil
+}
+
+func (c *AnomalyUseCase) UpdateDetector(ctx context.Context, detectorID string, request *model.UpdateAnomalyDetectorRequest) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	detector, err := c.findDetector(c.DB.WithContext(ctx), detectorID)
+	if err != nil {
+		return err
+	}
+
+	if err := c.validate(request); err != nil {
+		return err
+	}
+
+	if request.Params != nil {
+		detector.Params = request.Params
+	}
+	if request.Severity != nil {
+		detector.Severity = *request.Severity
+	}
+	if request.RaiseAlert != nil {
+		detector.RaiseAlert = *request.RaiseAlert
+	}
+	if request.IsActive != nil {
+		detector.IsActive = *request.IsActive
+	}
+
+	if _, err := anomaly.New(detector.Type, detector.Params); err != nil {
+		return fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
+	if err := c.AnomalyDetectorRepository.Update(c.DB.WithContext(ctx), detector); err != nil {
+		c.Log.Warnf("Failed update anomaly detector to database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	c.reload(ctx)
+
+	return nil
+}
+
+// DeleteDetector keeps the anomalies the detector found.
+func (c *AnomalyUseCase) DeleteDetector(ctx context.Context, detectorID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	detector, err := c.findDetector(c.DB.WithContext(ctx), detectorID)
+	if err != nil {
+		return err
+	}
+
+	if err := c.AnomalyDetectorRepository.Delete(c.DB.WithContext(ctx), detector); err != nil {
+		c.Log.Warnf("Failed delete anomaly detector from database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	c.reload(ctx)
+
+	return nil
+}
+
+// FindBySensor lists the anomalies found in a sensor's readings, newest
+// first unless asked otherwise.
+func (c *AnomalyUseCase) FindBySensor(ctx context.Context, sensorID string, filter *model.AnomalyFilter, pagination *utils.PaginationRequest) ([]model.AnomalyResponse, *utils.PaginationResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if filter.Detector != "" && !slices.Contains(anomaly.Types, filter.Detector) {
+		return nil, nil, fmt.Errorf("%w: detector must be one of %s", utils.ErrValidation, strings.Join(anomaly.Types, ", "))
+	}
+
+	sensor := &entity.Sensor{}
+	if _, err := c.SensorRepository.FindById(c.DB.WithContext(ctx), sensor, sensorID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Infof("Sensor not found, id=%s", sensorID)
+			return nil, nil, utils.ErrNotFound
+		}
+		c.Log.Warnf("Failed find sensor from database : %+v", err)
+		return nil, nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	var anomalies []entity.Anomaly
+	total, err := c.AnomalyRepository.FindAll(c.DB.WithContext(ctx), &anomalies, pagination,
+		repository.ByAnomalyFilter(sensor.ID, filter))
+	if err != nil {
+		c.Log.Warnf("Failed find all anomaly from database : %+v", err)
+		return nil, nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	responses := make([]model.AnomalyResponse, len(anomalies))
+	for i, found := range anomalies {
+		responses[i] = *converter.AnomalyToResponse(&found)
+	}
+
+	totalPage := int((total + int64(pagination.Limit) - 1) / int64(pagination.Limit))
+
+	paginationRes := &utils.PaginationResponse{
+		Page:      pagination.Page,
+		Limit:     pagination.Limit,
+		OrderBy:   pagination.OrderBy,
+		SortBy:    pagination.SortBy,
+		Search:    pagination.Search,
+		TotalData: total,
+		TotalPage: totalPage,
+	}
+
+	return responses, paginationRes, nil
+}
+
+// Detect runs the detectors of the reading's sensor with tx, saving the
+// anomalies found and recording their events. It returns how many were
+// found; callers wake the outbox relay after the commit when any were.
+func (c *AnomalyUseCase) Detect(tx *gorm.DB, sensor *entity.Sensor, reading *entity.Reading) (int, error) {
+	detectors := c.forSensor(tx.Statement.Context, sensor)
+	if len(detectors) == 0 {
+		return 0, nil
+	}
+
+	window := 0
+	for _, detector := range detectors {
+		window = max(window, detector.detector.Window())
+	}
+	readings, err := c.ReadingRepository.FindBefore(tx, sensor.ID, reading.RecordedAt, window)
+	if err != nil {
+		return 0, err
+	}
+	history := make([]anomaly.Point, len(readings))
+	for i, previous := range readings {
+		history[len(readings)-1-i] = anomaly.Point{Value: previous.EffectiveValue(), At: previous.RecordedAt}
+	}
+	current := anomaly.Point{Value: reading.EffectiveValue(), At: reading.RecordedAt}
+
+	var events []event.Event
+	for _, detector := range detectors {
+		finding := detector.detector.Detect(history, current)
+		if finding == nil {
+			continue
+		}
+
+		detectorID := detector.ID
+		found := &entity.Anomaly{
+			SensorID:   sensor.ID,
+			DetectorID: &detectorID,
+			Detector:   detector.Type,
+			ReadingID:  reading.ID,
+			Value:      current.Value,
+			Expected:   finding.Expected,
+			Score:      finding.Score,
+			Severity:   detector.Severity,
+			Message:    truncate(finding.Message, 500),
+			RecordedAt: reading.RecordedAt,
+		}
+		if err := c.AnomalyRepository.Create(tx, found); err != nil {
+			return 0, err
+		}
+
+		events = append(events, &event.Anomaly{AnomalyEvent: model.AnomalyEvent{
+			ID:         found.ID.String(),
+			DeviceID:   sensor.DeviceID.String(),
+			SensorID:   sensor.ID.String(),
+			SensorName: sensor.Name,
+			SensorType: sensor.Type,
+			Unit:       sensor.Unit,
+			DetectorID: detectorID.String(),
+			Detector:   found.Detector,
+			ReadingID:  reading.ID.String(),
+			Value:      found.Value,
+			Expected:   found.Expected,
+			Score:      found.Score,
+			Severity:   found.Severity,
+			Message:    found.Message,
+			RaiseAlert: detector.RaiseAlert,
+			Labels:     sensor.Labels,
+			RecordedAt: reading.RecordedAt.UTC().Format(time.RFC3339),
+		}})
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+	return len(events), c.Outbox.Record(tx, events...)
+}
+
+// forSensor returns the active detectors of a sensor: those set on it, and
+// those set on its sensor type unless one of the same type is set on it.
+func (c *AnomalyUseCase) forSensor(ctx context.Context, sensor *entity.Sensor) []activeDetector {
+	active := c.detectors.Load()
+	if active == nil {
+		c.reload(ctx)
+		if active = c.detectors.Load(); active == nil {
+			return nil
+		}
+	}
+
+	var own, inherited []activeDetector
+	for _, detector := range *active {
+		switch {
+		case detector.SensorID != nil && *detector.SensorID == sensor.ID:
+			own = append(own, detector)
+		case detector.SensorID == nil && detector.SensorType == sensor.Type:
+			inherited = append(inherited, detector)
+		}
+	}
+	for _, detector := range inherited {
+		overridden := false
+		for _, mine := range own {
+			overridden = overridden || mine.Type == detector.Type
+		}
+		if !overridden {
+			own = append(own, detector)
+		}
+	}
+	return own
+}
+
+// Start loads the active detectors and keeps reloading them. It stops when
+// ctx is done.
+func (c *AnomalyUseCase) Start(ctx context.Context) error {
+	c.reload(ctx)
+	go c.refresh(ctx)
+	return nil
+}
+
+// refresh reloads the active detectors every anomalyRefreshInterval.
+func (c *AnomalyUseCase) refresh(ctx context.Context) {
+	ticker := time.NewTicker(anomalyRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.reload(ctx)
+		}
+	}
+}
+
+// reload replaces the in-memory list of active detectors. Detectors whose
+// params no longer build are skipped; on failure the previous list is
+// kept.
+func (c *AnomalyUseCase) reload(ctx context.Context) {
+	detectors, err := c.AnomalyDetectorRepository.FindActive(c.DB.WithContext(ctx))
+	if err != nil {
+		c.Log.Warnf("Failed find active anomaly detectors from database : %+v", err)
+		return
+	}
+
+	active := make([]activeDetector, 0, len(detectors))
+	for _, detector := range detectors {
+		built, err := anomaly.New(detector.Type, detector.Params)
+		if err != nil {
+			c.Log.Warnf("Skipping anomaly detector %s with invalid params : %+v", detector.ID, err)
+			continue
+		}
+		active = append(active, activeDetector{AnomalyDetector: detector, detector: built})
+	}
+	c.detectors.Store(&active)
+}
+
+func (c *AnomalyUseCase) validate(request any) error {
+	err := c.Validator.Validate.Struct(request)
+	if err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+
+			var messages []string
+			for _, e := range validationErrors {
+				messages = append(messages, e.Translate(c.Validator.Translator))
+			}
+			return fmt.Errorf("%w: %s", utils.ErrValidation, strings.Join(messages, ", "))
+		}
+		return fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+	return nil
+}
+
+func (c *AnomalyUseCase) findDetector(db *gorm.DB, detectorID string) (*entity.AnomalyDetector, error) {
+	detector := &entity.AnomalyDetector{}
+	_, err := c.AnomalyDetectorRepository.FindById(db, detector, detectorID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Infof("Anomaly detector not found, id=%s", detectorID)
+			return nil, utils.ErrNotFound
+		}
+		c.Log.Warnf("Failed find anomaly detector from database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	return detector, nil
+}
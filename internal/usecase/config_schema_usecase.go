@@ -0,0 +1,183 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mertani_test/internal/entity"
+	"mertani_test/internal/model"
+	"mertani_test/internal/model/converter"
+	"mertani_test/internal/repository"
+	"mertani_test/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type ConfigSchemaUseCase struct {
+	DB                     *gorm.DB
+	Log                    *logrus.Logger
+	Validator              *utils.Validator
+	ConfigSchemaRepository *repository.ConfigSchemaRepository
+}
+
+func NewConfigSchemaUseCase(db *gorm.DB, logger *logrus.Logger, validator *utils.Validator,
+	configSchemaRepository *repository.ConfigSchemaRepository) *ConfigSchemaUseCase {
+	return &ConfigSchemaUseCase{
+		DB:                     db,
+		Log:                    logger,
+		Validator:              validator,
+		ConfigSchemaRepository: configSchemaRepository,
+	}
+}
+
+func (c *ConfigSchemaUseCase) Create(ctx context.Context, request *model.CreateConfigSchemaRequest) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	err := c.Validator.Validate.Struct(request)
+	if err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+
+			var messages []string
+			for _, e := range validationErrors {
+				messages = append(messages, e.Translate(c.Validator.Translator))
+			}
+			return fmt.Errorf("%w: %s", utils.ErrValidation, strings.Join(messages, ", "))
+		}
+		return fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
+	exists, err := c.ConfigSchemaRepository.ExistsByHardwareModel(c.DB.WithContext(ctx), request.HardwareModel)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("%w: %s", utils.ErrConflict, "config schema for hardware model already exist")
+	}
+
+	schema := &entity.ConfigSchema{
+		HardwareModel: request.HardwareModel,
+		Schema:        request.Schema,
+	}
+
+	if err := c.ConfigSchemaRepository.Create(c.DB.WithContext(ctx), schema); err != nil {
+		c.Log.Warnf("Failed create config schema to database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	return nil
+}
+
+func (c *ConfigSchemaUseCase) FindAll(ctx context.Context, pagination *utils.PaginationRequest) ([]model.ConfigSchemaResponse, *utils.PaginationResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var schemas []entity.ConfigSchema
+	total, err := c.ConfigSchemaRepository.FindAll(c.DB.WithContext(ctx), &schemas, pagination)
+	if err != nil {
+		c.Log.Warnf("Failed find all config schema from database : %+v", err)
+		return nil, nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	responses := make([]model.ConfigSchemaResponse, len(schemas))
+	for i, schema := range schemas {
+		responses[i] = *converter.ConfigSchemaToResponse(&schema)
+	}
+
+	totalPage := int((total + int64(pagination.Limit) - 1) / int64(pagination.Limit))
+
+	paginationRes := &utils.PaginationResponse{
+		Page:      pagination.Page,
+		Limit:     pagination.Limit,
+		OrderBy:   pagination.OrderBy,
+		SortBy:    pagination.SortBy,
+		Search:    pagination.Search,
+		TotalData: total,
+		TotalPage: totalPage,
+	}
+
+	return responses, paginationRes, nil
+}
+
+func (c *ConfigSchemaUseCase) FindByID(ctx context.Context, schemaID string) (*model.ConfigSchemaResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	schema := &entity.ConfigSchema{}
+	_, err := c.ConfigSchemaRepository.FindById(c.DB.WithContext(ctx), schema, schemaID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Infof("Config schema not found, id=%s", schemaID)
+			return nil, utils.ErrNotFound
+		}
+		c.Log.Warnf("Failed find config schema from database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	return converter.ConfigSchemaToResponse(schema), nil
+}
+
+func (c *ConfigSchemaUseCase) Update(ctx context.Context, schemaID string, request *model.UpdateConfigSchemaRequest) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	schema := &entity.ConfigSchema{}
+	_, err := c.ConfigSchemaRepository.FindById(c.DB.WithContext(ctx), schema, schemaID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Infof("Config schema not found, id=%s", schemaID)
+			return utils.ErrNotFound
+		}
+		c.Log.Warnf("Failed find config schema from database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	err = c.Validator.Validate.Struct(request)
+	if err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+
+			var messages []string
+			for _, e := range validationErrors {
+				messages = append(messages, e.Translate(c.Validator.Translator))
+			}
+			return fmt.Errorf("%w: %s", utils.ErrValidation, strings.Join(messages, ", "))
+		}
+		return fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
+	schema.Schema = request.Schema
+
+	if err := c.ConfigSchemaRepository.Update(c.DB.WithContext(ctx), schema); err != nil {
+		c.Log.Warnf("Failed update config schema from database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	return nil
+}
+
+func (c *ConfigSchemaUseCase) Delete(ctx context.Context, schemaID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	schema := &entity.ConfigSchema{}
+	_, err := c.ConfigSchemaRepository.FindById(c.DB.WithContext(ctx), schema, schemaID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Infof("Config schema not found, id=%s", schemaID)
+			return utils.ErrNotFound
+		}
+		c.Log.Warnf("Failed find config schema from database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	if err := c.ConfigSchemaRepository.Delete(c.DB.WithContext(ctx), schema); err != nil {
+		c.Log.Warnf("Failed delete config schema from database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// deliveryWorker claims due deliveries one at a time and attempts them, for
+// the webhook and notification worker pools. The deliveries live in the
+// database, so any instance may claim them and a crashed worker only delays
+// its claimed one until the lease runs out.
+type deliveryWorker[T any] struct {
+	Log *logrus.Logger
+	// Name is what a delivery is called in logs.
+	Name string
+	// PollInterval is how often an idle worker looks for retries that
+	// became due; Wake hands it new deliveries right away.
+	PollInterval time.Duration
+	Wake         <-chan struct{}
+	// Lease is how long a claimed delivery is held. It outlasts an attempt,
+	// so only a crashed worker lets it run out.
+	Lease   time.Duration
+	Claim   func(ctx context.Context, now time.Time, leaseUntil time.Time) (*T, error)
+	Attempt func(ctx context.Context, delivery *T)
+}
+
+// run works until ctx is done.
+func (w *deliveryWorker[T]) run(ctx context.Context) {
+	ticker := time.NewTicker(w.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now()
+		delivery, err := w.Claim(ctx, now, now.Add(w.Lease))
+		if err != nil {
+			w.Log.Warnf("Failed claim %s : %+v", w.Name, err)
+		}
+		if delivery != nil {
+			w.Attempt(ctx, delivery)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.Wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// retryAt returns when a delivery that failed its attempts-th attempt at
+// finished is tried again, or nil once it is out of attempts.
+func retryAt(finished time.Time, attempts int, maxAttempts int, base time.Duration, limit time.Duration) *time.Time {
+	if attempts >= maxAttempts {
+		return nil
+	}
+	next := finished.Add(backoff(attempts, base, limit))
+	return &next
+}
+
+// backoff is the wait after the given number of failed attempts: base,
+// doubled after every further attempt up to limit.
+func backoff(attempts int, base time.Duration, limit time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= limit {
+			return limit
+		}
+	}
+	return delay
+}
@@ -0,0 +1,37 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	for _, test := range []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, 30 * time.Second},
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{4, 4 * time.Minute},
+		{7, 32 * time.Minute},
+		{8, time.Hour},
+		{100, time.Hour},
+	} {
+		if got := backoff(test.attempts, 30*time.Second, time.Hour); got != test.want {
+			t.Errorf("backoff(%d) = %v, want %v", test.attempts, got, test.want)
+		}
+	}
+}
+
+func TestRetryAt(t *testing.T) {
+	finished := time.Date(2026, time.March, 10, 8, 0, 0, 0, time.UTC)
+
+	next := retryAt(finished, 2, 3, time.Minute, time.Hour)
+	if next == nil || !next.Equal(finished.Add(2*time.Minute)) {
+		t.Fatalf("retryAt = %v, want %v", next, finished.Add(2*time.Minute))
+	}
+	if next := retryAt(finished, 3, 3, time.Minute, time.Hour); next != nil {
+		t.Fatalf("retryAt out of attempts = %v, want nil", next)
+	}
+}
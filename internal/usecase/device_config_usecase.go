@@ -0,0 +1,374 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mertani_test/internal/entity"
+	"mertani_test/internal/model"
+	"mertani_test/internal/model/converter"
+	"mertani_test/internal/repository"
+	"mertani_test/internal/utils"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type DeviceConfigUseCase struct {
+	DB                     *gorm.DB
+	Log                    *logrus.Logger
+	Validator              *utils.Validator
+	DeviceRepository       *repository.DeviceRepository
+	DeviceConfigRepository *repository.DeviceConfigRepository
+	ConfigSchemaRepository *repository.ConfigSchemaRepository
+	Lookups                *LookupCache
+}
+
+func NewDeviceConfigUseCase(db *gorm.DB, logger *logrus.Logger, validator *utils.Validator,
+	deviceRepository *repository.DeviceRepository, deviceConfigRepository *repository.DeviceConfigRepository,
+	configSchemaRepository *repository.ConfigSchemaRepository, lookups *LookupCache) *DeviceConfigUseCase {
+	return &DeviceConfigUseCase{
+		DB:                     db,
+		Log:                    logger,
+		Validator:              validator,
+		DeviceRepository:       deviceRepository,
+		DeviceConfigRepository: deviceConfigRepository,
+		ConfigSchemaRepository: configSchemaRepository,
+		Lookups:                lookups,
+	}
+}
+
+func (c *DeviceConfigUseCase) SetDesired(ctx context.Context, deviceID string, request *model.UpdateDesiredConfigRequest) (*model.DeviceConfigResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	err := c.Validator.Validate.Struct(request)
+	if err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+
+			var messages []string
+			for _, e := range validationErrors {
+				messages = append(messages, e.Translate(c.Validator.Translator))
+			}
+			return nil, fmt.Errorf("%w: %s", utils.ErrValidation, strings.Join(messages, ", "))
+		}
+		return nil, fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
+	device, err := c.findDevice(c.DB.WithContext(ctx), deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if device.HardwareModel != "" {
+		schema, err := c.ConfigSchemaRepository.FindByHardwareModel(c.DB.WithContext(ctx), &entity.ConfigSchema{}, device.HardwareModel)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Warnf("Failed find config schema from database : %+v", err)
+			return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+		}
+		if schema != nil {
+			if messages := utils.ValidateConfigDocument(schema.Schema, request.Config); len(messages) > 0 {
+				return nil, fmt.Errorf("%w: %s", utils.ErrValidation, strings.Join(messages, ", "))
+			}
+		}
+	}
+
+	config := &entity.DeviceConfig{
+		DeviceID: device.ID,
+		Kind:     entity.DeviceConfigDesired,
+		Document: request.Config,
+	}
+
+	err = c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		version, err := c.DeviceConfigRepository.LatestVersion(tx, device.ID, entity.DeviceConfigDesired)
+		if err != nil {
+			return err
+		}
+		config.Version = version + 1
+		return c.DeviceConfigRepository.Create(tx, config)
+	})
+	if err != nil {
+		c.Log.Warnf("Failed create desired config to database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	return converter.DeviceConfigToResponse(config), nil
+}
+
+func (c *DeviceConfigUseCase) FindCurrent(ctx context.Context, deviceID string) (*model.DeviceConfigStateResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	device, err := c.findDevice(c.DB.WithContext(ctx), deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	desired, reported, err := c.findLatestPair(c.DB.WithContext(ctx), device.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &model.DeviceConfigStateResponse{
+		DeviceID: device.ID.String(),
+		Pending:  isConfigPending(desired, reported),
+	}
+	if desired != nil {
+		response.Desired = converter.DeviceConfigToResponse(desired)
+	}
+	if reported != nil {
+		response.Reported = converter.DeviceConfigToResponse(reported)
+	}
+
+	return response, nil
+}
+
+func (c *DeviceConfigUseCase) FindHistory(ctx context.Context, deviceID string, kind string, pagination *utils.PaginationRequest) ([]model.DeviceConfigResponse, *utils.PaginationResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if kind != "" && kind != entity.DeviceConfigDesired && kind != entity.DeviceConfigReported {
+		return nil, nil, fmt.Errorf("%w: kind must be one of [desired reported]", utils.ErrValidation)
+	}
+
+	device, err := c.findDevice(c.DB.WithContext(ctx), deviceID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var configs []entity.DeviceConfig
+	total, err := c.DeviceConfigRepository.FindAll(c.DB.WithContext(ctx), &configs, pagination,
+		repository.ByDeviceConfigKind(device.ID, kind))
+	if err != nil {
+		c.Log.Warnf("Failed find config history from database : %+v", err)
+		return nil, nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	responses := make([]model.DeviceConfigResponse, len(configs))
+	for i, config := range configs {
+		responses[i] = *converter.DeviceConfigToResponse(&config)
+	}
+
+	totalPage := int((total + int64(pagination.Limit) - 1) / int64(pagination.Limit))
+
+	paginationRes := &utils.PaginationResponse{
+		Page:      pagination.Page,
+		Limit:     pagination.Limit,
+		OrderBy:   pagination.OrderBy,
+		SortBy:    pagination.SortBy,
+		Search:    pagination.Search,
+		TotalData: total,
+		TotalPage: totalPage,
+	}
+
+	return responses, paginationRes, nil
+}
+
+func (c *DeviceConfigUseCase) Diff(ctx context.Context, deviceID string) (*model.DeviceConfigDiffResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	device, err := c.findDevice(c.DB.WithContext(ctx), deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	desired, reported, err := c.findLatestPair(c.DB.WithContext(ctx), device.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &model.DeviceConfigDiffResponse{
+		DeviceID: device.ID.String(),
+		Drift:    []model.ConfigDriftResponse{},
+	}
+
+	var desiredDocument, reportedDocument map[string]any
+	if desired != nil {
+		response.DesiredVersion = desired.Version
+		desiredDocument = desired.Document
+	}
+	if reported != nil {
+		response.ReportedVersion = reported.Version
+		response.AppliedVersion = reported.AppliedVersion
+		reportedDocument = reported.Document
+	}
+
+	diffConfig("", desiredDocument, reportedDocument, &response.Drift)
+	response.InSync = len(response.Drift) == 0 && !isConfigPending(desired, reported)
+
+	return response, nil
+}
+
+func (c *DeviceConfigUseCase) CheckIn(ctx context.Context, deviceID string, request *model.DeviceCheckInRequest) (*model.DeviceCheckInResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	err := c.Validator.Validate.Struct(request)
+	if err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+
+			var messages []string
+			for _, e := range validationErrors {
+				messages = append(messages, e.Translate(c.Validator.Translator))
+			}
+			return nil, fmt.Errorf("%w: %s", utils.ErrValidation, strings.Join(messages, ", "))
+		}
+		return nil, fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
+	device := &entity.Device{}
+	response := &model.DeviceCheckInResponse{}
+
+	// the device row is locked so concurrent check-ins number their
+	// reported configs one after the other
+	err = c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := c.DeviceRepository.FindByIdForUpdate(tx, device, deviceID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.Log.Infof("Device not found, id=%s", deviceID)
+				return utils.ErrNotFound
+			}
+			return err
+		}
+
+		desired, reported, err := c.findLatestPair(tx, device.ID)
+		if err != nil {
+			return err
+		}
+
+		document := request.Reported
+		if document == nil && reported != nil {
+			document = reported.Document
+		}
+
+		changed := reported == nil ||
+			reported.AppliedVersion != request.AppliedVersion ||
+			!reflect.DeepEqual(map[string]any(reported.Document), document)
+		if changed {
+			next := &entity.DeviceConfig{
+				DeviceID:       device.ID,
+				Kind:           entity.DeviceConfigReported,
+				Document:       document,
+				AppliedVersion: request.AppliedVersion,
+			}
+			if reported != nil {
+				next.Version = reported.Version + 1
+			} else {
+				next.Version = 1
+			}
+			if err := c.DeviceConfigRepository.Create(tx, next); err != nil {
+				return err
+			}
+		}
+
+		if err := c.DeviceRepository.UpdateLastCheckIn(tx, device.ID, time.Now()); err != nil {
+			return err
+		}
+
+		if desired != nil {
+			response.DesiredVersion = desired.Version
+			if desired.Version > request.AppliedVersion {
+				response.Pending = true
+				response.Config = desired.Document
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) || errors.Is(err, utils.ErrInternal) {
+			return nil, err
+		}
+		c.Log.Warnf("Failed record device check-in to database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	c.Lookups.InvalidateDevices(ctx, device.ID)
+
+	return response, nil
+}
+
+func (c *DeviceConfigUseCase) findDevice(db *gorm.DB, deviceID string) (*entity.Device, error) {
+	device := &entity.Device{}
+	_, err := c.DeviceRepository.FindById(db, device, deviceID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Infof("Device not found, id=%s", deviceID)
+			return nil, utils.ErrNotFound
+		}
+		c.Log.Warnf("Failed find device from database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	return device, nil
+}
+
+func (c *DeviceConfigUseCase) findLatestPair(db *gorm.DB, deviceID uuid.UUID) (*entity.DeviceConfig, *entity.DeviceConfig, error) {
+	desired, err := c.DeviceConfigRepository.FindLatest(db, &entity.DeviceConfig{}, deviceID, entity.DeviceConfigDesired)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.Log.Warnf("Failed find desired config from database : %+v", err)
+		return nil, nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	reported, err := c.DeviceConfigRepository.FindLatest(db, &entity.DeviceConfig{}, deviceID, entity.DeviceConfigReported)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.Log.Warnf("Failed find reported config from database : %+v", err)
+		return nil, nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	return desired, reported, nil
+}
+
+func isConfigPending(desired *entity.DeviceConfig, reported *entity.DeviceConfig) bool {
+	if desired == nil {
+		return false
+	}
+	return reported == nil || reported.AppliedVersion < desired.Version
+}
+
+// diffConfig walks both documents and records every leaf key whose value
+// differs, using dotted paths for nested objects.
+func diffConfig(prefix string, desired map[string]any, reported map[string]any, drift *[]model.ConfigDriftResponse) {
+	keys := make(map[string]struct{}, len(desired)+len(reported))
+	for key := range desired {
+		keys[key] = struct{}{}
+	}
+	for key := range reported {
+		keys[key] = struct{}{}
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	for _, key := range sorted {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		desiredValue, inDesired := desired[key]
+		reportedValue, inReported := reported[key]
+
+		desiredObject, desiredIsObject := desiredValue.(map[string]any)
+		reportedObject, reportedIsObject := reportedValue.(map[string]any)
+		if desiredIsObject && reportedIsObject {
+			diffConfig(path, desiredObject, reportedObject, drift)
+			continue
+		}
+
+		switch {
+		case !inReported:
+			*drift = append(*drift, model.ConfigDriftResponse{Key: path, Status: "missing", Desired: desiredValue})
+		case !inDesired:
+			*drift = append(*drift, model.ConfigDriftResponse{Key: path, Status: "unexpected", Reported: reportedValue})
+		case !reflect.DeepEqual(desiredValue, reportedValue):
+			*drift = append(*drift, model.ConfigDriftResponse{Key: path, Status: "changed", Desired: desiredValue, Reported: reportedValue})
+		}
+	}
+}
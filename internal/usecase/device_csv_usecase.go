@@ -0,0 +1,300 @@
+package usecase
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"mertani_test/internal/entity"
+	"mertani_test/internal/event"
+	"mertani_test/internal/model"
+	"mertani_test/internal/model/converter"
+	"mertani_test/internal/utils"
+	"strconv"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// MaxImportRows bounds a single CSV import.
+	MaxImportRows = 5000
+	// exportBatchSize is the number of rows loaded per query while exporting.
+	exportBatchSize = 500
+)
+
+var deviceCSVColumns = []string{"name", "location", "status", "hardware_model", "site_id", "latitude", "longitude", "labels"}
+
+// Import creates or updates devices from CSV, matching existing devices by
+// name. Only the columns present in the header are written on update. The
+// import is all or nothing: when any row fails, or on a dry run, the report
+// is returned without touching the database.
+func (c *DeviceUseCase) Import(ctx context.Context, r io.Reader, dryRun bool) (*model.ImportResponse, error) {
+	// up to MaxImportRows rows are checked and written in one transaction
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	rows, err := utils.ReadCSV(r, deviceCSVColumns, []string{"name"}, MaxImportRows)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
+	names := make([]string, 0, len(rows))
+	for _, row := range rows {
+		names = append(names, row.Get("name"))
+	}
+	existing, err := c.DeviceRepository.FindByNames(c.DB.WithContext(ctx), names)
+	if err != nil {
+		c.Log.Warnf("Failed find devices from database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	byName := make(map[string]*entity.Device, len(existing))
+	for i := range existing {
+		byName[existing[i].Name] = &existing[i]
+	}
+
+	result := &model.ImportResponse{DryRun: dryRun, Rows: len(rows)}
+	devices := make([]*entity.Device, 0, len(rows))
+	seen := make(map[string]int, len(rows))
+	sites := make(map[string]bool)
+
+	for _, row := range rows {
+		device := &entity.Device{}
+		if current, ok := byName[row.Get("name")]; ok {
+			*device = *current
+		}
+
+		messages, err := c.importDeviceRow(ctx, row, device, sites)
+		if err != nil {
+			return nil, err
+		}
+		if line, ok := seen[row.Get("name")]; ok && row.Get("name") != "" {
+			messages = append(messages, fmt.Sprintf("name duplicates row %d", line))
+		}
+		seen[row.Get("name")] = row.Line
+
+		if len(messages) > 0 {
+			result.Failed++
+			result.Errors = append(result.Errors, model.ImportRowError{Row: row.Line, Name: row.Get("name"), Errors: messages})
+			continue
+		}
+
+		if device.ID == uuid.Nil {
+			result.Created++
+		} else {
+			result.Updated++
+		}
+		devices = append(devices, device)
+	}
+
+	if dryRun || result.Failed > 0 {
+		return result, nil
+	}
+
+	err = c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, device := range devices {
+			if device.ID == uuid.Nil {
+				if err := c.DeviceRepository.Create(tx, device); err != nil {
+					return err
+				}
+				if err := c.Outbox.Record(tx, &event.DeviceCreated{DeviceResponse: *converter.DeviceToResponse(device)}); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := c.DeviceRepository.Update(tx, device); err != nil {
+				return err
+			}
+			if err := c.Outbox.Record(tx, deviceUpdatedEvents(device, byName[device.Name].Status)...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.Log.Warnf("Failed import devices to database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	c.Outbox.Notify()
+	for _, device := range devices {
+		c.Lookups.InvalidateDevices(ctx, device.ID)
+	}
+
+	return result, nil
+}
+
+// importDeviceRow overlays the row on device and validates the outcome with
+// the same rules as CreateDeviceRequest. It returns the row's problems; the
+// error is reserved for database failures.
+func (c *DeviceUseCase) importDeviceRow(ctx context.Context, row utils.CSVRow, device *entity.Device, sites map[string]bool) ([]string, error) {
+	var messages []string
+
+	request := &model.CreateDeviceRequest{
+		Name:          row.Get("name"),
+		Location:      device.Location,
+		Status:        device.Status,
+		HardwareModel: device.HardwareModel,
+		Latitude:      device.Latitude,
+		Longitude:     device.Longitude,
+		Labels:        device.Labels,
+	}
+	if device.SiteID != nil {
+		request.SiteID = device.SiteID.String()
+	}
+
+	if row.Has("location") {
+		request.Location = row.Get("location")
+	}
+	if row.Has("status") {
+		request.Status = row.Get("status")
+	}
+	if row.Has("hardware_model") {
+		request.HardwareModel = row.Get("hardware_model")
+	}
+	if row.Has("site_id") {
+		request.SiteID = row.Get("site_id")
+	}
+	coordinates := []struct {
+		column string
+		target **float64
+	}{
+		{"latitude", &request.Latitude},
+		{"longitude", &request.Longitude},
+	}
+	for _, coordinate := range coordinates {
+		if !row.Has(coordinate.column) {
+			continue
+		}
+		*coordinate.target = nil
+		if cell := row.Get(coordinate.column); cell != "" {
+			value, err := strconv.ParseFloat(cell, 64)
+			if err != nil {
+				messages = append(messages, fmt.Sprintf("%s must be a number", coordinate.column))
+				continue
+			}
+			*coordinate.target = &value
+		}
+	}
+	if row.Has("labels") {
+		labels, err := utils.ParseCSVLabels(row.Get("labels"))
+		if err != nil {
+			messages = append(messages, err.Error())
+		} else {
+			request.Labels = labels
+		}
+	}
+
+	if err := c.Validator.Validate.Struct(request); err != nil {
+		if _, ok := err.(validator.ValidationErrors); ok {
+			messages = append(messages, c.Validator.TranslateError(err)...)
+		} else {
+			messages = append(messages, err.Error())
+		}
+		return messages, nil
+	}
+
+	if err := utils.ValidateLabels(request.Labels); err != nil {
+		messages = append(messages, err.Error())
+	}
+
+	var siteID *uuid.UUID
+	if request.SiteID != "" {
+		exists, ok := sites[request.SiteID]
+		if !ok {
+			total, err := c.SiteRepository.CountById(c.DB.WithContext(ctx), request.SiteID)
+			if err != nil {
+				c.Log.Warnf("Failed find site from database : %+v", err)
+				return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+			}
+			exists = total > 0
+			sites[request.SiteID] = exists
+		}
+		if !exists {
+			messages = append(messages, fmt.Sprintf("site %s does not exist", request.SiteID))
+		}
+		id := uuid.MustParse(request.SiteID)
+		siteID = &id
+	}
+
+	if len(messages) > 0 {
+		return messages, nil
+	}
+
+	device.Name = request.Name
+	device.Location = request.Location
+	device.Status = request.Status
+	device.HardwareModel = request.HardwareModel
+	device.SiteID = siteID
+	device.Latitude = request.Latitude
+	device.Longitude = request.Longitude
+	device.Labels = request.Labels
+	return nil, nil
+}
+
+// Export streams devices matching the list search and filter as CSV, in
+// the column layout Import accepts. The filter is checked before anything
+// is written so the caller can still report a bad request; the returned
+// function does the streaming.
+func (c *DeviceUseCase) Export(ctx context.Context, search string, filter *model.DeviceFilter) (func(w io.Writer) error, error) {
+	if err := validateDeviceFilter(filter); err != nil {
+		return nil, err
+	}
+
+	return func(w io.Writer) error {
+		// the export runs after the handler returned and may be large
+		ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+		defer cancel()
+
+		writer := csv.NewWriter(w)
+		if err := writer.Write(deviceCSVColumns); err != nil {
+			return err
+		}
+
+		err := c.DeviceRepository.FindInBatches(c.DB.WithContext(ctx), search, exportBatchSize, func(devices []entity.Device) error {
+			for _, device := range devices {
+				record := []string{
+					device.Name,
+					device.Location,
+					device.Status,
+					device.HardwareModel,
+					"",
+					formatCSVFloat(device.Latitude),
+					formatCSVFloat(device.Longitude),
+					utils.FormatCSVLabels(device.Labels),
+				}
+				if device.SiteID != nil {
+					record[4] = device.SiteID.String()
+				}
+				if err := writer.Write(record); err != nil {
+					return err
+				}
+			}
+			return flushCSV(writer, w)
+		}, c.DeviceRepository.Filter(filter)...)
+		if err != nil {
+			c.Log.Warnf("Failed export devices : %+v", err)
+			return err
+		}
+
+		return flushCSV(writer, w)
+	}, nil
+}
+
+// flushCSV pushes buffered rows to the client so a long export streams
+// instead of accumulating in memory.
+func flushCSV(writer *csv.Writer, w io.Writer) error {
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	return flushWriter(w)
+}
+
+func formatCSVFloat(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', -1, 64)
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"mertani_test/internal/entity"
+	"mertani_test/internal/event"
 	"mertani_test/internal/model"
 	"mertani_test/internal/model/converter"
 	"mertani_test/internal/repository"
@@ -13,6 +14,7 @@ import (
 	"time"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
@@ -22,15 +24,27 @@ type DeviceUseCase struct {
 	Log                *logrus.Logger
 	Validator          *utils.Validator
 	DeviceRepository *repository.DeviceRepository
+	SensorRepository   *repository.SensorRepository
+	SensorLatestRepository *repository.SensorLatestRepository
+	SiteRepository     *repository.SiteRepository
+	Lookups            *LookupCache
+	Outbox             *OutboxUseCase
 }
 
 func NewDeviceUseCase(db *gorm.DB, logger *logrus.Logger, validator *utils.Validator,
-	deviceRepository *repository.DeviceRepository) *DeviceUseCase {
+	deviceRepository *repository.DeviceRepository, sensorRepository *repository.SensorRepository,
+	sensorLatestRepository *repository.SensorLatestRepository, siteRepository *repository.SiteRepository,
+	lookups *LookupCache, outbox *OutboxUseCase) *DeviceUseCase {
 	return &DeviceUseCase{
 		DB:                 db,
 		Log:                logger,
 		Validator:          validator,
 		DeviceRepository: deviceRepository,
+		SensorRepository:   sensorRepository,
+		SensorLatestRepository: sensorLatestRepository,
+		SiteRepository:     siteRepository,
+		Lookups:            lookups,
+		Outbox:             outbox,
 	}
 }
 
@@ -51,6 +65,16 @@ func (c *DeviceUseCase) Create(ctx context.Context, request *model.CreateDeviceR
 		return fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
 	}
 
+	if request.Boundary != nil {
+		if err := utils.ValidatePolygon(request.Boundary); err != nil {
+			return fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+		}
+	}
+
+	if err := utils.ValidateLabels(request.Labels); err != nil {
+		return fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
 	exists, err := c.DeviceRepository.ExistsByName(c.DB.WithContext(ctx), request.Name)
 	if err != nil {
 		return err
@@ -63,22 +87,51 @@ func (c *DeviceUseCase) Create(ctx context.Context, request *model.CreateDeviceR
 		Name: request.Name,
 		Location: request.Location,
 		Status: request.Status,
+		HardwareModel: request.HardwareModel,
+		Latitude: request.Latitude,
+		Longitude: request.Longitude,
+		Boundary: request.Boundary,
+		Labels: request.Labels,
 	}
 
-	if err := c.DeviceRepository.Create(c.DB.WithContext(ctx), category); err != nil {
+	if request.SiteID != "" {
+		siteID := uuid.MustParse(request.SiteID)
+		total, err := c.SiteRepository.CountById(c.DB.WithContext(ctx), siteID)
+		if err != nil {
+			c.Log.Warnf("Failed find site from database : %+v", err)
+			return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+		}
+		if total == 0 {
+			return fmt.Errorf("%w: site %s does not exist", utils.ErrValidation, request.SiteID)
+		}
+		category.SiteID = &siteID
+	}
+
+	err = c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := c.DeviceRepository.Create(tx, category); err != nil {
+			return err
+		}
+		return c.Outbox.Record(tx, &event.DeviceCreated{DeviceResponse: *converter.DeviceToResponse(category)})
+	})
+	if err != nil {
 		c.Log.Warnf("Failed create device to database : %+v", err)
 		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
 	}
+	c.Outbox.Notify()
 
 	return nil
 }
 
-func (c *DeviceUseCase) FindAll(ctx context.Context, pagination *utils.PaginationRequest) ([]model.DeviceResponse, *utils.PaginationResponse, error) {
+func (c *DeviceUseCase) FindAll(ctx context.Context, pagination *utils.PaginationRequest, filter *model.DeviceFilter) ([]model.DeviceResponse, *utils.PaginationResponse, error) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
+	if err := validateDeviceFilter(filter); err != nil {
+		return nil, nil, err
+	}
+
 	var devices []entity.Device
-	total, err := c.DeviceRepository.FindAll(c.DB.WithContext(ctx), &devices, pagination)
+	total, err := c.DeviceRepository.FindAll(c.DB.WithContext(ctx), &devices, pagination, c.DeviceRepository.Filter(filter)...)
 	if err != nil {
 		c.Log.Warnf("Failed find all device from database : %+v", err)
 		return nil, nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
@@ -87,6 +140,10 @@ func (c *DeviceUseCase) FindAll(ctx context.Context, pagination *utils.Paginatio
 	responses := make([]model.DeviceResponse, len(devices))
 	for i, device := range devices {
 		responses[i] = *converter.DeviceToResponse(&device)
+		if filter != nil && len(filter.Near) == 2 && device.Latitude != nil && device.Longitude != nil {
+			distance := utils.HaversineKm(filter.Near[0], filter.Near[1], *device.Latitude, *device.Longitude)
+			responses[i].DistanceKm = &distance
+		}
 	}
 
 	totalPage := int((total + int64(pagination.Limit) - 1) / int64(pagination.Limit))
@@ -104,18 +161,56 @@ func (c *DeviceUseCase) FindAll(ctx context.Context, pagination *utils.Paginatio
 	return responses, paginationRes, nil
 }
 
-func (c *DeviceUseCase) FindByID(ctx context.Context, deviceID string) (*model.DeviceResponse, error) {
+func (c *DeviceUseCase) FindAllGeoJSON(ctx context.Context, pagination *utils.PaginationRequest, filter *model.DeviceFilter) (*model.GeoJSONFeatureCollection, error) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	device := &entity.Device{} 
+	if err := validateDeviceFilter(filter); err != nil {
+		return nil, err
+	}
+
+	scopes := append(c.DeviceRepository.Filter(filter), repository.WithSensors)
+
+	var devices []entity.Device
+	if _, err := c.DeviceRepository.FindAll(c.DB.WithContext(ctx), &devices, pagination, scopes...); err != nil {
+		c.Log.Warnf("Failed find all device from database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	var sensorIDs []uuid.UUID
+	for _, device := range devices {
+		for _, sensor := range device.Sensors {
+			sensorIDs = append(sensorIDs, sensor.ID)
+		}
+	}
+
+	readings, err := c.SensorLatestRepository.FindBySensorIDs(c.DB.WithContext(ctx), sensorIDs)
+	if err != nil {
+		c.Log.Warnf("Failed find latest readings from database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	latest := make(map[uuid.UUID]entity.SensorLatest, len(readings))
+	for _, reading := range readings {
+		latest[reading.SensorID] = reading
+	}
+
+	collection := &model.GeoJSONFeatureCollection{
+		Type:     "FeatureCollection",
+		Features: make([]model.GeoJSONFeature, len(devices)),
+	}
+	for i, device := range devices {
+		collection.Features[i] = converter.DeviceToGeoJSONFeature(&device, latest)
+	}
 
-	_, err := c.DeviceRepository.FindByIdWithSensors(
-		c.DB.WithContext(ctx),
-		device,
-		deviceID,
-	)
+	return collection, nil
+}
 
+func (c *DeviceUseCase) FindByID(ctx context.Context, deviceID string) (*model.DeviceResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	device, err := c.Lookups.FindDevice(c.DB.WithContext(ctx), deviceID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			c.Log.Infof("Device not found, id=%s", deviceID)
@@ -164,11 +259,33 @@ func (c *DeviceUseCase) Update(ctx context.Context, deviceID string, request *mo
 		device.Location = *request.Location
 	}
 
+	previousStatus := device.Status
 	if request.Status != nil {
 		device.Status = *request.Status
 	}
 
-	err = c.DeviceRepository.Update(c.DB.WithContext(ctx), device)
+	if request.HardwareModel != nil {
+		device.HardwareModel = *request.HardwareModel
+	}
+
+	if request.Latitude != nil && request.Longitude != nil {
+		device.Latitude = request.Latitude
+		device.Longitude = request.Longitude
+	}
+
+	if request.Boundary != nil {
+		if err := utils.ValidatePolygon(*request.Boundary); err != nil {
+			return fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+		}
+		device.Boundary = *request.Boundary
+	}
+
+	err = c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := c.DeviceRepository.Update(tx, device); err != nil {
+			return err
+		}
+		return c.Outbox.Record(tx, deviceUpdatedEvents(device, previousStatus)...)
+	})
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			c.Log.Infof("Device not found, id=%s", deviceID)
@@ -177,6 +294,8 @@ func (c *DeviceUseCase) Update(ctx context.Context, deviceID string, request *mo
 		c.Log.Warnf("Failed find device from database : %+v", err)
 		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
 	}
+	c.Outbox.Notify()
+	c.Lookups.InvalidateDevices(ctx, device.ID)
 
 	return nil
 }
@@ -196,7 +315,98 @@ func (c *DeviceUseCase) Delete(ctx context.Context, deviceID string) error {
 		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
 	}
 
-	err = c.DeviceRepository.Delete(c.DB.WithContext(ctx), device)
+	// the sensors go with the device, so their cached entries too
+	var sensors []*entity.Sensor
+	err = c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		deviceSensors, err := c.SensorRepository.FindByDeviceID(tx, device.ID)
+		if err != nil {
+			return err
+		}
+		for i := range deviceSensors {
+			sensors = append(sensors, &deviceSensors[i])
+		}
+
+		if err := c.DeviceRepository.Delete(tx, device); err != nil {
+			return err
+		}
+		return c.Outbox.Record(tx, &event.DeviceDeleted{DeviceResponse: *converter.DeviceToResponse(device)})
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Infof("Device not found, id=%s", deviceID)
+			return utils.ErrNotFound
+		}
+		c.Log.Warnf("Failed find device from database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	c.Outbox.Notify()
+	c.Lookups.InvalidateSensors(ctx, sensors...)
+	c.Lookups.InvalidateDevices(ctx, device.ID)
+
+	return nil
+}
+
+func (c *DeviceUseCase) SetLabels(ctx context.Context, deviceID string, request *model.UpdateLabelsRequest) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	err := c.Validator.Validate.Struct(request)
+	if err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+
+			var messages []string
+			for _, e := range validationErrors {
+				messages = append(messages, e.Translate(c.Validator.Translator))
+			}
+			return fmt.Errorf("%w: %s", utils.ErrValidation, strings.Join(messages, ", "))
+		}
+		return fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
+	if err := utils.ValidateLabels(request.Labels); err != nil {
+		return fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
+	device := &entity.Device{}
+	_, err = c.DeviceRepository.FindById(c.DB.WithContext(ctx), device, deviceID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Infof("Device not found, id=%s", deviceID)
+			return utils.ErrNotFound
+		}
+		c.Log.Warnf("Failed find device from database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	if device.Labels == nil {
+		device.Labels = entity.Labels{}
+	}
+	for key, value := range request.Labels {
+		device.Labels[key] = value
+	}
+
+	err = c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := c.DeviceRepository.Update(tx, device); err != nil {
+			return err
+		}
+		return c.Outbox.Record(tx, deviceUpdatedEvents(device, device.Status)...)
+	})
+	if err != nil {
+		c.Log.Warnf("Failed update device labels to database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	c.Outbox.Notify()
+	c.Lookups.InvalidateDevices(ctx, device.ID)
+
+	return nil
+}
+
+func (c *DeviceUseCase) RemoveLabel(ctx context.Context, deviceID string, key string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	device := &entity.Device{}
+	_, err := c.DeviceRepository.FindById(c.DB.WithContext(ctx), device, deviceID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			c.Log.Infof("Device not found, id=%s", deviceID)
@@ -206,5 +416,69 @@ func (c *DeviceUseCase) Delete(ctx context.Context, deviceID string) error {
 		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
 	}
 
+	if _, exists := device.Labels[key]; !exists {
+		return fmt.Errorf("%w: label %s", utils.ErrNotFound, key)
+	}
+	delete(device.Labels, key)
+
+	err = c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := c.DeviceRepository.Update(tx, device); err != nil {
+			return err
+		}
+		return c.Outbox.Record(tx, deviceUpdatedEvents(device, device.Status)...)
+	})
+	if err != nil {
+		c.Log.Warnf("Failed update device labels to database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	c.Outbox.Notify()
+	c.Lookups.InvalidateDevices(ctx, device.ID)
+
+	return nil
+}
+
+// deviceUpdatedEvents describes an updated device, adding a status change
+// when its status differs from previous.
+func deviceUpdatedEvents(device *entity.Device, previous string) []event.Event {
+	response := converter.DeviceToResponse(device)
+	events := []event.Event{&event.DeviceUpdated{DeviceResponse: *response}}
+	if device.Status != previous {
+		events = append(events, &event.DeviceStatusChanged{
+			DeviceStatusEvent: model.DeviceStatusEvent{
+				DeviceID:       response.ID,
+				Name:           device.Name,
+				Status:         device.Status,
+				PreviousStatus: previous,
+			},
+			Labels: device.Labels,
+		})
+	}
+	return events
+}
+
+func validateDeviceFilter(filter *model.DeviceFilter) error {
+	if filter == nil {
+		return nil
+	}
+
+	if len(filter.Near) == 2 {
+		if !utils.ValidLatitude(filter.Near[0]) || !utils.ValidLongitude(filter.Near[1]) {
+			return fmt.Errorf("%w: near must be a valid latitude,longitude", utils.ErrValidation)
+		}
+		// written so NaN fails as well
+		if !(filter.RadiusKm > 0 && filter.RadiusKm <= utils.MaxRadiusKm) {
+			return fmt.Errorf("%w: radius_km must be greater than 0 and at most %.0f", utils.ErrValidation, utils.MaxRadiusKm)
+		}
+	}
+
+	if len(filter.BBox) == 4 {
+		minLng, minLat, maxLng, maxLat := filter.BBox[0], filter.BBox[1], filter.BBox[2], filter.BBox[3]
+		if !utils.ValidLongitude(minLng) || !utils.ValidLongitude(maxLng) ||
+			!utils.ValidLatitude(minLat) || !utils.ValidLatitude(maxLat) ||
+			minLng > maxLng || minLat > maxLat {
+			return fmt.Errorf("%w: bbox must be min_lng,min_lat,max_lng,max_lat", utils.ErrValidation)
+		}
+	}
+
 	return nil
 }
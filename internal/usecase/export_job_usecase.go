@@ -0,0 +1,407 @@
+package usecase
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"mertani_test/internal/entity"
+	"mertani_test/internal/model"
+	"mertani_test/internal/model/converter"
+	"mertani_test/internal/repository"
+	"mertani_test/internal/utils"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	// exportJobPollInterval is how often idle workers look for jobs queued
+	// by other instances; jobs created here wake a worker right away.
+	exportJobPollInterval = 5 * time.Second
+	// exportJobProgressInterval is how often a running job saves its row count.
+	exportJobProgressInterval = time.Second
+	// exportJobHeartbeatInterval is how often a running job renews its claim
+	// when its row count has not moved.
+	exportJobHeartbeatInterval = 30 * time.Second
+	// exportJobLease is how long a running job may go without renewing its
+	// claim before another worker takes it over.
+	exportJobLease = 2 * time.Minute
+	// exportJobCleanupInterval is how often expired files are removed.
+	exportJobCleanupInterval = time.Minute
+)
+
+// ExportJobUseCase runs readings exports in the background. Jobs are
+// persisted, claimed by a pool of workers and written to StorageDir, where
+// the file stays downloadable for Retention. A job may run for Timeout, or
+// without a limit when it is zero; workers renew their claim while they
+// run, so jobs of a crashed instance are picked up once exportJobLease
+// passes.
+type ExportJobUseCase struct {
+	DB                  *gorm.DB
+	Log                 *logrus.Logger
+	Validator           *utils.Validator
+	ExportJobRepository *repository.ExportJobRepository
+	ReadingUseCase      *ReadingUseCase
+	StorageDir          string
+	Workers             int
+	Retention           time.Duration
+	Timeout             time.Duration
+	wake                chan struct{}
+}
+
+func NewExportJobUseCase(db *gorm.DB, logger *logrus.Logger, validator *utils.Validator,
+	exportJobRepository *repository.ExportJobRepository, readingUseCase *ReadingUseCase,
+	storageDir string, workers int, retention time.Duration, timeout time.Duration) *ExportJobUseCase {
+	if storageDir == "" {
+		storageDir = "storage/exports"
+	}
+	if workers <= 0 {
+		workers = 2
+	}
+	if retention <= 0 {
+		retention = 24 * time.Hour
+	}
+	return &ExportJobUseCase{
+		DB:                  db,
+		Log:                 logger,
+		Validator:           validator,
+		ExportJobRepository: exportJobRepository,
+		ReadingUseCase:      readingUseCase,
+		StorageDir:          storageDir,
+		Workers:             workers,
+		Retention:           retention,
+		Timeout:             timeout,
+		wake:                make(chan struct{}, 1),
+	}
+}
+
+func (c *ExportJobUseCase) Create(ctx context.Context, request *model.CreateExportJobRequest) (*model.ExportJobResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	err := c.Validator.Validate.Struct(request)
+	if err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+
+			var messages []string
+			for _, e := range validationErrors {
+				messages = append(messages, e.Translate(c.Validator.Translator))
+			}
+			return nil, fmt.Errorf("%w: %s", utils.ErrValidation, strings.Join(messages, ", "))
+		}
+		return nil, fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
+	// preparing the export checks the target and the options now rather
+	// than in the worker
+	readingExport, err := c.prepare(ctx, request.Kind, request.TargetID, &request.ReadingExportRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := c.ReadingUseCase.readingExportFilter(&request.ReadingExportRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &entity.ExportJob{
+		Kind:        request.Kind,
+		TargetID:    uuid.MustParse(request.TargetID),
+		Format:      request.Format,
+		RangeFrom:   filter.From,
+		RangeTo:     filter.To,
+		Interval:    request.Interval,
+		Unit:        request.Unit,
+		Status:      entity.ExportJobPending,
+		FileName:    readingExport.Filename,
+		ContentType: readingExport.ContentType,
+	}
+	if err := c.ExportJobRepository.Create(c.DB.WithContext(ctx), job); err != nil {
+		c.Log.Warnf("Failed create export job to database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+
+	return converter.ExportJobToResponse(job), nil
+}
+
+func (c *ExportJobUseCase) FindByID(ctx context.Context, jobID string) (*model.ExportJobResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	job, err := c.findJob(c.DB.WithContext(ctx), jobID)
+	if err != nil {
+		return nil, err
+	}
+	return converter.ExportJobToResponse(job), nil
+}
+
+// Download returns a completed job with the path of its file. Jobs that are
+// not finished yet are a conflict; expired ones are gone.
+func (c *ExportJobUseCase) Download(ctx context.Context, jobID string) (*entity.ExportJob, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	job, err := c.findJob(c.DB.WithContext(ctx), jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch job.Status {
+	case entity.ExportJobCompleted:
+		if job.ExpiresAt != nil && !job.ExpiresAt.After(time.Now()) {
+			return nil, fmt.Errorf("%w: export has expired", utils.ErrNotFound)
+		}
+		return job, nil
+	case entity.ExportJobExpired:
+		return nil, fmt.Errorf("%w: export has expired", utils.ErrNotFound)
+	case entity.ExportJobFailed:
+		return nil, fmt.Errorf("%w: export failed: %s", utils.ErrConflict, job.Error)
+	}
+	return nil, fmt.Errorf("%w: export is %s", utils.ErrConflict, job.Status)
+}
+
+// Start launches the workers and the expiry cleanup. They stop when ctx is
+// done.
+func (c *ExportJobUseCase) Start(ctx context.Context) error {
+	if err := os.MkdirAll(c.StorageDir, 0o755); err != nil {
+		return err
+	}
+
+	for i := 0; i < c.Workers; i++ {
+		go c.work(ctx)
+	}
+	go c.cleanup(ctx)
+	return nil
+}
+
+func (c *ExportJobUseCase) work(ctx context.Context) {
+	ticker := time.NewTicker(exportJobPollInterval)
+	defer ticker.Stop()
+
+	for {
+		job, err := c.ExportJobRepository.ClaimNext(c.DB.WithContext(ctx), time.Now().Add(-exportJobLease))
+		if err != nil {
+			c.Log.Warnf("Failed claim export job : %+v", err)
+		}
+		if job != nil {
+			c.run(ctx, job)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// run produces the file of a claimed job. The file is written under a
+// temporary name and only renamed once complete, so a crash never leaves
+// a truncated download behind. Each claim writes its own file, so a worker
+// that lost its job to another one never overwrites the other's download.
+func (c *ExportJobUseCase) run(ctx context.Context, job *entity.ExportJob) {
+	request := &model.ReadingExportRequest{
+		Format:   job.Format,
+		Interval: job.Interval,
+		Unit:     job.Unit,
+	}
+	if job.RangeFrom != nil {
+		request.From = job.RangeFrom.Format(time.RFC3339Nano)
+	}
+	if job.RangeTo != nil {
+		request.To = job.RangeTo.Format(time.RFC3339Nano)
+	}
+
+	path := filepath.Join(c.StorageDir, fmt.Sprintf("%s.%d.%s", job.ID, job.StartedAt.UnixNano(), job.Format))
+	size, err := c.produce(ctx, job, request, path)
+
+	now := time.Now()
+	job.FinishedAt = &now
+	if err != nil {
+		c.Log.Warnf("Failed export job %s : %+v", job.ID, err)
+		job.Status = entity.ExportJobFailed
+		job.Error = err.Error()
+		if len(job.Error) > 500 {
+			job.Error = job.Error[:500]
+		}
+	} else {
+		expiresAt := now.Add(c.Retention)
+		job.Status = entity.ExportJobCompleted
+		job.FilePath = path
+		job.Size = size
+		job.ExpiresAt = &expiresAt
+	}
+
+	// the job is finished even when ctx was cancelled meanwhile
+	finished, err := c.ExportJobRepository.Finish(c.DB.WithContext(context.Background()), job)
+	if err != nil {
+		c.Log.Warnf("Failed update export job to database : %+v", err)
+	}
+	if !finished && job.FilePath != "" {
+		os.Remove(job.FilePath)
+	}
+	if err == nil && !finished {
+		c.Log.Infof("Export job %s was taken over by another worker", job.ID)
+	}
+}
+
+func (c *ExportJobUseCase) produce(ctx context.Context, job *entity.ExportJob, request *model.ReadingExportRequest, path string) (int64, error) {
+	readingExport, err := c.prepare(ctx, job.Kind, job.TargetID.String(), request)
+	if err != nil {
+		return 0, err
+	}
+	readingExport.Timeout = c.Timeout
+
+	// losing the claim stops the export, another worker having taken it over
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	temporary := path + ".part"
+	file, err := os.Create(temporary)
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(temporary)
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		c.reportProgress(job, readingExport, done, cancel)
+	}()
+
+	writer := bufio.NewWriterSize(file, 64*1024)
+	err = readingExport.Write(ctx, writer)
+	close(done)
+	wg.Wait()
+	job.Rows = readingExport.Rows()
+
+	if err == nil {
+		err = writer.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	info, err := os.Stat(temporary)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.Rename(temporary, path); err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// reportProgress saves the row count of a running job, which also renews
+// its claim, until done closes. It calls lost when the claim was lost.
+func (c *ExportJobUseCase) reportProgress(job *entity.ExportJob, readingExport *ReadingExport, done <-chan struct{}, lost func()) {
+	ticker := time.NewTicker(exportJobProgressInterval)
+	defer ticker.Stop()
+
+	var saved int64
+	savedAt := time.Now()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			rows := readingExport.Rows()
+			if rows == saved && time.Since(savedAt) < exportJobHeartbeatInterval {
+				continue
+			}
+			claimed, err := c.ExportJobRepository.UpdateProgress(c.DB, job, rows)
+			if err != nil {
+				c.Log.Warnf("Failed update export job progress : %+v", err)
+				continue
+			}
+			if !claimed {
+				c.Log.Warnf("Export job %s was taken over by another worker", job.ID)
+				lost()
+				return
+			}
+			saved, savedAt = rows, time.Now()
+		}
+	}
+}
+
+// cleanup removes the files of expired jobs and marks them expired.
+func (c *ExportJobUseCase) cleanup(ctx context.Context) {
+	ticker := time.NewTicker(exportJobCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		jobs, err := c.ExportJobRepository.FindExpired(c.DB.WithContext(ctx), time.Now())
+		if err != nil {
+			c.Log.Warnf("Failed find expired export jobs : %+v", err)
+			continue
+		}
+
+		for _, job := range jobs {
+			if err := os.Remove(job.FilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+				c.Log.Warnf("Failed remove export file %s : %+v", job.FilePath, err)
+				continue
+			}
+			job.Status = entity.ExportJobExpired
+			job.FilePath = ""
+			if err := c.ExportJobRepository.Update(c.DB.WithContext(ctx), &job); err != nil {
+				c.Log.Warnf("Failed update export job to database : %+v", err)
+			}
+		}
+	}
+}
+
+func (c *ExportJobUseCase) prepare(ctx context.Context, kind string, targetID string, request *model.ReadingExportRequest) (*ReadingExport, error) {
+	if kind == entity.ExportJobDeviceReadings {
+		readingExport, err := c.ReadingUseCase.ExportDevice(ctx, targetID, request)
+		if errors.Is(err, utils.ErrNotFound) {
+			return nil, fmt.Errorf("%w: device %s does not exist", utils.ErrValidation, targetID)
+		}
+		return readingExport, err
+	}
+
+	readingExport, err := c.ReadingUseCase.ExportSensor(ctx, targetID, request)
+	if errors.Is(err, utils.ErrNotFound) {
+		return nil, fmt.Errorf("%w: sensor %s does not exist", utils.ErrValidation, targetID)
+	}
+	return readingExport, err
+}
+
+func (c *ExportJobUseCase) findJob(db *gorm.DB, jobID string) (*entity.ExportJob, error) {
+	job := &entity.ExportJob{}
+	_, err := c.ExportJobRepository.FindById(db, job, jobID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Infof("Export job not found, id=%s", jobID)
+			return nil, utils.ErrNotFound
+		}
+		c.Log.Warnf("Failed find export job from database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	return job, nil
+}
@@ -0,0 +1,295 @@
+package usecase
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mertani_test/internal/entity"
+	"mertani_test/internal/event"
+	"mertani_test/internal/model"
+	"mertani_test/internal/model/converter"
+	"mertani_test/internal/repository"
+	"mertani_test/internal/utils"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	// MaxLineProtocolLines bounds a single write request.
+	MaxLineProtocolLines = 5000
+	// maxLineWriteErrors bounds the errors reported back for one write.
+	maxLineWriteErrors = 100
+)
+
+// LineProtocolUseCase ingests InfluxDB line protocol. The device is taken
+// from the device_id tag or, by name, from the configured device tag. Each
+// field becomes a reading of the device sensor matching
+// "<measurement>.<field>" or the field name, by channel label or name.
+type LineProtocolUseCase struct {
+	DB                *gorm.DB
+	Log               *logrus.Logger
+	DeviceRepository  *repository.DeviceRepository
+	SensorRepository  *repository.SensorRepository
+	SensorUseCase     *SensorUseCase
+	ReadingUseCase    *ReadingUseCase
+	DeviceTag         string
+	AutoCreateSensors bool
+}
+
+func NewLineProtocolUseCase(db *gorm.DB, logger *logrus.Logger,
+	deviceRepository *repository.DeviceRepository, sensorRepository *repository.SensorRepository,
+	sensorUseCase *SensorUseCase, readingUseCase *ReadingUseCase, deviceTag string, autoCreateSensors bool) *LineProtocolUseCase {
+	if deviceTag == "" {
+		deviceTag = "device"
+	}
+	return &LineProtocolUseCase{
+		DB:                db,
+		Log:               logger,
+		DeviceRepository:  deviceRepository,
+		SensorRepository:  sensorRepository,
+		SensorUseCase:     sensorUseCase,
+		ReadingUseCase:    readingUseCase,
+		DeviceTag:         deviceTag,
+		AutoCreateSensors: autoCreateSensors,
+	}
+}
+
+// lineDevice is a device resolved during one write with its sensor index.
+type lineDevice struct {
+	device  *entity.Device
+	sensors map[string]*entity.Sensor
+}
+
+// Write stores a batch of lines, reporting the lines rejected as invalid or
+// naming unknown devices. A database failure aborts the batch with
+// ErrInternal; lines written before it stay stored.
+func (c *LineProtocolUseCase) Write(ctx context.Context, body []byte, precision string) (*model.LineWriteResponse, error) {
+	// a write carries up to MaxLineProtocolLines lines, each stored in its
+	// own transaction
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if precision == "" {
+		precision = "ns"
+	}
+	if !utils.ValidLinePrecision(precision) {
+		return nil, fmt.Errorf("%w: precision must be one of ns, us, ms, s", utils.ErrValidation)
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if len(lines) > MaxLineProtocolLines {
+			return nil, fmt.Errorf("%w: a write may contain at most %d lines", utils.ErrValidation, MaxLineProtocolLines)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
+	response := &model.LineWriteResponse{}
+	devices := map[string]*lineDevice{}
+	now := time.Now()
+
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		response.Lines++
+
+		readings, created, err := c.writeLine(ctx, devices, line, precision, now)
+		response.SensorsCreated += created
+		if err != nil {
+			// only lines the client can fix are reported; anything else
+			// would fail the rest of the batch the same way
+			if !errors.Is(err, utils.ErrValidation) && !errors.Is(err, utils.ErrNotFound) {
+				c.Log.Warnf("Failed write line protocol : %+v", err)
+				return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+			}
+			response.Failed++
+			if len(response.Errors) < maxLineWriteErrors {
+				response.Errors = append(response.Errors, model.LineWriteError{Line: i + 1, Error: err.Error()})
+			}
+			continue
+		}
+		response.Written++
+		response.Readings += readings
+	}
+
+	return response, nil
+}
+
+// writeLine stores the fields of one line. A field that cannot be mapped or
+// is rejected fails the whole line.
+func (c *LineProtocolUseCase) writeLine(ctx context.Context, devices map[string]*lineDevice, line string, precision string, now time.Time) (int, int, error) {
+	point, err := utils.ParseLine(line, precision, now)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+	if len(point.Fields) == 0 {
+		return 0, 0, fmt.Errorf("%w: no numeric fields", utils.ErrValidation)
+	}
+
+	target, err := c.resolveDevice(c.DB.WithContext(ctx), devices, point)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	fields := make([]string, 0, len(point.Fields))
+	for field := range point.Fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var (
+		created []*entity.Sensor
+		stored  []storedReading
+	)
+	err = c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, field := range fields {
+			channel := point.Measurement + "." + field
+			sensor, ok := target.sensors[channel]
+			if !ok {
+				sensor, ok = target.sensors[field]
+			}
+			if !ok {
+				if !c.AutoCreateSensors {
+					return fmt.Errorf("%w: no sensor for %s on device %s", utils.ErrValidation, channel, target.device.Name)
+				}
+				sensor, err = c.createSensor(tx, target.device, point, field)
+				if err != nil {
+					return err
+				}
+				created = append(created, sensor)
+			}
+
+			if _, err := c.ReadingUseCase.store(tx, &stored, sensor, point.Fields[field], point.Time); err != nil {
+				if errors.Is(err, utils.ErrValidation) {
+					return fmt.Errorf("field %s: %w", field, err)
+				}
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// only index sensors once their transaction committed
+	for _, sensor := range created {
+		target.sensors[sensor.Labels[UplinkChannelLabel]] = sensor
+	}
+	if len(created) > 0 {
+		c.SensorUseCase.Outbox.Notify()
+		c.SensorUseCase.Lookups.InvalidateSensors(ctx, created...)
+	}
+	c.ReadingUseCase.committed(stored)
+	return len(fields), len(created), nil
+}
+
+func (c *LineProtocolUseCase) resolveDevice(db *gorm.DB, devices map[string]*lineDevice, point *utils.LinePoint) (*lineDevice, error) {
+	key, byID := point.Tags["device_id"], true
+	if key == "" {
+		key, byID = point.Tags[c.DeviceTag], false
+	}
+	if key == "" {
+		return nil, fmt.Errorf("%w: missing device_id or %s tag", utils.ErrValidation, c.DeviceTag)
+	}
+	if cached, ok := devices[key]; ok {
+		return cached, nil
+	}
+
+	device := &entity.Device{}
+	var err error
+	if byID {
+		if _, parseErr := uuid.Parse(key); parseErr != nil {
+			return nil, fmt.Errorf("%w: invalid device_id %q", utils.ErrValidation, key)
+		}
+		_, err = c.DeviceRepository.FindByIdWithSensors(db, device, key)
+	} else {
+		_, err = c.DeviceRepository.FindByNameWithSensors(db, device, key)
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: device %q", utils.ErrNotFound, key)
+		}
+		return nil, err
+	}
+
+	resolved := &lineDevice{device: device, sensors: uplinkSensors(device.Sensors)}
+	devices[key] = resolved
+	return resolved, nil
+}
+
+// createSensor auto-creates a sensor for a field. Its type is the field
+// name, or else the measurement, when either is a registered sensor type.
+func (c *LineProtocolUseCase) createSensor(tx *gorm.DB, device *entity.Device, point *utils.LinePoint, field string) (*entity.Sensor, error) {
+	channel := point.Measurement + "." + field
+
+	var code, unit string
+	var err error
+	for _, candidate := range []string{field, point.Measurement} {
+		unit, err = c.SensorUseCase.resolveSensorType(tx, candidate, "")
+		if err == nil {
+			code = candidate
+			break
+		}
+		if !errors.Is(err, utils.ErrValidation) {
+			return nil, err
+		}
+	}
+	if code == "" {
+		return nil, fmt.Errorf("%w: cannot create sensor for %s, neither %q nor %q is a sensor type",
+			utils.ErrValidation, channel, field, point.Measurement)
+	}
+
+	name := device.Name + "." + channel
+	if len(name) > 100 {
+		name = name[:100]
+	}
+	exists, err := c.SensorRepository.ExistsByName(tx, name)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, fmt.Errorf("%w: cannot create sensor %q, the name is taken", utils.ErrValidation, name)
+	}
+
+	labels := entity.Labels{UplinkChannelLabel: channel}
+	for key, value := range point.Tags {
+		if key == "device_id" || key == c.DeviceTag {
+			continue
+		}
+		if utils.ValidLabelKey(key) && utils.ValidLabelValue(value) {
+			labels[key] = value
+		}
+	}
+
+	sensor := &entity.Sensor{
+		DeviceID: device.ID,
+		Name:     name,
+		Type:     code,
+		Unit:     unit,
+		IsActive: true,
+		Labels:   labels,
+		Kind:     entity.SensorPhysical,
+	}
+	if err := c.SensorRepository.Create(tx, sensor); err != nil {
+		return nil, err
+	}
+	if err := c.SensorUseCase.Outbox.Record(tx, &event.SensorCreated{SensorResponse: *converter.SensorToResponse(sensor)}); err != nil {
+		return nil, err
+	}
+	return sensor, nil
+}
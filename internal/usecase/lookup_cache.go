@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"context"
+	"mertani_test/internal/cache"
+	"mertani_test/internal/entity"
+	"mertani_test/internal/model"
+	"mertani_test/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// lookupCacheTTL is how long a device or sensor stays cached unless
+// configured otherwise.
+const lookupCacheTTL = 5 * time.Minute
+
+// LookupCache reads devices and sensors by id through a cache. Devices are
+// cached with their sensors and sensors with their inputs, neither with
+// their latest readings, which change on every ingest and are loaded
+// apart. Usecases writing a device or sensor invalidate it once the write
+// committed; entries also expire after the TTL, which bounds how long a
+// lookup racing a write may keep what it read before the write.
+type LookupCache struct {
+	Log                    *logrus.Logger
+	DeviceRepository       *repository.DeviceRepository
+	SensorRepository       *repository.SensorRepository
+	SensorLatestRepository *repository.SensorLatestRepository
+	Devices                *cache.Lookup[entity.Device]
+	Sensors                *cache.Lookup[entity.Sensor]
+}
+
+func NewLookupCache(store cache.Cache, logger *logrus.Logger, deviceRepository *repository.DeviceRepository,
+	sensorRepository *repository.SensorRepository, sensorLatestRepository *repository.SensorLatestRepository,
+	ttl time.Duration) *LookupCache {
+	if ttl <= 0 {
+		ttl = lookupCacheTTL
+	}
+	return &LookupCache{
+		Log:                    logger,
+		DeviceRepository:       deviceRepository,
+		SensorRepository:       sensorRepository,
+		SensorLatestRepository: sensorLatestRepository,
+		Devices:                cache.NewLookup[entity.Device](store, logger, "device", ttl),
+		Sensors:                cache.NewLookup[entity.Sensor](store, logger, "sensor", ttl),
+	}
+}
+
+// FindDevice returns a device with its sensors and their latest readings.
+// It fails like the repository does, with gorm.ErrRecordNotFound for a
+// missing device.
+func (c *LookupCache) FindDevice(db *gorm.DB, deviceID string) (*entity.Device, error) {
+	device, err := c.findDevice(db, deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	sensors := make([]*entity.Sensor, len(device.Sensors))
+	for i := range device.Sensors {
+		sensors[i] = &device.Sensors[i]
+	}
+	if err := c.loadLatest(db, sensors...); err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+// FindSensor returns a sensor with its inputs.
+func (c *LookupCache) FindSensor(db *gorm.DB, sensorID string) (*entity.Sensor, error) {
+	id, err := uuid.Parse(sensorID)
+	if err != nil {
+		// left to the database to reject, as without the cache
+		return c.SensorRepository.FindById(db.Scopes(repository.WithInputs), &entity.Sensor{}, sensorID)
+	}
+
+	return c.Sensors.Get(db.Statement.Context, id.String(), func() (*entity.Sensor, error) {
+		return c.SensorRepository.FindById(db.Scopes(repository.WithInputs), &entity.Sensor{}, id)
+	})
+}
+
+// FindSensorWithDevice returns a sensor with its inputs, its device and
+// its latest reading.
+func (c *LookupCache) FindSensorWithDevice(db *gorm.DB, sensorID string) (*entity.Sensor, error) {
+	sensor, err := c.FindSensor(db, sensorID)
+	if err != nil {
+		return nil, err
+	}
+
+	device, err := c.findDevice(db, sensor.DeviceID.String())
+	if err != nil {
+		return nil, err
+	}
+	sensor.Device = *device
+	sensor.Device.Sensors = nil
+
+	if err := c.loadLatest(db, sensor); err != nil {
+		return nil, err
+	}
+	return sensor, nil
+}
+
+// InvalidateDevices drops cached devices.
+func (c *LookupCache) InvalidateDevices(ctx context.Context, deviceIDs ...uuid.UUID) {
+	ids := make([]string, len(deviceIDs))
+	for i, id := range deviceIDs {
+		ids[i] = id.String()
+	}
+	c.Devices.Invalidate(ctx, ids...)
+}
+
+// InvalidateSensors drops cached sensors along with the devices they belong
+// to, which list them.
+func (c *LookupCache) InvalidateSensors(ctx context.Context, sensors ...*entity.Sensor) {
+	sensorIDs := make([]string, len(sensors))
+	deviceIDs := make([]uuid.UUID, len(sensors))
+	for i, sensor := range sensors {
+		sensorIDs[i] = sensor.ID.String()
+		deviceIDs[i] = sensor.DeviceID
+	}
+	c.Sensors.Invalidate(ctx, sensorIDs...)
+	c.InvalidateDevices(ctx, deviceIDs...)
+}
+
+// Stats reports the lookups served so far, by cached record.
+func (c *LookupCache) Stats() *model.CacheStatsResponse {
+	response := &model.CacheStatsResponse{Lookups: map[string]model.CacheLookupStats{}}
+	for name, stats := range map[string]cache.Stats{
+		c.Devices.Name: c.Devices.Stats(),
+		c.Sensors.Name: c.Sensors.Stats(),
+	} {
+		response.Lookups[name] = model.CacheLookupStats{
+			Hits:     stats.Hits,
+			Misses:   stats.Misses,
+			Errors:   stats.Errors,
+			HitRatio: stats.HitRatio(),
+		}
+	}
+	return response
+}
+
+// findDevice returns a device with its sensors, without their latest
+// readings.
+func (c *LookupCache) findDevice(db *gorm.DB, deviceID string) (*entity.Device, error) {
+	id, err := uuid.Parse(deviceID)
+	if err != nil {
+		// left to the database to reject, as without the cache
+		return c.DeviceRepository.FindById(db.Scopes(repository.WithSensors), &entity.Device{}, deviceID)
+	}
+
+	return c.Devices.Get(db.Statement.Context, id.String(), func() (*entity.Device, error) {
+		return c.DeviceRepository.FindById(db.Scopes(repository.WithSensors), &entity.Device{}, id)
+	})
+}
+
+// loadLatest sets the latest reading of each sensor that has one.
+func (c *LookupCache) loadLatest(db *gorm.DB, sensors ...*entity.Sensor) error {
+	if len(sensors) == 0 {
+		return nil
+	}
+
+	sensorIDs := make([]uuid.UUID, len(sensors))
+	for i, sensor := range sensors {
+		sensorIDs[i] = sensor.ID
+	}
+	latest, err := c.SensorLatestRepository.FindBySensorIDs(db, sensorIDs)
+	if err != nil {
+		return err
+	}
+
+	bySensor := make(map[uuid.UUID]*entity.SensorLatest, len(latest))
+	for i := range latest {
+		bySensor[latest[i].SensorID] = &latest[i]
+	}
+	for _, sensor := range sensors {
+		sensor.Latest = bySensor[sensor.ID]
+	}
+	return nil
+}
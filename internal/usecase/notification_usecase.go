@@ -0,0 +1,944 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mertani_test/internal/entity"
+	"mertani_test/internal/event"
+	"mertani_test/internal/model"
+	"mertani_test/internal/model/converter"
+	"mertani_test/internal/notify"
+	"mertani_test/internal/repository"
+	"mertani_test/internal/utils"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	// notificationPollInterval is how often idle workers look for retries
+	// that became due; new notifications wake a worker right away.
+	notificationPollInterval = 5 * time.Second
+	// notificationBackoffBase doubles after every failed attempt, up to
+	// notificationBackoffMax.
+	notificationBackoffBase = 30 * time.Second
+	notificationBackoffMax  = time.Hour
+	// notificationLogLimit bounds the error kept per attempt.
+	notificationLogLimit = 500
+)
+
+// NotificationUseCase manages notification channels and routing rules and
+// turns alert events into notifications. Every alert routed by a rule to a
+// channel is stored as one notification, held back by quiet hours or
+// grouping or sent by a pool of workers, which retry failures with
+// exponential backoff up to MaxAttempts.
+type NotificationUseCase struct {
+	DB                            *gorm.DB
+	Log                           *logrus.Logger
+	Validator                     *utils.Validator
+	NotificationChannelRepository *repository.NotificationChannelRepository
+	NotificationRuleRepository    *repository.NotificationRuleRepository
+	NotificationRepository        *repository.NotificationRepository
+	Client                        *http.Client
+	Workers                       int
+	MaxAttempts                   int
+	wake                          chan struct{}
+}
+
+func NewNotificationUseCase(db *gorm.DB, logger *logrus.Logger, validator *utils.Validator,
+	notificationChannelRepository *repository.NotificationChannelRepository,
+	notificationRuleRepository *repository.NotificationRuleRepository,
+	notificationRepository *repository.NotificationRepository,
+	workers int, maxAttempts int, timeout time.Duration) *NotificationUseCase {
+	if workers <= 0 {
+		workers = 2
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = 5
+	}
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &NotificationUseCase{
+		DB:                            db,
+		Log:                           logger,
+		Validator:                     validator,
+		NotificationChannelRepository: notificationChannelRepository,
+		NotificationRuleRepository:    notificationRuleRepository,
+		NotificationRepository:        notificationRepository,
+		Client: &http.Client{
+			Timeout: timeout,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		Workers:     workers,
+		MaxAttempts: maxAttempts,
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+func (c *NotificationUseCase) CreateChannel(ctx context.Context, request *model.CreateNotificationChannelRequest) (*model.NotificationChannelResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := c.validate(request); err != nil {
+		return nil, err
+	}
+
+	channel := &entity.NotificationChannel{
+		Name:     request.Name,
+		Type:     request.Type,
+		Config:   request.Config,
+		IsActive: true,
+	}
+	if request.IsActive != nil {
+		channel.IsActive = *request.IsActive
+	}
+	if _, err := notify.New(channel.Type, channel.Config, c.Client); err != nil {
+		return nil, fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
+	if err := c.NotificationChannelRepository.Create(c.DB.WithContext(ctx), channel); err != nil {
+		c.Log.Warnf("Failed create notification channel to database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	return converter.NotificationChannelToResponse(channel), nil
+}
+
+func (c *NotificationUseCase) FindAllChannels(ctx context.Context, pagination *utils.PaginationRequest) ([]model.NotificationChannelResponse, *utils.PaginationResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var channels []entity.NotificationChannel
+	total, err := c.NotificationChannelRepository.FindAll(c.DB.WithContext(ctx), &channels, pagination)
+	if err != nil {
+		c.Log.Warnf("Failed find all notification channel from database : %+v", err)
+		return nil, nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	responses := make([]model.NotificationChannelResponse, len(channels))
+	for i, channel := range channels {
+		responses[i] = *converter.NotificationChannelToResponse(&channel)
+	}
+
+	totalPage := int((total + int64(pagination.Limit) - 1) / int64(pagination.Limit))
+
+	paginationRes := &utils.PaginationResponse{
+		Page:      pagination.Page,
+		Limit:     pagination.Limit,
+		OrderBy:   pagination.OrderBy,
+		SortBy:    pagination.SortBy,
+		Search:    pagination.Search,
+		TotalData: total,
+		TotalPage: totalPage,
+	}
+
+	return responses, paginationRes, nil
+}
+
+func (c *NotificationUseCase) FindChannelByID(ctx context.Context, channelID string) (*model.NotificationChannelResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	channel, err := c.findChannel(c.DB.WithContext(ctx), channelID)
+	if err != nil {
+		return nil, err
+	}
+	return converter.NotificationChannelToResponse(channel), nil
+}
+
+func (c *NotificationUseCase) UpdateChannel(ctx context.Context, channelID string, request *model.UpdateNotificationChannelRequest) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	channel, err := c.findChannel(c.DB.WithContext(ctx), channelID)
+	if err != nil {
+		return err
+	}
+
+	if err := c.validate(request); err != nil {
+		return err
+	}
+
+	if request.Name != nil {
+		channel.Name = *request.Name
+	}
+	if request.Config != nil {
+		channel.Config = keepMaskedSecrets(channel.Config, request.Config)
+	}
+	if request.IsActive != nil {
+		channel.IsActive = *request.IsActive
+	}
+	if _, err := notify.New(channel.Type, channel.Config, c.Client); err != nil {
+		return fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
+	if err := c.NotificationChannelRepository.Update(c.DB.WithContext(ctx), channel); err != nil {
+		c.Log.Warnf("Failed update notification channel to database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	return nil
+}
+
+func (c *NotificationUseCase) DeleteChannel(ctx context.Context, channelID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	channel, err := c.findChannel(c.DB.WithContext(ctx), channelID)
+	if err != nil {
+		return err
+	}
+
+	if err := c.NotificationChannelRepository.Delete(c.DB.WithContext(ctx), channel); err != nil {
+		c.Log.Warnf("Failed delete notification channel from database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	return nil
+}
+
+// TestChannel sends a sample alert through a channel right away, so its
+// settings can be checked, and reports the channel's error if any. Nothing
+// is written to the delivery log.
+func (c *NotificationUseCase) TestChannel(ctx context.Context, channelID string) error {
+	channel, err := c.findChannel(c.DB.WithContext(ctx), channelID)
+	if err != nil {
+		return err
+	}
+
+	sender, err := notify.New(channel.Type, channel.Config, c.Client)
+	if err != nil {
+		return fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
+	template, err := notify.ParseTemplate("", "")
+	if err != nil {
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	message, err := template.Render(&notify.TemplateData{AlertEvent: sampleAlert(), Rule: "test"})
+	if err != nil {
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	if err := sender.Send(ctx, message); err != nil {
+		return fmt.Errorf("%w: %s", utils.ErrIntegration, err.Error())
+	}
+	return nil
+}
+
+func (c *NotificationUseCase) CreateRule(ctx context.Context, request *model.CreateNotificationRuleRequest) (*model.NotificationRuleResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := c.validate(request); err != nil {
+		return nil, err
+	}
+
+	rule := &entity.NotificationRule{
+		Name:               request.Name,
+		MinSeverity:        request.MinSeverity,
+		Selector:           request.Selector,
+		ChannelIDs:         uniqueStrings(request.ChannelIDs),
+		SubjectTemplate:    request.SubjectTemplate,
+		BodyTemplate:       request.BodyTemplate,
+		QuietStart:         request.QuietStart,
+		QuietEnd:           request.QuietEnd,
+		Timezone:           request.Timezone,
+		GroupWindowSeconds: request.GroupWindowSeconds,
+		NotifyResolved:     true,
+		IsActive:           true,
+	}
+	if rule.MinSeverity == "" {
+		rule.MinSeverity = entity.AlertSeverityInfo
+	}
+	if request.NotifyResolved != nil {
+		rule.NotifyResolved = *request.NotifyResolved
+	}
+	if request.IsActive != nil {
+		rule.IsActive = *request.IsActive
+	}
+
+	if err := c.validateRule(c.DB.WithContext(ctx), rule); err != nil {
+		return nil, err
+	}
+
+	if err := c.NotificationRuleRepository.Create(c.DB.WithContext(ctx), rule); err != nil {
+		c.Log.Warnf("Failed create notification rule to database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	return converter.NotificationRuleToResponse(rule), nil
+}
+
+func (c *NotificationUseCase) FindAllRules(ctx context.Context, pagination *utils.PaginationRequest) ([]model.NotificationRuleResponse, *utils.PaginationResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var rules []entity.NotificationRule
+	total, err := c.NotificationRuleRepository.FindAll(c.DB.WithContext(ctx), &rules, pagination)
+	if err != nil {
+		c.Log.Warnf("Failed find all notification rule from database : %+v", err)
+		return nil, nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	responses := make([]model.NotificationRuleResponse, len(rules))
+	for i, rule := range rules {
+		responses[i] = *converter.NotificationRuleToResponse(&rule)
+	}
+
+	totalPage := int((total + int64(pagination.Limit) - 1) / int64(pagination.Limit))
+
+	paginationRes := &utils.PaginationResponse{
+		Page:      pagination.Page,
+		Limit:     pagination.Limit,
+		OrderBy:   pagination.OrderBy,
+		SortBy:    pagination.SortBy,
+		Search:    pagination.Search,
+		TotalData: total,
+		TotalPage: totalPage,
+	}
+
+	return responses, paginationRes, nil
+}
+
+func (c *NotificationUseCase) FindRuleByID(ctx context.Context, ruleID string) (*model.NotificationRuleResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rule, err := c.findRule(c.DB.WithContext(ctx), ruleID)
+	if err != nil {
+		return nil, err
+	}
+	return converter.NotificationRuleToResponse(rule), nil
+}
+
+func (c *NotificationUseCase) UpdateRule(ctx context.Context, ruleID string, request *model.UpdateNotificationRuleRequest) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rule, err := c.findRule(c.DB.WithContext(ctx), ruleID)
+	if err != nil {
+		return err
+	}
+
+	if err := c.validate(request); err != nil {
+		return err
+	}
+
+	if request.Name != nil {
+		rule.Name = *request.Name
+	}
+	if request.MinSeverity != nil {
+		rule.MinSeverity = *request.MinSeverity
+	}
+	if request.Selector != nil {
+		rule.Selector = *request.Selector
+	}
+	if request.ChannelIDs != nil {
+		rule.ChannelIDs = uniqueStrings(request.ChannelIDs)
+	}
+	if request.SubjectTemplate != nil {
+		rule.SubjectTemplate = *request.SubjectTemplate
+	}
+	if request.BodyTemplate != nil {
+		rule.BodyTemplate = *request.BodyTemplate
+	}
+	if request.QuietStart != nil {
+		rule.QuietStart = *request.QuietStart
+	}
+	if request.QuietEnd != nil {
+		rule.QuietEnd = *request.QuietEnd
+	}
+	if request.Timezone != nil {
+		rule.Timezone = *request.Timezone
+	}
+	if request.GroupWindowSeconds != nil {
+		rule.GroupWindowSeconds = *request.GroupWindowSeconds
+	}
+	if request.NotifyResolved != nil {
+		rule.NotifyResolved = *request.NotifyResolved
+	}
+	if request.IsActive != nil {
+		rule.IsActive = *request.IsActive
+	}
+
+	if err := c.validateRule(c.DB.WithContext(ctx), rule); err != nil {
+		return err
+	}
+
+	if err := c.NotificationRuleRepository.Update(c.DB.WithContext(ctx), rule); err != nil {
+		c.Log.Warnf("Failed update notification rule to database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	return nil
+}
+
+func (c *NotificationUseCase) DeleteRule(ctx context.Context, ruleID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rule, err := c.findRule(c.DB.WithContext(ctx), ruleID)
+	if err != nil {
+		return err
+	}
+
+	if err := c.NotificationRuleRepository.Delete(c.DB.WithContext(ctx), rule); err != nil {
+		c.Log.Warnf("Failed delete notification rule from database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	return nil
+}
+
+// FindAll lists the delivery log, newest first unless asked otherwise.
+func (c *NotificationUseCase) FindAll(ctx context.Context, pagination *utils.PaginationRequest, filter *model.NotificationFilter) ([]model.NotificationResponse, *utils.PaginationResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	switch filter.Status {
+	case "", entity.NotificationPending, entity.NotificationSent, entity.NotificationFailed,
+		entity.NotificationSuppressed, entity.NotificationQuiet:
+	default:
+		return nil, nil, fmt.Errorf("%w: status must be one of pending, sent, failed, suppressed, quiet", utils.ErrValidation)
+	}
+	for _, id := range []string{filter.ChannelID, filter.RuleID} {
+		if _, err := uuid.Parse(id); id != "" && err != nil {
+			return nil, nil, fmt.Errorf("%w: invalid id %q", utils.ErrValidation, id)
+		}
+	}
+
+	var notifications []entity.Notification
+	total, err := c.NotificationRepository.FindAll(c.DB.WithContext(ctx), &notifications, pagination,
+		repository.ByNotificationFilter(filter.Status, filter.ChannelID, filter.RuleID, filter.AlertID))
+	if err != nil {
+		c.Log.Warnf("Failed find all notification from database : %+v", err)
+		return nil, nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	responses := make([]model.NotificationResponse, len(notifications))
+	for i, notification := range notifications {
+		responses[i] = *converter.NotificationToResponse(&notification)
+	}
+
+	totalPage := int((total + int64(pagination.Limit) - 1) / int64(pagination.Limit))
+
+	paginationRes := &utils.PaginationResponse{
+		Page:      pagination.Page,
+		Limit:     pagination.Limit,
+		OrderBy:   pagination.OrderBy,
+		SortBy:    pagination.SortBy,
+		Search:    pagination.Search,
+		TotalData: total,
+		TotalPage: totalPage,
+	}
+
+	return responses, paginationRes, nil
+}
+
+// HandleEvent routes an alert relayed from the outbox through the active
+// rules and stores the resulting notifications. A relayed event may arrive
+// again after a failure, so one already routed is skipped; an error makes
+// the outbox retry it.
+func (c *NotificationUseCase) HandleEvent(ctx context.Context, envelope event.Envelope) error {
+	exists, err := c.NotificationRepository.ExistsByEventID(c.DB.WithContext(ctx), envelope.ID)
+	if err != nil || exists {
+		return err
+	}
+
+	if envelope.Type == event.TypeAlertEscalated {
+		return c.escalate(ctx, envelope)
+	}
+
+	alert := &event.Alert{}
+	if err := envelope.Decode(alert); err != nil {
+		c.Log.Warnf("Failed decode alert event %s : %+v", envelope.ID, err)
+		return nil
+	}
+
+	rules, err := c.NotificationRuleRepository.FindActive(c.DB.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	var notifications []entity.Notification
+	for _, rule := range rules {
+		routed, err := c.route(ctx, &rule, envelope, &alert.AlertEvent)
+		if err != nil {
+			return err
+		}
+		notifications = append(notifications, routed...)
+	}
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	if err := c.NotificationRepository.CreateAll(c.DB.WithContext(ctx), notifications); err != nil {
+		return err
+	}
+	c.notify()
+	return nil
+}
+
+// escalate sends an escalated alert to the channels of the escalation
+// step with the default templates. Quiet hours and grouping do not apply:
+// nobody acknowledged the alert and it has to reach someone.
+func (c *NotificationUseCase) escalate(ctx context.Context, envelope event.Envelope) error {
+	escalation := &event.AlertEscalated{}
+	if err := envelope.Decode(escalation); err != nil {
+		c.Log.Warnf("Failed decode alert escalation event %s : %+v", envelope.ID, err)
+		return nil
+	}
+	policyID, err := uuid.Parse(escalation.PolicyID)
+	if err != nil {
+		c.Log.Warnf("Skipping alert escalation event %s without policy : %+v", envelope.ID, err)
+		return nil
+	}
+
+	channels, err := c.NotificationChannelRepository.FindByIds(c.DB.WithContext(ctx), escalation.ChannelIDs)
+	if err != nil {
+		return err
+	}
+
+	template, err := notify.ParseTemplate("", "")
+	if err != nil {
+		return err
+	}
+	message, err := template.Render(&notify.TemplateData{
+		AlertEvent: escalation.AlertEvent,
+		Rule:       escalation.PolicyName,
+		Escalation: escalation.Level,
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var notifications []entity.Notification
+	for _, channel := range channels {
+		if !channel.IsActive {
+			continue
+		}
+		notifications = append(notifications, entity.Notification{
+			EventID:            envelope.ID,
+			EscalationPolicyID: &policyID,
+			EscalationLevel:    escalation.Level,
+			ChannelID:          channel.ID,
+			Fingerprint:        truncate(escalation.Fingerprint, 200),
+			AlertID:            escalation.ID,
+			AlertStatus:        escalation.Status,
+			Severity:           escalation.Severity,
+			Subject:            truncate(message.Subject, 500),
+			Body:               message.Body,
+			Payload:            string(envelope.Payload),
+			Status:             entity.NotificationPending,
+			NextAttemptAt:      &now,
+		})
+	}
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	if err := c.NotificationRepository.CreateAll(c.DB.WithContext(ctx), notifications); err != nil {
+		return err
+	}
+	c.notify()
+	return nil
+}
+
+// route returns the notifications of an alert for one rule: none when the
+// rule does not match, otherwise one per active channel, pending unless
+// grouping holds it back or quiet hours defer it to their end. Grouping
+// applies first, and an alert is deferred once per channel however often
+// it repeats during quiet hours.
+func (c *NotificationUseCase) route(ctx context.Context, rule *entity.NotificationRule, envelope event.Envelope, alert *model.AlertEvent) ([]entity.Notification, error) {
+	if entity.AlertSeverityRank(alert.Severity) < entity.AlertSeverityRank(rule.MinSeverity) {
+		return nil, nil
+	}
+	resolved := alert.Status == entity.AlertStatusResolved
+	if resolved && !rule.NotifyResolved {
+		return nil, nil
+	}
+	selector, err := utils.ParseLabelSelector(rule.Selector)
+	if err != nil {
+		c.Log.Warnf("Skipping notification rule %s with invalid selector : %+v", rule.ID, err)
+		return nil, nil
+	}
+	if !selector.Matches(alert.Labels) {
+		return nil, nil
+	}
+
+	template, err := notify.ParseTemplate(rule.SubjectTemplate, rule.BodyTemplate)
+	if err != nil {
+		c.Log.Warnf("Skipping notification rule %s with invalid template : %+v", rule.ID, err)
+		return nil, nil
+	}
+
+	channels, err := c.NotificationChannelRepository.FindByIds(c.DB.WithContext(ctx), rule.ChannelIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	ruleID := rule.ID
+	now := time.Now()
+	quietEnd, quiet := quietHoursEnd(rule, now)
+	quiet = quiet && alert.Severity != entity.AlertSeverityCritical
+	window := time.Duration(rule.GroupWindowSeconds) * time.Second
+
+	var notifications []entity.Notification
+	for _, channel := range channels {
+		if !channel.IsActive {
+			continue
+		}
+
+		notification := entity.Notification{
+			EventID:       envelope.ID,
+			RuleID:        &ruleID,
+			ChannelID:     channel.ID,
+			Fingerprint:   truncate(alert.Fingerprint, 200),
+			AlertID:       alert.ID,
+			AlertStatus:   alert.Status,
+			Severity:      alert.Severity,
+			Payload:       string(envelope.Payload),
+			Status:        entity.NotificationPending,
+			NextAttemptAt: &now,
+		}
+
+		data := &notify.TemplateData{AlertEvent: *alert, Rule: rule.Name}
+		if !resolved {
+			last, err := c.NotificationRepository.FindLastNotified(c.DB.WithContext(ctx), rule.ID, channel.ID, notification.Fingerprint)
+			if err != nil {
+				return nil, err
+			}
+			if last != nil && now.Sub(last.CreatedAt) < window {
+				notification.Status = entity.NotificationSuppressed
+				notification.NextAttemptAt = nil
+			} else if last != nil {
+				repeats, err := c.NotificationRepository.CountSuppressedSince(c.DB.WithContext(ctx), rule.ID, channel.ID, notification.Fingerprint, last.CreatedAt)
+				if err != nil {
+					return nil, err
+				}
+				data.Repeats = int(repeats)
+			}
+		}
+		if quiet && notification.Status == entity.NotificationPending {
+			// repeats of an alert already deferred are suppressed, and counted
+			// by the next notification about it like those held back by grouping
+			deferred := false
+			if !resolved && alert.ID != "" {
+				deferred, err = c.NotificationRepository.ExistsDeferredForAlert(c.DB.WithContext(ctx), rule.ID, channel.ID, alert.ID)
+				if err != nil {
+					return nil, err
+				}
+			}
+			if deferred {
+				notification.Status = entity.NotificationSuppressed
+				notification.NextAttemptAt = nil
+			} else {
+				notification.Status = entity.NotificationQuiet
+				notification.NextAttemptAt = &quietEnd
+			}
+		}
+
+		message, err := template.Render(data)
+		if err != nil {
+			notification.Status = entity.NotificationFailed
+			notification.NextAttemptAt = nil
+			notification.LastError = truncate(err.Error(), notificationLogLimit)
+		} else {
+			notification.Subject = truncate(message.Subject, 500)
+			notification.Body = message.Body
+		}
+		notifications = append(notifications, notification)
+	}
+	return notifications, nil
+}
+
+// Start launches the delivery workers. They stop when ctx is done.
+func (c *NotificationUseCase) Start(ctx context.Context) error {
+	worker := &deliveryWorker[entity.Notification]{
+		Log:          c.Log,
+		Name:         "notification",
+		PollInterval: notificationPollInterval,
+		Wake:         c.wake,
+		Lease:        2 * c.Client.Timeout,
+		Claim: func(ctx context.Context, now time.Time, leaseUntil time.Time) (*entity.Notification, error) {
+			return c.NotificationRepository.ClaimDue(c.DB.WithContext(ctx), now, leaseUntil)
+		},
+		Attempt: c.attempt,
+	}
+	for i := 0; i < c.Workers; i++ {
+		go worker.run(ctx)
+	}
+	return nil
+}
+
+// attempt sends a claimed notification and records the outcome: sent,
+// scheduled for a retry, or failed for good once out of attempts.
+func (c *NotificationUseCase) attempt(ctx context.Context, notification *entity.Notification) {
+	if notification.Status == entity.NotificationQuiet {
+		pointless, err := c.deferredPointless(ctx, notification)
+		if err != nil {
+			// retried once the lease ends
+			c.Log.Warnf("Failed check deferred notification : %+v", err)
+			return
+		}
+		if pointless {
+			notification.NextAttemptAt = nil
+			if err := c.NotificationRepository.Update(c.DB.WithContext(context.Background()), notification); err != nil {
+				c.Log.Warnf("Failed update notification to database : %+v", err)
+			}
+			return
+		}
+		notification.Status = entity.NotificationPending
+	}
+
+	channel := &entity.NotificationChannel{}
+	if _, err := c.NotificationChannelRepository.FindById(c.DB.WithContext(ctx), channel, notification.ChannelID); err != nil {
+		// deleting a channel deletes its notifications, so anything else is
+		// retried once the lease ends
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Warnf("Failed find notification channel from database : %+v", err)
+		}
+		return
+	}
+
+	var sender notify.Channel
+	var err error
+	if channel.IsActive {
+		sender, err = notify.New(channel.Type, channel.Config, c.Client)
+	} else {
+		err = errors.New("channel is disabled")
+	}
+	if err == nil {
+		message := &notify.Message{Subject: notification.Subject, Body: notification.Body}
+		if json.Valid([]byte(notification.Payload)) {
+			message.Data = json.RawMessage(notification.Payload)
+		}
+		err = sender.Send(ctx, message)
+	} else {
+		notification.Attempts = c.MaxAttempts
+	}
+	finished := time.Now()
+
+	notification.LastError = ""
+	switch {
+	case err == nil:
+		notification.Status = entity.NotificationSent
+		notification.SentAt = &finished
+		notification.NextAttemptAt = nil
+	default:
+		notification.LastError = truncate(err.Error(), notificationLogLimit)
+		notification.NextAttemptAt = retryAt(finished, notification.Attempts, c.MaxAttempts, notificationBackoffBase, notificationBackoffMax)
+		if notification.NextAttemptAt == nil {
+			notification.Status = entity.NotificationFailed
+		}
+	}
+
+	// the outcome is recorded even when ctx was cancelled meanwhile
+	if err := c.NotificationRepository.Update(c.DB.WithContext(context.Background()), notification); err != nil {
+		c.Log.Warnf("Failed update notification to database : %+v", err)
+	}
+}
+
+// deferredPointless reports whether a notification held by quiet hours has
+// nothing left to say once they end: the alert it announces was resolved
+// meanwhile, or the resolution it announces follows an alert the channel
+// never heard of.
+func (c *NotificationUseCase) deferredPointless(ctx context.Context, notification *entity.Notification) (bool, error) {
+	if notification.AlertID == "" || notification.RuleID == nil {
+		return false, nil
+	}
+	if notification.AlertStatus == entity.AlertStatusResolved {
+		sent, err := c.NotificationRepository.ExistsSentForAlert(c.DB.WithContext(ctx), notification.RuleID,
+			notification.ChannelID, notification.AlertID)
+		return !sent, err
+	}
+	firing, err := c.NotificationRepository.IsAlertFiring(c.DB.WithContext(ctx), notification.AlertID)
+	return !firing, err
+}
+
+func (c *NotificationUseCase) notify() {
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (c *NotificationUseCase) validate(request any) error {
+	err := c.Validator.Validate.Struct(request)
+	if err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+
+			var messages []string
+			for _, e := range validationErrors {
+				messages = append(messages, e.Translate(c.Validator.Translator))
+			}
+			return fmt.Errorf("%w: %s", utils.ErrValidation, strings.Join(messages, ", "))
+		}
+		return fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+	return nil
+}
+
+// validateRule checks what the request tags cannot: the selector, the
+// templates against a sample alert, quiet hours, the timezone and that
+// every channel exists.
+func (c *NotificationUseCase) validateRule(db *gorm.DB, rule *entity.NotificationRule) error {
+	if _, err := utils.ParseLabelSelector(rule.Selector); err != nil {
+		return fmt.Errorf("%w: selector: %s", utils.ErrValidation, err.Error())
+	}
+
+	template, err := notify.ParseTemplate(rule.SubjectTemplate, rule.BodyTemplate)
+	if err != nil {
+		return fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+	if _, err := template.Render(&notify.TemplateData{AlertEvent: sampleAlert(), Rule: rule.Name, Repeats: 1}); err != nil {
+		return fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
+	if (rule.QuietStart == "") != (rule.QuietEnd == "") {
+		return fmt.Errorf("%w: quiet_start and quiet_end go together", utils.ErrValidation)
+	}
+	if _, err := time.LoadLocation(rule.Timezone); err != nil {
+		return fmt.Errorf("%w: unknown timezone %q", utils.ErrValidation, rule.Timezone)
+	}
+
+	channels, err := c.NotificationChannelRepository.FindByIds(db, rule.ChannelIDs)
+	if err != nil {
+		c.Log.Warnf("Failed find notification channels from database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	if len(channels) != len(rule.ChannelIDs) {
+		return fmt.Errorf("%w: notification channel not found", utils.ErrValidation)
+	}
+	return nil
+}
+
+func (c *NotificationUseCase) findChannel(db *gorm.DB, channelID string) (*entity.NotificationChannel, error) {
+	channel := &entity.NotificationChannel{}
+	_, err := c.NotificationChannelRepository.FindById(db, channel, channelID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Infof("Notification channel not found, id=%s", channelID)
+			return nil, utils.ErrNotFound
+		}
+		c.Log.Warnf("Failed find notification channel from database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	return channel, nil
+}
+
+func (c *NotificationUseCase) findRule(db *gorm.DB, ruleID string) (*entity.NotificationRule, error) {
+	rule := &entity.NotificationRule{}
+	_, err := c.NotificationRuleRepository.FindById(db, rule, ruleID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Infof("Notification rule not found, id=%s", ruleID)
+			return nil, utils.ErrNotFound
+		}
+		c.Log.Warnf("Failed find notification rule from database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	return rule, nil
+}
+
+// quietHoursEnd reports whether now falls between the rule's quiet start
+// and end in its timezone, and if so when they end. A window whose end is
+// before its start spans midnight.
+func quietHoursEnd(rule *entity.NotificationRule, now time.Time) (time.Time, bool) {
+	if rule.QuietStart == "" || rule.QuietEnd == "" {
+		return time.Time{}, false
+	}
+	start, err := time.Parse("15:04", rule.QuietStart)
+	if err != nil {
+		return time.Time{}, false
+	}
+	end, err := time.Parse("15:04", rule.QuietEnd)
+	if err != nil {
+		return time.Time{}, false
+	}
+	location, err := time.LoadLocation(rule.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	local := now.In(location)
+	minute := local.Hour()*60 + local.Minute()
+	from := start.Hour()*60 + start.Minute()
+	to := end.Hour()*60 + end.Minute()
+	var quiet bool
+	switch {
+	case from < to:
+		quiet = minute >= from && minute < to
+	case from > to:
+		quiet = minute >= from || minute < to
+	}
+	if !quiet {
+		return time.Time{}, false
+	}
+
+	// the first end after now, today or tomorrow
+	ends := time.Date(local.Year(), local.Month(), local.Day(), end.Hour(), end.Minute(), 0, 0, location)
+	if !ends.After(local) {
+		ends = time.Date(local.Year(), local.Month(), local.Day()+1, end.Hour(), end.Minute(), 0, 0, location)
+	}
+	return ends, true
+}
+
+// keepMaskedSecrets returns config with secrets sent back masked, as they
+// appear in responses, replaced by their stored values.
+func keepMaskedSecrets(stored entity.JSONB, config map[string]any) entity.JSONB {
+	merged := make(entity.JSONB, len(config))
+	for key, value := range config {
+		if value == converter.MaskedSecret {
+			value = stored[key]
+		}
+		merged[key] = value
+	}
+
+	headers, ok := config["headers"].(map[string]any)
+	storedHeaders, _ := stored["headers"].(map[string]any)
+	if ok {
+		restored := make(map[string]any, len(headers))
+		for key, value := range headers {
+			if value == converter.MaskedSecret {
+				value = storedHeaders[key]
+			}
+			restored[key] = value
+		}
+		merged["headers"] = restored
+	}
+	return merged
+}
+
+// sampleAlert is the alert channel tests send and templates are checked
+// against.
+func sampleAlert() model.AlertEvent {
+	value := 42.5
+	return model.AlertEvent{
+		ID:          uuid.Nil.String(),
+		Fingerprint: "test",
+		Status:      entity.AlertStatusFiring,
+		Severity:    entity.AlertSeverityWarning,
+		Title:       "Test notification",
+		Message:     "This is a test of the notification channel.",
+		DeviceName:  "test-device",
+		SensorName:  "test-sensor",
+		SensorType:  "temperature",
+		Value:       &value,
+		Unit:        "celsius",
+		Labels:      map[string]string{},
+		StartsAt:    time.Now().UTC().Format(time.RFC3339),
+	}
+}
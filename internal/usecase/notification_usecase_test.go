@@ -0,0 +1,44 @@
+package usecase
+
+import (
+	"mertani_test/internal/entity"
+	"testing"
+	"time"
+)
+
+func TestQuietHoursEnd(t *testing.T) {
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Skip("no tzdata:", err)
+	}
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, time.March, day, hour, minute, 0, 0, jakarta)
+	}
+
+	overnight := &entity.NotificationRule{QuietStart: "22:00", QuietEnd: "07:00", Timezone: "Asia/Jakarta"}
+	daytime := &entity.NotificationRule{QuietStart: "12:00", QuietEnd: "13:30", Timezone: "Asia/Jakarta"}
+
+	for _, test := range []struct {
+		name  string
+		rule  *entity.NotificationRule
+		now   time.Time
+		quiet bool
+		end   time.Time
+	}{
+		{"before the night", overnight, at(10, 21, 59), false, time.Time{}},
+		{"evening", overnight, at(10, 22, 0), true, at(11, 7, 0)},
+		{"after midnight", overnight, at(11, 3, 15), true, at(11, 7, 0)},
+		{"at the end", overnight, at(11, 7, 0), false, time.Time{}},
+		{"lunch", daytime, at(10, 12, 45), true, at(10, 13, 30)},
+		{"after lunch", daytime, at(10, 13, 30), false, time.Time{}},
+		// compared in the rule's timezone, whatever the zone of now
+		{"evening in UTC", overnight, at(10, 23, 0).UTC(), true, at(11, 7, 0)},
+		{"no window", &entity.NotificationRule{}, at(10, 23, 0), false, time.Time{}},
+		{"empty window", &entity.NotificationRule{QuietStart: "08:00", QuietEnd: "08:00"}, at(10, 8, 0), false, time.Time{}},
+	} {
+		end, quiet := quietHoursEnd(test.rule, test.now)
+		if quiet != test.quiet || !end.Equal(test.end) {
+			t.Errorf("%s: got %v %v, want %v %v", test.name, quiet, end, test.quiet, test.end)
+		}
+	}
+}
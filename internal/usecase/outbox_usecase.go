@@ -0,0 +1,216 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mertani_test/internal/entity"
+	"mertani_test/internal/event"
+	"mertani_test/internal/repository"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	// outboxPollInterval is how often the relay looks for events written by
+	// other instances or due for a retry; local commits wake it right away.
+	outboxPollInterval = 2 * time.Second
+	// outboxBatchSize bounds the events relayed per transaction.
+	outboxBatchSize = 100
+	// outboxBackoffBase doubles after every failed publish, up to
+	// outboxBackoffMax.
+	outboxBackoffBase = 5 * time.Second
+	outboxBackoffMax  = 10 * time.Minute
+	// outboxMaxAttempts is how often an event is published before it is
+	// given up on, about an hour after the first attempt.
+	outboxMaxAttempts = 12
+	// outboxCleanupInterval is how often published events past Retention
+	// are deleted.
+	outboxCleanupInterval = time.Hour
+	// outboxErrorLimit bounds the publish error kept per event.
+	outboxErrorLimit = 500
+)
+
+// OutboxUseCase records domain events in the transaction of the change they
+// describe and relays them to Publisher once committed. Delivery is at
+// least once: an event stays in the outbox until Publisher accepts it, and
+// events are relayed in the order they were recorded, a failed one holding
+// back those after it until its retry succeeds or it runs out of attempts.
+// A retry skips the subscribers that handled the event already.
+type OutboxUseCase struct {
+	DB               *gorm.DB
+	Log              *logrus.Logger
+	OutboxRepository *repository.OutboxRepository
+	Publisher        event.Publisher
+	Retention        time.Duration
+	wake             chan struct{}
+}
+
+func NewOutboxUseCase(db *gorm.DB, logger *logrus.Logger, outboxRepository *repository.OutboxRepository,
+	publisher event.Publisher, retention time.Duration) *OutboxUseCase {
+	if retention <= 0 {
+		retention = 7 * 24 * time.Hour
+	}
+	return &OutboxUseCase{
+		DB:               db,
+		Log:              logger,
+		OutboxRepository: outboxRepository,
+		Publisher:        publisher,
+		Retention:        retention,
+		wake:             make(chan struct{}, 1),
+	}
+}
+
+// Record writes events to the outbox with tx, so they are published if and
+// only if tx commits. Callers wake the relay with Notify after the commit.
+func (c *OutboxUseCase) Record(tx *gorm.DB, events ...event.Event) error {
+	now := time.Now()
+	rows := make([]entity.OutboxEvent, 0, len(events))
+	for _, e := range events {
+		envelope, err := event.NewEnvelope(e, now)
+		if err != nil {
+			return fmt.Errorf("encode %s event: %w", e.EventType(), err)
+		}
+		rows = append(rows, entity.OutboxEvent{
+			EventID:    envelope.ID,
+			Type:       envelope.Type,
+			DeviceID:   envelope.DeviceID,
+			SensorID:   envelope.SensorID,
+			Labels:     envelope.Labels,
+			Payload:    string(envelope.Payload),
+			OccurredAt: envelope.OccurredAt,
+		})
+	}
+	return c.OutboxRepository.Append(tx, rows)
+}
+
+// Notify wakes the relay to publish events that were just committed.
+func (c *OutboxUseCase) Notify() {
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start launches the relay and the cleanup of published events. They stop
+// when ctx is done.
+func (c *OutboxUseCase) Start(ctx context.Context) error {
+	go c.relay(ctx)
+	go c.cleanup(ctx)
+	return nil
+}
+
+func (c *OutboxUseCase) relay(ctx context.Context) {
+	ticker := time.NewTicker(outboxPollInterval)
+	defer ticker.Stop()
+
+	for {
+		relayed, err := c.relayBatch(ctx)
+		if err != nil && ctx.Err() == nil {
+			c.Log.Warnf("Failed relay outbox events : %+v", err)
+		}
+		if relayed == outboxBatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// relayBatch publishes events under the relay lock and returns how many
+// were published. It stops at the first failure, and at the first event
+// waiting for its retry, so that events are not published out of order;
+// an event out of attempts is marked dead and the batch goes on.
+func (c *OutboxUseCase) relayBatch(ctx context.Context) (int, error) {
+	relayed := 0
+	err := c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		locked, err := c.OutboxRepository.TryLock(tx)
+		if err != nil || !locked {
+			return err
+		}
+
+		events, err := c.OutboxRepository.FindUnpublished(tx, outboxBatchSize)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, row := range events {
+			if row.NextAttemptAt != nil && row.NextAttemptAt.After(now) {
+				return nil
+			}
+			err := c.Publisher.Publish(event.WithDelivered(ctx, row.Delivered), outboxEnvelope(&row))
+			if err != nil {
+				message := err.Error()
+				if len(message) > outboxErrorLimit {
+					message = message[:outboxErrorLimit]
+				}
+				delivered := row.Delivered
+				var deliveryError *event.DeliveryError
+				if errors.As(err, &deliveryError) {
+					delivered = deliveryError.Delivered
+				}
+				if row.Attempts+1 >= outboxMaxAttempts {
+					c.Log.Errorf("Failed publish %s event %s after %d attempts, giving up : %+v", row.Type, row.EventID, row.Attempts+1, err)
+					if err := c.OutboxRepository.MarkDead(tx, row.ID, time.Now(), delivered, message); err != nil {
+						return err
+					}
+					continue
+				}
+				next := time.Now().Add(backoff(row.Attempts+1, outboxBackoffBase, outboxBackoffMax))
+				c.Log.Warnf("Failed publish %s event %s, retrying at %s : %+v", row.Type, row.EventID, next.Format(time.RFC3339), err)
+				return c.OutboxRepository.MarkFailed(tx, row.ID, next, delivered, message)
+			}
+			if err := c.OutboxRepository.MarkPublished(tx, row.ID, time.Now()); err != nil {
+				return err
+			}
+			relayed++
+		}
+		return nil
+	})
+	return relayed, err
+}
+
+// cleanup deletes events published or given up on longer than Retention
+// ago.
+func (c *OutboxUseCase) cleanup(ctx context.Context) {
+	ticker := time.NewTicker(outboxCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		deleted, err := c.OutboxRepository.DeletePublishedBefore(c.DB.WithContext(ctx), time.Now().Add(-c.Retention))
+		if err != nil {
+			c.Log.Warnf("Failed delete published outbox events : %+v", err)
+			continue
+		}
+		if deleted > 0 {
+			c.Log.Infof("Deleted %d published outbox events", deleted)
+		}
+	}
+}
+
+func outboxEnvelope(row *entity.OutboxEvent) event.Envelope {
+	return event.Envelope{
+		ID:         row.EventID,
+		Type:       row.Type,
+		DeviceID:   row.DeviceID,
+		SensorID:   row.SensorID,
+		Labels:     row.Labels,
+		OccurredAt: row.OccurredAt,
+		Payload:    json.RawMessage(row.Payload),
+	}
+}
@@ -0,0 +1,274 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mertani_test/internal/decoder"
+	"mertani_test/internal/entity"
+	"mertani_test/internal/model"
+	"mertani_test/internal/model/converter"
+	"mertani_test/internal/repository"
+	"mertani_test/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type PayloadDecoderUseCase struct {
+	DB                       *gorm.DB
+	Log                      *logrus.Logger
+	Validator                *utils.Validator
+	PayloadDecoderRepository *repository.PayloadDecoderRepository
+}
+
+func NewPayloadDecoderUseCase(db *gorm.DB, logger *logrus.Logger, validator *utils.Validator,
+	payloadDecoderRepository *repository.PayloadDecoderRepository) *PayloadDecoderUseCase {
+	return &PayloadDecoderUseCase{
+		DB:                       db,
+		Log:                      logger,
+		Validator:                validator,
+		PayloadDecoderRepository: payloadDecoderRepository,
+	}
+}
+
+func (c *PayloadDecoderUseCase) Create(ctx context.Context, request *model.CreatePayloadDecoderRequest) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	err := c.Validator.Validate.Struct(request)
+	if err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+
+			var messages []string
+			for _, e := range validationErrors {
+				messages = append(messages, e.Translate(c.Validator.Translator))
+			}
+			return fmt.Errorf("%w: %s", utils.ErrValidation, strings.Join(messages, ", "))
+		}
+		return fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
+	if _, err := resolvePayloadDecoder(request.Decoder, request.Layout); err != nil {
+		return err
+	}
+
+	exists, err := c.PayloadDecoderRepository.ExistsByHardwareModel(c.DB.WithContext(ctx), request.HardwareModel)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("%w: %s", utils.ErrConflict, "payload decoder for hardware model already exist")
+	}
+
+	payloadDecoder := &entity.PayloadDecoder{
+		HardwareModel: request.HardwareModel,
+		Decoder:       request.Decoder,
+	}
+	if request.Decoder == entity.PayloadDecoderLayout {
+		payloadDecoder.Layout = request.Layout
+	}
+
+	if err := c.PayloadDecoderRepository.Create(c.DB.WithContext(ctx), payloadDecoder); err != nil {
+		c.Log.Warnf("Failed create payload decoder to database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	return nil
+}
+
+func (c *PayloadDecoderUseCase) FindAll(ctx context.Context, pagination *utils.PaginationRequest) ([]model.PayloadDecoderResponse, *utils.PaginationResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var payloadDecoders []entity.PayloadDecoder
+	total, err := c.PayloadDecoderRepository.FindAll(c.DB.WithContext(ctx), &payloadDecoders, pagination)
+	if err != nil {
+		c.Log.Warnf("Failed find all payload decoder from database : %+v", err)
+		return nil, nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	responses := make([]model.PayloadDecoderResponse, len(payloadDecoders))
+	for i, payloadDecoder := range payloadDecoders {
+		responses[i] = *converter.PayloadDecoderToResponse(&payloadDecoder)
+	}
+
+	totalPage := int((total + int64(pagination.Limit) - 1) / int64(pagination.Limit))
+
+	paginationRes := &utils.PaginationResponse{
+		Page:      pagination.Page,
+		Limit:     pagination.Limit,
+		OrderBy:   pagination.OrderBy,
+		SortBy:    pagination.SortBy,
+		Search:    pagination.Search,
+		TotalData: total,
+		TotalPage: totalPage,
+	}
+
+	return responses, paginationRes, nil
+}
+
+func (c *PayloadDecoderUseCase) FindByID(ctx context.Context, payloadDecoderID string) (*model.PayloadDecoderResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	payloadDecoder := &entity.PayloadDecoder{}
+	_, err := c.PayloadDecoderRepository.FindById(c.DB.WithContext(ctx), payloadDecoder, payloadDecoderID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Infof("Payload decoder not found, id=%s", payloadDecoderID)
+			return nil, utils.ErrNotFound
+		}
+		c.Log.Warnf("Failed find payload decoder from database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	return converter.PayloadDecoderToResponse(payloadDecoder), nil
+}
+
+func (c *PayloadDecoderUseCase) Update(ctx context.Context, payloadDecoderID string, request *model.UpdatePayloadDecoderRequest) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	payloadDecoder := &entity.PayloadDecoder{}
+	_, err := c.PayloadDecoderRepository.FindById(c.DB.WithContext(ctx), payloadDecoder, payloadDecoderID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Infof("Payload decoder not found, id=%s", payloadDecoderID)
+			return utils.ErrNotFound
+		}
+		c.Log.Warnf("Failed find payload decoder from database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	err = c.Validator.Validate.Struct(request)
+	if err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+
+			var messages []string
+			for _, e := range validationErrors {
+				messages = append(messages, e.Translate(c.Validator.Translator))
+			}
+			return fmt.Errorf("%w: %s", utils.ErrValidation, strings.Join(messages, ", "))
+		}
+		return fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
+	if _, err := resolvePayloadDecoder(request.Decoder, request.Layout); err != nil {
+		return err
+	}
+
+	payloadDecoder.Decoder = request.Decoder
+	payloadDecoder.Layout = nil
+	if request.Decoder == entity.PayloadDecoderLayout {
+		payloadDecoder.Layout = request.Layout
+	}
+
+	if err := c.PayloadDecoderRepository.Update(c.DB.WithContext(ctx), payloadDecoder); err != nil {
+		c.Log.Warnf("Failed update payload decoder from database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	return nil
+}
+
+func (c *PayloadDecoderUseCase) Delete(ctx context.Context, payloadDecoderID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	payloadDecoder := &entity.PayloadDecoder{}
+	_, err := c.PayloadDecoderRepository.FindById(c.DB.WithContext(ctx), payloadDecoder, payloadDecoderID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Infof("Payload decoder not found, id=%s", payloadDecoderID)
+			return utils.ErrNotFound
+		}
+		c.Log.Warnf("Failed find payload decoder from database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	if err := c.PayloadDecoderRepository.Delete(c.DB.WithContext(ctx), payloadDecoder); err != nil {
+		c.Log.Warnf("Failed delete payload decoder from database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	return nil
+}
+
+// Test decodes a sample payload without storing anything.
+func (c *PayloadDecoderUseCase) Test(ctx context.Context, request *model.TestPayloadDecoderRequest) (*model.DecodedPayloadResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	err := c.Validator.Validate.Struct(request)
+	if err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+
+			var messages []string
+			for _, e := range validationErrors {
+				messages = append(messages, e.Translate(c.Validator.Translator))
+			}
+			return nil, fmt.Errorf("%w: %s", utils.ErrValidation, strings.Join(messages, ", "))
+		}
+		return nil, fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
+	name, layout := request.Decoder, request.Layout
+	if name == "" {
+		payloadDecoder, err := c.PayloadDecoderRepository.FindByHardwareModel(c.DB.WithContext(ctx), &entity.PayloadDecoder{}, request.HardwareModel)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("%w: no payload decoder for hardware model %q", utils.ErrNotFound, request.HardwareModel)
+			}
+			c.Log.Warnf("Failed find payload decoder from database : %+v", err)
+			return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+		}
+		name, layout = payloadDecoder.Decoder, payloadDecoder.Layout
+	}
+
+	d, err := resolvePayloadDecoder(name, layout)
+	if err != nil {
+		return nil, err
+	}
+
+	measurements, err := decodePayload(d, request.Payload, request.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.DecodedPayloadResponse{Decoder: name, Measurements: measurements}, nil
+}
+
+// resolvePayloadDecoder returns the built-in decoder of that name, or the
+// layout after validating it.
+func resolvePayloadDecoder(name string, layout decoder.Layout) (decoder.Decoder, error) {
+	if name == entity.PayloadDecoderLayout {
+		if err := layout.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: layout: %s", utils.ErrValidation, err.Error())
+		}
+		return layout, nil
+	}
+
+	d, ok := decoder.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown decoder %q, available: %s", utils.ErrValidation,
+			name, strings.Join(append(decoder.Names(), entity.PayloadDecoderLayout), ", "))
+	}
+	return d, nil
+}
+
+func decodePayload(d decoder.Decoder, payload string, encoding string) ([]decoder.Measurement, error) {
+	data, err := decoder.ParsePayload(payload, encoding)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
+	measurements, err := d.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: decode: %s", utils.ErrValidation, err.Error())
+	}
+	return measurements, nil
+}
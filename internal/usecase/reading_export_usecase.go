@@ -0,0 +1,315 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mertani_test/internal/entity"
+	"mertani_test/internal/export"
+	"mertani_test/internal/model"
+	"mertani_test/internal/utils"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// readingExportTimeout bounds streaming a single export over a request. It
+// is far above the usual request timeout since months of readings can be
+// requested; background export jobs set their own limit.
+const readingExportTimeout = 10 * time.Minute
+
+var (
+	readingExportColumns = []export.Column{
+		{Name: "recorded_at", Type: export.Timestamp},
+		{Name: "sensor_id", Type: export.String},
+		{Name: "sensor_name", Type: export.String},
+		{Name: "value", Type: export.Double},
+		{Name: "raw_value", Type: export.Double, Optional: true},
+		{Name: "unit", Type: export.String},
+	}
+	readingAggregateExportColumns = []export.Column{
+		{Name: "bucket", Type: export.Timestamp},
+		{Name: "sensor_id", Type: export.String},
+		{Name: "sensor_name", Type: export.String},
+		{Name: "count", Type: export.Int64},
+		{Name: "min", Type: export.Double},
+		{Name: "max", Type: export.Double},
+		{Name: "avg", Type: export.Double},
+		{Name: "unit", Type: export.String},
+	}
+
+	unsafeFilenameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+)
+
+// ReadingExport is an export whose sensors and options have been checked.
+// Write streams it within Timeout, or without a limit when it is zero;
+// Rows counts what has been written so far and may be read while Write
+// runs.
+type ReadingExport struct {
+	Filename    string
+	ContentType string
+	Timeout     time.Duration
+	Write       func(ctx context.Context, w io.Writer) error
+	Rows        func() int64
+}
+
+// readingExportSensor is a sensor included in an export with the conversion
+// of its values, nil when they are served as stored.
+type readingExportSensor struct {
+	sensor     entity.Sensor
+	conversion *utils.UnitConversion
+}
+
+// ExportSensor prepares a bulk export of one sensor's readings. A requested
+// unit must be convertible from the sensor's unit.
+func (c *ReadingUseCase) ExportSensor(ctx context.Context, sensorID string, request *model.ReadingExportRequest) (*ReadingExport, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	filter, err := c.readingExportFilter(request)
+	if err != nil {
+		return nil, err
+	}
+
+	sensor, err := c.findSensor(c.DB.WithContext(ctx), sensorID)
+	if err != nil {
+		return nil, err
+	}
+
+	conversion, err := sensorUnitConversion(sensor, request.Unit)
+	if err != nil {
+		return nil, err
+	}
+
+	sensors := []readingExportSensor{{sensor: *sensor, conversion: conversion}}
+	return c.newReadingExport(sensor.Name, sensors, filter, request), nil
+}
+
+// ExportDevice prepares a bulk export of the readings of all sensors of a
+// device. A requested unit applies to the sensors it is compatible with;
+// the others keep their own unit.
+func (c *ReadingUseCase) ExportDevice(ctx context.Context, deviceID string, request *model.ReadingExportRequest) (*ReadingExport, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	filter, err := c.readingExportFilter(request)
+	if err != nil {
+		return nil, err
+	}
+
+	device := &entity.Device{}
+	if _, err := c.DeviceRepository.FindById(c.DB.WithContext(ctx), device, deviceID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Infof("Device not found, id=%s", deviceID)
+			return nil, utils.ErrNotFound
+		}
+		c.Log.Warnf("Failed find device from database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	found, err := c.SensorRepository.FindByDeviceID(c.DB.WithContext(ctx), device.ID)
+	if err != nil {
+		c.Log.Warnf("Failed find sensors from database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	sensors := make([]readingExportSensor, len(found))
+	for i, sensor := range found {
+		sensors[i].sensor = sensor
+		if conversion, err := sensorUnitConversion(&sensor, request.Unit); err == nil {
+			sensors[i].conversion = conversion
+		}
+	}
+
+	return c.newReadingExport(device.Name, sensors, filter, request), nil
+}
+
+func (c *ReadingUseCase) readingExportFilter(request *model.ReadingExportRequest) (*model.ReadingFilter, error) {
+	err := c.Validator.Validate.Struct(request)
+	if err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+
+			var messages []string
+			for _, e := range validationErrors {
+				messages = append(messages, e.Translate(c.Validator.Translator))
+			}
+			return nil, fmt.Errorf("%w: %s", utils.ErrValidation, strings.Join(messages, ", "))
+		}
+		return nil, fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
+	filter := &model.ReadingFilter{Unit: request.Unit}
+	if request.From != "" {
+		from, _ := time.Parse(time.RFC3339, request.From)
+		filter.From = &from
+	}
+	if request.To != "" {
+		to, _ := time.Parse(time.RFC3339, request.To)
+		if filter.From != nil && to.Before(*filter.From) {
+			return nil, fmt.Errorf("%w: to must not be before from", utils.ErrValidation)
+		}
+		filter.To = &to
+	}
+	return filter, nil
+}
+
+func (c *ReadingUseCase) newReadingExport(name string, sensors []readingExportSensor, filter *model.ReadingFilter, request *model.ReadingExportRequest) *ReadingExport {
+	filename := strings.Trim(unsafeFilenameChars.ReplaceAllString(name, "_"), "_")
+	if filename == "" {
+		filename = "readings"
+	}
+	if request.Interval != "" {
+		filename += "_" + request.Interval
+	}
+
+	var rows atomic.Int64
+
+	readingExport := &ReadingExport{
+		Filename:    filename + "." + request.Format,
+		ContentType: export.ContentType(request.Format),
+		Timeout:     readingExportTimeout,
+		Rows:        rows.Load,
+	}
+	readingExport.Write = func(ctx context.Context, w io.Writer) error {
+		if readingExport.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, readingExport.Timeout)
+			defer cancel()
+		}
+
+		err := c.writeReadingExport(c.DB.WithContext(ctx), w, sensors, filter, request, func() { rows.Add(1) })
+		if err != nil {
+			c.Log.Warnf("Failed export readings : %+v", err)
+		}
+		return err
+	}
+	return readingExport
+}
+
+// writeReadingExport streams readings, or resampled buckets, from a
+// database cursor into the export writer.
+func (c *ReadingUseCase) writeReadingExport(db *gorm.DB, w io.Writer, sensors []readingExportSensor,
+	filter *model.ReadingFilter, request *model.ReadingExportRequest, addRow func()) error {
+	ids := make([]uuid.UUID, len(sensors))
+	byID := make(map[uuid.UUID]*readingExportSensor, len(sensors))
+	for i := range sensors {
+		ids[i] = sensors[i].sensor.ID
+		byID[ids[i]] = &sensors[i]
+	}
+
+	columns := readingExportColumns
+	if request.Interval != "" {
+		columns = readingAggregateExportColumns
+	}
+	writer, err := export.NewWriter(request.Format, w, columns)
+	if err != nil {
+		return err
+	}
+
+	if len(ids) > 0 {
+		if request.Interval != "" {
+			err = c.streamReadingAggregates(db, writer, ids, byID, filter, request.Interval, addRow)
+		} else {
+			err = c.streamReadings(db, writer, ids, byID, filter, addRow)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return flushWriter(w)
+}
+
+func (c *ReadingUseCase) streamReadings(db *gorm.DB, writer export.Writer, ids []uuid.UUID,
+	sensors map[uuid.UUID]*readingExportSensor, filter *model.ReadingFilter, addRow func()) error {
+	rows, err := c.ReadingRepository.StreamBySensors(db, ids, filter)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var reading entity.Reading
+		if err := db.ScanRows(rows, &reading); err != nil {
+			return err
+		}
+
+		item := sensors[reading.SensorID]
+		value, unit := reading.EffectiveValue(), item.sensor.Unit
+		var raw *float64
+		if reading.CalibratedValue != nil {
+			raw = &reading.Value
+		}
+		if item.conversion != nil {
+			value, unit = item.conversion.Apply(value), item.conversion.To
+			if raw != nil {
+				converted := item.conversion.Apply(*raw)
+				raw = &converted
+			}
+		}
+
+		err := writer.WriteRow(reading.RecordedAt, reading.SensorID.String(), item.sensor.Name, value, raw, unit)
+		if err != nil {
+			return err
+		}
+		addRow()
+	}
+	return rows.Err()
+}
+
+func (c *ReadingUseCase) streamReadingAggregates(db *gorm.DB, writer export.Writer, ids []uuid.UUID,
+	sensors map[uuid.UUID]*readingExportSensor, filter *model.ReadingFilter, interval string, addRow func()) error {
+	rollups, err := c.useRollups(db, ids, filter)
+	if err != nil {
+		return err
+	}
+
+	rows, err := c.ReadingRepository.StreamAggregateBySensors(db, ids, filter, interval, rollups)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var aggregate model.ReadingAggregate
+		if err := db.ScanRows(rows, &aggregate); err != nil {
+			return err
+		}
+
+		// conversions are affine with a positive scale and commute with
+		// min, max and avg
+		item := sensors[aggregate.SensorID]
+		unit := item.sensor.Unit
+		if item.conversion != nil {
+			aggregate.Min = item.conversion.Apply(aggregate.Min)
+			aggregate.Max = item.conversion.Apply(aggregate.Max)
+			aggregate.Avg = item.conversion.Apply(aggregate.Avg)
+			unit = item.conversion.To
+		}
+
+		err := writer.WriteRow(aggregate.Bucket, aggregate.SensorID.String(), item.sensor.Name,
+			aggregate.Count, aggregate.Min, aggregate.Max, aggregate.Avg, unit)
+		if err != nil {
+			return err
+		}
+		addRow()
+	}
+	return rows.Err()
+}
+
+// flushWriter pushes buffered output to the client when w buffers.
+func flushWriter(w io.Writer) error {
+	if flusher, ok := w.(interface{ Flush() error }); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"context"
+	"mertani_test/internal/repository"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	// readingPartitionInterval is how often the partitions of the readings
+	// table are maintained.
+	readingPartitionInterval = 6 * time.Hour
+	// readingPartitionAhead is how many months past the current one get a
+	// partition unless configured otherwise.
+	readingPartitionAhead = 3
+	// readingPartitionTimeout bounds one maintenance run, which may move
+	// readings out of the default partition.
+	readingPartitionTimeout = 10 * time.Minute
+)
+
+// ReadingPartitionUseCase maintains the monthly partitions of the readings
+// table: it creates those of the coming Ahead months before readings reach
+// them, and detaches and drops those that ended more than Retention months
+// before the current one. Readings dropped with a partition are not rolled
+// up, so Retention is meant to outlast every raw retention policy; zero
+// keeps partitions forever. Readings outside every partition, such as
+// backfilled ones, land in the default one until the next run gives their
+// month a partition. Backends other than
+// Postgres keep readings in a single table and have nothing to maintain.
+type ReadingPartitionUseCase struct {
+	DB                         *gorm.DB
+	Log                        *logrus.Logger
+	ReadingPartitionRepository *repository.ReadingPartitionRepository
+	Ahead                      int
+	Retention                  int
+}
+
+func NewReadingPartitionUseCase(db *gorm.DB, logger *logrus.Logger, readingPartitionRepository *repository.ReadingPartitionRepository,
+	ahead int, retention int) *ReadingPartitionUseCase {
+	if ahead <= 0 {
+		ahead = readingPartitionAhead
+	}
+	return &ReadingPartitionUseCase{
+		DB:                         db,
+		Log:                        logger,
+		ReadingPartitionRepository: readingPartitionRepository,
+		Ahead:                      ahead,
+		Retention:                  max(retention, 0),
+	}
+}
+
+// Start maintains the partitions once, so the coming months are covered
+// before readings arrive, then launches the maintenance job. It stops when
+// ctx is done.
+func (c *ReadingPartitionUseCase) Start(ctx context.Context) error {
+	if c.DB.Dialector.Name() != "postgres" {
+		c.Log.Infof("Readings kept in a single table on %s", c.DB.Dialector.Name())
+		return nil
+	}
+
+	if err := c.maintain(ctx, time.Now()); err != nil {
+		return err
+	}
+
+	go c.schedule(ctx)
+	return nil
+}
+
+func (c *ReadingPartitionUseCase) schedule(ctx context.Context) {
+	ticker := time.NewTicker(readingPartitionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := c.maintain(ctx, time.Now()); err != nil {
+			c.Log.Warnf("Failed maintain reading partitions : %+v", err)
+		}
+	}
+}
+
+// maintain creates the missing partitions from the month of now to Ahead
+// months past it and for the months the default partition caught, and
+// drops the expired ones.
+func (c *ReadingPartitionUseCase) maintain(ctx context.Context, now time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, readingPartitionTimeout)
+	defer cancel()
+
+	partitions, err := c.ReadingPartitionRepository.FindAll(c.DB.WithContext(ctx))
+	if err != nil {
+		c.Log.Warnf("Failed find reading partitions from database : %+v", err)
+		return err
+	}
+
+	existing := make(map[string]bool, len(partitions))
+	for _, partition := range partitions {
+		existing[partition.Month.Format("200601")] = true
+	}
+
+	now = now.UTC()
+	current := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	var months []time.Time
+	for i := 0; i <= c.Ahead; i++ {
+		months = append(months, current.AddDate(0, i, 0))
+	}
+
+	caught, err := c.ReadingPartitionRepository.FindDefaultMonths(c.DB.WithContext(ctx))
+	if err != nil {
+		c.Log.Warnf("Failed find default reading partition months from database : %+v", err)
+		return err
+	}
+	months = append(months, caught...)
+
+	for _, month := range months {
+		key := month.UTC().Format("200601")
+		if existing[key] {
+			continue
+		}
+		if err := c.ReadingPartitionRepository.Create(c.DB.WithContext(ctx), month); err != nil {
+			c.Log.Warnf("Failed create reading partition to database : %+v", err)
+			return err
+		}
+		existing[key] = true
+		c.Log.Infof("Created reading partition of %s", month.UTC().Format("2006-01"))
+	}
+
+	if c.Retention == 0 {
+		return nil
+	}
+
+	cutoff := current.AddDate(0, -c.Retention, 0)
+	for _, partition := range partitions {
+		if partition.Month.AddDate(0, 1, 0).After(cutoff) {
+			continue
+		}
+		// detached first, so dropping its readings does not hold a lock on
+		// the readings table
+		if err := c.ReadingPartitionRepository.Detach(c.DB.WithContext(ctx), partition.Name); err != nil {
+			c.Log.Warnf("Failed detach reading partition from database : %+v", err)
+			return err
+		}
+		if err := c.ReadingPartitionRepository.Drop(c.DB.WithContext(ctx), partition.Name); err != nil {
+			c.Log.Warnf("Failed drop reading partition from database : %+v", err)
+			return err
+		}
+		c.Log.Infof("Dropped expired reading partition of %s", partition.Month.Format("2006-01"))
+	}
+
+	return nil
+}
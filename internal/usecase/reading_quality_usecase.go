@@ -0,0 +1,169 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"mertani_test/internal/entity"
+	"mertani_test/internal/model"
+	"mertani_test/internal/utils"
+	"time"
+)
+
+const (
+	// readingQualityRange is the time range of a quality report without
+	// from; readingQualityMaxRange bounds the range a report may cover.
+	readingQualityRange    = 24 * time.Hour
+	readingQualityMaxRange = 31 * 24 * time.Hour
+	// readingGapFactor is how many expected intervals a sensor may stay
+	// silent for before the silence counts as a gap.
+	readingGapFactor = 2
+	// readingIntervalSamples bounds the readings an expected interval is
+	// estimated from.
+	readingIntervalSamples = 1000
+)
+
+// Reading interval sources of a quality report.
+const (
+	readingIntervalConfigured = "configured"
+	readingIntervalEstimated  = "estimated"
+	readingIntervalUnknown    = "unknown"
+)
+
+// Quality reports how well a sensor reported over a time range: how many of
+// the readings expected at its interval arrived, the gaps in which it
+// stayed silent, and how many readings were flagged on ingest. The range
+// ends now at the latest and spans readingQualityRange unless from is
+// given, and starts no earlier than the readings retention compacted into
+// rollups. Sensors without a configured interval have it estimated from
+// their latest readings; while it cannot be, only the counts are reported.
+func (c *ReadingUseCase) Quality(ctx context.Context, sensorID string, filter *model.ReadingFilter) (*model.ReadingQualityResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	to := time.Now()
+	if filter.To != nil && filter.To.Before(to) {
+		to = *filter.To
+	}
+	from := to.Add(-readingQualityRange)
+	if filter.From != nil {
+		from = *filter.From
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before to and in the past", utils.ErrValidation)
+	}
+	if to.Sub(from) > readingQualityMaxRange {
+		return nil, fmt.Errorf("%w: time range must not exceed %d days",
+			utils.ErrValidation, int(readingQualityMaxRange/(24*time.Hour)))
+	}
+
+	sensor, err := c.findSensor(c.DB.WithContext(ctx), sensorID)
+	if err != nil {
+		return nil, err
+	}
+
+	// compacted readings are only counted per bucket, so the report covers
+	// the raw readings alone
+	compactedUntil, err := c.ReadingRollupRepository.CompactedUntil(c.DB.WithContext(ctx), sensor.ID)
+	if err != nil {
+		c.Log.Warnf("Failed find reading rollups from database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	compacted := compactedUntil != nil && compactedUntil.After(from)
+	if compacted {
+		from = *compactedUntil
+		if from.After(to) {
+			from = to
+		}
+	}
+
+	interval, source := float64(sensor.ExpectedInterval), readingIntervalConfigured
+	if interval <= 0 {
+		interval, err = c.ReadingRepository.EstimateInterval(c.DB.WithContext(ctx), sensor.ID, to, readingIntervalSamples)
+		if err != nil {
+			c.Log.Warnf("Failed estimate reading interval from database : %+v", err)
+			return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+		}
+		source = readingIntervalEstimated
+		if interval <= 0 {
+			source = readingIntervalUnknown
+		}
+	}
+
+	summary, err := c.ReadingRepository.QualitySummary(c.DB.WithContext(ctx), sensor.ID, from, to)
+	if err != nil {
+		c.Log.Warnf("Failed summarize reading quality from database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	response := &model.ReadingQualityResponse{
+		SensorID:         sensor.ID.String(),
+		From:             from.Format("2006-01-02 15:04:05"),
+		To:               to.Format("2006-01-02 15:04:05"),
+		ExpectedInterval: interval,
+		IntervalSource:   source,
+		ReceivedReadings: summary.Received,
+		TotalReadings:    summary.Total,
+		Gaps:             []model.ReadingGapResponse{},
+		Flagged: map[string]int64{
+			entity.QualityCodes[entity.QualityOutOfRange]: summary.OutOfRange,
+			entity.QualityCodes[entity.QualityDuplicate]:  summary.Duplicate,
+		},
+	}
+	if compacted {
+		response.CompactedUntil = compactedUntil.Format("2006-01-02 15:04:05")
+	}
+	// nothing is expected of a range that was compacted whole
+	if source == readingIntervalUnknown || !from.Before(to) {
+		return response, nil
+	}
+
+	threshold := interval * readingGapFactor
+	if summary.First == nil {
+		if to.Sub(from).Seconds() > threshold {
+			response.Gaps = append(response.Gaps, readingGapToResponse(from, to, interval, false))
+		}
+	} else {
+		gaps, err := c.ReadingRepository.FindGaps(c.DB.WithContext(ctx), sensor.ID, from, to, threshold)
+		if err != nil {
+			c.Log.Warnf("Failed find reading gaps from database : %+v", err)
+			return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+		}
+
+		// silences at either end of the range count until its bounds
+		if summary.First.Sub(from).Seconds() > threshold {
+			response.Gaps = append(response.Gaps, readingGapToResponse(from, *summary.First, interval, false))
+		}
+		for _, gap := range gaps {
+			response.Gaps = append(response.Gaps, readingGapToResponse(gap.From, gap.To, interval, true))
+		}
+		if to.Sub(*summary.Last).Seconds() > threshold {
+			response.Gaps = append(response.Gaps, readingGapToResponse(*summary.Last, to, interval, false))
+		}
+	}
+
+	response.ExpectedReadings = max(1, int64(to.Sub(from).Seconds()/interval))
+	completeness := math.Min(100, float64(summary.Received)/float64(response.ExpectedReadings)*100)
+	completeness = math.Round(completeness*100) / 100
+	response.Completeness = &completeness
+
+	return response, nil
+}
+
+// readingGapToResponse describes a gap with the readings it lacks at the
+// expected interval. A gap between two readings misses those due strictly
+// between them; one running to a bound of the range also misses the one
+// due at that bound.
+func readingGapToResponse(from time.Time, to time.Time, interval float64, betweenReadings bool) model.ReadingGapResponse {
+	duration := to.Sub(from).Seconds()
+	missing := int64(duration / interval)
+	if betweenReadings && math.Mod(duration, interval) == 0 {
+		missing--
+	}
+	return model.ReadingGapResponse{
+		From:            from.Format("2006-01-02 15:04:05"),
+		To:              to.Format("2006-01-02 15:04:05"),
+		Duration:        duration,
+		MissingReadings: missing,
+	}
+}
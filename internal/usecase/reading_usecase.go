@@ -0,0 +1,549 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"mertani_test/internal/entity"
+	"mertani_test/internal/model"
+	"mertani_test/internal/model/converter"
+	"mertani_test/internal/repository"
+	"mertani_test/internal/stream"
+	"mertani_test/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// readingMaxClockSkew is how far ahead of this server's clock a device's
+// clock may run before its readings are rejected as from the future.
+const readingMaxClockSkew = 5 * time.Minute
+
+type ReadingUseCase struct {
+	DB                          *gorm.DB
+	Log                         *logrus.Logger
+	Validator                   *utils.Validator
+	ReadingRepository           *repository.ReadingRepository
+	SensorRepository            *repository.SensorRepository
+	SensorTypeRepository        *repository.SensorTypeRepository
+	SensorCalibrationRepository *repository.SensorCalibrationRepository
+	DeviceRepository            *repository.DeviceRepository
+	ReadingRollupRepository     *repository.ReadingRollupRepository
+	SensorLatestRepository      *repository.SensorLatestRepository
+	Lookups                     *LookupCache
+	Hub                         *stream.Hub
+	AnomalyUseCase              *AnomalyUseCase
+}
+
+// storedReading is a reading saved in a pending transaction together with
+// its sensor and the number of anomalies found in it, kept to be published
+// once the transaction commits.
+type storedReading struct {
+	sensor    entity.Sensor
+	reading   entity.Reading
+	anomalies int
+}
+
+func NewReadingUseCase(db *gorm.DB, logger *logrus.Logger, validator *utils.Validator,
+	readingRepository *repository.ReadingRepository, sensorRepository *repository.SensorRepository,
+	sensorTypeRepository *repository.SensorTypeRepository,
+	sensorCalibrationRepository *repository.SensorCalibrationRepository,
+	deviceRepository *repository.DeviceRepository, readingRollupRepository *repository.ReadingRollupRepository,
+	sensorLatestRepository *repository.SensorLatestRepository, lookups *LookupCache, hub *stream.Hub, anomalyUseCase *AnomalyUseCase) *ReadingUseCase {
+	return &ReadingUseCase{
+		DB:                          db,
+		Log:                         logger,
+		Validator:                   validator,
+		ReadingRepository:           readingRepository,
+		SensorRepository:            sensorRepository,
+		SensorTypeRepository:        sensorTypeRepository,
+		SensorCalibrationRepository: sensorCalibrationRepository,
+		DeviceRepository:            deviceRepository,
+		ReadingRollupRepository:     readingRollupRepository,
+		SensorLatestRepository:      sensorLatestRepository,
+		Lookups:                     lookups,
+		Hub:                         hub,
+		AnomalyUseCase:              anomalyUseCase,
+	}
+}
+
+func (c *ReadingUseCase) Create(ctx context.Context, sensorID string, request *model.CreateReadingRequest) (*model.ReadingResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	err := c.Validator.Validate.Struct(request)
+	if err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+
+			var messages []string
+			for _, e := range validationErrors {
+				messages = append(messages, e.Translate(c.Validator.Translator))
+			}
+			return nil, fmt.Errorf("%w: %s", utils.ErrValidation, strings.Join(messages, ", "))
+		}
+		return nil, fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
+	sensor, err := c.findSensor(c.DB.WithContext(ctx), sensorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if sensor.Kind == entity.SensorVirtual {
+		return nil, fmt.Errorf("%w: readings of virtual sensors are computed from their inputs", utils.ErrValidation)
+	}
+
+	recordedAt := time.Now()
+	if request.RecordedAt != "" {
+		recordedAt, _ = time.Parse(time.RFC3339, request.RecordedAt)
+	}
+
+	var (
+		reading *entity.Reading
+		stored  []storedReading
+	)
+	err = c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		reading, err = c.store(tx, &stored, sensor, *request.Value, recordedAt)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, utils.ErrValidation) || errors.Is(err, utils.ErrInternal) {
+			return nil, err
+		}
+		c.Log.Warnf("Failed create reading to database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	c.committed(stored)
+
+	response := converter.ReadingToResponse(reading)
+	response.Unit = sensor.Unit
+	return response, nil
+}
+
+func (c *ReadingUseCase) FindAll(ctx context.Context, sensorID string, filter *model.ReadingFilter, pagination *utils.PaginationRequest) ([]model.ReadingResponse, *utils.PaginationResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	sensor, err := c.findSensor(c.DB.WithContext(ctx), sensorID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	conversion, err := sensorUnitConversion(sensor, filter.Unit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var readings []entity.Reading
+	total, err := c.ReadingRepository.FindAll(c.DB.WithContext(ctx), &readings, pagination,
+		repository.ByReadingFilter(sensorID, filter))
+	if err != nil {
+		c.Log.Warnf("Failed find all reading from database : %+v", err)
+		return nil, nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	responses := make([]model.ReadingResponse, len(readings))
+	for i, reading := range readings {
+		responses[i] = *converter.ReadingToResponse(&reading)
+		responses[i].Unit = sensor.Unit
+		if conversion != nil {
+			convertReadingResponse(&responses[i], conversion)
+		}
+	}
+
+	totalPage := int((total + int64(pagination.Limit) - 1) / int64(pagination.Limit))
+
+	paginationRes := &utils.PaginationResponse{
+		Page:      pagination.Page,
+		Limit:     pagination.Limit,
+		OrderBy:   pagination.OrderBy,
+		SortBy:    pagination.SortBy,
+		Search:    pagination.Search,
+		TotalData: total,
+		TotalPage: totalPage,
+	}
+
+	return responses, paginationRes, nil
+}
+
+func (c *ReadingUseCase) Aggregate(ctx context.Context, sensorID string, filter *model.ReadingFilter, interval string) ([]model.ReadingAggregateResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !ReadingAggregateIntervals.Contains(interval) {
+		return nil, fmt.Errorf("%w: interval must be one of %s",
+			utils.ErrValidation, strings.Join(ReadingAggregateIntervals, ", "))
+	}
+
+	sensor, err := c.findSensor(c.DB.WithContext(ctx), sensorID)
+	if err != nil {
+		return nil, err
+	}
+
+	conversion, err := sensorUnitConversion(sensor, filter.Unit)
+	if err != nil {
+		return nil, err
+	}
+
+	rollups, err := c.useRollups(c.DB.WithContext(ctx), []uuid.UUID{sensor.ID}, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	aggregates, err := c.ReadingRepository.Aggregate(c.DB.WithContext(ctx), sensor.ID, filter, interval, rollups)
+	if err != nil {
+		c.Log.Warnf("Failed aggregate reading from database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	// Conversions are affine with a positive scale, so they commute with
+	// min, max and avg and can be applied to the buckets directly.
+	responses := make([]model.ReadingAggregateResponse, len(aggregates))
+	for i, aggregate := range aggregates {
+		responses[i] = *converter.ReadingAggregateToResponse(&aggregate)
+		responses[i].Unit = sensor.Unit
+		if conversion != nil {
+			responses[i].Min = conversion.Apply(aggregate.Min)
+			responses[i].Max = conversion.Apply(aggregate.Max)
+			responses[i].Avg = conversion.Apply(aggregate.Avg)
+			responses[i].Unit = conversion.To
+		}
+	}
+
+	return responses, nil
+}
+
+// store calibrates, range-checks, flags and saves a raw reading, then derives the
+// readings of virtual sensors fed by it. Every reading saved is appended to
+// stored for publishing after commit once the anomaly detectors have
+// checked it.
+func (c *ReadingUseCase) store(tx *gorm.DB, stored *[]storedReading, sensor *entity.Sensor, value float64, recordedAt time.Time) (*entity.Reading, error) {
+	// NaN passes every range check and cannot be encoded in responses, so
+	// whatever the ingest path, it stops here
+	if !isFinite(value) {
+		return nil, fmt.Errorf("%w: value must be a finite number", utils.ErrValidation)
+	}
+	// a reading from the future would stay the latest of its sensor until
+	// that time, hiding every real one
+	if recordedAt.After(time.Now().Add(readingMaxClockSkew)) {
+		return nil, fmt.Errorf("%w: recorded time %s is in the future", utils.ErrValidation, recordedAt.Format(time.RFC3339))
+	}
+
+	reading := &entity.Reading{
+		SensorID:   sensor.ID,
+		Value:      value,
+		RecordedAt: recordedAt,
+	}
+
+	calibration, err := c.SensorCalibrationRepository.FindInForce(tx, &entity.SensorCalibration{}, sensor.ID, recordedAt)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if calibration != nil {
+		calibrated := calibration.Apply(reading.Value)
+		if !isFinite(calibrated) {
+			return nil, fmt.Errorf("%w: calibrated value of %v is not a finite number", utils.ErrValidation, value)
+		}
+		reading.CalibratedValue = &calibrated
+	}
+
+	if err := c.checkPhysicalRange(tx, sensor, reading.EffectiveValue()); err != nil {
+		return nil, err
+	}
+	if err := c.flagQuality(tx, sensor, reading); err != nil {
+		return nil, err
+	}
+
+	if err := c.ReadingRepository.Create(tx, reading); err != nil {
+		return nil, err
+	}
+	if err := c.keep(tx, stored, sensor, reading); err != nil {
+		return nil, err
+	}
+
+	if err := c.deriveVirtualReadings(tx, stored, reading); err != nil {
+		return nil, err
+	}
+	return reading, nil
+}
+
+func isFinite(value float64) bool {
+	return !math.IsNaN(value) && !math.IsInf(value, 0)
+}
+
+// keep records a saved reading as the latest of its sensor, runs the
+// anomaly detectors on it and appends it to stored.
+func (c *ReadingUseCase) keep(tx *gorm.DB, stored *[]storedReading, sensor *entity.Sensor, reading *entity.Reading) error {
+	if err := c.SensorLatestRepository.Save(tx, reading); err != nil {
+		return err
+	}
+
+	anomalies := 0
+	if c.AnomalyUseCase != nil {
+		var err error
+		if anomalies, err = c.AnomalyUseCase.Detect(tx, sensor, reading); err != nil {
+			return err
+		}
+	}
+	*stored = append(*stored, storedReading{sensor: *sensor, reading: *reading, anomalies: anomalies})
+	return nil
+}
+
+// committed publishes readings once the transaction that stored them has
+// committed, and wakes the outbox relay when anomalies were recorded with
+// them.
+func (c *ReadingUseCase) committed(stored []storedReading) {
+	publishReadings(c.Hub, stored)
+	for _, reading := range stored {
+		if reading.anomalies > 0 {
+			c.AnomalyUseCase.Outbox.Notify()
+			return
+		}
+	}
+}
+
+// deriveVirtualReadings evaluates every virtual sensor downstream of the
+// reading's sensor at the reading's timestamp. Each one is evaluated once,
+// after all of its affected inputs, using the latest reading at or before
+// that time for inputs that did not change.
+func (c *ReadingUseCase) deriveVirtualReadings(tx *gorm.DB, stored *[]storedReading, reading *entity.Reading) error {
+	affected, order, err := findDownstream(tx, c.SensorRepository, reading.SensorID)
+	if err != nil || len(affected) == 0 {
+		return err
+	}
+
+	values := map[uuid.UUID]float64{reading.SensorID: reading.EffectiveValue()}
+	pending := order
+	for len(pending) > 0 {
+		var next []uuid.UUID
+		for _, id := range pending {
+			sensor := affected[id]
+			if !inputsSettled(&sensor, affected) {
+				next = append(next, id)
+				continue
+			}
+
+			value, ok, err := c.evaluateVirtualSensor(tx, &sensor, values, reading.RecordedAt)
+			if err != nil {
+				return err
+			}
+			// mark as settled even when skipped so dependents fall back to
+			// the stored history
+			delete(affected, id)
+			if !ok {
+				continue
+			}
+
+			derived := &entity.Reading{SensorID: sensor.ID, Value: value, RecordedAt: reading.RecordedAt}
+			if err := c.ReadingRepository.Create(tx, derived); err != nil {
+				return err
+			}
+			if err := c.keep(tx, stored, &sensor, derived); err != nil {
+				return err
+			}
+			values[sensor.ID] = value
+		}
+		if len(next) == len(pending) {
+			c.Log.Warnf("Virtual sensors form a dependency cycle, skipped %d sensor(s)", len(next))
+			return nil
+		}
+		pending = next
+	}
+	return nil
+}
+
+// findDownstream returns the virtual sensors fed by a sensor, directly or
+// through other virtual sensors, by id and in the order they were found.
+func findDownstream(tx *gorm.DB, sensorRepository *repository.SensorRepository, sensorID uuid.UUID) (map[uuid.UUID]entity.Sensor, []uuid.UUID, error) {
+	affected := map[uuid.UUID]entity.Sensor{}
+	var order []uuid.UUID
+	frontier := []uuid.UUID{sensorID}
+	for len(frontier) > 0 {
+		dependents, err := sensorRepository.FindDependents(tx, frontier)
+		if err != nil {
+			return nil, nil, err
+		}
+		frontier = nil
+		for _, dependent := range dependents {
+			if _, ok := affected[dependent.ID]; ok {
+				continue
+			}
+			affected[dependent.ID] = dependent
+			order = append(order, dependent.ID)
+			frontier = append(frontier, dependent.ID)
+		}
+	}
+	return affected, order, nil
+}
+
+// inputsSettled reports whether none of the sensor's inputs is still waiting
+// to be evaluated in this pass.
+func inputsSettled(sensor *entity.Sensor, affected map[uuid.UUID]entity.Sensor) bool {
+	for _, input := range sensor.Inputs {
+		if _, waiting := affected[input.InputSensorID]; waiting {
+			return false
+		}
+	}
+	return true
+}
+
+// evaluateVirtualSensor computes a virtual sensor from fresh values of this
+// pass and, for the remaining inputs, their latest stored readings. ok is
+// false when an input has no value yet or the expression cannot be evaluated.
+func (c *ReadingUseCase) evaluateVirtualSensor(tx *gorm.DB, sensor *entity.Sensor, values map[uuid.UUID]float64, at time.Time) (float64, bool, error) {
+	expression, err := utils.ParseExpression(sensor.Expression)
+	if err != nil {
+		c.Log.Warnf("Invalid expression of virtual sensor %s : %+v", sensor.ID, err)
+		return 0, false, nil
+	}
+
+	var missing []uuid.UUID
+	for _, input := range sensor.Inputs {
+		if _, ok := values[input.InputSensorID]; !ok {
+			missing = append(missing, input.InputSensorID)
+		}
+	}
+	latest, err := c.ReadingRepository.FindLatestBySensorIDsAt(tx, missing, at)
+	if err != nil {
+		return 0, false, err
+	}
+	stored := make(map[uuid.UUID]float64, len(latest))
+	for _, r := range latest {
+		stored[r.SensorID] = r.EffectiveValue()
+	}
+
+	vars := make(map[string]float64, len(sensor.Inputs))
+	for _, input := range sensor.Inputs {
+		if value, ok := values[input.InputSensorID]; ok {
+			vars[input.Alias] = value
+		} else if value, ok := stored[input.InputSensorID]; ok {
+			vars[input.Alias] = value
+		} else {
+			return 0, false, nil
+		}
+	}
+
+	value, err := expression.Evaluate(vars)
+	if err != nil {
+		c.Log.Warnf("Failed evaluate virtual sensor %s : %+v", sensor.ID, err)
+		return 0, false, nil
+	}
+	return value, true, nil
+}
+
+// useRollups routes an aggregate query to the rollup tiers as well as the
+// raw readings when any of the sensors has rollups in its range, that is
+// when part of the range was compacted by retention.
+func (c *ReadingUseCase) useRollups(db *gorm.DB, sensorIDs []uuid.UUID, filter *model.ReadingFilter) (bool, error) {
+	exists, err := c.ReadingRollupRepository.ExistsInRange(db, sensorIDs, filter)
+	if err != nil {
+		c.Log.Warnf("Failed find reading rollups from database : %+v", err)
+		return false, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	return exists, nil
+}
+
+func (c *ReadingUseCase) findSensor(db *gorm.DB, sensorID string) (*entity.Sensor, error) {
+	sensor, err := c.Lookups.FindSensor(db, sensorID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Infof("Sensor not found, id=%s", sensorID)
+			return nil, utils.ErrNotFound
+		}
+		c.Log.Warnf("Failed find sensor from database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	return sensor, nil
+}
+
+// flagQuality flags a reading outside the sensor's operating range, and one
+// recorded at the same time as a reading the sensor has already.
+func (c *ReadingUseCase) flagQuality(tx *gorm.DB, sensor *entity.Sensor, reading *entity.Reading) error {
+	if !sensor.InValidRange(reading.EffectiveValue()) {
+		reading.Quality |= entity.QualityOutOfRange
+	}
+
+	duplicate, err := c.ReadingRepository.ExistsAt(tx, sensor.ID, reading.RecordedAt)
+	if err != nil {
+		return err
+	}
+	if duplicate {
+		reading.Quality |= entity.QualityDuplicate
+	}
+	return nil
+}
+
+// checkPhysicalRange rejects values the sensor type declares impossible. The
+// range is expressed in the canonical unit, so values reported in another
+// unit are converted first.
+func (c *ReadingUseCase) checkPhysicalRange(db *gorm.DB, sensor *entity.Sensor, value float64) error {
+	sensorType, err := c.SensorTypeRepository.FindByCode(db, &entity.SensorType{}, sensor.Type)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		c.Log.Warnf("Failed find sensor type from database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	canonical := value
+	if sensor.Unit != "" && sensor.Unit != sensorType.CanonicalUnit {
+		canonical, err = utils.ConvertUnit(value, sensor.Unit, sensorType.CanonicalUnit)
+		if err != nil {
+			// the unit is allowed by the registry but not known to the
+			// converter, so there is nothing to compare against
+			return nil
+		}
+	}
+
+	if !sensorType.InRange(canonical) {
+		return fmt.Errorf("%w: value %v %s is outside the physical range of sensor type %q",
+			utils.ErrValidation, value, sensor.Unit, sensorType.Code)
+	}
+	return nil
+}
+
+// convertReadingResponse applies a unit conversion to a served reading.
+func convertReadingResponse(response *model.ReadingResponse, conversion *utils.UnitConversion) {
+	response.Value = conversion.Apply(response.Value)
+	if response.RawValue != nil {
+		raw := conversion.Apply(*response.RawValue)
+		response.RawValue = &raw
+	}
+	response.Unit = conversion.To
+}
+
+// convertSensorResponse applies a unit conversion to the latest value of a
+// served sensor. A nil conversion leaves it as stored.
+func convertSensorResponse(response *model.SensorResponse, conversion *utils.UnitConversion) {
+	if conversion == nil || response.LastValue == nil {
+		return
+	}
+	value := conversion.Apply(*response.LastValue)
+	response.LastValue = &value
+}
+
+// ReadingAggregateIntervals are the date_trunc fields accepted as aggregate buckets.
+var ReadingAggregateIntervals = entity.StringList{"minute", "hour", "day", "week", "month"}
+
+// sensorUnitConversion resolves the conversion from the sensor's unit to the
+// requested one. A nil conversion means values are served as stored.
+func sensorUnitConversion(sensor *entity.Sensor, unit string) (*utils.UnitConversion, error) {
+	if unit == "" || unit == sensor.Unit {
+		return nil, nil
+	}
+	if sensor.Unit == "" {
+		return nil, fmt.Errorf("%w: sensor has no unit to convert from", utils.ErrValidation)
+	}
+
+	conversion, err := utils.NewUnitConversion(sensor.Unit, unit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unit: %s", utils.ErrValidation, err.Error())
+	}
+	return &conversion, nil
+}
@@ -0,0 +1,348 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"mertani_test/internal/entity"
+	"mertani_test/internal/model"
+	"mertani_test/internal/model/converter"
+	"mertani_test/internal/repository"
+	"mertani_test/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	// retentionInterval is how often readings are compacted unless
+	// configured otherwise.
+	retentionInterval = time.Hour
+	// retentionBatchSize bounds the sensors loaded at a time while
+	// compacting.
+	retentionBatchSize = 500
+	// retentionSensorTimeout bounds compacting the readings of one sensor.
+	retentionSensorTimeout = 5 * time.Minute
+)
+
+// RetentionUseCase manages retention policies and compacts readings by
+// them. A background job moves raw readings past a policy's raw retention
+// into hourly rollups, hourly rollups past the hourly retention into daily
+// rollups, and deletes daily rollups past the daily retention. Rollups are
+// written by the statement deleting what they roll up, so no reading is
+// lost or counted twice; aggregate queries read them alongside the raw
+// readings.
+type RetentionUseCase struct {
+	DB                        *gorm.DB
+	Log                       *logrus.Logger
+	Validator                 *utils.Validator
+	RetentionPolicyRepository *repository.RetentionPolicyRepository
+	ReadingRollupRepository   *repository.ReadingRollupRepository
+	SensorRepository          *repository.SensorRepository
+	SensorTypeRepository      *repository.SensorTypeRepository
+	Interval                  time.Duration
+}
+
+func NewRetentionUseCase(db *gorm.DB, logger *logrus.Logger, validator *utils.Validator,
+	retentionPolicyRepository *repository.RetentionPolicyRepository, readingRollupRepository *repository.ReadingRollupRepository,
+	sensorRepository *repository.SensorRepository, sensorTypeRepository *repository.SensorTypeRepository,
+	interval time.Duration) *RetentionUseCase {
+	if interval <= 0 {
+		interval = retentionInterval
+	}
+	return &RetentionUseCase{
+		DB:                        db,
+		Log:                       logger,
+		Validator:                 validator,
+		RetentionPolicyRepository: retentionPolicyRepository,
+		ReadingRollupRepository:   readingRollupRepository,
+		SensorRepository:          sensorRepository,
+		SensorTypeRepository:      sensorTypeRepository,
+		Interval:                  interval,
+	}
+}
+
+func (c *RetentionUseCase) Create(ctx context.Context, request *model.CreateRetentionPolicyRequest) (*model.RetentionPolicyResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := c.validate(request); err != nil {
+		return nil, err
+	}
+
+	policy := &entity.RetentionPolicy{
+		SensorType: request.SensorType,
+		RawDays:    request.RawDays,
+		HourlyDays: request.HourlyDays,
+		DailyDays:  request.DailyDays,
+	}
+	if err := checkRetentionTiers(policy); err != nil {
+		return nil, err
+	}
+
+	if policy.SensorType != "" {
+		exists, err := c.SensorTypeRepository.ExistsByCode(c.DB.WithContext(ctx), policy.SensorType)
+		if err != nil {
+			c.Log.Warnf("Failed find sensor type from database : %+v", err)
+			return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+		}
+		if !exists {
+			return nil, fmt.Errorf("%w: sensor type not found", utils.ErrValidation)
+		}
+	}
+
+	exists, err := c.RetentionPolicyRepository.ExistsBySensorType(c.DB.WithContext(ctx), policy.SensorType)
+	if err != nil {
+		c.Log.Warnf("Failed find retention policy from database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	if exists {
+		return nil, fmt.Errorf("%w: %s", utils.ErrConflict, "a retention policy is already set for this sensor type")
+	}
+
+	if err := c.RetentionPolicyRepository.Create(c.DB.WithContext(ctx), policy); err != nil {
+		c.Log.Warnf("Failed create retention policy to database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	return converter.RetentionPolicyToResponse(policy), nil
+}
+
+func (c *RetentionUseCase) FindAll(ctx context.Context, pagination *utils.PaginationRequest) ([]model.RetentionPolicyResponse, *utils.PaginationResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var policies []entity.RetentionPolicy
+	total, err := c.RetentionPolicyRepository.FindAll(c.DB.WithContext(ctx), &policies, pagination)
+	if err != nil {
+		c.Log.Warnf("Failed find all retention policy from database : %+v", err)
+		return nil, nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	responses := make([]model.RetentionPolicyResponse, len(policies))
+	for i, policy := range policies {
+		responses[i] = *converter.RetentionPolicyToResponse(&policy)
+	}
+
+	totalPage := int((total + int64(pagination.Limit) - 1) / int64(pagination.Limit))
+
+	paginationRes := &utils.PaginationResponse{
+		Page:      pagination.Page,
+		Limit:     pagination.Limit,
+		OrderBy:   pagination.OrderBy,
+		SortBy:    pagination.SortBy,
+		Search:    pagination.Search,
+		TotalData: total,
+		TotalPage: totalPage,
+	}
+
+	return responses, paginationRes, nil
+}
+
+func (c *RetentionUseCase) FindByID(ctx context.Context, policyID string) (*model.RetentionPolicyResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	policy, err := c.findPolicy(c.DB.WithContext(ctx), policyID)
+	if err != nil {
+		return nil, err
+	}
+	return converter.RetentionPolicyToResponse(policy), nil
+}
+
+// Update changes how long readings are kept; the next compaction applies
+// it. Shortening a retention compacts readings sooner, while lengthening
+// one cannot bring back readings already compacted.
+func (c *RetentionUseCase) Update(ctx context.Context, policyID string, request *model.UpdateRetentionPolicyRequest) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	policy, err := c.findPolicy(c.DB.WithContext(ctx), policyID)
+	if err != nil {
+		return err
+	}
+
+	if err := c.validate(request); err != nil {
+		return err
+	}
+
+	if request.RawDays != nil {
+		policy.RawDays = *request.RawDays
+	}
+	if request.HourlyDays != nil {
+		policy.HourlyDays = *request.HourlyDays
+	}
+	if request.DailyDays != nil {
+		policy.DailyDays = *request.DailyDays
+	}
+	if err := checkRetentionTiers(policy); err != nil {
+		return err
+	}
+
+	if err := c.RetentionPolicyRepository.Update(c.DB.WithContext(ctx), policy); err != nil {
+		c.Log.Warnf("Failed update retention policy to database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	return nil
+}
+
+// Delete removes a policy. Sensors it applied to fall back to the default
+// policy, or keep every reading from then on when there is none.
+func (c *RetentionUseCase) Delete(ctx context.Context, policyID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	policy, err := c.findPolicy(c.DB.WithContext(ctx), policyID)
+	if err != nil {
+		return err
+	}
+
+	if err := c.RetentionPolicyRepository.Delete(c.DB.WithContext(ctx), policy); err != nil {
+		c.Log.Warnf("Failed delete retention policy from database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	return nil
+}
+
+// Start launches the compaction job. It stops when ctx is done.
+func (c *RetentionUseCase) Start(ctx context.Context) error {
+	go c.schedule(ctx)
+	return nil
+}
+
+func (c *RetentionUseCase) schedule(ctx context.Context) {
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		c.compact(ctx, time.Now())
+	}
+}
+
+// compact applies the retention policies to every sensor under one.
+func (c *RetentionUseCase) compact(ctx context.Context, now time.Time) {
+	policies, err := c.RetentionPolicyRepository.FindBySensorTypes(c.DB.WithContext(ctx))
+	if err != nil {
+		c.Log.Warnf("Failed find retention policies from database : %+v", err)
+		return
+	}
+	if len(policies) == 0 {
+		return
+	}
+
+	var moved int64
+	err = c.SensorRepository.FindInBatches(c.DB.WithContext(ctx), "", retentionBatchSize, func(sensors []entity.Sensor) error {
+		for _, sensor := range sensors {
+			policy, ok := policies[sensor.Type]
+			if !ok {
+				if policy, ok = policies[""]; !ok {
+					continue
+				}
+			}
+
+			compacted, err := c.compactSensor(ctx, &sensor, &policy, now)
+			if err != nil {
+				c.Log.Warnf("Failed compact readings of sensor %s : %+v", sensor.ID, err)
+			}
+			moved += compacted
+		}
+		return ctx.Err()
+	})
+	if err != nil && !errors.Is(err, context.Canceled) {
+		c.Log.Warnf("Failed find sensors from database : %+v", err)
+	}
+	if moved > 0 {
+		c.Log.Infof("Compacted %d readings into rollups", moved)
+	}
+}
+
+// compactSensor compacts the readings of one sensor by a policy, finest
+// tier first, and returns how many raw readings it moved into rollups. Raw
+// readings are cut at an hour and hourly rollups at a day.
+func (c *RetentionUseCase) compactSensor(ctx context.Context, sensor *entity.Sensor, policy *entity.RetentionPolicy, now time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, retentionSensorTimeout)
+	defer cancel()
+
+	db := c.DB.WithContext(ctx)
+	day := 24 * time.Hour
+
+	var moved int64
+	if policy.RawDays > 0 {
+		var err error
+		moved, err = c.ReadingRollupRepository.CompactReadings(db, sensor.ID, now.Add(-time.Duration(policy.RawDays)*day).Truncate(time.Hour))
+		if err != nil {
+			return 0, err
+		}
+	}
+	if policy.HourlyDays > 0 {
+		if _, err := c.ReadingRollupRepository.CompactHours(db, sensor.ID, now.Add(-time.Duration(policy.HourlyDays)*day).Truncate(day)); err != nil {
+			return moved, err
+		}
+	}
+	if policy.DailyDays > 0 {
+		if _, err := c.ReadingRollupRepository.DeleteDays(db, sensor.ID, now.Add(-time.Duration(policy.DailyDays)*day).Truncate(day)); err != nil {
+			return moved, err
+		}
+	}
+	return moved, nil
+}
+
+// checkRetentionTiers rejects a policy keeping a resolution for less time
+// than the finer one before it, zero days counting as forever.
+func checkRetentionTiers(policy *entity.RetentionPolicy) error {
+	keeps := func(days int) int {
+		if days == 0 {
+			return math.MaxInt
+		}
+		return days
+	}
+	if keeps(policy.HourlyDays) < keeps(policy.RawDays) {
+		return fmt.Errorf("%w: hourly_days must not be shorter than raw_days", utils.ErrValidation)
+	}
+	if keeps(policy.DailyDays) < keeps(policy.HourlyDays) {
+		return fmt.Errorf("%w: daily_days must not be shorter than hourly_days", utils.ErrValidation)
+	}
+	return nil
+}
+
+func (c *RetentionUseCase) validate(request any) error {
+	err := c.Validator.Validate.Struct(request)
+	if err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+
+			var messages []string
+			for _, e := range validationErrors {
+				messages = append(messages, e.Translate(c.Validator.Translator))
+			}
+			return fmt.Errorf("%w: %s", utils.ErrValidation, strings.Join(messages, ", "))
+		}
+		return fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+	return nil
+}
+
+func (c *RetentionUseCase) findPolicy(db *gorm.DB, policyID string) (*entity.RetentionPolicy, error) {
+	policy := &entity.RetentionPolicy{}
+	_, err := c.RetentionPolicyRepository.FindById(db, policy, policyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Infof("Retention policy not found, id=%s", policyID)
+			return nil, utils.ErrNotFound
+		}
+		c.Log.Warnf("Failed find retention policy from database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	return policy, nil
+}
@@ -0,0 +1,369 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mertani_test/internal/entity"
+	"mertani_test/internal/model"
+	"mertani_test/internal/model/converter"
+	"mertani_test/internal/repository"
+	"mertani_test/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type SensorCalibrationUseCase struct {
+	DB                          *gorm.DB
+	Log                         *logrus.Logger
+	Validator                   *utils.Validator
+	SensorRepository            *repository.SensorRepository
+	SensorCalibrationRepository *repository.SensorCalibrationRepository
+	ReadingRepository           *repository.ReadingRepository
+	SensorLatestRepository      *repository.SensorLatestRepository
+}
+
+func NewSensorCalibrationUseCase(db *gorm.DB, logger *logrus.Logger, validator *utils.Validator,
+	sensorRepository *repository.SensorRepository, sensorCalibrationRepository *repository.SensorCalibrationRepository,
+	readingRepository *repository.ReadingRepository, sensorLatestRepository *repository.SensorLatestRepository) *SensorCalibrationUseCase {
+	return &SensorCalibrationUseCase{
+		DB:                          db,
+		Log:                         logger,
+		Validator:                   validator,
+		SensorRepository:            sensorRepository,
+		SensorCalibrationRepository: sensorCalibrationRepository,
+		ReadingRepository:           readingRepository,
+		SensorLatestRepository:      sensorLatestRepository,
+	}
+}
+
+// Create adds a calibration version. Existing readings keep their calibrated
+// values until the affected range is reprocessed.
+func (c *SensorCalibrationUseCase) Create(ctx context.Context, sensorID string, request *model.CreateSensorCalibrationRequest) (*model.SensorCalibrationResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	err := c.Validator.Validate.Struct(request)
+	if err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+
+			var messages []string
+			for _, e := range validationErrors {
+				messages = append(messages, e.Translate(c.Validator.Translator))
+			}
+			return nil, fmt.Errorf("%w: %s", utils.ErrValidation, strings.Join(messages, ", "))
+		}
+		return nil, fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
+	calibration, err := newSensorCalibration(request)
+	if err != nil {
+		return nil, err
+	}
+
+	sensor, err := c.findSensor(c.DB.WithContext(ctx), sensorID)
+	if err != nil {
+		return nil, err
+	}
+	calibration.SensorID = sensor.ID
+
+	exists, err := c.SensorCalibrationRepository.ExistsAt(c.DB.WithContext(ctx), sensor.ID, calibration.EffectiveFrom)
+	if err != nil {
+		c.Log.Warnf("Failed check sensor calibration : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	if exists {
+		return nil, fmt.Errorf("%w: a calibration effective from %s already exists",
+			utils.ErrConflict, calibration.EffectiveFrom.Format(time.RFC3339))
+	}
+
+	if err := c.SensorCalibrationRepository.Create(c.DB.WithContext(ctx), calibration); err != nil {
+		c.Log.Warnf("Failed create sensor calibration to database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	return converter.SensorCalibrationToResponse(calibration), nil
+}
+
+func (c *SensorCalibrationUseCase) FindAll(ctx context.Context, sensorID string) ([]model.SensorCalibrationResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	sensor, err := c.findSensor(c.DB.WithContext(ctx), sensorID)
+	if err != nil {
+		return nil, err
+	}
+
+	calibrations, err := c.SensorCalibrationRepository.FindAllBySensor(c.DB.WithContext(ctx), sensor.ID)
+	if err != nil {
+		c.Log.Warnf("Failed find sensor calibration from database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	responses := make([]model.SensorCalibrationResponse, len(calibrations))
+	for i, calibration := range calibrations {
+		responses[i] = *converter.SensorCalibrationToResponse(&calibration)
+	}
+
+	return responses, nil
+}
+
+// Reprocess recomputes the calibrated values of the sensor's readings in a
+// time range from their raw values and the calibration history, along with
+// their out-of-range flag, which depends on the calibrated value. Readings
+// of virtual sensors derived from it in the range are derived again.
+func (c *SensorCalibrationUseCase) Reprocess(ctx context.Context, sensorID string, request *model.ReprocessReadingsRequest) (*model.ReprocessReadingsResponse, error) {
+	// reprocessing touches every reading in the range, so it gets a longer
+	// deadline than the lookups
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	err := c.Validator.Validate.Struct(request)
+	if err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+
+			var messages []string
+			for _, e := range validationErrors {
+				messages = append(messages, e.Translate(c.Validator.Translator))
+			}
+			return nil, fmt.Errorf("%w: %s", utils.ErrValidation, strings.Join(messages, ", "))
+		}
+		return nil, fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
+	filter := &model.ReadingFilter{}
+	from, _ := time.Parse(time.RFC3339, request.From)
+	filter.From = &from
+	if request.To != "" {
+		to, _ := time.Parse(time.RFC3339, request.To)
+		if to.Before(from) {
+			return nil, fmt.Errorf("%w: to must not be before from", utils.ErrValidation)
+		}
+		filter.To = &to
+	}
+
+	sensor, err := c.findSensor(c.DB.WithContext(ctx), sensorID)
+	if err != nil {
+		return nil, err
+	}
+
+	var reprocessed, rederived int64
+	err = c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		calibrations, err := c.SensorCalibrationRepository.FindAllBySensor(tx, sensor.ID)
+		if err != nil {
+			return err
+		}
+
+		// one statement per calibration interval, starting with the readings
+		// before the first calibration, which have no calibrated value
+		for i := -1; i < len(calibrations); i++ {
+			var calibration *entity.SensorCalibration
+			var start, end *time.Time
+			if i >= 0 {
+				calibration = &calibrations[i]
+				start = &calibration.EffectiveFrom
+			}
+			if i+1 < len(calibrations) {
+				end = &calibrations[i+1].EffectiveFrom
+			}
+			if (end != nil && !end.After(from)) || (start != nil && filter.To != nil && start.After(*filter.To)) {
+				continue
+			}
+
+			updated, err := c.ReadingRepository.Recalibrate(tx, calibration, sensor.ValidMin, sensor.ValidMax,
+				repository.ByReadingFilter(sensor.ID, filter), repository.RecordedBetween(start, end))
+			if err != nil {
+				return err
+			}
+			reprocessed += updated
+		}
+		if reprocessed == 0 {
+			return nil
+		}
+
+		// the latest reading may have been recalibrated
+		if err := c.SensorLatestRepository.Refresh(tx, sensor.ID); err != nil {
+			return err
+		}
+
+		rederived, err = c.rederiveVirtualReadings(tx, sensor.ID, filter)
+		return err
+	})
+	if err != nil {
+		c.Log.Warnf("Failed reprocess readings : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	return &model.ReprocessReadingsResponse{Reprocessed: reprocessed, Rederived: rederived}, nil
+}
+
+// rederiveVirtualReadings recomputes the readings of the virtual sensors
+// downstream of a reprocessed sensor in the same range, each one after its
+// affected inputs. It returns how many readings changed.
+func (c *SensorCalibrationUseCase) rederiveVirtualReadings(tx *gorm.DB, sensorID uuid.UUID, filter *model.ReadingFilter) (int64, error) {
+	affected, order, err := findDownstream(tx, c.SensorRepository, sensorID)
+	if err != nil {
+		return 0, err
+	}
+
+	var rederived int64
+	pending := order
+	for len(pending) > 0 {
+		var next []uuid.UUID
+		for _, id := range pending {
+			sensor := affected[id]
+			if !inputsSettled(&sensor, affected) {
+				next = append(next, id)
+				continue
+			}
+			delete(affected, id)
+
+			changed, err := c.rederive(tx, &sensor, filter)
+			if err != nil {
+				return 0, err
+			}
+			rederived += changed
+		}
+		if len(next) == len(pending) {
+			c.Log.Warnf("Virtual sensors form a dependency cycle, skipped %d sensor(s)", len(next))
+			break
+		}
+		pending = next
+	}
+	return rederived, nil
+}
+
+// rederive evaluates a virtual sensor again at each of its readings in the
+// range, from the latest reading of every input at or before that time as
+// deriving it at ingest does. Readings it cannot evaluate are left as they
+// are.
+func (c *SensorCalibrationUseCase) rederive(tx *gorm.DB, sensor *entity.Sensor, filter *model.ReadingFilter) (int64, error) {
+	expression, err := utils.ParseExpression(sensor.Expression)
+	if err != nil {
+		c.Log.Warnf("Invalid expression of virtual sensor %s : %+v", sensor.ID, err)
+		return 0, nil
+	}
+
+	readings, err := c.ReadingRepository.FindSeries(tx, []uuid.UUID{sensor.ID}, filter)
+	if err != nil || len(readings) == 0 {
+		return 0, err
+	}
+
+	inputIDs := make([]uuid.UUID, len(sensor.Inputs))
+	aliases := make(map[uuid.UUID][]string, len(sensor.Inputs))
+	for i, input := range sensor.Inputs {
+		inputIDs[i] = input.InputSensorID
+		aliases[input.InputSensorID] = append(aliases[input.InputSensorID], input.Alias)
+	}
+
+	// the inputs as of the first reading, then every change until the last
+	first, last := readings[0].RecordedAt, readings[len(readings)-1].RecordedAt
+	history, err := c.ReadingRepository.FindLatestBySensorIDsAt(tx, inputIDs, first)
+	if err != nil {
+		return 0, err
+	}
+	series, err := c.ReadingRepository.FindSeries(tx, inputIDs, &model.ReadingFilter{From: &first, To: &last})
+	if err != nil {
+		return 0, err
+	}
+
+	vars := make(map[string]float64, len(sensor.Inputs))
+	set := func(input *entity.Reading) {
+		for _, alias := range aliases[input.SensorID] {
+			vars[alias] = input.EffectiveValue()
+		}
+	}
+	for i := range history {
+		set(&history[i])
+	}
+
+	changed := make(map[uuid.UUID]float64)
+	next := 0
+	for _, reading := range readings {
+		for next < len(series) && !series[next].RecordedAt.After(reading.RecordedAt) {
+			set(&series[next])
+			next++
+		}
+		if len(vars) < len(sensor.Inputs) {
+			continue
+		}
+		value, err := expression.Evaluate(vars)
+		if err != nil || value == reading.Value {
+			continue
+		}
+		changed[reading.ID] = value
+	}
+	if len(changed) == 0 {
+		return 0, nil
+	}
+
+	if err := c.ReadingRepository.UpdateValues(tx, changed); err != nil {
+		return 0, err
+	}
+	if err := c.SensorLatestRepository.Refresh(tx, sensor.ID); err != nil {
+		return 0, err
+	}
+	return int64(len(changed)), nil
+}
+
+func (c *SensorCalibrationUseCase) findSensor(db *gorm.DB, sensorID string) (*entity.Sensor, error) {
+	sensor := &entity.Sensor{}
+	_, err := c.SensorRepository.FindById(db, sensor, sensorID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Infof("Sensor not found, id=%s", sensorID)
+			return nil, utils.ErrNotFound
+		}
+		c.Log.Warnf("Failed find sensor from database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	return sensor, nil
+}
+
+// newSensorCalibration checks the coefficients of the requested method and
+// builds the entity. Coefficients of other methods are ignored.
+func newSensorCalibration(request *model.CreateSensorCalibrationRequest) (*entity.SensorCalibration, error) {
+	calibration := &entity.SensorCalibration{
+		Method:        request.Method,
+		Gain:          1,
+		EffectiveFrom: time.Now(),
+		Note:          request.Note,
+	}
+	if request.EffectiveFrom != "" {
+		calibration.EffectiveFrom, _ = time.Parse(time.RFC3339, request.EffectiveFrom)
+	}
+
+	switch request.Method {
+	case entity.CalibrationOffsetGain:
+		if request.Offset == nil && request.Gain == nil {
+			return nil, fmt.Errorf("%w: offset_gain needs an offset or a gain", utils.ErrValidation)
+		}
+		if request.Offset != nil {
+			calibration.Offset = *request.Offset
+		}
+		if request.Gain != nil {
+			if *request.Gain == 0 {
+				return nil, fmt.Errorf("%w: gain must not be zero", utils.ErrValidation)
+			}
+			calibration.Gain = *request.Gain
+		}
+
+	case entity.CalibrationPolynomial:
+		calibration.Polynomial = request.Polynomial
+
+	case entity.CalibrationLookup:
+		for i := 1; i < len(request.Table); i++ {
+			if request.Table[i][0] <= request.Table[i-1][0] {
+				return nil, fmt.Errorf("%w: lookup table raw values must be strictly increasing", utils.ErrValidation)
+			}
+		}
+		calibration.Table = request.Table
+	}
+
+	return calibration, nil
+}
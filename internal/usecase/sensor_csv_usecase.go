@@ -0,0 +1,272 @@
+package usecase
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"mertani_test/internal/entity"
+	"mertani_test/internal/event"
+	"mertani_test/internal/model"
+	"mertani_test/internal/model/converter"
+	"mertani_test/internal/repository"
+	"mertani_test/internal/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// sensorCSVColumns identify the device by name; virtual sensor expressions
+// and inputs are not part of the CSV layout.
+var sensorCSVColumns = []string{"device", "name", "type", "unit", "is_active", "labels"}
+
+// Import creates or updates sensors from CSV, matching existing sensors by
+// name. Only the columns present in the header are written on update. The
+// import is all or nothing: when any row fails, or on a dry run, the report
+// is returned without touching the database.
+func (c *SensorUseCase) Import(ctx context.Context, r io.Reader, dryRun bool) (*model.ImportResponse, error) {
+	// up to MaxImportRows rows are checked and written in one transaction
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	rows, err := utils.ReadCSV(r, sensorCSVColumns, []string{"name"}, MaxImportRows)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
+	names := make([]string, 0, len(rows))
+	deviceNames := make([]string, 0, len(rows))
+	for _, row := range rows {
+		names = append(names, row.Get("name"))
+		if row.Get("device") != "" {
+			deviceNames = append(deviceNames, row.Get("device"))
+		}
+	}
+
+	existing, err := c.SensorRepository.FindByNames(c.DB.WithContext(ctx), names)
+	if err != nil {
+		c.Log.Warnf("Failed find sensors from database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	byName := make(map[string]*entity.Sensor, len(existing))
+	for i := range existing {
+		byName[existing[i].Name] = &existing[i]
+	}
+
+	devices, err := c.DeviceRepository.FindByNames(c.DB.WithContext(ctx), deviceNames)
+	if err != nil {
+		c.Log.Warnf("Failed find devices from database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	deviceIDs := make(map[string]uuid.UUID, len(devices))
+	for _, device := range devices {
+		deviceIDs[device.Name] = device.ID
+	}
+
+	result := &model.ImportResponse{DryRun: dryRun, Rows: len(rows)}
+	sensors := make([]*entity.Sensor, 0, len(rows))
+	seen := make(map[string]int, len(rows))
+
+	for _, row := range rows {
+		sensor := &entity.Sensor{}
+		if current, ok := byName[row.Get("name")]; ok {
+			*sensor = *current
+		}
+
+		messages, err := c.importSensorRow(ctx, row, sensor, deviceIDs)
+		if err != nil {
+			return nil, err
+		}
+		if line, ok := seen[row.Get("name")]; ok && row.Get("name") != "" {
+			messages = append(messages, fmt.Sprintf("name duplicates row %d", line))
+		}
+		seen[row.Get("name")] = row.Line
+
+		if len(messages) > 0 {
+			result.Failed++
+			result.Errors = append(result.Errors, model.ImportRowError{Row: row.Line, Name: row.Get("name"), Errors: messages})
+			continue
+		}
+
+		if sensor.ID == uuid.Nil {
+			result.Created++
+		} else {
+			result.Updated++
+		}
+		sensors = append(sensors, sensor)
+	}
+
+	if dryRun || result.Failed > 0 {
+		return result, nil
+	}
+
+	err = c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, sensor := range sensors {
+			if sensor.ID == uuid.Nil {
+				if err := c.SensorRepository.Create(tx, sensor); err != nil {
+					return err
+				}
+				if err := c.Outbox.Record(tx, &event.SensorCreated{SensorResponse: *converter.SensorToResponse(sensor)}); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := c.SensorRepository.Update(tx, sensor); err != nil {
+				return err
+			}
+			if err := c.Outbox.Record(tx, sensorUpdatedEvents(sensor, byName[sensor.Name].IsActive)...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.Log.Warnf("Failed import sensors to database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	c.Outbox.Notify()
+	// sensors moved to another device leave the old one's listing too
+	c.Lookups.InvalidateSensors(ctx, sensors...)
+	for _, sensor := range sensors {
+		if current, ok := byName[sensor.Name]; ok && current.DeviceID != sensor.DeviceID {
+			c.Lookups.InvalidateDevices(ctx, current.DeviceID)
+		}
+	}
+
+	return result, nil
+}
+
+// importSensorRow overlays the row on sensor and validates the outcome with
+// the same rules as CreateSensorRequest. It returns the row's problems; the
+// error is reserved for database failures.
+func (c *SensorUseCase) importSensorRow(ctx context.Context, row utils.CSVRow, sensor *entity.Sensor, deviceIDs map[string]uuid.UUID) ([]string, error) {
+	var messages []string
+
+	isActive := sensor.IsActive
+	request := &model.CreateSensorRequest{
+		Name:     row.Get("name"),
+		Type:     sensor.Type,
+		Unit:     sensor.Unit,
+		IsActive: &isActive,
+		Labels:   sensor.Labels,
+	}
+	if sensor.DeviceID != uuid.Nil {
+		request.DeviceID = sensor.DeviceID.String()
+	}
+
+	if row.Has("device") && row.Get("device") != "" {
+		deviceID, ok := deviceIDs[row.Get("device")]
+		if !ok {
+			messages = append(messages, fmt.Sprintf("device %q not found", row.Get("device")))
+		} else {
+			request.DeviceID = deviceID.String()
+		}
+	}
+	if row.Has("type") {
+		request.Type = row.Get("type")
+	}
+	if row.Has("unit") {
+		request.Unit = row.Get("unit")
+	}
+	if row.Has("is_active") && row.Get("is_active") != "" {
+		value, err := strconv.ParseBool(row.Get("is_active"))
+		if err != nil {
+			messages = append(messages, "is_active must be true or false")
+		} else {
+			*request.IsActive = value
+		}
+	}
+	if row.Has("labels") {
+		labels, err := utils.ParseCSVLabels(row.Get("labels"))
+		if err != nil {
+			messages = append(messages, err.Error())
+		} else {
+			request.Labels = labels
+		}
+	}
+
+	if err := c.Validator.Validate.Struct(request); err != nil {
+		if _, ok := err.(validator.ValidationErrors); ok {
+			messages = append(messages, c.Validator.TranslateError(err)...)
+		} else {
+			messages = append(messages, err.Error())
+		}
+		return messages, nil
+	}
+
+	if err := utils.ValidateLabels(request.Labels); err != nil {
+		messages = append(messages, err.Error())
+	}
+
+	// an empty unit falls back to the canonical unit of the type
+	if sensor.ID == uuid.Nil || row.Has("type") || row.Has("unit") {
+		unit, err := c.resolveSensorType(c.DB.WithContext(ctx), request.Type, request.Unit)
+		if err != nil {
+			if !errors.Is(err, utils.ErrValidation) {
+				return nil, err
+			}
+			messages = append(messages, strings.TrimPrefix(err.Error(), utils.ErrValidation.Error()+": "))
+		}
+		request.Unit = unit
+	}
+
+	if len(messages) > 0 {
+		return messages, nil
+	}
+
+	sensor.DeviceID = uuid.MustParse(request.DeviceID)
+	sensor.Name = request.Name
+	sensor.Type = request.Type
+	sensor.Unit = request.Unit
+	sensor.IsActive = *request.IsActive
+	sensor.Labels = request.Labels
+	if sensor.Kind == "" {
+		sensor.Kind = entity.SensorPhysical
+	}
+	return nil, nil
+}
+
+// Export streams sensors matching the list search and filter as CSV, in the
+// column layout Import accepts. The returned function does the streaming.
+func (c *SensorUseCase) Export(ctx context.Context, search string, filter *model.SensorFilter) (func(w io.Writer) error, error) {
+	scopes := append(c.SensorRepository.Filter(filter), repository.WithDevice)
+
+	return func(w io.Writer) error {
+		// the export runs after the handler returned and may be large
+		ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+		defer cancel()
+
+		writer := csv.NewWriter(w)
+		if err := writer.Write(sensorCSVColumns); err != nil {
+			return err
+		}
+
+		err := c.SensorRepository.FindInBatches(c.DB.WithContext(ctx), search, exportBatchSize, func(sensors []entity.Sensor) error {
+			for _, sensor := range sensors {
+				record := []string{
+					sensor.Device.Name,
+					sensor.Name,
+					sensor.Type,
+					sensor.Unit,
+					strconv.FormatBool(sensor.IsActive),
+					utils.FormatCSVLabels(sensor.Labels),
+				}
+				if err := writer.Write(record); err != nil {
+					return err
+				}
+			}
+			return flushCSV(writer, w)
+		}, scopes...)
+		if err != nil {
+			c.Log.Warnf("Failed export sensors : %+v", err)
+			return err
+		}
+
+		return flushCSV(writer, w)
+	}, nil
+}
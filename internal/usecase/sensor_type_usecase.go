@@ -0,0 +1,268 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mertani_test/internal/entity"
+	"mertani_test/internal/model"
+	"mertani_test/internal/model/converter"
+	"mertani_test/internal/repository"
+	"mertani_test/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type SensorTypeUseCase struct {
+	DB                   *gorm.DB
+	Log                  *logrus.Logger
+	Validator            *utils.Validator
+	SensorTypeRepository *repository.SensorTypeRepository
+}
+
+func NewSensorTypeUseCase(db *gorm.DB, logger *logrus.Logger, validator *utils.Validator,
+	sensorTypeRepository *repository.SensorTypeRepository) *SensorTypeUseCase {
+	return &SensorTypeUseCase{
+		DB:                   db,
+		Log:                  logger,
+		Validator:            validator,
+		SensorTypeRepository: sensorTypeRepository,
+	}
+}
+
+func (c *SensorTypeUseCase) Create(ctx context.Context, request *model.CreateSensorTypeRequest) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	err := c.Validator.Validate.Struct(request)
+	if err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+
+			var messages []string
+			for _, e := range validationErrors {
+				messages = append(messages, e.Translate(c.Validator.Translator))
+			}
+			return fmt.Errorf("%w: %s", utils.ErrValidation, strings.Join(messages, ", "))
+		}
+		return fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
+	sensorType := &entity.SensorType{
+		Code:          request.Code,
+		DisplayName:   request.DisplayName,
+		CanonicalUnit: request.CanonicalUnit,
+		AllowedUnits:  normalizeAllowedUnits(request.CanonicalUnit, request.AllowedUnits),
+		PhysicalMin:   request.PhysicalMin,
+		PhysicalMax:   request.PhysicalMax,
+		Precision:     2,
+	}
+	if request.Precision != nil {
+		sensorType.Precision = *request.Precision
+	}
+
+	if err := validatePhysicalRange(sensorType); err != nil {
+		return err
+	}
+
+	exists, err := c.SensorTypeRepository.ExistsByCode(c.DB.WithContext(ctx), request.Code)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("%w: %s", utils.ErrConflict, "sensor type code already exist")
+	}
+
+	if err := c.SensorTypeRepository.Create(c.DB.WithContext(ctx), sensorType); err != nil {
+		c.Log.Warnf("Failed create sensor type to database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	return nil
+}
+
+func (c *SensorTypeUseCase) FindAll(ctx context.Context, pagination *utils.PaginationRequest) ([]model.SensorTypeResponse, *utils.PaginationResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var sensorTypes []entity.SensorType
+	total, err := c.SensorTypeRepository.FindAll(c.DB.WithContext(ctx), &sensorTypes, pagination)
+	if err != nil {
+		c.Log.Warnf("Failed find all sensor type from database : %+v", err)
+		return nil, nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	responses := make([]model.SensorTypeResponse, len(sensorTypes))
+	for i, sensorType := range sensorTypes {
+		responses[i] = *converter.SensorTypeToResponse(&sensorType)
+	}
+
+	totalPage := int((total + int64(pagination.Limit) - 1) / int64(pagination.Limit))
+
+	paginationRes := &utils.PaginationResponse{
+		Page:      pagination.Page,
+		Limit:     pagination.Limit,
+		OrderBy:   pagination.OrderBy,
+		SortBy:    pagination.SortBy,
+		Search:    pagination.Search,
+		TotalData: total,
+		TotalPage: totalPage,
+	}
+
+	return responses, paginationRes, nil
+}
+
+func (c *SensorTypeUseCase) FindByID(ctx context.Context, sensorTypeID string) (*model.SensorTypeResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	sensorType := &entity.SensorType{}
+	_, err := c.SensorTypeRepository.FindById(c.DB.WithContext(ctx), sensorType, sensorTypeID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Infof("Sensor type not found, id=%s", sensorTypeID)
+			return nil, utils.ErrNotFound
+		}
+		c.Log.Warnf("Failed find sensor type from database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	return converter.SensorTypeToResponse(sensorType), nil
+}
+
+func (c *SensorTypeUseCase) Update(ctx context.Context, sensorTypeID string, request *model.UpdateSensorTypeRequest) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	sensorType := &entity.SensorType{}
+	_, err := c.SensorTypeRepository.FindById(c.DB.WithContext(ctx), sensorType, sensorTypeID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Infof("Sensor type not found, id=%s", sensorTypeID)
+			return utils.ErrNotFound
+		}
+		c.Log.Warnf("Failed find sensor type from database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	err = c.Validator.Validate.Struct(request)
+	if err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+
+			var messages []string
+			for _, e := range validationErrors {
+				messages = append(messages, e.Translate(c.Validator.Translator))
+			}
+			return fmt.Errorf("%w: %s", utils.ErrValidation, strings.Join(messages, ", "))
+		}
+		return fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
+	if request.DisplayName != nil {
+		sensorType.DisplayName = *request.DisplayName
+	}
+	if request.CanonicalUnit != nil {
+		sensorType.CanonicalUnit = *request.CanonicalUnit
+	}
+	if request.AllowedUnits != nil {
+		sensorType.AllowedUnits = *request.AllowedUnits
+	}
+	sensorType.AllowedUnits = normalizeAllowedUnits(sensorType.CanonicalUnit, sensorType.AllowedUnits)
+	if request.PhysicalMin != nil {
+		sensorType.PhysicalMin = request.PhysicalMin
+	}
+	if request.ClearPhysicalMin {
+		sensorType.PhysicalMin = nil
+	}
+	if request.PhysicalMax != nil {
+		sensorType.PhysicalMax = request.PhysicalMax
+	}
+	if request.ClearPhysicalMax {
+		sensorType.PhysicalMax = nil
+	}
+	if request.Precision != nil {
+		sensorType.Precision = *request.Precision
+	}
+
+	if err := validatePhysicalRange(sensorType); err != nil {
+		return err
+	}
+
+	// sensors keep the unit they were created with, so it has to stay allowed
+	units, err := c.SensorTypeRepository.FindSensorUnits(c.DB.WithContext(ctx), sensorType.Code)
+	if err != nil {
+		c.Log.Warnf("Failed find sensor units of type from database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	var removed []string
+	for _, unit := range units {
+		if !sensorType.AllowedUnits.Contains(unit) {
+			removed = append(removed, unit)
+		}
+	}
+	if len(removed) > 0 {
+		return fmt.Errorf("%w: units %s are still used by sensors of this type",
+			utils.ErrConflict, strings.Join(removed, ", "))
+	}
+
+	if err := c.SensorTypeRepository.Update(c.DB.WithContext(ctx), sensorType); err != nil {
+		c.Log.Warnf("Failed update sensor type from database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	return nil
+}
+
+func (c *SensorTypeUseCase) Delete(ctx context.Context, sensorTypeID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	sensorType := &entity.SensorType{}
+	_, err := c.SensorTypeRepository.FindById(c.DB.WithContext(ctx), sensorType, sensorTypeID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Infof("Sensor type not found, id=%s", sensorTypeID)
+			return utils.ErrNotFound
+		}
+		c.Log.Warnf("Failed find sensor type from database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	inUse, err := c.SensorTypeRepository.CountSensors(c.DB.WithContext(ctx), sensorType.Code)
+	if err != nil {
+		c.Log.Warnf("Failed count sensors of type from database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	if inUse > 0 {
+		return fmt.Errorf("%w: %s", utils.ErrConflict, "sensor type is still used by sensors")
+	}
+
+	if err := c.SensorTypeRepository.Delete(c.DB.WithContext(ctx), sensorType); err != nil {
+		c.Log.Warnf("Failed delete sensor type from database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	return nil
+}
+
+// normalizeAllowedUnits makes sure the canonical unit is always allowed and
+// drops duplicates while keeping the given order.
+func normalizeAllowedUnits(canonical string, allowed []string) entity.StringList {
+	units := entity.StringList{canonical}
+	for _, unit := range allowed {
+		if !units.Contains(unit) {
+			units = append(units, unit)
+		}
+	}
+	return units
+}
+
+func validatePhysicalRange(sensorType *entity.SensorType) error {
+	if sensorType.PhysicalMin != nil && sensorType.PhysicalMax != nil && *sensorType.PhysicalMin > *sensorType.PhysicalMax {
+		return fmt.Errorf("%w: physical_min must not be greater than physical_max", utils.ErrValidation)
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"mertani_test/internal/entity"
+	"mertani_test/internal/event"
 	"mertani_test/internal/model"
 	"mertani_test/internal/model/converter"
 	"mertani_test/internal/repository"
@@ -23,15 +24,24 @@ type SensorUseCase struct {
 	Log                *logrus.Logger
 	Validator          *utils.Validator
 	SensorRepository *repository.SensorRepository
+	SensorTypeRepository *repository.SensorTypeRepository
+	DeviceRepository     *repository.DeviceRepository
+	Lookups              *LookupCache
+	Outbox               *OutboxUseCase
 }
 
 func NewSensorUseCase(db *gorm.DB, logger *logrus.Logger, validator *utils.Validator,
-	sensorRepository *repository.SensorRepository) *SensorUseCase {
+	sensorRepository *repository.SensorRepository, sensorTypeRepository *repository.SensorTypeRepository,
+	deviceRepository *repository.DeviceRepository, lookups *LookupCache, outbox *OutboxUseCase) *SensorUseCase {
 	return &SensorUseCase{
 		DB:                 db,
 		Log:                logger,
 		Validator:          validator,
 		SensorRepository: sensorRepository,
+		SensorTypeRepository: sensorTypeRepository,
+		DeviceRepository:     deviceRepository,
+		Lookups:              lookups,
+		Outbox:               outbox,
 	}
 }
 
@@ -60,33 +70,73 @@ func (c *SensorUseCase) Create(ctx context.Context, request *model.CreateSensorR
 		return fmt.Errorf("%w: %s", utils.ErrConflict, "sensor name already exist")
 	}
 
+	if err := utils.ValidateLabels(request.Labels); err != nil {
+		return fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
 	deviceUUID, err := uuid.Parse(request.DeviceID)
 	if err != nil {
 		return fmt.Errorf("%w: invalid device_id", utils.ErrValidation)
 	}
 
+	unit, err := c.resolveSensorType(c.DB.WithContext(ctx), request.Type, request.Unit)
+	if err != nil {
+		return err
+	}
+
 	sensor := &entity.Sensor{
-		DeviceID: deviceUUID,
-		Name:     request.Name,
-		Type:     request.Type,
-		Unit:     request.Unit,
-		IsActive: request.IsActive != nil && *request.IsActive,
+		DeviceID:         deviceUUID,
+		Name:             request.Name,
+		Type:             request.Type,
+		Unit:             unit,
+		IsActive:         request.IsActive != nil && *request.IsActive,
+		Labels:           request.Labels,
+		Kind:             entity.SensorPhysical,
+		ExpectedInterval: request.ExpectedInterval,
+		ValidMin:         request.ValidMin,
+		ValidMax:         request.ValidMax,
+	}
+	if err := checkValidRange(sensor); err != nil {
+		return err
 	}
 
-	if err := c.SensorRepository.Create(c.DB.WithContext(ctx), sensor); err != nil {
+	if request.Kind == entity.SensorVirtual {
+		// a sensor that does not exist yet cannot be an input of another
+		// one, so only the bindings need checking here
+		inputs, err := c.resolveVirtualInputs(c.DB.WithContext(ctx), uuid.Nil, request.Expression, request.Inputs)
+		if err != nil {
+			return err
+		}
+		sensor.Kind = entity.SensorVirtual
+		sensor.Expression = request.Expression
+		sensor.Inputs = inputs
+	} else if request.Expression != "" || len(request.Inputs) > 0 {
+		return fmt.Errorf("%w: expression and inputs are only allowed for virtual sensors", utils.ErrValidation)
+	}
+
+	err = c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := c.SensorRepository.Create(tx, sensor); err != nil {
+			return err
+		}
+		return c.Outbox.Record(tx, &event.SensorCreated{SensorResponse: *converter.SensorToResponse(sensor)})
+	})
+	if err != nil {
 		c.Log.Warnf("Failed create sensor to database : %+v", err)
 		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
 	}
+	c.Outbox.Notify()
+	c.Lookups.InvalidateSensors(ctx, sensor)
 
 	return nil
 }
 
-func (c *SensorUseCase) FindAll(ctx context.Context, pagination *utils.PaginationRequest) ([]model.SensorResponse, *utils.PaginationResponse, error) {
+func (c *SensorUseCase) FindAll(ctx context.Context, pagination *utils.PaginationRequest, filter *model.SensorFilter) ([]model.SensorResponse, *utils.PaginationResponse, error) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	var sensors []entity.Sensor
-	total, err := c.SensorRepository.FindAll(c.DB.WithContext(ctx), &sensors, pagination)
+	scopes := append(c.SensorRepository.Filter(filter), repository.WithInputs, repository.WithLatest)
+	total, err := c.SensorRepository.FindAll(c.DB.WithContext(ctx), &sensors, pagination, scopes...)
 	if err != nil {
 		c.Log.Warnf("Failed find all sensor from database : %+v", err)
 		return nil, nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
@@ -95,6 +145,13 @@ func (c *SensorUseCase) FindAll(ctx context.Context, pagination *utils.Paginatio
 	responses := make([]model.SensorResponse, len(sensors))
 	for i, sensor := range sensors {
 		responses[i] = *converter.SensorToResponse(&sensor)
+		// sensors of other dimensions keep their own unit in a mixed listing
+		if filter != nil && filter.Unit != "" {
+			if conversion, err := sensorUnitConversion(&sensor, filter.Unit); err == nil {
+				convertSensorResponse(&responses[i], conversion)
+				responses[i].EffectiveUnit = filter.Unit
+			}
+		}
 	}
 
 	totalPage := int((total + int64(pagination.Limit) - 1) / int64(pagination.Limit))
@@ -112,16 +169,13 @@ func (c *SensorUseCase) FindAll(ctx context.Context, pagination *utils.Paginatio
 	return responses, paginationRes, nil
 }
 
-func (c *SensorUseCase) FindByID(ctx context.Context, sensorID string) (*model.SensorResponse, error) {
+// FindByID returns a sensor; a non-empty unit must be convertible from the
+// sensor's unit and is reported as the effective unit.
+func (c *SensorUseCase) FindByID(ctx context.Context, sensorID string, unit string) (*model.SensorResponse, error) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 	
-	sensor := &entity.Sensor{}
-	_, err := c.SensorRepository.FindByIdWithDevice(
-		c.DB.WithContext(ctx),
-		sensor,
-		sensorID,
-	)
+	sensor, err := c.Lookups.FindSensorWithDevice(c.DB.WithContext(ctx), sensorID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			c.Log.Infof("Sensor not found, id=%s", sensorID)
@@ -130,8 +184,18 @@ func (c *SensorUseCase) FindByID(ctx context.Context, sensorID string) (*model.S
 		c.Log.Warnf("Failed find sensor from database : %+v", err)
 		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
 	}
-	
-	return converter.SensorToResponse(sensor), nil
+
+	response := converter.SensorToResponse(sensor)
+	if unit != "" {
+		conversion, err := sensorUnitConversion(sensor, unit)
+		if err != nil {
+			return nil, err
+		}
+		convertSensorResponse(response, conversion)
+		response.EffectiveUnit = unit
+	}
+
+	return response, nil
 }
 
 func (c *SensorUseCase) Update(ctx context.Context, sensorID string, request *model.UpdateSensorRequest) error {
@@ -170,11 +234,76 @@ func (c *SensorUseCase) Update(ctx context.Context, sensorID string, request *mo
 	if request.Unit != nil {
 		sensor.Unit = *request.Unit
 	}
+	wasActive := sensor.IsActive
 	if request.IsActive != nil {
 		sensor.IsActive = *request.IsActive
 	}
+	if request.ExpectedInterval != nil {
+		sensor.ExpectedInterval = *request.ExpectedInterval
+	}
+	if request.ClearValidRange {
+		sensor.ValidMin, sensor.ValidMax = nil, nil
+	}
+	if request.ValidMin != nil {
+		sensor.ValidMin = request.ValidMin
+	}
+	if request.ValidMax != nil {
+		sensor.ValidMax = request.ValidMax
+	}
+	if err := checkValidRange(sensor); err != nil {
+		return err
+	}
+	if request.Type != nil || request.Unit != nil {
+		unit := sensor.Unit
+		if request.Type != nil && request.Unit == nil {
+			unit = ""
+		}
+		sensor.Unit, err = c.resolveSensorType(c.DB.WithContext(ctx), sensor.Type, unit)
+		if err != nil {
+			return err
+		}
+	}
+
+	var inputs []entity.SensorInput
+	formulaChanged := request.Expression != nil || request.Inputs != nil
+	if formulaChanged {
+		if sensor.Kind != entity.SensorVirtual {
+			return fmt.Errorf("%w: expression and inputs are only allowed for virtual sensors", utils.ErrValidation)
+		}
+
+		if request.Expression != nil {
+			sensor.Expression = *request.Expression
+		}
+		bindings := request.Inputs
+		if bindings == nil {
+			bindings = map[string]string{}
+			existing, err := c.SensorRepository.FindInputs(c.DB.WithContext(ctx), sensor.ID)
+			if err != nil {
+				c.Log.Warnf("Failed find sensor inputs from database : %+v", err)
+				return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+			}
+			for _, input := range existing {
+				bindings[input.Alias] = input.InputSensorID.String()
+			}
+		}
+
+		inputs, err = c.resolveVirtualInputs(c.DB.WithContext(ctx), sensor.ID, sensor.Expression, bindings)
+		if err != nil {
+			return err
+		}
+	}
 
-	err = c.SensorRepository.Update(c.DB.WithContext(ctx), sensor)
+	err = c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := c.SensorRepository.Update(tx, sensor); err != nil {
+			return err
+		}
+		if formulaChanged {
+			if err := c.SensorRepository.ReplaceInputs(tx, sensor.ID, inputs); err != nil {
+				return err
+			}
+		}
+		return c.Outbox.Record(tx, sensorUpdatedEvents(sensor, wasActive)...)
+	})
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			c.Log.Infof("Sensor not found, id=%s", sensorID)
@@ -183,6 +312,8 @@ func (c *SensorUseCase) Update(ctx context.Context, sensorID string, request *mo
 		c.Log.Warnf("Failed update sensor from database : %+v", err)
 		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
 	}
+	c.Outbox.Notify()
+	c.Lookups.InvalidateSensors(ctx, sensor)
 
 	return nil
 }
@@ -202,7 +333,21 @@ func (c *SensorUseCase) Delete(ctx context.Context, sensorID string) error {
 		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
 	}
 
-	err = c.SensorRepository.Delete(c.DB.WithContext(ctx), sensor)
+	dependents, err := c.SensorRepository.CountDependents(c.DB.WithContext(ctx), sensor.ID)
+	if err != nil {
+		c.Log.Warnf("Failed count sensor dependents from database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	if dependents > 0 {
+		return fmt.Errorf("%w: sensor is an input of %d virtual sensor(s)", utils.ErrConflict, dependents)
+	}
+
+	err = c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := c.SensorRepository.Delete(tx, sensor); err != nil {
+			return err
+		}
+		return c.Outbox.Record(tx, &event.SensorDeleted{SensorResponse: *converter.SensorToResponse(sensor)})
+	})
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			c.Log.Infof("Sensor not found, id=%s", sensorID)
@@ -211,6 +356,225 @@ func (c *SensorUseCase) Delete(ctx context.Context, sensorID string) error {
 		c.Log.Warnf("Failed delete sensor from database : %+v", err)
 		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
 	}
+	c.Outbox.Notify()
+	c.Lookups.InvalidateSensors(ctx, sensor)
+
+	return nil
+}
+
+func (c *SensorUseCase) SetLabels(ctx context.Context, sensorID string, request *model.UpdateLabelsRequest) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	err := c.Validator.Validate.Struct(request)
+	if err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+
+			var messages []string
+			for _, e := range validationErrors {
+				messages = append(messages, e.Translate(c.Validator.Translator))
+			}
+			return fmt.Errorf("%w: %s", utils.ErrValidation, strings.Join(messages, ", "))
+		}
+		return fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
+	if err := utils.ValidateLabels(request.Labels); err != nil {
+		return fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
+	sensor := &entity.Sensor{}
+	_, err = c.SensorRepository.FindById(c.DB.WithContext(ctx), sensor, sensorID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Infof("Sensor not found, id=%s", sensorID)
+			return utils.ErrNotFound
+		}
+		c.Log.Warnf("Failed find sensor from database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	if sensor.Labels == nil {
+		sensor.Labels = entity.Labels{}
+	}
+	for key, value := range request.Labels {
+		sensor.Labels[key] = value
+	}
 
+	err = c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := c.SensorRepository.Update(tx, sensor); err != nil {
+			return err
+		}
+		return c.Outbox.Record(tx, sensorUpdatedEvents(sensor, sensor.IsActive)...)
+	})
+	if err != nil {
+		c.Log.Warnf("Failed update sensor labels to database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	c.Outbox.Notify()
+	c.Lookups.InvalidateSensors(ctx, sensor)
+
+	return nil
+}
+
+func (c *SensorUseCase) RemoveLabel(ctx context.Context, sensorID string, key string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	sensor := &entity.Sensor{}
+	_, err := c.SensorRepository.FindById(c.DB.WithContext(ctx), sensor, sensorID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Infof("Sensor not found, id=%s", sensorID)
+			return utils.ErrNotFound
+		}
+		c.Log.Warnf("Failed find sensor from database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	if _, exists := sensor.Labels[key]; !exists {
+		return fmt.Errorf("%w: label %s", utils.ErrNotFound, key)
+	}
+	delete(sensor.Labels, key)
+
+	err = c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := c.SensorRepository.Update(tx, sensor); err != nil {
+			return err
+		}
+		return c.Outbox.Record(tx, sensorUpdatedEvents(sensor, sensor.IsActive)...)
+	})
+	if err != nil {
+		c.Log.Warnf("Failed update sensor labels to database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	c.Outbox.Notify()
+	c.Lookups.InvalidateSensors(ctx, sensor)
+
+	return nil
+}
+
+// sensorUpdatedEvents describes an updated sensor, adding its activation or
+// deactivation when is_active differs from wasActive.
+func sensorUpdatedEvents(sensor *entity.Sensor, wasActive bool) []event.Event {
+	events := []event.Event{&event.SensorUpdated{SensorResponse: *converter.SensorToResponse(sensor)}}
+	if sensor.IsActive == wasActive {
+		return events
+	}
+
+	state := event.SensorState{
+		SensorID: sensor.ID.String(),
+		DeviceID: sensor.DeviceID.String(),
+		Name:     sensor.Name,
+		Labels:   sensor.Labels,
+	}
+	if sensor.IsActive {
+		return append(events, &event.SensorActivated{SensorState: state})
+	}
+	return append(events, &event.SensorDeactivated{SensorState: state})
+}
+
+// resolveSensorType checks the type code against the sensor type registry
+// and returns the unit to store, defaulting to the canonical unit.
+func (c *SensorUseCase) resolveSensorType(db *gorm.DB, code string, unit string) (string, error) {
+	sensorType, err := c.SensorTypeRepository.FindByCode(db, &entity.SensorType{}, code)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", fmt.Errorf("%w: unknown sensor type %q", utils.ErrValidation, code)
+		}
+		c.Log.Warnf("Failed find sensor type from database : %+v", err)
+		return "", fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	if unit == "" {
+		return sensorType.CanonicalUnit, nil
+	}
+	if !sensorType.AllowedUnits.Contains(unit) {
+		return "", fmt.Errorf("%w: unit %q is not allowed for sensor type %q, allowed units: %s",
+			utils.ErrValidation, unit, code, strings.Join(sensorType.AllowedUnits, ", "))
+	}
+	return unit, nil
+}
+
+// resolveVirtualInputs validates a virtual sensor's expression against its
+// input bindings and rejects bindings that would make the sensor depend on
+// itself, directly or through other virtual sensors.
+func (c *SensorUseCase) resolveVirtualInputs(db *gorm.DB, sensorID uuid.UUID, source string, bindings map[string]string) ([]entity.SensorInput, error) {
+	expression, err := utils.ParseExpression(source)
+	if err != nil {
+		return nil, fmt.Errorf("%w: expression: %s", utils.ErrValidation, err.Error())
+	}
+
+	for _, variable := range expression.Variables() {
+		if _, ok := bindings[variable]; !ok {
+			return nil, fmt.Errorf("%w: expression variable %q has no input sensor", utils.ErrValidation, variable)
+		}
+	}
+
+	inputs := make([]entity.SensorInput, 0, len(bindings))
+	inputIDs := make([]uuid.UUID, 0, len(bindings))
+	seen := map[uuid.UUID]bool{}
+	for alias, id := range bindings {
+		if !entity.StringList(expression.Variables()).Contains(alias) {
+			return nil, fmt.Errorf("%w: input %q is not used by the expression", utils.ErrValidation, alias)
+		}
+		inputID, err := uuid.Parse(id)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid input sensor id for %q", utils.ErrValidation, alias)
+		}
+		if inputID == sensorID {
+			return nil, fmt.Errorf("%w: a sensor cannot be its own input", utils.ErrValidation)
+		}
+		inputs = append(inputs, entity.SensorInput{Alias: alias, InputSensorID: inputID})
+		if !seen[inputID] {
+			seen[inputID] = true
+			inputIDs = append(inputIDs, inputID)
+		}
+	}
+
+	count, err := c.SensorRepository.CountByIds(db, inputIDs)
+	if err != nil {
+		c.Log.Warnf("Failed find input sensors from database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	if count != int64(len(inputIDs)) {
+		return nil, fmt.Errorf("%w: input sensor not found", utils.ErrValidation)
+	}
+
+	if sensorID == uuid.Nil {
+		return inputs, nil
+	}
+
+	// walk the inputs' own inputs; reaching sensorID closes a cycle
+	visited := map[uuid.UUID]bool{}
+	frontier := inputIDs
+	for len(frontier) > 0 {
+		for _, id := range frontier {
+			visited[id] = true
+		}
+		upstream, err := c.SensorRepository.FindInputIDs(db, frontier)
+		if err != nil {
+			c.Log.Warnf("Failed find sensor inputs from database : %+v", err)
+			return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+		}
+
+		frontier = frontier[:0:0]
+		for _, id := range upstream {
+			if id == sensorID {
+				return nil, fmt.Errorf("%w: inputs create a dependency cycle", utils.ErrValidation)
+			}
+			if !visited[id] {
+				frontier = append(frontier, id)
+			}
+		}
+	}
+
+	return inputs, nil
+}
+
+// checkValidRange rejects an operating range whose bounds are reversed.
+func checkValidRange(sensor *entity.Sensor) error {
+	if sensor.ValidMin != nil && sensor.ValidMax != nil && *sensor.ValidMin > *sensor.ValidMax {
+		return fmt.Errorf("%w: valid_min must not be greater than valid_max", utils.ErrValidation)
+	}
 	return nil
 }
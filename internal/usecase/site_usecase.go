@@ -0,0 +1,495 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mertani_test/internal/entity"
+	"mertani_test/internal/event"
+	"mertani_test/internal/model"
+	"mertani_test/internal/model/converter"
+	"mertani_test/internal/repository"
+	"mertani_test/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type SiteUseCase struct {
+	DB               *gorm.DB
+	Log              *logrus.Logger
+	Validator        *utils.Validator
+	SiteRepository   *repository.SiteRepository
+	DeviceRepository *repository.DeviceRepository
+	Lookups          *LookupCache
+	Outbox           *OutboxUseCase
+}
+
+func NewSiteUseCase(db *gorm.DB, logger *logrus.Logger, validator *utils.Validator,
+	siteRepository *repository.SiteRepository, deviceRepository *repository.DeviceRepository,
+	lookups *LookupCache, outbox *OutboxUseCase) *SiteUseCase {
+	return &SiteUseCase{
+		DB:               db,
+		Log:              logger,
+		Validator:        validator,
+		SiteRepository:   siteRepository,
+		DeviceRepository: deviceRepository,
+		Lookups:          lookups,
+		Outbox:           outbox,
+	}
+}
+
+func (c *SiteUseCase) Create(ctx context.Context, request *model.CreateSiteRequest) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	err := c.Validator.Validate.Struct(request)
+	if err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+
+			var messages []string
+			for _, e := range validationErrors {
+				messages = append(messages, e.Translate(c.Validator.Translator))
+			}
+			return fmt.Errorf("%w: %s", utils.ErrValidation, strings.Join(messages, ", "))
+		}
+		return fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
+	site := &entity.Site{
+		Name: request.Name,
+		Kind: request.Kind,
+	}
+
+	if request.ParentID != "" {
+		parentID, err := c.findSiteID(c.DB.WithContext(ctx), request.ParentID)
+		if err != nil {
+			return err
+		}
+		site.ParentID = &parentID
+	}
+
+	if err := c.SiteRepository.Create(c.DB.WithContext(ctx), site); err != nil {
+		c.Log.Warnf("Failed create site to database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	return nil
+}
+
+func (c *SiteUseCase) FindAll(ctx context.Context, pagination *utils.PaginationRequest) ([]model.SiteResponse, *utils.PaginationResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var sites []entity.Site
+	total, err := c.SiteRepository.FindAll(c.DB.WithContext(ctx), &sites, pagination)
+	if err != nil {
+		c.Log.Warnf("Failed find all site from database : %+v", err)
+		return nil, nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	responses := make([]model.SiteResponse, len(sites))
+	for i, site := range sites {
+		responses[i] = *converter.SiteToResponse(&site)
+	}
+
+	totalPage := int((total + int64(pagination.Limit) - 1) / int64(pagination.Limit))
+
+	paginationRes := &utils.PaginationResponse{
+		Page:      pagination.Page,
+		Limit:     pagination.Limit,
+		OrderBy:   pagination.OrderBy,
+		SortBy:    pagination.SortBy,
+		Search:    pagination.Search,
+		TotalData: total,
+		TotalPage: totalPage,
+	}
+
+	return responses, paginationRes, nil
+}
+
+func (c *SiteUseCase) FindByID(ctx context.Context, siteID string) (*model.SiteResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	site := &entity.Site{}
+	_, err := c.SiteRepository.FindByIdWithChildren(c.DB.WithContext(ctx), site, siteID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Infof("Site not found, id=%s", siteID)
+			return nil, utils.ErrNotFound
+		}
+		c.Log.Warnf("Failed find site from database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	return converter.SiteToResponse(site), nil
+}
+
+func (c *SiteUseCase) Update(ctx context.Context, siteID string, request *model.UpdateSiteRequest) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	site := &entity.Site{}
+	_, err := c.SiteRepository.FindById(c.DB.WithContext(ctx), site, siteID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Infof("Site not found, id=%s", siteID)
+			return utils.ErrNotFound
+		}
+		c.Log.Warnf("Failed find site from database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	err = c.Validator.Validate.Struct(request)
+	if err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+
+			var messages []string
+			for _, e := range validationErrors {
+				messages = append(messages, e.Translate(c.Validator.Translator))
+			}
+			return fmt.Errorf("%w: %s", utils.ErrValidation, strings.Join(messages, ", "))
+		}
+		return fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
+	if request.Name != nil {
+		site.Name = *request.Name
+	}
+
+	if request.Kind != nil {
+		site.Kind = *request.Kind
+	}
+
+	if request.ParentID != nil {
+		if *request.ParentID == "" {
+			site.ParentID = nil
+		} else {
+			parentID, err := c.findSiteID(c.DB.WithContext(ctx), *request.ParentID)
+			if err != nil {
+				return err
+			}
+
+			subtree, err := c.SiteRepository.FindSubtreeIDs(c.DB.WithContext(ctx), site.ID)
+			if err != nil {
+				c.Log.Warnf("Failed find site subtree from database : %+v", err)
+				return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+			}
+			for _, id := range subtree {
+				if id == parentID {
+					return fmt.Errorf("%w: a site cannot be moved under itself or its descendants", utils.ErrValidation)
+				}
+			}
+			site.ParentID = &parentID
+		}
+	}
+
+	if err := c.SiteRepository.Update(c.DB.WithContext(ctx), site); err != nil {
+		c.Log.Warnf("Failed update site from database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	return nil
+}
+
+func (c *SiteUseCase) Delete(ctx context.Context, siteID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	site := &entity.Site{}
+	_, err := c.SiteRepository.FindById(c.DB.WithContext(ctx), site, siteID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Infof("Site not found, id=%s", siteID)
+			return utils.ErrNotFound
+		}
+		c.Log.Warnf("Failed find site from database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	children, err := c.SiteRepository.CountChildren(c.DB.WithContext(ctx), site.ID)
+	if err != nil {
+		c.Log.Warnf("Failed count site children from database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	if children > 0 {
+		return fmt.Errorf("%w: %s", utils.ErrConflict, "site still has child sites")
+	}
+
+	// its devices are detached by the database
+	var deviceIDs []uuid.UUID
+	err = c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if deviceIDs, err = c.SiteRepository.FindDeviceIDs(tx, []uuid.UUID{site.ID}); err != nil {
+			return err
+		}
+		if err := c.SiteRepository.Delete(tx, site); err != nil {
+			return err
+		}
+		return c.recordDevicesUpdated(tx, deviceIDs)
+	})
+	if err != nil {
+		c.Log.Warnf("Failed delete site from database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	c.Outbox.Notify()
+	c.Lookups.InvalidateDevices(ctx, deviceIDs...)
+
+	return nil
+}
+
+// MoveDevices attaches the given devices to the site, detaching them from
+// whichever node they belonged to before.
+func (c *SiteUseCase) MoveDevices(ctx context.Context, siteID string, request *model.MoveDevicesRequest) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	err := c.Validator.Validate.Struct(request)
+	if err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+
+			var messages []string
+			for _, e := range validationErrors {
+				messages = append(messages, e.Translate(c.Validator.Translator))
+			}
+			return fmt.Errorf("%w: %s", utils.ErrValidation, strings.Join(messages, ", "))
+		}
+		return fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
+	id, err := c.findSiteID(c.DB.WithContext(ctx), siteID)
+	if err != nil {
+		if errors.Is(err, utils.ErrValidation) {
+			return utils.ErrNotFound
+		}
+		return err
+	}
+
+	deviceIDs := parseDeviceIDs(request.DeviceIDs)
+
+	err = c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		moved, err := c.SiteRepository.MoveDevices(tx, id, deviceIDs)
+		if err != nil {
+			return err
+		}
+		if moved != int64(len(deviceIDs)) {
+			return utils.ErrNotFound
+		}
+		return c.recordDevicesUpdated(tx, deviceIDs)
+	})
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return fmt.Errorf("%w: one or more devices", utils.ErrNotFound)
+		}
+		c.Log.Warnf("Failed move devices to site : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	c.Outbox.Notify()
+	c.Lookups.InvalidateDevices(ctx, deviceIDs...)
+
+	return nil
+}
+
+// RemoveDevices detaches the given devices from the site, leaving them
+// without one. Every device must belong to the site.
+func (c *SiteUseCase) RemoveDevices(ctx context.Context, siteID string, request *model.MoveDevicesRequest) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	err := c.Validator.Validate.Struct(request)
+	if err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+
+			var messages []string
+			for _, e := range validationErrors {
+				messages = append(messages, e.Translate(c.Validator.Translator))
+			}
+			return fmt.Errorf("%w: %s", utils.ErrValidation, strings.Join(messages, ", "))
+		}
+		return fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
+	id, err := c.findSiteID(c.DB.WithContext(ctx), siteID)
+	if err != nil {
+		if errors.Is(err, utils.ErrValidation) {
+			return utils.ErrNotFound
+		}
+		return err
+	}
+
+	deviceIDs := parseDeviceIDs(request.DeviceIDs)
+
+	err = c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		detached, err := c.SiteRepository.DetachDevices(tx, id, deviceIDs)
+		if err != nil {
+			return err
+		}
+		if detached != int64(len(deviceIDs)) {
+			return utils.ErrNotFound
+		}
+		return c.recordDevicesUpdated(tx, deviceIDs)
+	})
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return fmt.Errorf("%w: one or more devices in this site", utils.ErrNotFound)
+		}
+		c.Log.Warnf("Failed detach devices from site : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	c.Outbox.Notify()
+	c.Lookups.InvalidateDevices(ctx, deviceIDs...)
+
+	return nil
+}
+
+func (c *SiteUseCase) FindDevices(ctx context.Context, siteID string, recursive bool, pagination *utils.PaginationRequest) ([]model.DeviceResponse, *utils.PaginationResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	siteIDs, err := c.resolveSiteIDs(c.DB.WithContext(ctx), siteID, recursive)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var devices []entity.Device
+	total, err := c.DeviceRepository.FindAll(c.DB.WithContext(ctx), &devices, pagination,
+		c.DeviceRepository.Filter(&model.DeviceFilter{SiteIDs: siteIDs})...)
+	if err != nil {
+		c.Log.Warnf("Failed find site devices from database : %+v", err)
+		return nil, nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	responses := make([]model.DeviceResponse, len(devices))
+	for i, device := range devices {
+		responses[i] = *converter.DeviceToResponse(&device)
+	}
+
+	totalPage := int((total + int64(pagination.Limit) - 1) / int64(pagination.Limit))
+
+	paginationRes := &utils.PaginationResponse{
+		Page:      pagination.Page,
+		Limit:     pagination.Limit,
+		OrderBy:   pagination.OrderBy,
+		SortBy:    pagination.SortBy,
+		Search:    pagination.Search,
+		TotalData: total,
+		TotalPage: totalPage,
+	}
+
+	return responses, paginationRes, nil
+}
+
+func (c *SiteUseCase) Stats(ctx context.Context, siteID string, filter *model.SiteStatsFilter) (*model.SiteStatsResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if filter.From != nil && filter.To != nil && filter.From.After(*filter.To) {
+		return nil, fmt.Errorf("%w: from must be before to", utils.ErrValidation)
+	}
+
+	siteIDs, err := c.resolveSiteIDs(c.DB.WithContext(ctx), siteID, filter.Recursive)
+	if err != nil {
+		return nil, err
+	}
+
+	deviceCount, err := c.SiteRepository.CountDevices(c.DB.WithContext(ctx), siteIDs)
+	if err != nil {
+		c.Log.Warnf("Failed count site devices from database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	sensorTypes, err := c.SiteRepository.SensorTypeStats(c.DB.WithContext(ctx), siteIDs, filter.From, filter.To)
+	if err != nil {
+		c.Log.Warnf("Failed aggregate site sensor stats from database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	response := &model.SiteStatsResponse{
+		SiteID:      siteID,
+		Recursive:   filter.Recursive,
+		SiteCount:   len(siteIDs),
+		DeviceCount: deviceCount,
+		SensorTypes: sensorTypes,
+	}
+	if response.SensorTypes == nil {
+		response.SensorTypes = []model.SensorTypeStatsResponse{}
+	}
+	for _, stats := range sensorTypes {
+		response.SensorCount += stats.SensorCount
+		response.ActiveSensorCount += stats.ActiveSensorCount
+	}
+
+	return response, nil
+}
+
+func (c *SiteUseCase) findSiteID(db *gorm.DB, siteID string) (uuid.UUID, error) {
+	id, err := uuid.Parse(siteID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: invalid site id", utils.ErrValidation)
+	}
+
+	total, err := c.SiteRepository.CountById(db, id)
+	if err != nil {
+		c.Log.Warnf("Failed find site from database : %+v", err)
+		return uuid.Nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	if total == 0 {
+		return uuid.Nil, fmt.Errorf("%w: site %s does not exist", utils.ErrValidation, siteID)
+	}
+	return id, nil
+}
+
+func (c *SiteUseCase) resolveSiteIDs(db *gorm.DB, siteID string, recursive bool) ([]uuid.UUID, error) {
+	id, err := c.findSiteID(db, siteID)
+	if err != nil {
+		if errors.Is(err, utils.ErrValidation) {
+			c.Log.Infof("Site not found, id=%s", siteID)
+			return nil, utils.ErrNotFound
+		}
+		return nil, err
+	}
+
+	if !recursive {
+		return []uuid.UUID{id}, nil
+	}
+
+	ids, err := c.SiteRepository.FindSubtreeIDs(db, id)
+	if err != nil {
+		c.Log.Warnf("Failed find site subtree from database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	return ids, nil
+}
+
+// recordDevicesUpdated records a DeviceUpdated event for each of the given
+// devices as tx left them, after their site changed.
+func (c *SiteUseCase) recordDevicesUpdated(tx *gorm.DB, deviceIDs []uuid.UUID) error {
+	devices, err := c.DeviceRepository.FindByIds(tx, deviceIDs)
+	if err != nil {
+		return err
+	}
+	events := make([]event.Event, 0, len(devices))
+	for i := range devices {
+		events = append(events, &event.DeviceUpdated{DeviceResponse: *converter.DeviceToResponse(&devices[i])})
+	}
+	return c.Outbox.Record(tx, events...)
+}
+
+// parseDeviceIDs parses validated device ids, dropping repeated ones so
+// they can be compared with the rows a statement affected.
+func parseDeviceIDs(values []string) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(values))
+	ids := make([]uuid.UUID, 0, len(values))
+	for _, value := range values {
+		id := uuid.MustParse(value)
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"mertani_test/internal/event"
+	"mertani_test/internal/model"
+	"mertani_test/internal/model/converter"
+	"mertani_test/internal/stream"
+	"mertani_test/internal/utils"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+)
+
+// StreamUseCase subscribes live clients to the hub that ingest and device
+// updates publish to.
+type StreamUseCase struct {
+	Log       *logrus.Logger
+	Validator *utils.Validator
+	Hub       *stream.Hub
+}
+
+func NewStreamUseCase(logger *logrus.Logger, validator *utils.Validator, hub *stream.Hub) *StreamUseCase {
+	return &StreamUseCase{
+		Log:       logger,
+		Validator: validator,
+		Hub:       hub,
+	}
+}
+
+func (c *StreamUseCase) Subscribe(request *model.StreamSubscribeRequest) (*stream.Subscriber, error) {
+	filter, err := c.Filter(request)
+	if err != nil {
+		return nil, err
+	}
+	return c.Hub.Subscribe(filter), nil
+}
+
+func (c *StreamUseCase) Unsubscribe(subscriber *stream.Subscriber) {
+	c.Hub.Unsubscribe(subscriber)
+}
+
+// Filter checks a subscription request and builds the hub filter for it.
+func (c *StreamUseCase) Filter(request *model.StreamSubscribeRequest) (*stream.Filter, error) {
+	err := c.Validator.Validate.Struct(request)
+	if err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+
+			var messages []string
+			for _, e := range validationErrors {
+				messages = append(messages, e.Translate(c.Validator.Translator))
+			}
+			return nil, fmt.Errorf("%w: %s", utils.ErrValidation, strings.Join(messages, ", "))
+		}
+		return nil, fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
+	selector, err := utils.ParseLabelSelector(request.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
+	return &stream.Filter{
+		Types:     stringSet(request.Types),
+		DeviceIDs: stringSet(request.DeviceIDs),
+		SensorIDs: stringSet(request.SensorIDs),
+		Selector:  selector,
+	}, nil
+}
+
+// HandleEvent forwards a domain event relayed from the outbox to live
+// subscribers. Its payload is sent as is.
+func (c *StreamUseCase) HandleEvent(ctx context.Context, envelope event.Envelope) error {
+	c.Hub.Publish(stream.Event{
+		Type:     envelope.Type,
+		DeviceID: envelope.DeviceID,
+		SensorID: envelope.SensorID,
+		Time:     envelope.OccurredAt,
+		Data:     envelope.Payload,
+		Labels:   envelope.Labels,
+	})
+	return nil
+}
+
+func stringSet(values []string) map[string]bool {
+	if len(values) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[strings.ToLower(value)] = true
+	}
+	return set
+}
+
+// publishReadings announces readings once the transaction that stored them
+// has committed. A nil hub disables publishing.
+func publishReadings(hub *stream.Hub, readings []storedReading) {
+	if hub == nil {
+		return
+	}
+	for _, stored := range readings {
+		response := converter.ReadingToResponse(&stored.reading)
+		response.Unit = stored.sensor.Unit
+		hub.Publish(stream.Event{
+			Type:     stream.EventReading,
+			DeviceID: stored.sensor.DeviceID.String(),
+			SensorID: stored.sensor.ID.String(),
+			Data:     response,
+			Labels:   stored.sensor.Labels,
+		})
+	}
+}
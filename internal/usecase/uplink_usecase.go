@@ -0,0 +1,165 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mertani_test/internal/entity"
+	"mertani_test/internal/model"
+	"mertani_test/internal/repository"
+	"mertani_test/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// UplinkChannelLabel maps a decoded measurement to a sensor whose name
+// differs from the measurement name.
+const UplinkChannelLabel = "channel"
+
+type UplinkUseCase struct {
+	DB                       *gorm.DB
+	Log                      *logrus.Logger
+	Validator                *utils.Validator
+	DeviceRepository         *repository.DeviceRepository
+	PayloadDecoderRepository *repository.PayloadDecoderRepository
+	ReadingUseCase           *ReadingUseCase
+}
+
+func NewUplinkUseCase(db *gorm.DB, logger *logrus.Logger, validator *utils.Validator,
+	deviceRepository *repository.DeviceRepository, payloadDecoderRepository *repository.PayloadDecoderRepository,
+	readingUseCase *ReadingUseCase) *UplinkUseCase {
+	return &UplinkUseCase{
+		DB:                       db,
+		Log:                      logger,
+		Validator:                validator,
+		DeviceRepository:         deviceRepository,
+		PayloadDecoderRepository: payloadDecoderRepository,
+		ReadingUseCase:           readingUseCase,
+	}
+}
+
+// Uplink decodes a raw payload with the decoder of the device's hardware
+// model and stores each measurement as a reading of the matching sensor. A
+// sensor matches when its channel label, or else its name, equals the
+// measurement name. Unmatched or rejected measurements are reported, not
+// stored.
+func (c *UplinkUseCase) Uplink(ctx context.Context, deviceID string, request *model.UplinkRequest) (*model.UplinkResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	err := c.Validator.Validate.Struct(request)
+	if err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+
+			var messages []string
+			for _, e := range validationErrors {
+				messages = append(messages, e.Translate(c.Validator.Translator))
+			}
+			return nil, fmt.Errorf("%w: %s", utils.ErrValidation, strings.Join(messages, ", "))
+		}
+		return nil, fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
+	device := &entity.Device{}
+	_, err = c.DeviceRepository.FindByIdWithSensors(c.DB.WithContext(ctx), device, deviceID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Infof("Device not found, id=%s", deviceID)
+			return nil, utils.ErrNotFound
+		}
+		c.Log.Warnf("Failed find device from database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	if device.HardwareModel == "" {
+		return nil, fmt.Errorf("%w: device has no hardware model to pick a decoder", utils.ErrValidation)
+	}
+	payloadDecoder, err := c.PayloadDecoderRepository.FindByHardwareModel(c.DB.WithContext(ctx), &entity.PayloadDecoder{}, device.HardwareModel)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: no payload decoder for hardware model %q", utils.ErrValidation, device.HardwareModel)
+		}
+		c.Log.Warnf("Failed find payload decoder from database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	d, err := resolvePayloadDecoder(payloadDecoder.Decoder, payloadDecoder.Layout)
+	if err != nil {
+		return nil, err
+	}
+	measurements, err := decodePayload(d, request.Payload, request.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	receivedAt := time.Now()
+	if request.ReceivedAt != "" {
+		receivedAt, _ = time.Parse(time.RFC3339, request.ReceivedAt)
+	}
+
+	sensors := uplinkSensors(device.Sensors)
+	response := &model.UplinkResponse{
+		DeviceID:     device.ID.String(),
+		Decoder:      payloadDecoder.Decoder,
+		Measurements: make([]model.UplinkMeasurementResponse, len(measurements)),
+	}
+
+	var stored []storedReading
+	err = c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, measurement := range measurements {
+			result := &response.Measurements[i]
+			result.Name = measurement.Name
+			result.Value = measurement.Value
+
+			sensor, ok := sensors[measurement.Name]
+			if !ok {
+				result.Error = "no matching sensor"
+				continue
+			}
+			result.SensorID = sensor.ID.String()
+
+			if _, err := c.ReadingUseCase.store(tx, &stored, sensor, measurement.Value, receivedAt); err != nil {
+				if errors.Is(err, utils.ErrValidation) {
+					result.Error = err.Error()
+					continue
+				}
+				return err
+			}
+			result.Stored = true
+		}
+		return nil
+	})
+	if err != nil {
+		c.Log.Warnf("Failed store uplink readings : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	c.ReadingUseCase.committed(stored)
+
+	return response, nil
+}
+
+// uplinkSensors indexes the physical sensors of a device by channel label,
+// falling back to the sensor name.
+func uplinkSensors(sensors []entity.Sensor) map[string]*entity.Sensor {
+	index := make(map[string]*entity.Sensor, len(sensors))
+	for i := range sensors {
+		sensor := &sensors[i]
+		if sensor.Kind == entity.SensorVirtual {
+			continue
+		}
+		if _, taken := index[sensor.Name]; !taken {
+			index[sensor.Name] = sensor
+		}
+	}
+	for i := range sensors {
+		sensor := &sensors[i]
+		if channel, ok := sensor.Labels[UplinkChannelLabel]; ok && sensor.Kind != entity.SensorVirtual {
+			index[channel] = sensor
+		}
+	}
+	return index
+}
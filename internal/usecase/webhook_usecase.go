@@ -0,0 +1,610 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mertani_test/internal/entity"
+	"mertani_test/internal/event"
+	"mertani_test/internal/model"
+	"mertani_test/internal/model/converter"
+	"mertani_test/internal/repository"
+	"mertani_test/internal/stream"
+	"mertani_test/internal/utils"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	// webhookDispatchBuffer is the number of hub events the dispatcher can
+	// fall behind by before events are dropped.
+	webhookDispatchBuffer = 4096
+	// webhookPollInterval is how often idle workers look for retries that
+	// became due; new deliveries wake a worker right away.
+	webhookPollInterval = 5 * time.Second
+	// webhookRefreshInterval is how often the subscribed webhooks are
+	// reloaded, picking up changes made by other instances.
+	webhookRefreshInterval = 30 * time.Second
+	// webhookBackoffBase doubles after every failed attempt, up to
+	// webhookBackoffMax.
+	webhookBackoffBase = 30 * time.Second
+	webhookBackoffMax  = 6 * time.Hour
+	// webhookLogLimit bounds the response body and error kept per attempt.
+	webhookLogLimit = 500
+)
+
+// domainEventTypes are relayed from the outbox rather than the hub.
+var domainEventTypes = func() map[string]bool {
+	types := make(map[string]bool, len(event.Types))
+	for _, t := range event.Types {
+		types[t] = true
+	}
+	return types
+}()
+
+// WebhookUseCase manages webhook subscriptions and delivers hub events and
+// domain events to them. Every event is stored as one delivery per
+// subscribed webhook and sent by a pool of workers, which retry failures
+// with exponential backoff up to MaxAttempts.
+type WebhookUseCase struct {
+	DB                        *gorm.DB
+	Log                       *logrus.Logger
+	Validator                 *utils.Validator
+	WebhookRepository         *repository.WebhookRepository
+	WebhookDeliveryRepository *repository.WebhookDeliveryRepository
+	Hub                       *stream.Hub
+	Client                    *http.Client
+	Workers                   int
+	MaxAttempts               int
+	webhooks                  atomic.Pointer[[]entity.Webhook]
+	wake                      chan struct{}
+}
+
+func NewWebhookUseCase(db *gorm.DB, logger *logrus.Logger, validator *utils.Validator,
+	webhookRepository *repository.WebhookRepository, webhookDeliveryRepository *repository.WebhookDeliveryRepository,
+	hub *stream.Hub, workers int, maxAttempts int, timeout time.Duration) *WebhookUseCase {
+	if workers <= 0 {
+		workers = 4
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = 8
+	}
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &WebhookUseCase{
+		DB:                        db,
+		Log:                       logger,
+		Validator:                 validator,
+		WebhookRepository:         webhookRepository,
+		WebhookDeliveryRepository: webhookDeliveryRepository,
+		Hub:                       hub,
+		Client: &http.Client{
+			Timeout: timeout,
+			// a redirect is reported as the receiver's answer, not followed
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		Workers:     workers,
+		MaxAttempts: maxAttempts,
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+func (c *WebhookUseCase) Create(ctx context.Context, request *model.CreateWebhookRequest) (*model.WebhookResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	err := c.Validator.Validate.Struct(request)
+	if err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+
+			var messages []string
+			for _, e := range validationErrors {
+				messages = append(messages, e.Translate(c.Validator.Translator))
+			}
+			return nil, fmt.Errorf("%w: %s", utils.ErrValidation, strings.Join(messages, ", "))
+		}
+		return nil, fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
+	webhook := &entity.Webhook{
+		Name:       request.Name,
+		URL:        request.URL,
+		EventTypes: uniqueStrings(request.EventTypes),
+		Secret:     request.Secret,
+		IsActive:   true,
+	}
+	if request.IsActive != nil {
+		webhook.IsActive = *request.IsActive
+	}
+	if webhook.Secret == "" {
+		webhook.Secret = "whsec_" + rand.Text()
+	}
+
+	if err := c.WebhookRepository.Create(c.DB.WithContext(ctx), webhook); err != nil {
+		c.Log.Warnf("Failed create webhook to database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	c.reload(ctx)
+
+	// the secret is only ever returned here
+	response := converter.WebhookToResponse(webhook)
+	response.Secret = webhook.Secret
+	return response, nil
+}
+
+func (c *WebhookUseCase) FindAll(ctx context.Context, pagination *utils.PaginationRequest) ([]model.WebhookResponse, *utils.PaginationResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var webhooks []entity.Webhook
+	total, err := c.WebhookRepository.FindAll(c.DB.WithContext(ctx), &webhooks, pagination)
+	if err != nil {
+		c.Log.Warnf("Failed find all webhook from database : %+v", err)
+		return nil, nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	responses := make([]model.WebhookResponse, len(webhooks))
+	for i, webhook := range webhooks {
+		responses[i] = *converter.WebhookToResponse(&webhook)
+	}
+
+	totalPage := int((total + int64(pagination.Limit) - 1) / int64(pagination.Limit))
+
+	paginationRes := &utils.PaginationResponse{
+		Page:      pagination.Page,
+		Limit:     pagination.Limit,
+		OrderBy:   pagination.OrderBy,
+		SortBy:    pagination.SortBy,
+		Search:    pagination.Search,
+		TotalData: total,
+		TotalPage: totalPage,
+	}
+
+	return responses, paginationRes, nil
+}
+
+func (c *WebhookUseCase) FindByID(ctx context.Context, webhookID string) (*model.WebhookResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	webhook, err := c.findWebhook(c.DB.WithContext(ctx), webhookID)
+	if err != nil {
+		return nil, err
+	}
+	return converter.WebhookToResponse(webhook), nil
+}
+
+func (c *WebhookUseCase) Update(ctx context.Context, webhookID string, request *model.UpdateWebhookRequest) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	webhook, err := c.findWebhook(c.DB.WithContext(ctx), webhookID)
+	if err != nil {
+		return err
+	}
+
+	err = c.Validator.Validate.Struct(request)
+	if err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+
+			var messages []string
+			for _, e := range validationErrors {
+				messages = append(messages, e.Translate(c.Validator.Translator))
+			}
+			return fmt.Errorf("%w: %s", utils.ErrValidation, strings.Join(messages, ", "))
+		}
+		return fmt.Errorf("%w: %s", utils.ErrValidation, err.Error())
+	}
+
+	if request.Name != nil {
+		webhook.Name = *request.Name
+	}
+	if request.URL != nil {
+		webhook.URL = *request.URL
+	}
+	if request.EventTypes != nil {
+		webhook.EventTypes = uniqueStrings(request.EventTypes)
+	}
+	if request.Secret != nil {
+		webhook.Secret = *request.Secret
+	}
+	if request.IsActive != nil {
+		webhook.IsActive = *request.IsActive
+	}
+
+	if err := c.WebhookRepository.Update(c.DB.WithContext(ctx), webhook); err != nil {
+		c.Log.Warnf("Failed update webhook to database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	c.reload(ctx)
+
+	return nil
+}
+
+func (c *WebhookUseCase) Delete(ctx context.Context, webhookID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	webhook, err := c.findWebhook(c.DB.WithContext(ctx), webhookID)
+	if err != nil {
+		return err
+	}
+
+	if err := c.WebhookRepository.Delete(c.DB.WithContext(ctx), webhook); err != nil {
+		c.Log.Warnf("Failed delete webhook from database : %+v", err)
+		return fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	c.reload(ctx)
+
+	return nil
+}
+
+// FindDeliveries lists the delivery log of a webhook, optionally only the
+// deliveries in one status.
+func (c *WebhookUseCase) FindDeliveries(ctx context.Context, webhookID string, status string, pagination *utils.PaginationRequest) ([]model.WebhookDeliveryResponse, *utils.PaginationResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	switch status {
+	case "", entity.WebhookDeliveryPending, entity.WebhookDeliverySucceeded, entity.WebhookDeliveryFailed:
+	default:
+		return nil, nil, fmt.Errorf("%w: status must be one of pending, succeeded, failed", utils.ErrValidation)
+	}
+
+	webhook, err := c.findWebhook(c.DB.WithContext(ctx), webhookID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var deliveries []entity.WebhookDelivery
+	total, err := c.WebhookDeliveryRepository.FindAll(c.DB.WithContext(ctx), &deliveries, pagination,
+		repository.ByWebhookDeliveryStatus(webhook.ID, status))
+	if err != nil {
+		c.Log.Warnf("Failed find all webhook delivery from database : %+v", err)
+		return nil, nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	responses := make([]model.WebhookDeliveryResponse, len(deliveries))
+	for i, delivery := range deliveries {
+		responses[i] = *converter.WebhookDeliveryToResponse(&delivery)
+	}
+
+	totalPage := int((total + int64(pagination.Limit) - 1) / int64(pagination.Limit))
+
+	paginationRes := &utils.PaginationResponse{
+		Page:      pagination.Page,
+		Limit:     pagination.Limit,
+		OrderBy:   pagination.OrderBy,
+		SortBy:    pagination.SortBy,
+		Search:    pagination.Search,
+		TotalData: total,
+		TotalPage: totalPage,
+	}
+
+	return responses, paginationRes, nil
+}
+
+// Redeliver queues the event of a past delivery again as a new delivery
+// with a fresh attempt budget; the original stays in the log untouched.
+func (c *WebhookUseCase) Redeliver(ctx context.Context, webhookID string, deliveryID string) (*model.WebhookDeliveryResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	webhook, err := c.findWebhook(c.DB.WithContext(ctx), webhookID)
+	if err != nil {
+		return nil, err
+	}
+	if !webhook.IsActive {
+		return nil, fmt.Errorf("%w: webhook is disabled", utils.ErrConflict)
+	}
+
+	original := &entity.WebhookDelivery{}
+	if _, err := c.WebhookDeliveryRepository.FindByWebhook(c.DB.WithContext(ctx), original, webhook.ID, deliveryID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Infof("Webhook delivery not found, id=%s", deliveryID)
+			return nil, utils.ErrNotFound
+		}
+		c.Log.Warnf("Failed find webhook delivery from database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+
+	now := time.Now()
+	deliveries := []entity.WebhookDelivery{{
+		WebhookID:     webhook.ID,
+		EventID:       original.EventID,
+		EventType:     original.EventType,
+		Payload:       original.Payload,
+		Status:        entity.WebhookDeliveryPending,
+		NextAttemptAt: &now,
+		RedeliveryOf:  &original.ID,
+	}}
+	if err := c.WebhookDeliveryRepository.CreateAll(c.DB.WithContext(ctx), deliveries); err != nil {
+		c.Log.Warnf("Failed create webhook delivery to database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	c.notify()
+
+	return converter.WebhookDeliveryToResponse(&deliveries[0]), nil
+}
+
+// Start loads the subscribed webhooks and launches the dispatcher, the
+// delivery workers and the periodic reload. They stop when ctx is done.
+func (c *WebhookUseCase) Start(ctx context.Context) error {
+	webhooks, err := c.WebhookRepository.FindActive(c.DB.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	c.webhooks.Store(&webhooks)
+
+	go c.dispatch(ctx)
+	worker := &deliveryWorker[entity.WebhookDelivery]{
+		Log:          c.Log,
+		Name:         "webhook delivery",
+		PollInterval: webhookPollInterval,
+		Wake:         c.wake,
+		Lease:        2 * c.Client.Timeout,
+		Claim: func(ctx context.Context, now time.Time, leaseUntil time.Time) (*entity.WebhookDelivery, error) {
+			return c.WebhookDeliveryRepository.ClaimDue(c.DB.WithContext(ctx), now, leaseUntil)
+		},
+		Attempt: c.attempt,
+	}
+	for i := 0; i < c.Workers; i++ {
+		go worker.run(ctx)
+	}
+	go c.refresh(ctx)
+	return nil
+}
+
+// dispatch turns hub events such as readings into deliveries. Should it
+// fall so far behind that the hub drops it, it subscribes again; the events
+// missed meanwhile are lost and logged.
+func (c *WebhookUseCase) dispatch(ctx context.Context) {
+	for {
+		subscriber := c.Hub.SubscribeSize(&stream.Filter{}, webhookDispatchBuffer)
+		c.consume(ctx, subscriber)
+		c.Hub.Unsubscribe(subscriber)
+		if ctx.Err() != nil {
+			return
+		}
+		c.Log.Warnf("Webhook dispatcher fell behind the event hub, resubscribing")
+	}
+}
+
+func (c *WebhookUseCase) consume(ctx context.Context, subscriber *stream.Subscriber) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-subscriber.Done():
+			return
+		case hubEvent := <-subscriber.Events():
+			if dropped := subscriber.TakeDropped(); dropped > 0 {
+				c.Log.Warnf("Webhook dispatcher dropped %d events", dropped)
+			}
+			// domain events reach webhooks from the outbox, see HandleEvent
+			if domainEventTypes[hubEvent.Type] {
+				continue
+			}
+			if err := c.enqueue(ctx, uuid.New(), hubEvent.Type, hubEvent.Time, hubEvent.Data); err != nil {
+				c.Log.Warnf("Failed create webhook deliveries to database : %+v", err)
+			}
+		}
+	}
+}
+
+// HandleEvent turns a domain event relayed from the outbox into deliveries.
+// A relayed event may arrive again after a failure, so one whose deliveries
+// already exist is skipped; an error makes the outbox retry it.
+func (c *WebhookUseCase) HandleEvent(ctx context.Context, envelope event.Envelope) error {
+	exists, err := c.WebhookDeliveryRepository.ExistsByEventID(c.DB.WithContext(ctx), envelope.ID)
+	if err != nil || exists {
+		return err
+	}
+	return c.enqueue(ctx, envelope.ID, envelope.Type, envelope.OccurredAt, envelope.Payload)
+}
+
+// enqueue stores one pending delivery of the event per webhook subscribed
+// to its type. All of them share the payload and its event id.
+func (c *WebhookUseCase) enqueue(ctx context.Context, eventID uuid.UUID, eventType string, occurredAt time.Time, data any) error {
+	var deliveries []entity.WebhookDelivery
+	now := time.Now()
+	for _, webhook := range *c.webhooks.Load() {
+		if webhook.EventTypes.Contains(eventType) {
+			deliveries = append(deliveries, entity.WebhookDelivery{
+				WebhookID:     webhook.ID,
+				EventID:       eventID,
+				EventType:     eventType,
+				Status:        entity.WebhookDeliveryPending,
+				NextAttemptAt: &now,
+			})
+		}
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(&model.WebhookPayload{
+		ID:         eventID.String(),
+		Type:       eventType,
+		OccurredAt: occurredAt.UTC().Format(time.RFC3339Nano),
+		Data:       data,
+	})
+	if err != nil {
+		return fmt.Errorf("encode webhook payload: %w", err)
+	}
+	for i := range deliveries {
+		deliveries[i].Payload = string(payload)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := c.WebhookDeliveryRepository.CreateAll(c.DB.WithContext(ctx), deliveries); err != nil {
+		return err
+	}
+	c.notify()
+	return nil
+}
+
+// attempt sends a claimed delivery and records the outcome: delivered,
+// scheduled for a retry, or failed for good once out of attempts.
+func (c *WebhookUseCase) attempt(ctx context.Context, delivery *entity.WebhookDelivery) {
+	webhook := &entity.Webhook{}
+	if _, err := c.WebhookRepository.FindById(c.DB.WithContext(ctx), webhook, delivery.WebhookID); err != nil {
+		// deleting a webhook deletes its deliveries, so anything else is
+		// retried once the lease ends
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Warnf("Failed find webhook from database : %+v", err)
+		}
+		return
+	}
+
+	var (
+		started  = time.Now()
+		code     int
+		response string
+		err      error
+	)
+	if webhook.IsActive {
+		code, response, err = c.send(ctx, webhook, delivery)
+	} else {
+		err = errors.New("webhook is disabled")
+		delivery.Attempts = c.MaxAttempts
+	}
+	finished := time.Now()
+
+	delivery.LastStatusCode = code
+	delivery.LastResponse = truncate(strings.ToValidUTF8(response, ""), webhookLogLimit)
+	delivery.LastDurationMs = finished.Sub(started).Milliseconds()
+	delivery.LastError = ""
+	switch {
+	case err == nil:
+		delivery.Status = entity.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &finished
+		delivery.NextAttemptAt = nil
+	default:
+		delivery.LastError = truncate(err.Error(), webhookLogLimit)
+		delivery.NextAttemptAt = retryAt(finished, delivery.Attempts, c.MaxAttempts, webhookBackoffBase, webhookBackoffMax)
+		if delivery.NextAttemptAt == nil {
+			delivery.Status = entity.WebhookDeliveryFailed
+		}
+	}
+
+	// the outcome is recorded even when ctx was cancelled meanwhile
+	if err := c.WebhookDeliveryRepository.Update(c.DB.WithContext(context.Background()), delivery); err != nil {
+		c.Log.Warnf("Failed update webhook delivery to database : %+v", err)
+	}
+}
+
+// send POSTs the payload signed with the webhook secret. Any status other
+// than 2xx is an error; the start of the response body is returned for
+// the log either way.
+func (c *WebhookUseCase) send(ctx context.Context, webhook *entity.Webhook, delivery *entity.WebhookDelivery) (int, string, error) {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "mertani_test-webhook")
+	request.Header.Set(utils.WebhookEventHeader, delivery.EventType)
+	request.Header.Set(utils.WebhookDeliveryHeader, delivery.ID.String())
+	request.Header.Set(utils.WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(utils.WebhookSignatureHeader, utils.SignWebhook(webhook.Secret, timestamp, body))
+
+	response, err := c.Client.Do(request)
+	if err != nil {
+		return 0, "", err
+	}
+	defer response.Body.Close()
+
+	snippet, _ := io.ReadAll(io.LimitReader(response.Body, webhookLogLimit))
+	// drain a little more so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64*1024))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, string(snippet), fmt.Errorf("receiver answered %d", response.StatusCode)
+	}
+	return response.StatusCode, string(snippet), nil
+}
+
+// refresh reloads the subscribed webhooks every webhookRefreshInterval.
+func (c *WebhookUseCase) refresh(ctx context.Context) {
+	ticker := time.NewTicker(webhookRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.reload(ctx)
+		}
+	}
+}
+
+// reload replaces the in-memory list of active webhooks the dispatcher
+// matches events against. On failure the previous list is kept.
+func (c *WebhookUseCase) reload(ctx context.Context) {
+	webhooks, err := c.WebhookRepository.FindActive(c.DB.WithContext(ctx))
+	if err != nil {
+		c.Log.Warnf("Failed find active webhooks from database : %+v", err)
+		return
+	}
+	c.webhooks.Store(&webhooks)
+}
+
+func (c *WebhookUseCase) notify() {
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (c *WebhookUseCase) findWebhook(db *gorm.DB, webhookID string) (*entity.Webhook, error) {
+	webhook := &entity.Webhook{}
+	_, err := c.WebhookRepository.FindById(db, webhook, webhookID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Log.Infof("Webhook not found, id=%s", webhookID)
+			return nil, utils.ErrNotFound
+		}
+		c.Log.Warnf("Failed find webhook from database : %+v", err)
+		return nil, fmt.Errorf("%w: %s", utils.ErrInternal, err.Error())
+	}
+	return webhook, nil
+}
+
+func uniqueStrings(values []string) entity.StringList {
+	unique := make(entity.StringList, 0, len(values))
+	for _, value := range values {
+		if !unique.Contains(value) {
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
+
+func truncate(value string, limit int) string {
+	if len(value) <= limit {
+		return value
+	}
+	return strings.ToValidUTF8(value[:limit], "")
+}
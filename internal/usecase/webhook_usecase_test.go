@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mertani_test/internal/entity"
+	"mertani_test/internal/utils"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestWebhookSendSignature(t *testing.T) {
+	const secret = "whsec_test"
+
+	type received struct {
+		header http.Header
+		body   []byte
+		err    error
+	}
+	deliveries := make(chan received, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		err := utils.VerifyWebhook(secret, r.Header.Get(utils.WebhookTimestampHeader),
+			r.Header.Get(utils.WebhookSignatureHeader), body, time.Now(), 5*time.Minute)
+		deliveries <- received{header: r.Header.Clone(), body: body, err: err}
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	c := &WebhookUseCase{Client: server.Client()}
+	webhook := &entity.Webhook{URL: server.URL, Secret: secret}
+	delivery := &entity.WebhookDelivery{
+		ID:        uuid.New(),
+		EventType: "reading.created",
+		Payload:   `{"type":"reading.created","data":{"value":21.5}}`,
+	}
+
+	status, snippet, err := c.send(context.Background(), webhook, delivery)
+	if err != nil {
+		t.Fatalf("send: %v (status %d, %q)", err, status, snippet)
+	}
+	if status != http.StatusOK || snippet != "ok" {
+		t.Fatalf("got %d %q", status, snippet)
+	}
+
+	got := <-deliveries
+	if got.err != nil {
+		t.Fatalf("receiver rejected the delivery: %v", got.err)
+	}
+	if got.header.Get(utils.WebhookEventHeader) != delivery.EventType ||
+		got.header.Get(utils.WebhookDeliveryHeader) != delivery.ID.String() {
+		t.Fatalf("headers = %v", got.header)
+	}
+
+	timestamp := got.header.Get(utils.WebhookTimestampHeader)
+	signature := got.header.Get(utils.WebhookSignatureHeader)
+
+	t.Run("tampered body", func(t *testing.T) {
+		tampered := append([]byte{}, got.body...)
+		tampered[len(tampered)-3] = '9'
+		err := utils.VerifyWebhook(secret, timestamp, signature, tampered, time.Now(), 5*time.Minute)
+		if !errors.Is(err, utils.ErrInvalidSignature) {
+			t.Fatalf("err = %v, want ErrInvalidSignature", err)
+		}
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		err := utils.VerifyWebhook("other", timestamp, signature, got.body, time.Now(), 5*time.Minute)
+		if !errors.Is(err, utils.ErrInvalidSignature) {
+			t.Fatalf("err = %v, want ErrInvalidSignature", err)
+		}
+	})
+
+	t.Run("old timestamp", func(t *testing.T) {
+		// replayed an hour later, the signature still matches the body
+		later := time.Now().Add(time.Hour)
+		err := utils.VerifyWebhook(secret, timestamp, signature, got.body, later, 5*time.Minute)
+		if !errors.Is(err, utils.ErrInvalidSignature) {
+			t.Fatalf("err = %v, want ErrInvalidSignature", err)
+		}
+	})
+
+	t.Run("rewritten timestamp", func(t *testing.T) {
+		sent, _ := strconv.ParseInt(timestamp, 10, 64)
+		rewritten := strconv.FormatInt(sent+60, 10)
+		err := utils.VerifyWebhook(secret, rewritten, signature, got.body, time.Now(), 5*time.Minute)
+		if !errors.Is(err, utils.ErrInvalidSignature) {
+			t.Fatalf("err = %v, want ErrInvalidSignature", err)
+		}
+	})
+}
+
+func TestWebhookSendRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		err := utils.VerifyWebhook("expected", r.Header.Get(utils.WebhookTimestampHeader),
+			r.Header.Get(utils.WebhookSignatureHeader), body, time.Now(), 5*time.Minute)
+		if errors.Is(err, utils.ErrInvalidSignature) {
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	c := &WebhookUseCase{Client: server.Client()}
+	webhook := &entity.Webhook{URL: server.URL, Secret: "rotated"}
+	delivery := &entity.WebhookDelivery{ID: uuid.New(), EventType: "reading.created", Payload: `{}`}
+
+	status, snippet, err := c.send(context.Background(), webhook, delivery)
+	if err == nil || status != http.StatusUnauthorized {
+		t.Fatalf("got %d %q %v, want a 401 failure", status, snippet, err)
+	}
+}
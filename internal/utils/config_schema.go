@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// ValidateConfigDocument checks a configuration document against a small
+// subset of JSON Schema: "type", "properties", "required",
+// "additionalProperties", "minimum", "maximum" and "enum".
+func ValidateConfigDocument(schema map[string]any, document map[string]any) []string {
+	var errs []string
+	validateConfigValue(schema, document, "config", &errs)
+	return errs
+}
+
+func validateConfigValue(schema map[string]any, value any, path string, errs *[]string) {
+	if expected, ok := schema["type"].(string); ok && !matchesConfigType(expected, value) {
+		*errs = append(*errs, fmt.Sprintf("%s must be of type %s", path, expected))
+		return
+	}
+
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, candidate := range enum {
+			if fmt.Sprint(candidate) == fmt.Sprint(value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			*errs = append(*errs, fmt.Sprintf("%s must be one of %v", path, enum))
+		}
+	}
+
+	if number, ok := value.(float64); ok {
+		if min, ok := schema["minimum"].(float64); ok && number < min {
+			*errs = append(*errs, fmt.Sprintf("%s must be %v or greater", path, min))
+		}
+		if max, ok := schema["maximum"].(float64); ok && number > max {
+			*errs = append(*errs, fmt.Sprintf("%s must be %v or less", path, max))
+		}
+	}
+
+	object, ok := value.(map[string]any)
+	if !ok {
+		return
+	}
+
+	if required, ok := schema["required"].([]any); ok {
+		for _, name := range required {
+			key, _ := name.(string)
+			if _, exists := object[key]; !exists {
+				*errs = append(*errs, fmt.Sprintf("%s.%s is required", path, key))
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]any)
+	additional, hasAdditional := schema["additionalProperties"].(bool)
+
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		property, known := properties[key].(map[string]any)
+		if !known {
+			if hasAdditional && !additional {
+				*errs = append(*errs, fmt.Sprintf("%s.%s is not allowed", path, key))
+			}
+			continue
+		}
+		validateConfigValue(property, object[key], path+"."+key, errs)
+	}
+}
+
+func matchesConfigType(expected string, value any) bool {
+	switch expected {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		number, ok := value.(float64)
+		return ok && number == math.Trunc(number)
+	case "null":
+		return value == nil
+	}
+	return true
+}
@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// CSVRow is one data row keyed by lower-cased header. Columns missing from
+// the header are absent, which lets an import tell "not given" from "empty".
+type CSVRow struct {
+	Line   int
+	Values map[string]string
+}
+
+// Has reports whether the column was part of the header.
+func (r CSVRow) Has(column string) bool {
+	_, ok := r.Values[column]
+	return ok
+}
+
+// Get returns the trimmed cell of a column, empty when the column is absent.
+func (r CSVRow) Get(column string) string {
+	return r.Values[column]
+}
+
+// ReadCSV reads a CSV document with a header row. Header names must be
+// among allowed, required columns must be present and at most maxRows data
+// rows are accepted. Blank lines are skipped.
+func ReadCSV(r io.Reader, allowed []string, required []string, maxRows int) ([]CSVRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("csv is empty")
+		}
+		return nil, fmt.Errorf("header: %s", err.Error())
+	}
+
+	columns := make([]string, len(header))
+	seen := make(map[string]bool, len(header))
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !containsString(allowed, name) {
+			return nil, fmt.Errorf("unknown column %q, allowed columns: %s", name, strings.Join(allowed, ", "))
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate column %q", name)
+		}
+		seen[name] = true
+		columns[i] = name
+	}
+	for _, name := range required {
+		if !seen[name] {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+
+	var rows []CSVRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", line, err.Error())
+		}
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		if len(record) != len(columns) {
+			return nil, fmt.Errorf("line %d: expected %d fields, got %d", line, len(columns), len(record))
+		}
+		if len(rows) == maxRows {
+			return nil, fmt.Errorf("too many rows, at most %d are allowed", maxRows)
+		}
+
+		row := CSVRow{Line: line, Values: make(map[string]string, len(columns))}
+		for i, name := range columns {
+			row.Values[name] = strings.TrimSpace(record[i])
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// FormatCSVLabels renders labels as "key=value;key=value" sorted by key.
+// Valid label keys and values contain neither separator.
+func FormatCSVLabels(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + "=" + labels[key]
+	}
+	return strings.Join(pairs, ";")
+}
+
+// ParseCSVLabels is the inverse of FormatCSVLabels. An empty cell yields
+// no labels.
+func ParseCSVLabels(cell string) (map[string]string, error) {
+	labels := map[string]string{}
+	for _, pair := range strings.Split(cell, ";") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("label %q must be key=value", pair)
+		}
+		key = strings.TrimSpace(key)
+		if _, exists := labels[key]; exists {
+			return nil, fmt.Errorf("duplicate label %q", key)
+		}
+		labels[key] = strings.TrimSpace(value)
+	}
+	return labels, nil
+}
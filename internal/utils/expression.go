@@ -0,0 +1,394 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	maxExpressionLength = 1000
+	maxExpressionDepth  = 32
+)
+
+// Expression is a parsed arithmetic formula over named variables. It supports
+// numbers, + - * / % ^, parentheses and a fixed set of math functions; there
+// is no way to reach anything outside the given variables.
+type Expression struct {
+	source    string
+	root      expressionNode
+	variables []string
+}
+
+type expressionNode interface {
+	eval(vars map[string]float64) (float64, error)
+}
+
+type numberNode float64
+
+type variableNode string
+
+type unaryNode struct {
+	operand expressionNode
+}
+
+type binaryNode struct {
+	op          byte
+	left, right expressionNode
+}
+
+type callNode struct {
+	name string
+	args []expressionNode
+}
+
+type expressionFunction struct {
+	arity int // -1 for variadic with at least one argument
+	fn    func(args []float64) float64
+}
+
+var expressionFunctions = map[string]expressionFunction{
+	"abs":   {1, func(a []float64) float64 { return math.Abs(a[0]) }},
+	"sqrt":  {1, func(a []float64) float64 { return math.Sqrt(a[0]) }},
+	"exp":   {1, func(a []float64) float64 { return math.Exp(a[0]) }},
+	"ln":    {1, func(a []float64) float64 { return math.Log(a[0]) }},
+	"log10": {1, func(a []float64) float64 { return math.Log10(a[0]) }},
+	"floor": {1, func(a []float64) float64 { return math.Floor(a[0]) }},
+	"ceil":  {1, func(a []float64) float64 { return math.Ceil(a[0]) }},
+	"round": {1, func(a []float64) float64 { return math.Round(a[0]) }},
+	"pow":   {2, func(a []float64) float64 { return math.Pow(a[0], a[1]) }},
+	"min": {-1, func(a []float64) float64 {
+		result := a[0]
+		for _, v := range a[1:] {
+			result = math.Min(result, v)
+		}
+		return result
+	}},
+	"max": {-1, func(a []float64) float64 {
+		result := a[0]
+		for _, v := range a[1:] {
+			result = math.Max(result, v)
+		}
+		return result
+	}},
+}
+
+var expressionConstants = map[string]float64{
+	"pi": math.Pi,
+}
+
+// ParseExpression parses and checks a formula, e.g.
+// "t - ((100 - rh) / 5)" or "0.6108 * exp(17.27 * t / (t + 237.3)) * (1 - rh / 100)".
+func ParseExpression(source string) (*Expression, error) {
+	if strings.TrimSpace(source) == "" {
+		return nil, errors.New("expression is empty")
+	}
+	if len(source) > maxExpressionLength {
+		return nil, fmt.Errorf("expression is longer than %d characters", maxExpressionLength)
+	}
+
+	p := &expressionParser{source: source, variables: map[string]bool{}}
+	root, err := p.parseSum(0)
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.source) {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.source[p.pos], p.pos+1)
+	}
+
+	variables := make([]string, 0, len(p.variables))
+	for name := range p.variables {
+		variables = append(variables, name)
+	}
+	sort.Strings(variables)
+
+	return &Expression{source: source, root: root, variables: variables}, nil
+}
+
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Variables returns the variable names the expression references, sorted.
+func (e *Expression) Variables() []string {
+	return e.variables
+}
+
+// Evaluate computes the expression. Every variable must be bound and the
+// result must be a finite number.
+func (e *Expression) Evaluate(vars map[string]float64) (float64, error) {
+	result, err := e.root.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(result) || math.IsInf(result, 0) {
+		return 0, errors.New("expression result is not a finite number")
+	}
+	return result, nil
+}
+
+func (n numberNode) eval(map[string]float64) (float64, error) {
+	return float64(n), nil
+}
+
+func (n variableNode) eval(vars map[string]float64) (float64, error) {
+	value, ok := vars[string(n)]
+	if !ok {
+		return 0, fmt.Errorf("variable %q is not bound", string(n))
+	}
+	return value, nil
+}
+
+func (n unaryNode) eval(vars map[string]float64) (float64, error) {
+	value, err := n.operand.eval(vars)
+	return -value, err
+}
+
+func (n binaryNode) eval(vars map[string]float64) (float64, error) {
+	left, err := n.left.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	right, err := n.right.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+
+	switch n.op {
+	case '+':
+		return left + right, nil
+	case '-':
+		return left - right, nil
+	case '*':
+		return left * right, nil
+	case '/':
+		if right == 0 {
+			return 0, errors.New("division by zero")
+		}
+		return left / right, nil
+	case '%':
+		if right == 0 {
+			return 0, errors.New("division by zero")
+		}
+		return math.Mod(left, right), nil
+	default:
+		return math.Pow(left, right), nil
+	}
+}
+
+func (n callNode) eval(vars map[string]float64) (float64, error) {
+	args := make([]float64, len(n.args))
+	for i, arg := range n.args {
+		value, err := arg.eval(vars)
+		if err != nil {
+			return 0, err
+		}
+		args[i] = value
+	}
+	return expressionFunctions[n.name].fn(args), nil
+}
+
+type expressionParser struct {
+	source    string
+	pos       int
+	variables map[string]bool
+}
+
+func (p *expressionParser) skipSpace() {
+	for p.pos < len(p.source) && unicode.IsSpace(rune(p.source[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *expressionParser) peek() byte {
+	p.skipSpace()
+	if p.pos >= len(p.source) {
+		return 0
+	}
+	return p.source[p.pos]
+}
+
+func (p *expressionParser) parseSum(depth int) (expressionNode, error) {
+	left, err := p.parseProduct(depth)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek()
+		if op != '+' && op != '-' {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseProduct(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *expressionParser) parseProduct(depth int) (expressionNode, error) {
+	left, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek()
+		if op != '*' && op != '/' && op != '%' {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *expressionParser) parseUnary(depth int) (expressionNode, error) {
+	if depth > maxExpressionDepth {
+		return nil, errors.New("expression is nested too deeply")
+	}
+	switch p.peek() {
+	case '-':
+		p.pos++
+		operand, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return unaryNode{operand: operand}, nil
+	case '+':
+		p.pos++
+		return p.parseUnary(depth + 1)
+	}
+	return p.parsePower(depth)
+}
+
+func (p *expressionParser) parsePower(depth int) (expressionNode, error) {
+	base, err := p.parsePrimary(depth)
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() != '^' {
+		return base, nil
+	}
+	p.pos++
+	exponent, err := p.parseUnary(depth + 1)
+	if err != nil {
+		return nil, err
+	}
+	return binaryNode{op: '^', left: base, right: exponent}, nil
+}
+
+func (p *expressionParser) parsePrimary(depth int) (expressionNode, error) {
+	c := p.peek()
+	switch {
+	case c == 0:
+		return nil, errors.New("unexpected end of expression")
+
+	case c == '(':
+		p.pos++
+		inner, err := p.parseSum(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ')' {
+			return nil, fmt.Errorf("missing ) at position %d", p.pos+1)
+		}
+		p.pos++
+		return inner, nil
+
+	case c == '.' || (c >= '0' && c <= '9'):
+		return p.parseNumber()
+
+	case c == '_' || unicode.IsLetter(rune(c)):
+		return p.parseIdentifier(depth)
+	}
+	return nil, fmt.Errorf("unexpected %q at position %d", c, p.pos+1)
+}
+
+func (p *expressionParser) parseNumber() (expressionNode, error) {
+	start := p.pos
+	for p.pos < len(p.source) {
+		c := p.source[p.pos]
+		if (c >= '0' && c <= '9') || c == '.' {
+			p.pos++
+			continue
+		}
+		// exponent, e.g. 1e-3
+		if (c == 'e' || c == 'E') && p.pos+1 < len(p.source) {
+			next := p.source[p.pos+1]
+			if next >= '0' && next <= '9' {
+				p.pos++
+				continue
+			}
+			if (next == '-' || next == '+') && p.pos+2 < len(p.source) &&
+				p.source[p.pos+2] >= '0' && p.source[p.pos+2] <= '9' {
+				p.pos += 2
+				continue
+			}
+		}
+		break
+	}
+	value, err := strconv.ParseFloat(p.source[start:p.pos], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number %q at position %d", p.source[start:p.pos], start+1)
+	}
+	return numberNode(value), nil
+}
+
+func (p *expressionParser) parseIdentifier(depth int) (expressionNode, error) {
+	start := p.pos
+	for p.pos < len(p.source) {
+		c := rune(p.source[p.pos])
+		if c != '_' && !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			break
+		}
+		p.pos++
+	}
+	name := p.source[start:p.pos]
+
+	if p.peek() != '(' {
+		if value, ok := expressionConstants[name]; ok {
+			return numberNode(value), nil
+		}
+		p.variables[name] = true
+		return variableNode(name), nil
+	}
+
+	function, ok := expressionFunctions[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q", name)
+	}
+	p.pos++
+
+	var args []expressionNode
+	if p.peek() != ')' {
+		for {
+			arg, err := p.parseSum(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.peek() != ',' {
+				break
+			}
+			p.pos++
+		}
+	}
+	if p.peek() != ')' {
+		return nil, fmt.Errorf("missing ) after arguments of %s", name)
+	}
+	p.pos++
+
+	if (function.arity >= 0 && len(args) != function.arity) || (function.arity < 0 && len(args) == 0) {
+		return nil, fmt.Errorf("wrong number of arguments for %s", name)
+	}
+	return callNode{name: name, args: args}, nil
+}
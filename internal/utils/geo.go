@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const EarthRadiusKm = 6371.0
+
+// MaxRadiusKm is the distance to the opposite side of the earth, beyond
+// which a radius covers every point.
+const MaxRadiusKm = math.Pi * EarthRadiusKm
+
+// HaversineKm returns the great-circle distance between two points in kilometres.
+func HaversineKm(lat1, lng1, lat2, lng2 float64) float64 {
+	dLat := (lat2 - lat1) * math.Pi / 180
+	dLng := (lng2 - lng1) * math.Pi / 180
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*math.Pi/180)*math.Cos(lat2*math.Pi/180)*math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return EarthRadiusKm * 2 * math.Asin(math.Sqrt(a))
+}
+
+// ParseCoordinates parses a comma separated list of exactly n numbers,
+// e.g. "-7.25,112.75" for a point or "minLng,minLat,maxLng,maxLat" for a bbox.
+func ParseCoordinates(value string, n int) ([]float64, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != n {
+		return nil, fmt.Errorf("expected %d comma separated numbers, got %d", n, len(parts))
+	}
+
+	numbers := make([]float64, n)
+	for i, part := range parts {
+		number, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", part)
+		}
+		numbers[i] = number
+	}
+	return numbers, nil
+}
+
+func ValidLatitude(lat float64) bool {
+	return lat >= -90 && lat <= 90
+}
+
+func ValidLongitude(lng float64) bool {
+	return lng >= -180 && lng <= 180
+}
+
+// ValidatePolygon checks a single GeoJSON ring: at least four [lng, lat]
+// positions, valid ranges and first position equal to the last.
+func ValidatePolygon(ring [][]float64) error {
+	if len(ring) < 4 {
+		return fmt.Errorf("boundary must have at least 4 positions")
+	}
+	for i, position := range ring {
+		if len(position) != 2 {
+			return fmt.Errorf("boundary position %d must be [longitude, latitude]", i)
+		}
+		if !ValidLongitude(position[0]) || !ValidLatitude(position[1]) {
+			return fmt.Errorf("boundary position %d is out of range", i)
+		}
+	}
+	first, last := ring[0], ring[len(ring)-1]
+	if first[0] != last[0] || first[1] != last[1] {
+		return fmt.Errorf("boundary must be closed (first and last positions equal)")
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	SelectorEquals       = "="
+	SelectorNotEquals    = "!="
+	SelectorIn           = "in"
+	SelectorNotIn        = "notin"
+	SelectorExists       = "exists"
+	SelectorDoesNotExist = "!"
+)
+
+var labelPattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._/-]{0,61}[A-Za-z0-9])?$`)
+
+// LabelRequirement is a single clause of a label selector.
+type LabelRequirement struct {
+	Key      string
+	Operator string
+	Values   []string
+}
+
+// LabelSelector is a conjunction of requirements, parsed from the
+// Kubernetes-style syntax: "crop=rice,field in (A,B),!deprecated".
+type LabelSelector []LabelRequirement
+
+func ValidLabelKey(key string) bool {
+	return labelPattern.MatchString(key)
+}
+
+func ValidLabelValue(value string) bool {
+	return value == "" || labelPattern.MatchString(value)
+}
+
+// ValidateLabels checks every key and value of a label set.
+func ValidateLabels(labels map[string]string) error {
+	for key, value := range labels {
+		if !ValidLabelKey(key) {
+			return fmt.Errorf("invalid label key %q", key)
+		}
+		if !ValidLabelValue(value) {
+			return fmt.Errorf("invalid label value %q for key %q", value, key)
+		}
+	}
+	return nil
+}
+
+func ParseLabelSelector(selector string) (LabelSelector, error) {
+	var requirements LabelSelector
+
+	for _, clause := range splitSelector(selector) {
+		clause = strings.TrimSpace(clause)
+		if clause == "" {
+			continue
+		}
+
+		requirement, err := parseRequirement(clause)
+		if err != nil {
+			return nil, err
+		}
+		requirements = append(requirements, requirement)
+	}
+
+	return requirements, nil
+}
+
+// Matches reports whether the label set satisfies every requirement.
+func (s LabelSelector) Matches(labels map[string]string) bool {
+	for _, requirement := range s {
+		value, exists := labels[requirement.Key]
+
+		switch requirement.Operator {
+		case SelectorExists:
+			if !exists {
+				return false
+			}
+		case SelectorDoesNotExist:
+			if exists {
+				return false
+			}
+		case SelectorEquals:
+			if !exists || value != requirement.Values[0] {
+				return false
+			}
+		case SelectorNotEquals:
+			if exists && value == requirement.Values[0] {
+				return false
+			}
+		case SelectorIn:
+			if !exists || !containsString(requirement.Values, value) {
+				return false
+			}
+		case SelectorNotIn:
+			if exists && containsString(requirement.Values, value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func parseRequirement(clause string) (LabelRequirement, error) {
+	if strings.HasPrefix(clause, "!") {
+		key := strings.TrimSpace(clause[1:])
+		if !ValidLabelKey(key) {
+			return LabelRequirement{}, fmt.Errorf("invalid label key %q", key)
+		}
+		return LabelRequirement{Key: key, Operator: SelectorDoesNotExist}, nil
+	}
+
+	for _, operator := range []string{"!=", "==", "="} {
+		if index := strings.Index(clause, operator); index > 0 {
+			key := strings.TrimSpace(clause[:index])
+			value := strings.TrimSpace(clause[index+len(operator):])
+			if !ValidLabelKey(key) {
+				return LabelRequirement{}, fmt.Errorf("invalid label key %q", key)
+			}
+			if !ValidLabelValue(value) {
+				return LabelRequirement{}, fmt.Errorf("invalid label value %q", value)
+			}
+			op := SelectorEquals
+			if operator == "!=" {
+				op = SelectorNotEquals
+			}
+			return LabelRequirement{Key: key, Operator: op, Values: []string{value}}, nil
+		}
+	}
+
+	if open := strings.Index(clause, "("); open > 0 {
+		if !strings.HasSuffix(clause, ")") {
+			return LabelRequirement{}, fmt.Errorf("unterminated value list in %q", clause)
+		}
+
+		fields := strings.Fields(clause[:open])
+		if len(fields) != 2 || (fields[1] != SelectorIn && fields[1] != SelectorNotIn) {
+			return LabelRequirement{}, fmt.Errorf("invalid set requirement %q", clause)
+		}
+		if !ValidLabelKey(fields[0]) {
+			return LabelRequirement{}, fmt.Errorf("invalid label key %q", fields[0])
+		}
+
+		var values []string
+		for _, value := range strings.Split(clause[open+1:len(clause)-1], ",") {
+			value = strings.TrimSpace(value)
+			if !ValidLabelValue(value) {
+				return LabelRequirement{}, fmt.Errorf("invalid label value %q", value)
+			}
+			values = append(values, value)
+		}
+		return LabelRequirement{Key: fields[0], Operator: fields[1], Values: values}, nil
+	}
+
+	if !ValidLabelKey(clause) {
+		return LabelRequirement{}, fmt.Errorf("invalid selector clause %q", clause)
+	}
+	return LabelRequirement{Key: clause, Operator: SelectorExists}, nil
+}
+
+// splitSelector splits on commas that are not inside a parenthesised set.
+func splitSelector(selector string) []string {
+	var clauses []string
+	depth, start := 0, 0
+
+	for i, r := range selector {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				clauses = append(clauses, selector[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(clauses, selector[start:])
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
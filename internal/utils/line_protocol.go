@@ -0,0 +1,217 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LinePoint is one parsed line of InfluxDB line protocol. String fields are
+// not representable as readings and are left out of Fields.
+type LinePoint struct {
+	Measurement string
+	Tags        map[string]string
+	Fields      map[string]float64
+	Time        time.Time
+}
+
+var linePrecisions = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+}
+
+// ValidLinePrecision reports whether precision is a timestamp unit accepted
+// by ParseLine.
+func ValidLinePrecision(precision string) bool {
+	_, ok := linePrecisions[precision]
+	return ok
+}
+
+// ParseLine parses "measurement,tag=v field=1,other=2i 1700000000000000000".
+// Timestamps are read in the given precision (ns, us, ms or s); lines
+// without one get now.
+func ParseLine(line string, precision string, now time.Time) (*LinePoint, error) {
+	unit, ok := linePrecisions[precision]
+	if !ok {
+		return nil, fmt.Errorf("unknown precision %q", precision)
+	}
+
+	sections, err := splitLine(line)
+	if err != nil {
+		return nil, err
+	}
+	if len(sections) < 2 {
+		return nil, errors.New("missing fields")
+	}
+	if len(sections) > 3 {
+		return nil, errors.New("unexpected text after timestamp")
+	}
+
+	point := &LinePoint{Tags: map[string]string{}, Fields: map[string]float64{}, Time: now}
+
+	keyParts := splitUnescaped(sections[0], ',')
+	point.Measurement = unescapeLine(keyParts[0])
+	if point.Measurement == "" {
+		return nil, errors.New("missing measurement")
+	}
+	for _, tag := range keyParts[1:] {
+		key, value, ok := cutUnescaped(tag, '=')
+		if !ok || key == "" || value == "" {
+			return nil, fmt.Errorf("invalid tag %q", tag)
+		}
+		point.Tags[unescapeLine(key)] = unescapeLine(value)
+	}
+
+	fieldCount := 0
+	for _, field := range splitUnescaped(sections[1], ',') {
+		key, raw, ok := cutUnescaped(field, '=')
+		if !ok || key == "" || raw == "" {
+			return nil, fmt.Errorf("invalid field %q", field)
+		}
+		fieldCount++
+		value, numeric, err := parseFieldValue(raw)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %s", unescapeLine(key), err.Error())
+		}
+		if numeric {
+			point.Fields[unescapeLine(key)] = value
+		}
+	}
+	if fieldCount == 0 {
+		return nil, errors.New("missing fields")
+	}
+
+	if len(sections) == 3 {
+		timestamp, err := strconv.ParseInt(sections[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp %q", sections[2])
+		}
+		if timestamp > math.MaxInt64/int64(unit) || timestamp < math.MinInt64/int64(unit) {
+			return nil, fmt.Errorf("timestamp %d is out of range for precision %s", timestamp, precision)
+		}
+		point.Time = time.Unix(0, timestamp*int64(unit))
+	}
+
+	return point, nil
+}
+
+// parseFieldValue returns the numeric value of a field; numeric is false for
+// string fields.
+func parseFieldValue(raw string) (float64, bool, error) {
+	switch {
+	case strings.HasPrefix(raw, `"`):
+		if len(raw) < 2 || !strings.HasSuffix(raw, `"`) {
+			return 0, false, errors.New("unterminated string")
+		}
+		return 0, false, nil
+
+	case raw == "t" || raw == "T" || raw == "true" || raw == "True" || raw == "TRUE":
+		return 1, true, nil
+
+	case raw == "f" || raw == "F" || raw == "false" || raw == "False" || raw == "FALSE":
+		return 0, true, nil
+
+	case strings.HasSuffix(raw, "i"):
+		value, err := strconv.ParseInt(strings.TrimSuffix(raw, "i"), 10, 64)
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid integer %q", raw)
+		}
+		return float64(value), true, nil
+
+	case strings.HasSuffix(raw, "u"):
+		value, err := strconv.ParseUint(strings.TrimSuffix(raw, "u"), 10, 64)
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid unsigned integer %q", raw)
+		}
+		return float64(value), true, nil
+	}
+
+	// ParseFloat also reads nan and inf, which no reading can hold
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, false, fmt.Errorf("invalid number %q", raw)
+	}
+	return value, true, nil
+}
+
+// splitLine splits a line on unescaped spaces outside quoted field strings.
+func splitLine(line string) ([]string, error) {
+	var sections []string
+	var current strings.Builder
+	escaped, quoted := false, false
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '"' && len(sections) == 1:
+			quoted = !quoted
+		case c == ' ' && !quoted:
+			if current.Len() > 0 {
+				sections = append(sections, current.String())
+				current.Reset()
+			}
+			continue
+		}
+		current.WriteByte(c)
+	}
+	if quoted {
+		return nil, errors.New("unterminated string")
+	}
+	if current.Len() > 0 {
+		sections = append(sections, current.String())
+	}
+	return sections, nil
+}
+
+// splitUnescaped splits s on sep, ignoring escaped separators and separators
+// inside double quotes.
+func splitUnescaped(s string, sep byte) []string {
+	var parts []string
+	start := 0
+	escaped, quoted := false, false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '"':
+			quoted = !quoted
+		case c == sep && !quoted:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func cutUnescaped(s string, sep byte) (string, string, bool) {
+	escaped := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case escaped:
+			escaped = false
+		case s[i] == '\\':
+			escaped = true
+		case s[i] == sep:
+			return s[:i], s[i+1:], true
+		}
+	}
+	return s, "", false
+}
+
+var lineUnescaper = strings.NewReplacer(`\,`, ",", `\=`, "=", `\ `, " ", `\\`, `\`)
+
+func unescapeLine(s string) string {
+	return lineUnescaper.Replace(s)
+}
@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLine(t *testing.T) {
+	now := time.Date(2026, time.March, 10, 8, 0, 0, 0, time.UTC)
+
+	for _, test := range []struct {
+		name      string
+		line      string
+		precision string
+		want      LinePoint
+	}{
+		{"fields and tags", "weather,site=kebun,device=d1 temp=21.5,hum=60i,on=t,off=F,count=7u 1700000000000000000", "ns", LinePoint{
+			Measurement: "weather",
+			Tags:        map[string]string{"site": "kebun", "device": "d1"},
+			Fields:      map[string]float64{"temp": 21.5, "hum": 60, "on": 1, "off": 0, "count": 7},
+			Time:        time.Unix(0, 1700000000000000000),
+		}},
+		{"no timestamp", "weather temp=-3e2", "ns", LinePoint{
+			Measurement: "weather",
+			Tags:        map[string]string{},
+			Fields:      map[string]float64{"temp": -300},
+			Time:        now,
+		}},
+		{"precision", "weather temp=1 1700000000", "s", LinePoint{
+			Measurement: "weather",
+			Tags:        map[string]string{},
+			Fields:      map[string]float64{"temp": 1},
+			Time:        time.Unix(1700000000, 0),
+		}},
+		{"escapes", `my\ weather,site\,name=a\=b\ c temp\ out=2`, "ms", LinePoint{
+			Measurement: "my weather",
+			Tags:        map[string]string{"site,name": "a=b c"},
+			Fields:      map[string]float64{"temp out": 2},
+			Time:        now,
+		}},
+		{"string fields are left out", `weather note="a, b=c d",temp=4`, "ns", LinePoint{
+			Measurement: "weather",
+			Tags:        map[string]string{},
+			Fields:      map[string]float64{"temp": 4},
+			Time:        now,
+		}},
+	} {
+		got, err := ParseLine(test.line, test.precision, now)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if !reflect.DeepEqual(*got, test.want) {
+			t.Fatalf("%s: got %+v, want %+v", test.name, *got, test.want)
+		}
+	}
+}
+
+func TestParseLineRejects(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		line      string
+		precision string
+		err       string
+	}{
+		{"nan", "weather v=nan", "ns", `field v: invalid number "nan"`},
+		{"NaN", "weather v=1,w=NaN", "ns", `field w: invalid number "NaN"`},
+		{"inf", "weather v=inf", "ns", `field v: invalid number "inf"`},
+		{"negative infinity", "weather v=-Infinity", "ns", `field v: invalid number "-Infinity"`},
+		{"overflow", "weather v=1e400", "ns", `field v: invalid number "1e400"`},
+		{"not a number", "weather v=abc", "ns", `field v: invalid number "abc"`},
+		{"integer", "weather v=1.5i", "ns", `field v: invalid integer "1.5i"`},
+		{"unsigned", "weather v=-1u", "ns", `field v: invalid unsigned integer "-1u"`},
+		{"unterminated string", `weather v="abc`, "ns", "unterminated string"},
+		{"no fields", "weather", "ns", "missing fields"},
+		{"empty field", "weather v=", "ns", `invalid field "v="`},
+		{"empty tag", "weather,site= v=1", "ns", `invalid tag "site="`},
+		{"no measurement", ",site=a v=1", "ns", "missing measurement"},
+		{"trailing text", "weather v=1 1700000000 extra", "ns", "unexpected text after timestamp"},
+		{"bad timestamp", "weather v=1 soon", "ns", `invalid timestamp "soon"`},
+		{"timestamp overflow", "weather v=1 9223372036854775807", "s", "out of range for precision s"},
+		{"precision", "weather v=1", "m", `unknown precision "m"`},
+	} {
+		_, err := ParseLine(test.line, test.precision, time.Now())
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: err = %v, want %q", test.name, err, test.err)
+		}
+	}
+}
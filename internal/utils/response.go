@@ -54,3 +54,12 @@ func ErrorResponse(code int, message string) fiber.Map {
 		"message": message,
 	}
 }
+
+func ErrorResponseWithData(code int, message string, data interface{}) fiber.Map {
+	return fiber.Map{
+		"code":    code,
+		"status":  false,
+		"message": message,
+		"result":  data,
+	}
+}
@@ -0,0 +1,180 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// unitDefinition maps a unit onto the base unit of its dimension:
+// base = value*scale + offset.
+type unitDefinition struct {
+	dimension  string
+	scale      float64
+	offset     float64
+	prefixable bool
+}
+
+var units = map[string]unitDefinition{
+	// temperature, base kelvin
+	"K":  {dimension: "temperature", scale: 1},
+	"°C": {dimension: "temperature", scale: 1, offset: 273.15},
+	"°F": {dimension: "temperature", scale: 5.0 / 9.0, offset: 459.67 * 5.0 / 9.0},
+
+	// length, base metre
+	"m":  {dimension: "length", scale: 1, prefixable: true},
+	"in": {dimension: "length", scale: 0.0254},
+	"ft": {dimension: "length", scale: 0.3048},
+	"yd": {dimension: "length", scale: 0.9144},
+	"mi": {dimension: "length", scale: 1609.344},
+
+	// mass, base gram
+	"g":  {dimension: "mass", scale: 1, prefixable: true},
+	"lb": {dimension: "mass", scale: 453.59237},
+	"oz": {dimension: "mass", scale: 28.349523125},
+
+	// time, base second
+	"s":   {dimension: "time", scale: 1, prefixable: true},
+	"min": {dimension: "time", scale: 60},
+	"h":   {dimension: "time", scale: 3600},
+	"d":   {dimension: "time", scale: 86400},
+
+	// volume, base litre
+	"L":   {dimension: "volume", scale: 1, prefixable: true},
+	"m³":  {dimension: "volume", scale: 1000},
+	"gal": {dimension: "volume", scale: 3.785411784},
+
+	// pressure, base pascal
+	"Pa":   {dimension: "pressure", scale: 1, prefixable: true},
+	"bar":  {dimension: "pressure", scale: 1e5, prefixable: true},
+	"atm":  {dimension: "pressure", scale: 101325},
+	"psi":  {dimension: "pressure", scale: 6894.757293168},
+	"mmHg": {dimension: "pressure", scale: 133.322387415},
+
+	// speed, base metre per second
+	"km/h": {dimension: "length/time", scale: 1000.0 / 3600.0},
+	"mph":  {dimension: "length/time", scale: 0.44704},
+	"kn":   {dimension: "length/time", scale: 1852.0 / 3600.0},
+
+	// electrical and energy
+	"V":  {dimension: "voltage", scale: 1, prefixable: true},
+	"A":  {dimension: "current", scale: 1, prefixable: true},
+	"W":  {dimension: "power", scale: 1, prefixable: true},
+	"J":  {dimension: "energy", scale: 1, prefixable: true},
+	"Wh": {dimension: "energy", scale: 3600, prefixable: true},
+	"S":  {dimension: "conductance", scale: 1, prefixable: true},
+
+	// light
+	"lx": {dimension: "illuminance", scale: 1, prefixable: true},
+
+	// dimensionless ratios, base fraction
+	"1":   {dimension: "ratio", scale: 1},
+	"%":   {dimension: "ratio", scale: 1e-2},
+	"ppm": {dimension: "ratio", scale: 1e-6},
+	"ppb": {dimension: "ratio", scale: 1e-9},
+}
+
+var unitAliases = map[string]string{
+	"C": "°C", "celsius": "°C", "celcius": "°C", "degC": "°C", "℃": "°C",
+	"F": "°F", "fahrenheit": "°F", "degF": "°F", "℉": "°F",
+	"kelvin": "K",
+	"inch":   "in", "inches": "in",
+	"l": "L", "liter": "L", "litre": "L",
+	"m3":      "m³",
+	"percent": "%",
+	"kph":     "km/h",
+	"lux":     "lx",
+	"hr":      "h", "sec": "s",
+}
+
+var siPrefixes = map[string]float64{
+	"Y": 1e24, "Z": 1e21, "E": 1e18, "P": 1e15, "T": 1e12, "G": 1e9, "M": 1e6,
+	"k": 1e3, "h": 1e2, "da": 1e1, "d": 1e-1, "c": 1e-2, "m": 1e-3,
+	"µ": 1e-6, "μ": 1e-6, "u": 1e-6, "n": 1e-9, "p": 1e-12, "f": 1e-15,
+}
+
+// UnitConversion is an affine conversion between two units: to = from*Scale + Offset.
+type UnitConversion struct {
+	From   string
+	To     string
+	Scale  float64
+	Offset float64
+}
+
+func (c UnitConversion) Apply(value float64) float64 {
+	return value*c.Scale + c.Offset
+}
+
+// NewUnitConversion resolves a conversion between two units of the same
+// dimension. Units may carry SI prefixes ("mm", "kPa") and may be simple
+// quotients of linear units ("mS/cm", "L/min").
+func NewUnitConversion(from, to string) (UnitConversion, error) {
+	source, err := resolveUnit(from)
+	if err != nil {
+		return UnitConversion{}, err
+	}
+	target, err := resolveUnit(to)
+	if err != nil {
+		return UnitConversion{}, err
+	}
+	if source.dimension != target.dimension {
+		return UnitConversion{}, fmt.Errorf("cannot convert %s (%s) to %s (%s)", from, source.dimension, to, target.dimension)
+	}
+
+	return UnitConversion{
+		From:   from,
+		To:     to,
+		Scale:  source.scale / target.scale,
+		Offset: (source.offset - target.offset) / target.scale,
+	}, nil
+}
+
+// ConvertUnit converts a single value between two units.
+func ConvertUnit(value float64, from, to string) (float64, error) {
+	conversion, err := NewUnitConversion(from, to)
+	if err != nil {
+		return 0, err
+	}
+	return conversion.Apply(value), nil
+}
+
+func resolveUnit(unit string) (unitDefinition, error) {
+	unit = strings.TrimSpace(unit)
+	if alias, ok := unitAliases[unit]; ok {
+		unit = alias
+	}
+	if definition, ok := units[unit]; ok {
+		return definition, nil
+	}
+
+	if numerator, denominator, ok := strings.Cut(unit, "/"); ok {
+		top, err := resolveUnit(numerator)
+		if err != nil {
+			return unitDefinition{}, err
+		}
+		bottom, err := resolveUnit(denominator)
+		if err != nil {
+			return unitDefinition{}, err
+		}
+		if top.offset != 0 || bottom.offset != 0 {
+			return unitDefinition{}, fmt.Errorf("unit %q combines an affine unit", unit)
+		}
+		return unitDefinition{
+			dimension: top.dimension + "/" + bottom.dimension,
+			scale:     top.scale / bottom.scale,
+		}, nil
+	}
+
+	for prefix, factor := range siPrefixes {
+		base, ok := strings.CutPrefix(unit, prefix)
+		if !ok || base == "" {
+			continue
+		}
+		if definition, ok := units[base]; ok && definition.prefixable {
+			definition.scale *= factor
+			definition.prefixable = false
+			return definition, nil
+		}
+	}
+
+	return unitDefinition{}, fmt.Errorf("unknown unit %q", unit)
+}
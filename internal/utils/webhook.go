@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers of a webhook delivery.
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+)
+
+const webhookSignaturePrefix = "sha256="
+
+// SignWebhook returns the signature header value for a body sent at the
+// unix timestamp: the hex HMAC-SHA256 of "<timestamp>.<body>" keyed with
+// the webhook secret. Covering the timestamp lets receivers reject replays.
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return webhookSignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhook checks the signature and timestamp headers of a received
+// delivery. A tolerance above zero also rejects timestamps further than it
+// from now. Any mismatch is ErrInvalidSignature.
+func VerifyWebhook(secret string, timestamp string, signature string, body []byte, now time.Time, tolerance time.Duration) error {
+	sent, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: malformed timestamp", ErrInvalidSignature)
+	}
+	if tolerance > 0 {
+		age := now.Sub(time.Unix(sent, 0))
+		if age > tolerance || age < -tolerance {
+			return fmt.Errorf("%w: timestamp outside tolerance", ErrInvalidSignature)
+		}
+	}
+
+	if !strings.HasPrefix(signature, webhookSignaturePrefix) {
+		return fmt.Errorf("%w: unsupported signature scheme", ErrInvalidSignature)
+	}
+	expected := SignWebhook(secret, sent, body)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}